@@ -29,6 +29,8 @@ const (
 	ActivityPipelineTaskStatementUpdate ActivityType = "bb.pipeline.task.statement.update"
 	// ActivityPipelineTaskEarliestAllowedTimeUpdate is the type for updating pipeline task the earliest allowed time.
 	ActivityPipelineTaskEarliestAllowedTimeUpdate ActivityType = "bb.pipeline.task.general.earliest-allowed-time.update"
+	// ActivityPipelineTaskGhostControl is the type for controlling a running gh-ost migration, e.g. throttling it.
+	ActivityPipelineTaskGhostControl ActivityType = "bb.pipeline.task.ghost.control"
 
 	// Member related.
 
@@ -150,6 +152,16 @@ type ActivityPipelineTaskEarliestAllowedTimeUpdatePayload struct {
 	TaskName  string `json:"taskName"`
 }
 
+// ActivityPipelineTaskGhostControlPayload is the API message payloads for controlling a running gh-ost migration.
+type ActivityPipelineTaskGhostControlPayload struct {
+	TaskID int `json:"taskId"`
+	// Command is the gh-ost interactive command, e.g. throttle or chunk-size=1000.
+	Command string `json:"command"`
+	// Used by inbox to display info without paying the join cost
+	IssueName string `json:"issueName"`
+	TaskName  string `json:"taskName"`
+}
+
 // ActivityMemberCreatePayload is the API message payloads for creating members.
 type ActivityMemberCreatePayload struct {
 	PrincipalID    int          `json:"principalId"`
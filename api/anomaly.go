@@ -20,6 +20,10 @@ const (
 	AnomalyDatabaseConnection AnomalyType = "bb.anomaly.database.connection"
 	// AnomalyDatabaseSchemaDrift is the anomaly type for database schema drifts.
 	AnomalyDatabaseSchemaDrift AnomalyType = "bb.anomaly.database.schema.drift"
+	// AnomalyDatabaseSchemaInconsistency is the anomaly type for tenant databases whose schema differs from the majority of their peers.
+	AnomalyDatabaseSchemaInconsistency AnomalyType = "bb.anomaly.database.schema.inconsistency"
+	// AnomalyDatabaseMigrationChecksumMismatch is the anomaly type for applied migration files modified in the repository.
+	AnomalyDatabaseMigrationChecksumMismatch AnomalyType = "bb.anomaly.database.migration.checksum-mismatch"
 )
 
 // AnomalySeverity is the severity of anomaly.
@@ -41,6 +45,10 @@ func AnomalySeverityFromType(anomalyType AnomalyType) AnomalySeverity {
 		return AnomalySeverityMedium
 	case AnomalyDatabaseBackupMissing:
 		return AnomalySeverityHigh
+	case AnomalyDatabaseSchemaInconsistency:
+		return AnomalySeverityHigh
+	case AnomalyDatabaseMigrationChecksumMismatch:
+		return AnomalySeverityHigh
 	case AnomalyInstanceConnection:
 	case AnomalyInstanceMigrationSchema:
 	case AnomalyDatabaseConnection:
@@ -84,6 +92,41 @@ type AnomalyDatabaseSchemaDriftPayload struct {
 	Expect string `json:"expect,omitempty"`
 	// The actual schema dumped from the database
 	Actual string `json:"actual,omitempty"`
+	// The URL of the pull request committing the actual schema to the schema file in the repository for VCS workflow projects
+	PullRequestURL string `json:"pullRequestUrl,omitempty"`
+}
+
+// AnomalyDatabaseSchemaInconsistencyPayload is the API message for tenant database schema inconsistency payloads.
+type AnomalyDatabaseSchemaInconsistencyPayload struct {
+	ProjectID int `json:"projectId,omitempty"`
+	// The latest schema version of the database
+	Version string `json:"version,omitempty"`
+	// The peer tenant database having the majority schema
+	ExpectDatabaseID   int    `json:"expectDatabaseId,omitempty"`
+	ExpectDatabaseName string `json:"expectDatabaseName,omitempty"`
+	// The latest schema version of the peer tenant database having the majority schema
+	ExpectVersion string `json:"expectVersion,omitempty"`
+	// The unified diff from the majority schema to the actual schema of the database
+	Diff string `json:"diff,omitempty"`
+}
+
+// MigrationChecksumMismatch is the applied migration version whose migration file has been modified in the repository.
+type MigrationChecksumMismatch struct {
+	Version string `json:"version,omitempty"`
+	// The migration file in the repository
+	File string `json:"file,omitempty"`
+	// The commit modifying the migration file
+	CommitID  string `json:"commitId,omitempty"`
+	CommitURL string `json:"commitUrl,omitempty"`
+	// The checksum of the statement applied, stored in the migration history
+	Expect string `json:"expect,omitempty"`
+	// The checksum of the migration file in the repository
+	Actual string `json:"actual,omitempty"`
+}
+
+// AnomalyDatabaseMigrationChecksumMismatchPayload is the API message for migration checksum mismatch payloads.
+type AnomalyDatabaseMigrationChecksumMismatchPayload struct {
+	MismatchList []*MigrationChecksumMismatch `json:"mismatchList,omitempty"`
 }
 
 // Anomaly is the API message for an anomaly.
@@ -51,15 +51,18 @@ type LabelSelector struct {
 }
 
 // OperatorType is the type of label selector requirement operator.
-// Valid operators are In, Exists.
-// Note: NotIn and DoesNotExist are not supported initially.
+// Valid operators are In, NotIn, Exists and DoesNotExist.
 type OperatorType string
 
 const (
 	// InOperatorType is the operator type for In.
 	InOperatorType OperatorType = "In"
+	// NotInOperatorType is the operator type for NotIn.
+	NotInOperatorType OperatorType = "NotIn"
 	// ExistsOperatorType is the operator type for Exists.
 	ExistsOperatorType OperatorType = "Exists"
+	// DoesNotExistOperatorType is the operator type for DoesNotExist.
+	DoesNotExistOperatorType OperatorType = "DoesNotExist"
 )
 
 // LabelSelectorRequirement is the API message for label selector.
@@ -99,6 +102,36 @@ type DeploymentConfigUpsert struct {
 	Payload string `jsonapi:"attr,payload"`
 }
 
+// DeploymentSchedulePreview is the API message for previewing a deployment schedule against the databases in a project.
+type DeploymentSchedulePreview struct {
+	// Payload is a json serialization of DeploymentSchedule.
+	// If it's empty, the saved deployment configuration of the project is used.
+	Payload string `jsonapi:"attr,payload"`
+	// DatabaseName is the base database name used with the project database name template.
+	// If it's empty, databases are not filtered by name.
+	DatabaseName string `jsonapi:"attr,databaseName"`
+}
+
+// DeploymentPreview is the API message for the resolved stages of a deployment schedule.
+type DeploymentPreview struct {
+	// StageList has a stage for every deployment in the schedule, including the ones with no matched database.
+	StageList []*DeploymentPreviewStage `jsonapi:"attr,stageList"`
+	// UnmatchedDatabaseList is the list of databases matching no deployment.
+	UnmatchedDatabaseList []*DeploymentPreviewDatabase `jsonapi:"attr,unmatchedDatabaseList"`
+}
+
+// DeploymentPreviewStage is the API message for a stage in the deployment preview.
+type DeploymentPreviewStage struct {
+	Name         string                       `json:"name"`
+	DatabaseList []*DeploymentPreviewDatabase `json:"databaseList"`
+}
+
+// DeploymentPreviewDatabase is the API message for a database in the deployment preview.
+type DeploymentPreviewDatabase struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
 // ValidateAndGetDeploymentSchedule validates and returns the deployment schedule.
 // Note: this validation only checks whether the payloads is a valid json, however, invalid field name errors are ignored.
 func ValidateAndGetDeploymentSchedule(payload string) (*DeploymentSchedule, error) {
@@ -114,11 +147,11 @@ func ValidateAndGetDeploymentSchedule(payload string) (*DeploymentSchedule, erro
 		hasEnv := false
 		for _, e := range d.Spec.Selector.MatchExpressions {
 			switch e.Operator {
-			case InOperatorType:
+			case InOperatorType, NotInOperatorType:
 				if len(e.Values) == 0 {
 					return nil, common.Errorf(common.Invalid, "expression key %q with %q operator should have at least one value", e.Key, e.Operator)
 				}
-			case ExistsOperatorType:
+			case ExistsOperatorType, DoesNotExistOperatorType:
 				if len(e.Values) > 0 {
 					return nil, common.Errorf(common.Invalid, "expression key %q with %q operator shouldn't have values", e.Key, e.Operator)
 				}
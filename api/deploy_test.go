@@ -77,6 +77,16 @@ func TestGetDeploymentSchedule(t *testing.T) {
 			`{"deployments":[{"name":"deployment1","spec":{"selector":{"matchExpressions":[{"key":"bb.environment","operator":"In","values":["prod"]},{"key":"location","operator":"Exists","values":["us-central1","europe-west1"]}]}}}]}`,
 			nil,
 			"operator shouldn't have values",
+		}, {
+			"notInOperatorWithNoValue",
+			`{"deployments":[{"name":"deployment1","spec":{"selector":{"matchExpressions":[{"key":"bb.environment","operator":"In","values":["prod"]},{"key":"bb.tenant","operator":"NotIn"}]}}}]}`,
+			nil,
+			"operator should have at least one value",
+		}, {
+			"doesNotExistOperatorWithValues",
+			`{"deployments":[{"name":"deployment1","spec":{"selector":{"matchExpressions":[{"key":"bb.environment","operator":"In","values":["prod"]},{"key":"location","operator":"DoesNotExist","values":["us-central1"]}]}}}]}`,
+			nil,
+			"operator shouldn't have values",
 		}, {
 			"invalidOperator",
 			`{"deployments":[{"name":"deployment1","spec":{"selector":{"matchExpressions":[{"key":"bb.environment","operator":"In","values":["prod"]},{"key":"location","operator":"invalid"}]}}}]}`,
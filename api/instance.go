@@ -165,3 +165,18 @@ type MigrationHistory struct {
 	IssueID string `jsonapi:"attr,issueId"`
 	Payload string `jsonapi:"attr,payload"`
 }
+
+// MigrationRollback is the API message for rolling back a database to a migration version.
+// The down statements of the later migrations are applied in the reverse order.
+type MigrationRollback struct {
+	// Domain specific fields
+	Version    string `jsonapi:"attr,version"`
+	AssigneeID int    `jsonapi:"attr,assigneeId"`
+}
+
+// MigrationHistoryImport is the API message for importing the migration history recorded by other migration tools,
+// e.g. Flyway, Liquibase and golang-migrate, so that the version ordering continues from the imported versions.
+type MigrationHistoryImport struct {
+	// Domain specific fields
+	Source db.MigrationSource `jsonapi:"attr,source"`
+}
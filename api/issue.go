@@ -149,6 +149,15 @@ type UpdateSchemaDetail struct {
 	Statement string `json:"statement"`
 	// EarliestAllowedTs the earliest execution time of the change at system local Unix timestamp in seconds.
 	EarliestAllowedTs int64 `jsonapi:"attr,earliestAllowedTs"`
+	// BatchConfig enables the batched execution for the data update, only supported for MySQL, TiDB and Postgres.
+	BatchConfig *DataUpdateBatchConfig `json:"batchConfig,omitempty"`
+	// RollbackEnabled enables the pre-change backup for the data update, only supported for MySQL, TiDB and Postgres.
+	RollbackEnabled bool `json:"rollbackEnabled,omitempty"`
+	// RollbackStatement is the statement rolling back the schema migration, which is generated if the migration is purely additive.
+	RollbackStatement string `json:"rollbackStatement,omitempty"`
+	// DesiredSchema is the full desired schema of the database for the declarative (MIGRATE_SDL) migration.
+	// The statement is generated by diffing it against the live schema.
+	DesiredSchema string `json:"desiredSchema,omitempty"`
 }
 
 // UpdateSchemaContext is the issue create context for updating database schema.
@@ -2,6 +2,8 @@ package api
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 )
 
 const (
@@ -9,15 +11,37 @@ const (
 	EnvironmentKeyName string = "bb.environment"
 
 	// DatabaseLabelSizeMax is the maximum size of database labels.
-	DatabaseLabelSizeMax = 4
+	DatabaseLabelSizeMax = 8
 	labelLengthMax       = 63
 
 	// LocationLabelKey is the label key for location.
 	LocationLabelKey = "bb.location"
 	// TenantLabelKey is the label key for tenant.
 	TenantLabelKey = "bb.tenant"
+
+	// reservedLabelKeyPrefix is the prefix of built-in label keys, which cannot be used by custom label keys.
+	reservedLabelKeyPrefix = "bb."
 )
 
+// labelKeyRegexp is the format of custom label keys, e.g. region, customer-size.
+// We only allow lower case letters so that keys map to distinct tokens in database name templates.
+var labelKeyRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9_-]*[a-z0-9])?$`)
+
+// GetLabelToken returns the database name template token for the label key, e.g. {{LOCATION}} for bb.location and {{REGION}} for region.
+func GetLabelToken(key string) string {
+	return fmt.Sprintf("{{%s}}", strings.ToUpper(strings.TrimPrefix(key, reservedLabelKeyPrefix)))
+}
+
+// isReservedLabelToken returns true if the token is used by the database name or the built-in label keys.
+func isReservedLabelToken(token string) bool {
+	for _, reserved := range []string{DBNameToken, GetLabelToken(EnvironmentKeyName), GetLabelToken(LocationLabelKey), GetLabelToken(TenantLabelKey)} {
+		if token == reserved {
+			return true
+		}
+	}
+	return false
+}
+
 // LabelKey is the available key for labels.
 type LabelKey struct {
 	ID int `jsonapi:"primary,labelKey"`
@@ -42,6 +66,32 @@ type LabelKeyFind struct {
 	RowStatus *RowStatus
 }
 
+// LabelKeyCreate is the API message for creating a label key.
+type LabelKeyCreate struct {
+	// Standard fields
+	// Value is assigned from the jwt subject field passed by the client.
+	CreatorID int
+
+	// Domain specific fields
+	Key       string   `jsonapi:"attr,key"`
+	ValueList []string `jsonapi:"attr,valueList"`
+}
+
+// Validate validates the sanity of create values.
+func (create *LabelKeyCreate) Validate() error {
+	if strings.HasPrefix(create.Key, reservedLabelKeyPrefix) {
+		return fmt.Errorf("label key %q cannot use the reserved prefix %q", create.Key, reservedLabelKeyPrefix)
+	}
+	if len(create.Key) > labelLengthMax || !labelKeyRegexp.MatchString(create.Key) {
+		return fmt.Errorf("label key %q has a maximum length of %v characters and must consist of lower case alphanumeric characters, '-' or '_', and must start and end with an alphanumeric character", create.Key, labelLengthMax)
+	}
+	// The token of the custom label key must not collide with the built-in tokens in database name templates, e.g. location for {{LOCATION}}.
+	if token := GetLabelToken(create.Key); isReservedLabelToken(token) {
+		return fmt.Errorf("label key %q is reserved since its token %s is a built-in token", create.Key, token)
+	}
+	return validateLabelValueList(create.ValueList)
+}
+
 // LabelKeyPatch is the message to patch a label key.
 type LabelKeyPatch struct {
 	ID int
@@ -59,7 +109,11 @@ type LabelKeyPatch struct {
 
 // Validate validates the sanity of patch values.
 func (patch *LabelKeyPatch) Validate() error {
-	for _, v := range patch.ValueList {
+	return validateLabelValueList(patch.ValueList)
+}
+
+func validateLabelValueList(valueList []string) error {
+	for _, v := range valueList {
 		if len(v) == 0 || len(v) > labelLengthMax {
 			return fmt.Errorf("label value has a maximum length of %v characters and cannot be empty", labelLengthMax)
 		}
@@ -34,3 +34,64 @@ func TestUnmarshal(t *testing.T) {
 		require.Equal(t, values[i], label.Value)
 	}
 }
+
+func TestLabelKeyCreateValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		create  *LabelKeyCreate
+		errPart string
+	}{
+		{
+			"ok",
+			&LabelKeyCreate{Key: "customer-size", ValueList: []string{"small", "large"}},
+			"",
+		}, {
+			"reservedPrefix",
+			&LabelKeyCreate{Key: "bb.region"},
+			"reserved prefix",
+		}, {
+			"upperCase",
+			&LabelKeyCreate{Key: "Region"},
+			"lower case alphanumeric characters",
+		}, {
+			"emptyKey",
+			&LabelKeyCreate{Key: ""},
+			"lower case alphanumeric characters",
+		}, {
+			"reservedLocationToken",
+			&LabelKeyCreate{Key: "location"},
+			"built-in token",
+		}, {
+			"reservedTenantToken",
+			&LabelKeyCreate{Key: "tenant"},
+			"built-in token",
+		}, {
+			"reservedDatabaseNameToken",
+			&LabelKeyCreate{Key: "db_name"},
+			"built-in token",
+		}, {
+			"reservedEnvironmentToken",
+			&LabelKeyCreate{Key: "environment"},
+			"built-in token",
+		}, {
+			"emptyValue",
+			&LabelKeyCreate{Key: "tier", ValueList: []string{""}},
+			"cannot be empty",
+		},
+	}
+
+	for _, test := range tests {
+		err := test.create.Validate()
+		if test.errPart == "" {
+			require.NoError(t, err, test.name)
+		} else {
+			require.Contains(t, err.Error(), test.errPart, test.name)
+		}
+	}
+}
+
+func TestGetLabelToken(t *testing.T) {
+	require.Equal(t, LocationToken, GetLabelToken(LocationLabelKey))
+	require.Equal(t, TenantToken, GetLabelToken(TenantLabelKey))
+	require.Equal(t, "{{CUSTOMER-SIZE}}", GetLabelToken("customer-size"))
+}
@@ -0,0 +1,28 @@
+package api
+
+// ProjectMigrationStatus is the API message for the status of the migration files in the VCS repository linked to
+// a project against the migration versions applied to the databases in the project.
+type ProjectMigrationStatus struct {
+	ProjectID    int                        `jsonapi:"attr,projectId"`
+	DatabaseList []*DatabaseMigrationStatus `jsonapi:"attr,databaseList"`
+}
+
+// DatabaseMigrationStatus is the API message for the status of the migration files of a database.
+// The versions in the lists are sorted in ascending order.
+type DatabaseMigrationStatus struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	EnvironmentName string `json:"environmentName"`
+	// Branch is the branch holding the migration files applied to the database.
+	Branch string `json:"branch"`
+	// LatestVersion is the latest migration version applied to the database since the last baseline.
+	LatestVersion string `json:"latestVersion"`
+	// AppliedVersionList is the list of versions of the migration files applied to the database.
+	AppliedVersionList []string `json:"appliedVersionList"`
+	// PendingVersionList is the list of versions of the migration files newer than the latest version, which are to be applied.
+	PendingVersionList []string `json:"pendingVersionList"`
+	// OutOfOrderVersionList is the list of versions of the migration files older than the latest version but not applied.
+	OutOfOrderVersionList []string `json:"outOfOrderVersionList"`
+	// Error is the reason why the status of the database cannot be fetched, e.g. connection failures.
+	Error string `json:"error"`
+}
@@ -3,8 +3,10 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/bytebase/bytebase/plugin/advisor"
+	"github.com/bytebase/bytebase/plugin/db"
 )
 
 // PolicyType is the type or name of a policy.
@@ -26,6 +28,8 @@ const (
 	PolicyTypeBackupPlan PolicyType = "bb.policy.backup-plan"
 	// PolicyTypeSchemaReview is the schema review policy type.
 	PolicyTypeSchemaReview PolicyType = "bb.policy.schema-review"
+	// PolicyTypeTaskRetry is the task retry policy type.
+	PolicyTypeTaskRetry PolicyType = "bb.policy.task-retry"
 
 	// PipelineApprovalValueManualNever means the pipeline will automatically be approved without user intervention.
 	PipelineApprovalValueManualNever PipelineApprovalValue = "MANUAL_APPROVAL_NEVER"
@@ -46,7 +50,22 @@ var (
 		PolicyTypePipelineApproval: true,
 		PolicyTypeBackupPlan:       true,
 		PolicyTypeSchemaReview:     true,
+		PolicyTypeTaskRetry:        true,
 	}
+
+	// DefaultRetryableErrorClassList is the list of error classes retried for engines not specified in the task retry policy.
+	DefaultRetryableErrorClassList = []db.ErrorClass{
+		db.ErrorClassLockTimeout,
+		db.ErrorClassDeadlock,
+		db.ErrorClassSerialization,
+	}
+)
+
+const (
+	// TaskRetryPolicyMaxAttemptsLimit is the upper limit of the max attempts in the task retry policy.
+	TaskRetryPolicyMaxAttemptsLimit = 10
+	// TaskRetryPolicyBackoffSecondsLimit is the upper limit of the backoff seconds in the task retry policy.
+	TaskRetryPolicyBackoffSecondsLimit = 3600
 )
 
 // Policy is the API message for a policy.
@@ -169,6 +188,58 @@ func UnmarshalSQLReviewPolicy(payload string) (*advisor.SQLReviewPolicy, error)
 	return &sr, nil
 }
 
+// TaskRetryPolicy is the policy configuration for automatically retrying failed tasks.
+type TaskRetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a task, including the first run.
+	// Automatic retry is disabled if it's less than 2.
+	MaxAttempts int `json:"maxAttempts"`
+	// BackoffSeconds is the delay before the first retry, and the delay doubles for each subsequent retry.
+	BackoffSeconds int `json:"backoffSeconds"`
+	// RetryableErrorClassList is the retryable error classes keyed by the database engine.
+	// Engines not in the map use DefaultRetryableErrorClassList.
+	RetryableErrorClassList map[db.Type][]db.ErrorClass `json:"retryableErrorClassList,omitempty"`
+}
+
+func (tr TaskRetryPolicy) String() (string, error) {
+	s, err := json.Marshal(tr)
+	if err != nil {
+		return "", err
+	}
+	return string(s), nil
+}
+
+// IsRetryable returns whether the error class is retryable for the engine.
+func (tr TaskRetryPolicy) IsRetryable(engine db.Type, errorClass db.ErrorClass) bool {
+	classList, ok := tr.RetryableErrorClassList[engine]
+	if !ok {
+		classList = DefaultRetryableErrorClassList
+	}
+	for _, class := range classList {
+		if class == errorClass {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBackoff returns the delay before the retry following the given number of attempts.
+func (tr TaskRetryPolicy) GetBackoff(attempts int) time.Duration {
+	backoff := time.Duration(tr.BackoffSeconds) * time.Second
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+	}
+	return backoff
+}
+
+// UnmarshalTaskRetryPolicy will unmarshal payload to task retry policy.
+func UnmarshalTaskRetryPolicy(payload string) (*TaskRetryPolicy, error) {
+	var tr TaskRetryPolicy
+	if err := json.Unmarshal([]byte(payload), &tr); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task retry policy %q: %q", payload, err)
+	}
+	return &tr, nil
+}
+
 // ValidatePolicy will validate the policy type and payload values.
 func ValidatePolicy(pType PolicyType, payload string) error {
 	if !PolicyTypes[pType] {
@@ -203,6 +274,26 @@ func ValidatePolicy(pType PolicyType, payload string) error {
 		if err := sr.Validate(); err != nil {
 			return fmt.Errorf("invalid schema review policy: %w", err)
 		}
+	case PolicyTypeTaskRetry:
+		tr, err := UnmarshalTaskRetryPolicy(payload)
+		if err != nil {
+			return err
+		}
+		if tr.MaxAttempts < 0 || tr.MaxAttempts > TaskRetryPolicyMaxAttemptsLimit {
+			return fmt.Errorf("invalid task retry policy max attempts %d, should be between 0 and %d", tr.MaxAttempts, TaskRetryPolicyMaxAttemptsLimit)
+		}
+		if tr.BackoffSeconds < 0 || tr.BackoffSeconds > TaskRetryPolicyBackoffSecondsLimit {
+			return fmt.Errorf("invalid task retry policy backoff seconds %d, should be between 0 and %d", tr.BackoffSeconds, TaskRetryPolicyBackoffSecondsLimit)
+		}
+		for engine, classList := range tr.RetryableErrorClassList {
+			for _, class := range classList {
+				switch class {
+				case db.ErrorClassLockTimeout, db.ErrorClassDeadlock, db.ErrorClassSerialization:
+				default:
+					return fmt.Errorf("invalid task retry policy error class %q for engine %q", class, engine)
+				}
+			}
+		}
 	}
 	return nil
 }
@@ -222,6 +313,10 @@ func GetDefaultPolicy(pType PolicyType) (string, error) {
 	case PolicyTypeSchemaReview:
 		// TODO(ed): we may need to define the default schema review policy payload in the PR of policy data migration.
 		return "{}", nil
+	case PolicyTypeTaskRetry:
+		return TaskRetryPolicy{
+			MaxAttempts: 0,
+		}.String()
 	}
 	return "", nil
 }
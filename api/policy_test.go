@@ -0,0 +1,50 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+func TestValidateTaskRetryPolicy(t *testing.T) {
+	tests := []struct {
+		payload string
+		wantErr bool
+	}{
+		{`{"maxAttempts":3,"backoffSeconds":10}`, false},
+		{`{"maxAttempts":3,"backoffSeconds":10,"retryableErrorClassList":{"MYSQL":["DEADLOCK","LOCK_TIMEOUT"]}}`, false},
+		{`{"maxAttempts":-1}`, true},
+		{`{"maxAttempts":11}`, true},
+		{`{"maxAttempts":3,"backoffSeconds":3601}`, true},
+		{`{"maxAttempts":3,"retryableErrorClassList":{"MYSQL":["SYNTAX"]}}`, true},
+	}
+	for _, test := range tests {
+		err := ValidatePolicy(PolicyTypeTaskRetry, test.payload)
+		if test.wantErr {
+			require.Error(t, err, test.payload)
+		} else {
+			require.NoError(t, err, test.payload)
+		}
+	}
+}
+
+func TestTaskRetryPolicy(t *testing.T) {
+	policy := TaskRetryPolicy{
+		MaxAttempts:    3,
+		BackoffSeconds: 10,
+		RetryableErrorClassList: map[db.Type][]db.ErrorClass{
+			db.MySQL: {db.ErrorClassDeadlock},
+		},
+	}
+	require.True(t, policy.IsRetryable(db.MySQL, db.ErrorClassDeadlock))
+	require.False(t, policy.IsRetryable(db.MySQL, db.ErrorClassLockTimeout))
+	require.True(t, policy.IsRetryable(db.Postgres, db.ErrorClassLockTimeout))
+	require.False(t, policy.IsRetryable(db.Postgres, db.ErrorClassUnknown))
+
+	require.Equal(t, 10*time.Second, policy.GetBackoff(1))
+	require.Equal(t, 20*time.Second, policy.GetBackoff(2))
+	require.Equal(t, 40*time.Second, policy.GetBackoff(3))
+}
@@ -42,6 +42,29 @@ const (
 	TenantModeTenant ProjectTenantMode = "TENANT"
 )
 
+// ProjectSchemaChangeType is the schema change type for projects.
+type ProjectSchemaChangeType string
+
+const (
+	// ProjectSchemaChangeTypeDDL is the imperative schema change type, where the migrations are the DDL statements.
+	ProjectSchemaChangeTypeDDL ProjectSchemaChangeType = "DDL"
+	// ProjectSchemaChangeTypeSDL is the declarative (state-based) schema change type, where the migrations are the desired schemas.
+	ProjectSchemaChangeTypeSDL ProjectSchemaChangeType = "SDL"
+)
+
+// ProjectMigrationOrder is the setting of how the versions of the migration files pushed to the VCS are ordered
+// against the versions applied to the databases.
+type ProjectMigrationOrder string
+
+const (
+	// MigrationOrderStrict rejects the migration file whose version is older than the latest version applied to the databases.
+	MigrationOrderStrict ProjectMigrationOrder = "STRICT"
+	// MigrationOrderAllowOutOfOrder applies the migration file whose version is older than the latest version applied to the databases with a warning.
+	MigrationOrderAllowOutOfOrder ProjectMigrationOrder = "ALLOW_OUT_OF_ORDER"
+	// MigrationOrderIgnore doesn't compare the version of the migration file with the versions applied to the databases.
+	MigrationOrderIgnore ProjectMigrationOrder = "IGNORE"
+)
+
 // Project is the API message for a project.
 type Project struct {
 	ID int `jsonapi:"primary,project"`
@@ -66,8 +89,13 @@ type Project struct {
 	TenantMode   ProjectTenantMode   `jsonapi:"attr,tenantMode"`
 	// DBNameTemplate is only used when a project is in tenant mode.
 	// Empty value means {{DB_NAME}}.
-	DBNameTemplate string              `jsonapi:"attr,dbNameTemplate"`
-	RoleProvider   ProjectRoleProvider `jsonapi:"attr,roleProvider"`
+	DBNameTemplate   string                  `jsonapi:"attr,dbNameTemplate"`
+	RoleProvider     ProjectRoleProvider     `jsonapi:"attr,roleProvider"`
+	SchemaChangeType ProjectSchemaChangeType `jsonapi:"attr,schemaChangeType"`
+	MigrationOrder   ProjectMigrationOrder   `jsonapi:"attr,migrationOrder"`
+	// TaskRetryPolicy is the TaskRetryPolicy in json format overriding the task retry policy of the environments.
+	// Empty value means the task retry policy of the environment applies.
+	TaskRetryPolicy string `jsonapi:"attr,taskRetryPolicy"`
 }
 
 // ProjectCreate is the API message for creating a project.
@@ -77,11 +105,13 @@ type ProjectCreate struct {
 	CreatorID int
 
 	// Domain specific fields
-	Name           string              `jsonapi:"attr,name"`
-	Key            string              `jsonapi:"attr,key"`
-	TenantMode     ProjectTenantMode   `jsonapi:"attr,tenantMode"`
-	DBNameTemplate string              `jsonapi:"attr,dbNameTemplate"`
-	RoleProvider   ProjectRoleProvider `jsonapi:"attr,roleProvider"`
+	Name             string                  `jsonapi:"attr,name"`
+	Key              string                  `jsonapi:"attr,key"`
+	TenantMode       ProjectTenantMode       `jsonapi:"attr,tenantMode"`
+	DBNameTemplate   string                  `jsonapi:"attr,dbNameTemplate"`
+	RoleProvider     ProjectRoleProvider     `jsonapi:"attr,roleProvider"`
+	SchemaChangeType ProjectSchemaChangeType `jsonapi:"attr,schemaChangeType"`
+	MigrationOrder   ProjectMigrationOrder   `jsonapi:"attr,migrationOrder"`
 }
 
 // ProjectFind is the API message for finding projects.
@@ -114,10 +144,13 @@ type ProjectPatch struct {
 	UpdaterID int
 
 	// Domain specific fields
-	Name         *string              `jsonapi:"attr,name"`
-	Key          *string              `jsonapi:"attr,key"`
-	WorkflowType *ProjectWorkflowType `jsonapi:"attr,workflowType"`
-	RoleProvider *string              `jsonapi:"attr,roleProvider"`
+	Name             *string                  `jsonapi:"attr,name"`
+	Key              *string                  `jsonapi:"attr,key"`
+	WorkflowType     *ProjectWorkflowType     `jsonapi:"attr,workflowType"`
+	RoleProvider     *string                  `jsonapi:"attr,roleProvider"`
+	SchemaChangeType *ProjectSchemaChangeType `jsonapi:"attr,schemaChangeType"`
+	MigrationOrder   *ProjectMigrationOrder   `jsonapi:"attr,migrationOrder"`
+	TaskRetryPolicy  *string                  `jsonapi:"attr,taskRetryPolicy"`
 }
 
 var (
@@ -209,10 +242,22 @@ func ValidateRepositorySchemaPathTemplate(schemaPathTemplate string, tenantMode
 }
 
 // ValidateProjectDBNameTemplate validates the project database name template.
-func ValidateProjectDBNameTemplate(template string) error {
+// Besides the built-in tokens, the template can use the tokens of label keys in labelKeyList, e.g. {{REGION}} for label key region.
+func ValidateProjectDBNameTemplate(template string, labelKeyList []*LabelKey) error {
 	if template == "" {
 		return nil
 	}
+	allowedTokens := make(map[string]bool)
+	for token := range allowedProjectDBNameTemplateTokens {
+		allowedTokens[token] = true
+	}
+	for _, labelKey := range labelKeyList {
+		// The environment is not allowed because tenant databases are selected by labels across environments.
+		if labelKey.Key == EnvironmentKeyName {
+			continue
+		}
+		allowedTokens[GetLabelToken(labelKey.Key)] = true
+	}
 	tokens, _ := common.ParseTemplateTokens(template)
 	// Must contain {{DB_NAME}}
 	hasDBName := false
@@ -220,7 +265,7 @@ func ValidateProjectDBNameTemplate(template string) error {
 		if token == DBNameToken {
 			hasDBName = true
 		}
-		if _, ok := allowedProjectDBNameTemplateTokens[token]; !ok {
+		if _, ok := allowedTokens[token]; !ok {
 			return fmt.Errorf("invalid token %v in database name template", token)
 		}
 	}
@@ -276,14 +321,9 @@ func GetBaseDatabaseName(databaseName, dbNameTemplate, labelsJSON string) (strin
 	}
 	labelMap := map[string]string{}
 	for _, label := range labels {
-		switch label.Key {
-		case LocationLabelKey:
-			labelMap[LocationToken] = label.Value
-		case TenantLabelKey:
-			labelMap[TenantToken] = label.Value
-		}
+		labelMap[GetLabelToken(label.Key)] = label.Value
 	}
-	labelMap["{{DB_NAME}}"] = "(?P<NAME>.+)"
+	labelMap[DBNameToken] = "(?P<NAME>.+)"
 
 	expr, err := formatTemplateRegexp(dbNameTemplate, labelMap)
 	if err != nil {
@@ -17,6 +17,12 @@ const (
 	ProjectRoleProviderGitLabSelfHost ProjectRoleProvider = "GITLAB_SELF_HOST"
 	// ProjectRoleProviderGitHubCom indicates the role provider is the GitHub.com.
 	ProjectRoleProviderGitHubCom ProjectRoleProvider = "GITHUB_COM"
+	// ProjectRoleProviderGitHubEnterprise indicates the role provider is the GitHub Enterprise Server.
+	ProjectRoleProviderGitHubEnterprise ProjectRoleProvider = "GITHUB_ENTERPRISE"
+	// ProjectRoleProviderGitea indicates the role provider is the Gitea.
+	ProjectRoleProviderGitea ProjectRoleProvider = "GITEA"
+	// ProjectRoleProviderBitbucket indicates the role provider is the Bitbucket.
+	ProjectRoleProviderBitbucket ProjectRoleProvider = "BITBUCKET"
 )
 
 // ProjectRoleProviderPayload is the payload for role provider.
@@ -129,6 +129,52 @@ func TestValidateRepositorySchemaPathTemplate(t *testing.T) {
 	}
 }
 
+func TestParseBranchEnvironmentList(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    int
+		errPart string
+	}{
+		{
+			"Empty",
+			"",
+			0,
+			"",
+		}, {
+			"OK",
+			`[{"branchFilter":"develop","environmentId":101},{"branchFilter":"release/*","environmentId":102}]`,
+			2,
+			"",
+		}, {
+			"Malformed",
+			`{"branchFilter":"develop"}`,
+			0,
+			"malformed branch environment list",
+		}, {
+			"MissingBranchFilter",
+			`[{"environmentId":101}]`,
+			0,
+			"branch filter is required",
+		}, {
+			"MissingEnvironment",
+			`[{"branchFilter":"develop"}]`,
+			0,
+			"invalid environment ID 0",
+		},
+	}
+
+	for _, test := range tests {
+		list, err := ParseBranchEnvironmentList(test.list)
+		if test.errPart == "" {
+			require.NoError(t, err)
+			require.Len(t, list, test.want)
+		} else {
+			require.Contains(t, err.Error(), test.errPart)
+		}
+	}
+}
+
 func TestValidateProjectDBNameTemplate(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -151,11 +197,26 @@ func TestValidateProjectDBNameTemplate(t *testing.T) {
 			"DatabaseNameTokenNotExists",
 			"{{TENANT}}",
 			"must include token {{DB_NAME}}",
+		}, {
+			"customLabelKey",
+			"{{DB_NAME}}_{{REGION}}_{{CUSTOMER-SIZE}}",
+			"",
+		}, {
+			"environment",
+			"{{DB_NAME}}_{{ENVIRONMENT}}",
+			"invalid token {{ENVIRONMENT}}",
 		},
 	}
 
+	labelKeyList := []*LabelKey{
+		{Key: EnvironmentKeyName},
+		{Key: LocationLabelKey},
+		{Key: TenantLabelKey},
+		{Key: "region"},
+		{Key: "customer-size"},
+	}
 	for _, test := range tests {
-		err := ValidateProjectDBNameTemplate(test.template)
+		err := ValidateProjectDBNameTemplate(test.template, labelKeyList)
 		if test.errPart == "" {
 			require.NoError(t, err)
 		} else {
@@ -321,6 +382,14 @@ func TestGetBaseDatabaseName(t *testing.T) {
 			"db你好",
 			"",
 		},
+		{
+			"custom_label_success",
+			"db1_us-west_large",
+			"{{DB_NAME}}_{{REGION}}_{{CUSTOMER-SIZE}}",
+			"[{\"key\":\"region\",\"value\":\"us-west\"},{\"key\":\"customer-size\",\"value\":\"large\"},{\"key\":\"bb.environment\",\"value\":\"Dev\"}]",
+			"db1",
+			"",
+		},
 		{
 			"tenant_label_fail",
 			"db1_tenant123",
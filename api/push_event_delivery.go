@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+// PushEventDeliveryStatus is the status of a push event delivery.
+type PushEventDeliveryStatus string
+
+const (
+	// PushEventDeliveryRunning is the push event delivery status when the files of the push event are being processed,
+	// either by the webhook or by a replay.
+	PushEventDeliveryRunning PushEventDeliveryStatus = "RUNNING"
+	// PushEventDeliveryDone is the push event delivery status when every file of the push event has been processed.
+	PushEventDeliveryDone PushEventDeliveryStatus = "DONE"
+	// PushEventDeliveryFailed is the push event delivery status when some files of the push event failed to be processed.
+	PushEventDeliveryFailed PushEventDeliveryStatus = "FAILED"
+)
+
+func (e PushEventDeliveryStatus) String() string {
+	switch e {
+	case PushEventDeliveryRunning:
+		return "RUNNING"
+	case PushEventDeliveryDone:
+		return "DONE"
+	case PushEventDeliveryFailed:
+		return "FAILED"
+	}
+	return ""
+}
+
+// PushEventFileStatus is the outcome of processing a file of the push event.
+type PushEventFileStatus string
+
+const (
+	// PushEventFileIgnored is the push event file status when the file is not applicable for creating an issue.
+	PushEventFileIgnored PushEventFileStatus = "IGNORED"
+	// PushEventFileIssueCreated is the push event file status when an issue has been created from the file.
+	PushEventFileIssueCreated PushEventFileStatus = "ISSUE_CREATED"
+	// PushEventFileError is the push event file status when an error occurred while creating the issue from the file.
+	PushEventFileError PushEventFileStatus = "ERROR"
+)
+
+// PushEventFile is an added file of the push event along with the outcome of processing it.
+type PushEventFile struct {
+	PushEvent vcs.PushEvent `json:"pushEvent"`
+	File      string        `json:"file"`
+
+	Status PushEventFileStatus `json:"status"`
+	// Message is the reason for an ignored or failed file, or the issue creation message.
+	Message string `json:"message"`
+	IssueID int    `json:"issueId,omitempty"`
+}
+
+// PushEventDeliveryPayload is the payload of a push event delivery.
+type PushEventDeliveryPayload struct {
+	FileList []*PushEventFile `json:"fileList"`
+}
+
+// PushEventDelivery is the API message for a VCS push event received by the project.
+type PushEventDelivery struct {
+	ID int `jsonapi:"primary,pushEventDelivery"`
+
+	// Standard fields
+	CreatorID int
+	Creator   *Principal `jsonapi:"relation,creator"`
+	CreatedTs int64      `jsonapi:"attr,createdTs"`
+	UpdaterID int
+	Updater   *Principal `jsonapi:"relation,updater"`
+	UpdatedTs int64      `jsonapi:"attr,updatedTs"`
+
+	// Related fields
+	ProjectID int `jsonapi:"attr,projectId"`
+	// RepositoryID is the ID of the repository receiving the push event.
+	// The repository may have been unlinked from the project since then.
+	RepositoryID int `jsonapi:"attr,repositoryId"`
+
+	// Domain specific fields
+	Status PushEventDeliveryStatus `jsonapi:"attr,status"`
+	// RawPayload is the webhook request body sent by the VCS.
+	RawPayload string `jsonapi:"attr,rawPayload"`
+	// Payload is the JSON encoded PushEventDeliveryPayload.
+	Payload string `jsonapi:"attr,payload"`
+}
+
+// PushEventDeliveryCreate is the API message for creating a push event delivery.
+type PushEventDeliveryCreate struct {
+	// Standard fields
+	CreatorID int
+
+	// Related fields
+	ProjectID    int
+	RepositoryID int
+
+	// Domain specific fields
+	Status     PushEventDeliveryStatus
+	RawPayload string
+	Payload    string
+}
+
+// PushEventDeliveryFind is the API message for finding push event deliveries.
+type PushEventDeliveryFind struct {
+	ID *int
+
+	// Related fields
+	ProjectID *int
+
+	// Domain specific fields
+	// The list is sorted by ID in descending order, i.e. the latest delivery comes first.
+	Limit *int
+}
+
+func (find *PushEventDeliveryFind) String() string {
+	str, err := json.Marshal(*find)
+	if err != nil {
+		return err.Error()
+	}
+	return string(str)
+}
+
+// PushEventDeliveryPatch is the API message for patching a push event delivery.
+type PushEventDeliveryPatch struct {
+	ID int
+
+	// Standard fields
+	// Value is assigned from the jwt subject field passed by the client.
+	UpdaterID int
+
+	// Domain specific fields
+	Status  *PushEventDeliveryStatus
+	Payload *string
+	// ExpectedStatus is the status the delivery must have for the patch to apply, otherwise a conflict error is returned.
+	// It is used to claim the delivery so that it isn't processed concurrently.
+	ExpectedStatus *PushEventDeliveryStatus
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 )
 
 // Repository is the API message for a repository.
@@ -23,11 +24,13 @@ type Repository struct {
 	Project   *Project `jsonapi:"relation,project"`
 
 	// Domain specific fields
-	Name          string `jsonapi:"attr,name"`
-	FullPath      string `jsonapi:"attr,fullPath"`
-	WebURL        string `jsonapi:"attr,webUrl"`
-	BranchFilter  string `jsonapi:"attr,branchFilter"`
-	BaseDirectory string `jsonapi:"attr,baseDirectory"`
+	Name         string `jsonapi:"attr,name"`
+	FullPath     string `jsonapi:"attr,fullPath"`
+	WebURL       string `jsonapi:"attr,webUrl"`
+	BranchFilter string `jsonapi:"attr,branchFilter"`
+	// The JSON encoded BranchEnvironment list. If not empty, it supersedes the branch filter.
+	BranchEnvironmentList string `jsonapi:"attr,branchEnvironmentList"`
+	BaseDirectory         string `jsonapi:"attr,baseDirectory"`
 	// The file path template for matching the committed migration script.
 	FilePathTemplate string `jsonapi:"attr,filePathTemplate"`
 	// The file path template for storing the latest schema auto-generated by Bytebase after migration.
@@ -46,6 +49,35 @@ type Repository struct {
 	RefreshToken string
 }
 
+// BranchEnvironment maps the branches to an environment. A push to the branch only creates
+// issues for the databases in the environment, so that merging a branch into another one
+// promotes the same migration files to the next environment.
+type BranchEnvironment struct {
+	// BranchFilter is the branch name, which may contain wildcards, e.g. "release/*".
+	BranchFilter  string `json:"branchFilter"`
+	EnvironmentID int    `json:"environmentId"`
+}
+
+// ParseBranchEnvironmentList parses the JSON encoded BranchEnvironment list, where an empty string is an empty list.
+func ParseBranchEnvironmentList(branchEnvironmentList string) ([]*BranchEnvironment, error) {
+	var list []*BranchEnvironment
+	if branchEnvironmentList == "" {
+		return list, nil
+	}
+	if err := json.Unmarshal([]byte(branchEnvironmentList), &list); err != nil {
+		return nil, fmt.Errorf("malformed branch environment list %q, error: %w", branchEnvironmentList, err)
+	}
+	for _, branchEnvironment := range list {
+		if branchEnvironment == nil || branchEnvironment.BranchFilter == "" {
+			return nil, fmt.Errorf("branch filter is required in the branch environment list")
+		}
+		if branchEnvironment.EnvironmentID <= 0 {
+			return nil, fmt.Errorf("invalid environment ID %d for branch filter %q in the branch environment list", branchEnvironment.EnvironmentID, branchEnvironment.BranchFilter)
+		}
+	}
+	return list, nil
+}
+
 // RepositoryCreate is the API message for creating a repository.
 type RepositoryCreate struct {
 	// Standard fields
@@ -57,15 +89,17 @@ type RepositoryCreate struct {
 	ProjectID int
 
 	// Domain specific fields
-	Name               string `jsonapi:"attr,name"`
-	FullPath           string `jsonapi:"attr,fullPath"`
-	WebURL             string `jsonapi:"attr,webUrl"`
-	BranchFilter       string `jsonapi:"attr,branchFilter"`
-	BaseDirectory      string `jsonapi:"attr,baseDirectory"`
-	FilePathTemplate   string `jsonapi:"attr,filePathTemplate"`
-	SchemaPathTemplate string `jsonapi:"attr,schemaPathTemplate"`
-	SheetPathTemplate  string `jsonapi:"attr,sheetPathTemplate"`
-	ExternalID         string `jsonapi:"attr,externalId"`
+	Name         string `jsonapi:"attr,name"`
+	FullPath     string `jsonapi:"attr,fullPath"`
+	WebURL       string `jsonapi:"attr,webUrl"`
+	BranchFilter string `jsonapi:"attr,branchFilter"`
+	// The JSON encoded BranchEnvironment list.
+	BranchEnvironmentList string `jsonapi:"attr,branchEnvironmentList"`
+	BaseDirectory         string `jsonapi:"attr,baseDirectory"`
+	FilePathTemplate      string `jsonapi:"attr,filePathTemplate"`
+	SchemaPathTemplate    string `jsonapi:"attr,schemaPathTemplate"`
+	SheetPathTemplate     string `jsonapi:"attr,sheetPathTemplate"`
+	ExternalID            string `jsonapi:"attr,externalId"`
 	// Token belonged by the user linking the project to the VCS repository. We store this token together
 	// with the refresh token in the new repository record so we can use it to call VCS API on
 	// behalf of that user to perform tasks like webhook CRUD later.
@@ -107,14 +141,16 @@ type RepositoryPatch struct {
 	UpdaterID int
 
 	// Domain specific fields
-	BranchFilter       *string `jsonapi:"attr,branchFilter"`
-	BaseDirectory      *string `jsonapi:"attr,baseDirectory"`
-	FilePathTemplate   *string `jsonapi:"attr,filePathTemplate"`
-	SchemaPathTemplate *string `jsonapi:"attr,schemaPathTemplate"`
-	SheetPathTemplate  *string `jsonapi:"attr,sheetPathTemplate"`
-	AccessToken        *string
-	ExpiresTs          *int64
-	RefreshToken       *string
+	BranchFilter *string `jsonapi:"attr,branchFilter"`
+	// The JSON encoded BranchEnvironment list.
+	BranchEnvironmentList *string `jsonapi:"attr,branchEnvironmentList"`
+	BaseDirectory         *string `jsonapi:"attr,baseDirectory"`
+	FilePathTemplate      *string `jsonapi:"attr,filePathTemplate"`
+	SchemaPathTemplate    *string `jsonapi:"attr,schemaPathTemplate"`
+	SheetPathTemplate     *string `jsonapi:"attr,sheetPathTemplate"`
+	AccessToken           *string
+	ExpiresTs             *int64
+	RefreshToken          *string
 }
 
 // RepositoryDelete is the API message for deleting a repository.
@@ -2,6 +2,8 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
 
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/plugin/db"
@@ -45,8 +47,10 @@ const (
 	// TaskDatabaseSchemaUpdate is the task type for updating database schemas.
 	TaskDatabaseSchemaUpdate TaskType = "bb.task.database.schema.update"
 	// TaskDatabaseSchemaUpdateGhostSync is the task type for gh-ost syncing ghost table.
+	// For Postgres, it's the trigger-based online schema change syncing the shadow table.
 	TaskDatabaseSchemaUpdateGhostSync TaskType = "bb.task.database.schema.update.ghost.sync"
 	// TaskDatabaseSchemaUpdateGhostCutover is the task type for gh-ost switching the original table and the ghost table.
+	// For Postgres, it's the trigger-based online schema change switching the original table and the shadow table.
 	TaskDatabaseSchemaUpdateGhostCutover TaskType = "bb.task.database.schema.update.ghost.cutover"
 	// TaskDatabaseDataUpdate is the task type for updating database data.
 	TaskDatabaseDataUpdate TaskType = "bb.task.database.data.update"
@@ -95,6 +99,10 @@ type TaskDatabaseSchemaUpdatePayload struct {
 	Statement     string           `json:"statement,omitempty"`
 	SchemaVersion string           `json:"schemaVersion,omitempty"`
 	VCSPushEvent  *vcs.PushEvent   `json:"pushEvent,omitempty"`
+	// RollbackStatement is the statement rolling back the schema migration.
+	RollbackStatement string `json:"rollbackStatement,omitempty"`
+	// DesiredSchema is the desired schema of the declarative migration, from which the statement is generated.
+	DesiredSchema string `json:"desiredSchema,omitempty"`
 }
 
 // TaskDatabaseSchemaUpdateGhostSyncPayload is the task payload for gh-ost syncing ghost table.
@@ -117,6 +125,96 @@ type TaskDatabaseDataUpdatePayload struct {
 	Statement     string         `json:"statement,omitempty"`
 	SchemaVersion string         `json:"schemaVersion,omitempty"`
 	VCSPushEvent  *vcs.PushEvent `json:"pushEvent,omitempty"`
+	// BatchConfig is set for the batched data update, which executes the eligible UPDATE / DELETE statements in batches of primary key ranges.
+	BatchConfig *DataUpdateBatchConfig `json:"batchConfig,omitempty"`
+	// RollbackEnabled backs up the rows touched by the UPDATE / DELETE statements before the data update, so that it can be rolled back.
+	RollbackEnabled bool `json:"rollbackEnabled,omitempty"`
+}
+
+const (
+	// DataUpdateBatchSizeLimit is the max batch size of the batched data update.
+	DataUpdateBatchSizeLimit = 1000000
+	// DataUpdateBatchSleepMsLimit is the max sleep interval in milliseconds between two batches of the batched data update.
+	DataUpdateBatchSleepMsLimit = 60000
+)
+
+// DataUpdateBatchConfig is the config of the batched data update.
+type DataUpdateBatchConfig struct {
+	// BatchSize is the size of the primary key range of a batch.
+	BatchSize int64 `json:"batchSize"`
+	// SleepMs is the sleep interval in milliseconds between two batches.
+	SleepMs int64 `json:"sleepMs"`
+}
+
+// DataUpdateBatchProgressPayload is the progress payload of the batched data update.
+type DataUpdateBatchProgressPayload struct {
+	RowsAffected int64 `json:"rowsAffected"`
+	Paused       bool  `json:"paused"`
+}
+
+// Validate validates the batch config.
+func (c *DataUpdateBatchConfig) Validate() error {
+	if c.BatchSize <= 0 || c.BatchSize > DataUpdateBatchSizeLimit {
+		return fmt.Errorf("batch size must be between 1 and %d, got %d", DataUpdateBatchSizeLimit, c.BatchSize)
+	}
+	if c.SleepMs < 0 || c.SleepMs > DataUpdateBatchSleepMsLimit {
+		return fmt.Errorf("batch sleep interval must be between 0 and %d ms, got %d", DataUpdateBatchSleepMsLimit, c.SleepMs)
+	}
+	return nil
+}
+
+const (
+	// GhostChunkSizeMin is the min chunk size of gh-ost.
+	GhostChunkSizeMin = 100
+	// GhostChunkSizeMax is the max chunk size of gh-ost.
+	GhostChunkSizeMax = 100000
+)
+
+// ghostLoadRegexp matches the gh-ost load thresholds, e.g. Threads_running=25,Threads_connected=100.
+var ghostLoadRegexp = regexp.MustCompile(`^(\w+=\d+(,\w+=\d+)*)?$`)
+
+// TaskGhostConfigPatch is the API message for changing the throttling config of a running gh-ost migration.
+type TaskGhostConfigPatch struct {
+	ID int
+
+	// Standard fields
+	// Value is assigned from the jwt subject field passed by the client.
+	UpdaterID int
+
+	// Domain specific fields
+	// ChunkSize is the number of rows gh-ost copies in a chunk.
+	ChunkSize *int64 `jsonapi:"attr,chunkSize"`
+	// MaxLoad is the status thresholds over which gh-ost throttles, e.g. Threads_running=25. Empty means no threshold.
+	MaxLoad *string `jsonapi:"attr,maxLoad"`
+	// CriticalLoad is the status thresholds over which gh-ost bails out, e.g. Threads_running=1000. Empty means no threshold.
+	CriticalLoad *string `jsonapi:"attr,criticalLoad"`
+}
+
+// Validate validates the gh-ost config patch.
+func (patch *TaskGhostConfigPatch) Validate() error {
+	if patch.ChunkSize == nil && patch.MaxLoad == nil && patch.CriticalLoad == nil {
+		return fmt.Errorf("nothing to change")
+	}
+	if patch.ChunkSize != nil && (*patch.ChunkSize < GhostChunkSizeMin || *patch.ChunkSize > GhostChunkSizeMax) {
+		return fmt.Errorf("chunk size must be between %d and %d, got %d", GhostChunkSizeMin, GhostChunkSizeMax, *patch.ChunkSize)
+	}
+	if patch.MaxLoad != nil && !ghostLoadRegexp.MatchString(*patch.MaxLoad) {
+		return fmt.Errorf("invalid max load %q, expecting comma separated thresholds like Threads_running=25", *patch.MaxLoad)
+	}
+	if patch.CriticalLoad != nil && !ghostLoadRegexp.MatchString(*patch.CriticalLoad) {
+		return fmt.Errorf("invalid critical load %q, expecting comma separated thresholds like Threads_running=1000", *patch.CriticalLoad)
+	}
+	return nil
+}
+
+// TaskGhostStatus is the API message for the live status of a running gh-ost migration.
+type TaskGhostStatus struct {
+	// TaskID is the ID of the gh-ost sync task.
+	TaskID int `jsonapi:"primary,taskGhostStatus"`
+
+	// Domain specific fields
+	// Status is the status message printed by gh-ost.
+	Status string `jsonapi:"attr,status"`
 }
 
 // TaskDatabaseBackupPayload is the task payload for database backup.
@@ -221,6 +319,7 @@ type TaskFind struct {
 	// Related fields
 	PipelineID *int
 	StageID    *int
+	DatabaseID *int
 
 	// Domain specific fields
 	StatusList *[]TaskStatus
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
 )
 
 // TaskRunStatus is the status of a task run.
@@ -27,6 +28,16 @@ type TaskRunResultPayload struct {
 	Detail      string `json:"detail,omitempty"`
 	MigrationID int64  `json:"migrationId,omitempty"`
 	Version     string `json:"version,omitempty"`
+	// StatementList is the execution detail of each statement in the migration.
+	StatementList []*db.StatementResult `json:"statementList,omitempty"`
+	// DataBackupList is the pre-change backup of the rows touched by the data update, used to roll back the data update.
+	DataBackupList []*db.DataBackupTable `json:"dataBackupList,omitempty"`
+	// BinlogRange is the MySQL binlog range written by the data update, used to generate the reverse statements of the data update.
+	BinlogRange *db.BinlogRange `json:"binlogRange,omitempty"`
+	// RetryCount is the number of automatic retries of the task before this run.
+	RetryCount int `json:"retryCount,omitempty"`
+	// NextRetryTs is when the failed task is retried automatically, or 0 if it isn't retried.
+	NextRetryTs int64 `json:"nextRetryTs,omitempty"`
 }
 
 // TaskRun is the API message for a task run.
@@ -67,6 +78,8 @@ type TaskRunCreate struct {
 	Name    string   `jsonapi:"attr,name"`
 	Type    TaskType `jsonapi:"attr,type"`
 	Payload string   `jsonapi:"attr,payload"`
+	// Result is the initial result of the task run, e.g. the retry count of an automatic retry.
+	Result string
 }
 
 // TaskRunFind is the API message for finding task runs.
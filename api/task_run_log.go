@@ -0,0 +1,70 @@
+package api
+
+// TaskRunLogType is the type of a task run log event.
+type TaskRunLogType string
+
+const (
+	// TaskRunLogStatementStart is the task run log type for a statement starting to execute.
+	TaskRunLogStatementStart TaskRunLogType = "bb.task-run-log.statement.start"
+	// TaskRunLogStatementEnd is the task run log type for a statement finishing executing.
+	TaskRunLogStatementEnd TaskRunLogType = "bb.task-run-log.statement.end"
+	// TaskRunLogGhostProgress is the task run log type for the progress of gh-ost.
+	TaskRunLogGhostProgress TaskRunLogType = "bb.task-run-log.ghost.progress"
+	// TaskRunLogBackupProgress is the task run log type for the progress of taking a backup.
+	TaskRunLogBackupProgress TaskRunLogType = "bb.task-run-log.backup.progress"
+	// TaskRunLogError is the task run log type for errors.
+	TaskRunLogError TaskRunLogType = "bb.task-run-log.error"
+	// TaskRunLogEnd is the task run log type for the end of a task run.
+	// It's the last event of a task run log, and is never persisted.
+	TaskRunLogEnd TaskRunLogType = "bb.task-run-log.end"
+)
+
+// TaskRunLogPayload is the payload of a task run log event.
+type TaskRunLogPayload struct {
+	// Statement is the statement being executed, truncated if it's too long.
+	Statement string `json:"statement,omitempty"`
+	// DurationNs is the execution duration of the statement.
+	DurationNs int64 `json:"durationNs,omitempty"`
+	// RowsAffected is the number of rows affected by the statement, -1 if unknown.
+	RowsAffected int64 `json:"rowsAffected,omitempty"`
+	// BytesWritten is the number of bytes written to the backup file.
+	BytesWritten int64 `json:"bytesWritten,omitempty"`
+	// Message is the human readable message, e.g. the gh-ost progress line or the error message.
+	Message string `json:"message,omitempty"`
+	// Status is the task run status, only set for TaskRunLogEnd.
+	Status TaskRunStatus `json:"status,omitempty"`
+}
+
+// TaskRunLog is the API message for a task run log event.
+type TaskRunLog struct {
+	ID int `json:"id"`
+
+	// Standard fields
+	CreatedTs int64 `json:"createdTs"`
+
+	// Related fields
+	TaskRunID int `json:"taskRunId"`
+
+	// Domain specific fields
+	Type    TaskRunLogType    `json:"type"`
+	Payload TaskRunLogPayload `json:"payload"`
+}
+
+// TaskRunLogCreate is the API message for creating a task run log event.
+type TaskRunLogCreate struct {
+	// Related fields
+	TaskRunID int
+
+	// Domain specific fields
+	Type    TaskRunLogType
+	Payload TaskRunLogPayload
+}
+
+// TaskRunLogFind is the API message for finding task run log events.
+type TaskRunLogFind struct {
+	// Related fields
+	TaskRunID int
+
+	// AfterID finds the events created after the event with the ID.
+	AfterID *int
+}
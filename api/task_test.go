@@ -0,0 +1,56 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskGhostConfigPatchValidate(t *testing.T) {
+	chunkSize := func(v int64) *int64 { return &v }
+	load := func(v string) *string { return &v }
+	tests := []struct {
+		patch   TaskGhostConfigPatch
+		wantErr bool
+	}{
+		{
+			patch:   TaskGhostConfigPatch{},
+			wantErr: true,
+		},
+		{
+			patch: TaskGhostConfigPatch{ChunkSize: chunkSize(1000)},
+		},
+		{
+			patch:   TaskGhostConfigPatch{ChunkSize: chunkSize(10)},
+			wantErr: true,
+		},
+		{
+			patch:   TaskGhostConfigPatch{ChunkSize: chunkSize(1000000)},
+			wantErr: true,
+		},
+		{
+			patch: TaskGhostConfigPatch{MaxLoad: load("Threads_running=25,Threads_connected=100")},
+		},
+		{
+			// Empty clears the thresholds.
+			patch: TaskGhostConfigPatch{CriticalLoad: load("")},
+		},
+		{
+			patch:   TaskGhostConfigPatch{MaxLoad: load("Threads_running=25\nthrottle")},
+			wantErr: true,
+		},
+		{
+			patch:   TaskGhostConfigPatch{CriticalLoad: load("Threads_running")},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.patch.Validate()
+		if test.wantErr {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+}
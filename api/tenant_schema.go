@@ -0,0 +1,37 @@
+package api
+
+// TenantSchemaReport is the API message for the schema consistency of tenant databases in a project.
+type TenantSchemaReport struct {
+	ProjectID int `jsonapi:"attr,projectId"`
+	// GroupList has a group for each base database name, because only tenant databases sharing the same base database name are supposed to have the same schema.
+	GroupList []*TenantSchemaGroup `jsonapi:"attr,groupList"`
+}
+
+// TenantSchemaGroup is the API message for the tenant databases sharing the same base database name.
+type TenantSchemaGroup struct {
+	BaseDatabaseName string `json:"baseDatabaseName"`
+	// SchemaList is the list of distinct schemas in the group.
+	// The first schema is the majority schema, and the others are outliers.
+	SchemaList []*TenantSchema `json:"schemaList"`
+	// UncheckedDatabaseList is the list of databases whose schema cannot be fetched, e.g. connection failures.
+	UncheckedDatabaseList []*TenantSchemaDatabase `json:"uncheckedDatabaseList"`
+}
+
+// TenantSchema is the API message for a distinct schema shared by a list of tenant databases.
+type TenantSchema struct {
+	// Hash is the SHA-256 hex digest of the schema.
+	Hash         string                  `json:"hash"`
+	DatabaseList []*TenantSchemaDatabase `json:"databaseList"`
+	// Diff is the unified diff from the majority schema to this schema. It's empty for the majority schema.
+	Diff string `json:"diff"`
+}
+
+// TenantSchemaDatabase is the API message for a tenant database in the schema consistency report.
+type TenantSchemaDatabase struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Version is the latest migration version of the database.
+	Version string `json:"version"`
+	// Error is the reason why the database cannot be checked.
+	Error string `json:"error,omitempty"`
+}
@@ -5,6 +5,7 @@ import {
   ResourceObject,
   LabelState,
   LabelId,
+  LabelCreate,
   LabelPatch,
   LabelValueType,
 } from "@/types";
@@ -50,6 +51,22 @@ export const useLabelStore = defineStore("label", {
       return labelList;
     },
 
+    async createLabel(labelCreate: LabelCreate) {
+      const data = (
+        await axios.post(`/api/label`, {
+          data: {
+            type: "labelCreate",
+            attributes: labelCreate,
+          },
+        })
+      ).data;
+      const createdLabel = convert(data.data);
+
+      this.labelList.push(createdLabel);
+
+      return createdLabel;
+    },
+
     async patchLabel({
       id,
       labelPatch,
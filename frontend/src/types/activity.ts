@@ -15,7 +15,8 @@ export type IssueActivityType =
   | "bb.pipeline.task.status.update"
   | "bb.pipeline.task.file.commit"
   | "bb.pipeline.task.statement.update"
-  | "bb.pipeline.task.general.earliest-allowed-time.update";
+  | "bb.pipeline.task.general.earliest-allowed-time.update"
+  | "bb.pipeline.task.ghost.control";
 
 export type MemberActivityType =
   | "bb.member.create"
@@ -56,6 +57,8 @@ export function activityName(type: ActivityType): string {
       return t("activity.type.pipeline-task-statement-update");
     case "bb.pipeline.task.general.earliest-allowed-time.update":
       return t("activity.type.pipeline-task-earliest-allowed-time-update");
+    case "bb.pipeline.task.ghost.control":
+      return t("activity.type.pipeline-task-ghost-control");
     case "bb.member.create":
       return t("activity.type.member-create");
     case "bb.member.role.update":
@@ -135,6 +138,13 @@ export type ActivityTaskEarliestAllowedTimeUpdatePayload = {
   taskName: string;
 };
 
+export type ActivityTaskGhostControlPayload = {
+  taskId: TaskId;
+  command: string;
+  issueName: string;
+  taskName: string;
+};
+
 export type ActivityMemberCreatePayload = {
   principalId: PrincipalId;
   principalName: string;
@@ -178,6 +188,7 @@ export type ActionPayloadType =
   | ActivityTaskFileCommitPayload
   | ActivityTaskStatementUpdatePayload
   | ActivityTaskEarliestAllowedTimeUpdatePayload
+  | ActivityTaskGhostControlPayload
   | ActivityMemberCreatePayload
   | ActivityMemberRoleUpdatePayload
   | ActivityMemberActivateDeactivatePayload
@@ -7,6 +7,7 @@ import {
   Instance,
   InstanceId,
   Principal,
+  ProjectId,
 } from ".";
 
 export type AnomalyType =
@@ -15,7 +16,9 @@ export type AnomalyType =
   | "bb.anomaly.database.backup.policy-violation"
   | "bb.anomaly.database.backup.missing"
   | "bb.anomaly.database.connection"
-  | "bb.anomaly.database.schema.drift";
+  | "bb.anomaly.database.schema.drift"
+  | "bb.anomaly.database.schema.inconsistency"
+  | "bb.anomaly.database.migration.checksum-mismatch";
 
 export type AnomalyInstanceConnectionPayload = {
   detail: string;
@@ -40,13 +43,38 @@ export type AnomalyDatabaseSchemaDriftPayload = {
   version: string;
   expect: string;
   actual: string;
+  pullRequestUrl?: string;
+};
+
+export type AnomalyDatabaseSchemaInconsistencyPayload = {
+  projectId: ProjectId;
+  version: string;
+  expectDatabaseId: DatabaseId;
+  expectDatabaseName: string;
+  expectVersion: string;
+  diff: string;
+};
+
+export type MigrationChecksumMismatch = {
+  version: string;
+  file: string;
+  commitId: string;
+  commitUrl: string;
+  expect: string;
+  actual: string;
+};
+
+export type AnomalyDatabaseMigrationChecksumMismatchPayload = {
+  mismatchList: MigrationChecksumMismatch[];
 };
 
 export type AnomalyPayload =
   | AnomalyDatabaseBackupPolicyViolationPayload
   | AnomalyDatabaseBackupMissingPayload
   | AnomalyDatabaseConnectionPayload
-  | AnomalyDatabaseSchemaDriftPayload;
+  | AnomalyDatabaseSchemaDriftPayload
+  | AnomalyDatabaseSchemaInconsistencyPayload
+  | AnomalyDatabaseMigrationChecksumMismatchPayload;
 
 export type AnomalySeverity = "MEDIUM" | "HIGH" | "CRITICAL";
 
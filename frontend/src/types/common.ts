@@ -220,6 +220,9 @@ const makeUnknown = (type: ResourceType) => {
     tenantMode: "DISABLED",
     dbNameTemplate: "",
     roleProvider: "BYTEBASE",
+    schemaChangeType: "DDL",
+    migrationOrder: "IGNORE",
+    taskRetryPolicy: "",
   };
 
   const UNKNOWN_PROJECT_HOOK: ProjectWebhook = {
@@ -448,6 +451,7 @@ const makeUnknown = (type: ResourceType) => {
     webUrl: "",
     baseDirectory: "",
     branchFilter: "",
+    branchEnvironmentList: "[]",
     filePathTemplate: "",
     schemaPathTemplate: "",
     sheetPathTemplate: "",
@@ -623,6 +627,9 @@ const makeEmpty = (type: ResourceType) => {
     tenantMode: "DISABLED",
     dbNameTemplate: "",
     roleProvider: "BYTEBASE",
+    schemaChangeType: "DDL",
+    migrationOrder: "IGNORE",
+    taskRetryPolicy: "",
   };
 
   const EMPTY_PROJECT_HOOK: ProjectWebhook = {
@@ -851,6 +858,7 @@ const makeEmpty = (type: ResourceType) => {
     webUrl: "",
     baseDirectory: "",
     branchFilter: "",
+    branchEnvironmentList: "[]",
     filePathTemplate: "",
     schemaPathTemplate: "",
     sheetPathTemplate: "",
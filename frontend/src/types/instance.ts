@@ -1,7 +1,12 @@
 import { Anomaly, DataSource } from ".";
 import { RowStatus } from "./common";
 import { Environment } from "./environment";
-import { EnvironmentId, InstanceId, MigrationHistoryId } from "./id";
+import {
+  EnvironmentId,
+  InstanceId,
+  MigrationHistoryId,
+  PrincipalId,
+} from "./id";
 import { Principal } from "./principal";
 import { VCSPushEvent } from "./vcs";
 
@@ -105,14 +110,29 @@ export type InstanceMigration = {
   error: string;
 };
 
-export type MigrationSource = "UI" | "VCS" | "LIBRARY";
-
-export type MigrationType = "BASELINE" | "MIGRATE" | "BRANCH" | "DATA";
+export type MigrationSource =
+  | "UI"
+  | "VCS"
+  | "LIBRARY"
+  | "FLYWAY"
+  | "LIQUIBASE"
+  | "GOLANG_MIGRATE";
+
+export type MigrationType =
+  | "BASELINE"
+  | "MIGRATE"
+  | "MIGRATE_SDL"
+  | "BRANCH"
+  | "DATA";
 
 export type MigrationStatus = "PENDING" | "DONE" | "FAILED";
 
 export type MigrationHistoryPayload = {
   pushEvent?: VCSPushEvent;
+  rollbackStatement?: string;
+  desiredSchema?: string;
+  checksum?: string;
+  sourceChecksum?: string;
 };
 
 export type MigrationHistory = {
@@ -135,3 +155,12 @@ export type MigrationHistory = {
   issueId: number;
   payload?: MigrationHistoryPayload;
 };
+
+export type MigrationRollback = {
+  version: string;
+  assigneeId: PrincipalId;
+};
+
+export type MigrationHistoryImport = {
+  source: MigrationSource;
+};
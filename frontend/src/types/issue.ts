@@ -6,7 +6,7 @@ import {
   PrincipalId,
   ProjectId,
 } from "./id";
-import { Pipeline, PipelineCreate } from "./pipeline";
+import { DataUpdateBatchConfig, Pipeline, PipelineCreate } from "./pipeline";
 import { Principal } from "./principal";
 import { Project } from "./project";
 import { MigrationType } from "./instance";
@@ -48,6 +48,13 @@ export type UpdateSchemaDetail = {
   databaseName: string;
   statement: string;
   earliestAllowedTs: number;
+  // Only for the data update on MySQL, TiDB and Postgres.
+  batchConfig?: DataUpdateBatchConfig;
+  rollbackEnabled?: boolean;
+  // Only for the schema migration.
+  rollbackStatement?: string;
+  // Only for the declarative schema migration, the statement is generated from it.
+  desiredSchema?: string;
 };
 
 export type UpdateSchemaGhostDetail = UpdateSchemaDetail & {
@@ -15,6 +15,11 @@ export type Label = {
   valueList: LabelValueType[];
 };
 
+export type LabelCreate = {
+  key: LabelKeyType;
+  valueList: LabelValueType[];
+};
+
 export type LabelPatch = {
   valueList?: LabelValueType[];
 };
@@ -1,5 +1,5 @@
 import { useWindowScroll } from "@vueuse/core";
-import { VCSType } from ".";
+import { isGitHubVCSType, VCSType } from ".";
 import { randomString, vcsSlug } from "../utils";
 
 export type OAuthConfig = {
@@ -35,6 +35,14 @@ export type OAuthType =
   | "bb.oauth.link-vcs-repository"
   | "bb.oauth.unknown";
 
+// bitbucketAuthorizeUrl returns the OAuth authorize URL of Bitbucket Cloud or Bitbucket Data Center.
+export function bitbucketAuthorizeUrl(instanceUrl: string): string {
+  if (instanceUrl == "https://bitbucket.org") {
+    return "https://bitbucket.org/site/oauth2/authorize";
+  }
+  return `${instanceUrl}/rest/oauth2/latest/authorize`;
+}
+
 export function openWindowForOAuth(
   endpoint: string,
   applicationId: string,
@@ -45,7 +53,7 @@ export function openWindowForOAuth(
   const stateQueryParameter = `${type}-${randomString(20)}`;
   sessionStorage.setItem(OAuthStateSessionKey, stateQueryParameter);
 
-  if (vcsType == "GITHUB_COM") {
+  if (isGitHubVCSType(vcsType)) {
     return window.open(
       `${endpoint}?client_id=${applicationId}&redirect_uri=${encodeURIComponent(
         redirectUrl()
@@ -54,6 +62,29 @@ export function openWindowForOAuth(
       "location=yes,left=200,top=200,height=640,width=480,scrollbars=yes,status=yes"
     );
   }
+  if (vcsType == "GITEA") {
+    // Gitea does not support OAuth scopes, the token has the full access of the user.
+    return window.open(
+      `${endpoint}?client_id=${applicationId}&redirect_uri=${encodeURIComponent(
+        redirectUrl()
+      )}&state=${stateQueryParameter}&response_type=code`,
+      "oauth",
+      "location=yes,left=200,top=200,height=640,width=480,scrollbars=yes,status=yes"
+    );
+  }
+  if (vcsType == "BITBUCKET") {
+    // Bitbucket Cloud takes the scopes from the OAuth consumer, while Bitbucket Data Center requires the scope explicitly.
+    const scope = endpoint.startsWith("https://bitbucket.org/")
+      ? ""
+      : "&scope=REPO_ADMIN";
+    return window.open(
+      `${endpoint}?client_id=${applicationId}&redirect_uri=${encodeURIComponent(
+        redirectUrl()
+      )}&state=${stateQueryParameter}&response_type=code${scope}`,
+      "oauth",
+      "location=yes,left=200,top=200,height=640,width=480,scrollbars=yes,status=yes"
+    );
+  }
   // GITLAB_SELF_HOST
   return window.open(
     `${endpoint}?client_id=${applicationId}&redirect_uri=${encodeURIComponent(
@@ -81,9 +81,21 @@ export type TaskDatabasePITRDeletePayload = {
   // more input and output parameters in the future
 };
 
+export type DataUpdateBatchConfig = {
+  batchSize: number;
+  sleepMs: number;
+};
+
+export type DataUpdateBatchProgressPayload = {
+  rowsAffected: number;
+  paused: boolean;
+};
+
 export type TaskDatabaseDataUpdatePayload = {
   statement: string;
   pushEvent?: VCSPushEvent;
+  batchConfig?: DataUpdateBatchConfig;
+  rollbackEnabled?: boolean;
 };
 
 export type TaskDatabaseRestorePayload = {
@@ -178,13 +190,63 @@ export type TaskStatusPatch = {
   updatedTs?: number;
 };
 
+export type TaskGhostConfigPatch = {
+  chunkSize?: number;
+  maxLoad?: string;
+  criticalLoad?: string;
+};
+
+export type TaskGhostStatus = {
+  taskId: TaskId;
+  status: string;
+};
+
 // TaskRun is one run of a particular task
 export type TaskRunStatus = "RUNNING" | "DONE" | "FAILED" | "CANCELED";
 
+export type StatementStatus = "DONE" | "FAILED" | "SKIPPED";
+
+export type StatementResult = {
+  index: number;
+  statement: string;
+  durationNs: number;
+  // -1 if unknown
+  rowsAffected: number;
+  status: StatementStatus;
+  error?: string;
+  committed: boolean;
+};
+
+export type DataBackupTable = {
+  index: number;
+  table: string;
+  backupTable: string;
+  primaryKeyList: string[];
+  columnList: string[];
+  rowCount: number;
+  // The backup table expires after the retention period, and is dropped when taking the next pre-change backup.
+  createdTs: number;
+};
+
+export type BinlogRange = {
+  threadId: number;
+  startFileName: string;
+  startPosition: number;
+  endFileName: string;
+  endPosition: number;
+};
+
 export type TaskRunResultPayload = {
   detail: string;
   migrationId?: MigrationHistoryId;
   version?: string;
+  statementList?: StatementResult[];
+  dataBackupList?: DataBackupTable[];
+  binlogRange?: BinlogRange;
+  // The number of automatic retries of the task before this run.
+  retryCount?: number;
+  // When the failed task is retried automatically.
+  nextRetryTs?: number;
 };
 
 export type TaskRun = {
@@ -206,6 +268,32 @@ export type TaskRun = {
   payload?: TaskPayload;
 };
 
+// TaskRunLog is a log event of a task run streamed by the server-sent events endpoint
+export type TaskRunLogType =
+  | "bb.task-run-log.statement.start"
+  | "bb.task-run-log.statement.end"
+  | "bb.task-run-log.ghost.progress"
+  | "bb.task-run-log.backup.progress"
+  | "bb.task-run-log.error"
+  | "bb.task-run-log.end";
+
+export type TaskRunLogPayload = {
+  statement?: string;
+  durationNs?: number;
+  rowsAffected?: number;
+  bytesWritten?: number;
+  message?: string;
+  status?: TaskRunStatus;
+};
+
+export type TaskRunLog = {
+  id: number;
+  createdTs: number;
+  taskRunId: TaskRunId;
+  type: TaskRunLogType;
+  payload: TaskRunLogPayload;
+};
+
 export type TaskCheckRunStatus = "RUNNING" | "DONE" | "FAILED" | "CANCELED";
 
 export type TaskCheckType =
@@ -1,4 +1,5 @@
 import {
+  EngineType,
   RowStatus,
   Environment,
   PolicyId,
@@ -10,7 +11,8 @@ import {
 export type PolicyType =
   | "bb.policy.pipeline-approval"
   | "bb.policy.backup-plan"
-  | "bb.policy.schema-review";
+  | "bb.policy.schema-review"
+  | "bb.policy.task-retry";
 
 export type PipelineApprovalPolicyValue =
   | "MANUAL_APPROVAL_NEVER"
@@ -41,10 +43,26 @@ export type SQLReviewPolicyPayload = {
   }[];
 };
 
+export type TaskRetryErrorClass =
+  | "LOCK_TIMEOUT"
+  | "DEADLOCK"
+  | "SERIALIZATION_FAILURE";
+
+// TaskRetryPolicyPayload is the payload for task retry policy in the backend.
+// Automatic retry is disabled if maxAttempts is less than 2.
+export type TaskRetryPolicyPayload = {
+  maxAttempts: number;
+  backoffSeconds: number;
+  retryableErrorClassList?: {
+    [engine in EngineType]?: TaskRetryErrorClass[];
+  };
+};
+
 export type PolicyPayload =
   | PipelineApporvalPolicyPayload
   | BackupPlanPolicyPayload
-  | SQLReviewPolicyPayload;
+  | SQLReviewPolicyPayload
+  | TaskRetryPolicyPayload;
 
 export type Policy = {
   id: PolicyId;
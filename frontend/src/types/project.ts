@@ -1,5 +1,5 @@
 import { RowStatus } from "./common";
-import { MemberId, PrincipalId, ProjectId } from "./id";
+import { DatabaseId, MemberId, PrincipalId, ProjectId } from "./id";
 import { OAuthToken } from "./oauth";
 import { Principal } from "./principal";
 import { ExternalRepositoryInfo, RepositoryConfig } from "./repository";
@@ -16,8 +16,17 @@ export type ProjectTenantMode = "DISABLED" | "TENANT";
 export type ProjectRoleProvider =
   | "GITLAB_SELF_HOST"
   | "GITHUB_COM"
+  | "GITHUB_ENTERPRISE"
+  | "GITEA"
+  | "BITBUCKET"
   | "BYTEBASE";
 
+// DDL applies the migration statements, SDL applies the desired schema declaratively.
+export type ProjectSchemaChangeType = "DDL" | "SDL";
+
+// How the versions of the migration files pushed to the VCS are ordered against the versions applied to the databases.
+export type ProjectMigrationOrder = "STRICT" | "ALLOW_OUT_OF_ORDER" | "IGNORE";
+
 export type ProjectRoleProviderPayload = {
   vcsRole: string;
   lastSyncTs: number;
@@ -50,6 +59,10 @@ export type Project = {
   tenantMode: ProjectTenantMode;
   dbNameTemplate: string;
   roleProvider: ProjectRoleProvider;
+  schemaChangeType: ProjectSchemaChangeType;
+  migrationOrder: ProjectMigrationOrder;
+  // The task retry policy in json format overriding the one of the environments, empty means the policy of the environment applies.
+  taskRetryPolicy: string;
 };
 
 export type ProjectCreate = {
@@ -59,6 +72,8 @@ export type ProjectCreate = {
   tenantMode: ProjectTenantMode;
   dbNameTemplate: string;
   roleProvider: ProjectRoleProvider;
+  schemaChangeType: ProjectSchemaChangeType;
+  migrationOrder: ProjectMigrationOrder;
 };
 
 export type ProjectPatch = {
@@ -69,6 +84,27 @@ export type ProjectPatch = {
   name?: string;
   key?: string;
   roleProvider?: ProjectRoleProvider;
+  schemaChangeType?: ProjectSchemaChangeType;
+  migrationOrder?: ProjectMigrationOrder;
+  taskRetryPolicy?: string;
+};
+
+// Project migration status
+export type DatabaseMigrationStatus = {
+  id: DatabaseId;
+  name: string;
+  environmentName: string;
+  branch: string;
+  latestVersion: string;
+  appliedVersionList: string[];
+  pendingVersionList: string[];
+  outOfOrderVersionList: string[];
+  error: string;
+};
+
+export type ProjectMigrationStatus = {
+  projectId: ProjectId;
+  databaseList: DatabaseMigrationStatus[];
 };
 
 // Project Member
@@ -1,8 +1,8 @@
 import isEmpty from "lodash-es/isEmpty";
-import { ProjectId, RepositoryId, VCSId } from "./id";
+import { EnvironmentId, ProjectId, RepositoryId, VCSId } from "./id";
 import { Principal } from "./principal";
 import { Project } from "./project";
-import { VCS } from "./vcs";
+import { isGitHubVCSType, VCS } from "./vcs";
 
 export type Repository = {
   id: RepositoryId;
@@ -26,6 +26,8 @@ export type Repository = {
   webUrl: string;
   baseDirectory: string;
   branchFilter: string;
+  // JSON encoded BranchEnvironment list, which supersedes the branchFilter if not empty.
+  branchEnvironmentList: string;
   filePathTemplate: string;
   schemaPathTemplate: string;
   sheetPathTemplate: string;
@@ -42,6 +44,7 @@ export type RepositoryCreate = {
   fullPath: string;
   webUrl: string;
   branchFilter: string;
+  branchEnvironmentList?: string;
   baseDirectory: string;
   filePathTemplate: string;
   schemaPathTemplate: string;
@@ -55,11 +58,18 @@ export type RepositoryCreate = {
 export type RepositoryPatch = {
   baseDirectory?: string;
   branchFilter?: string;
+  branchEnvironmentList?: string;
   filePathTemplate?: string;
   schemaPathTemplate?: string;
   sheetPathTemplate?: string;
 };
 
+// BranchEnvironment maps the pushed branch to the environment whose databases the migration files apply to.
+export type BranchEnvironment = {
+  branchFilter: string;
+  environmentId: EnvironmentId;
+};
+
 export type RepositoryConfig = {
   baseDirectory: string;
   branchFilter: string;
@@ -94,12 +104,23 @@ export function baseDirectoryWebUrl(repository: Repository): string {
       url += `/${repository.baseDirectory}`;
     }
     return url;
-  } else if (repository.vcs.type == "GITHUB_COM") {
+  } else if (isGitHubVCSType(repository.vcs.type)) {
     let url = `${repository.webUrl}/tree/${repository.branchFilter}`;
     if (!isEmpty(repository.baseDirectory)) {
       url += `/${repository.baseDirectory}`;
     }
     return url;
+  } else if (repository.vcs.type == "BITBUCKET") {
+    if (repository.vcs.instanceUrl == "https://bitbucket.org") {
+      return `${repository.webUrl}/src/${repository.branchFilter}/${repository.baseDirectory}`;
+    }
+    return `${repository.webUrl}/browse/${repository.baseDirectory}?at=refs/heads/${repository.branchFilter}`;
+  } else if (repository.vcs.type == "GITEA") {
+    let url = `${repository.webUrl}/src/branch/${repository.branchFilter}`;
+    if (!isEmpty(repository.baseDirectory)) {
+      url += `/${repository.baseDirectory}`;
+    }
+    return url;
   }
 
   return repository.webUrl;
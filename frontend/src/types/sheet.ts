@@ -11,7 +11,13 @@ import {
 
 export type SheetVisibility = "PRIVATE" | "PROJECT" | "PUBLIC";
 
-export type SheetSource = "BYTEBASE" | "GITLAB_SELF_HOST" | "GITHUB_COM";
+export type SheetSource =
+  | "BYTEBASE"
+  | "GITLAB_SELF_HOST"
+  | "GITHUB_COM"
+  | "GITHUB_ENTERPRISE"
+  | "GITEA"
+  | "BITBUCKET";
 
 export type SheetType = "SQL";
 
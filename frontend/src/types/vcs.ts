@@ -1,7 +1,12 @@
 import { VCSId } from "./id";
 import { Principal } from "./principal";
 
-export type VCSType = "GITLAB_SELF_HOST" | "GITHUB_COM";
+export type VCSType =
+  | "GITLAB_SELF_HOST"
+  | "GITHUB_COM"
+  | "GITHUB_ENTERPRISE"
+  | "GITEA"
+  | "BITBUCKET";
 
 export interface VCSConfig {
   type: VCSType;
@@ -70,14 +75,25 @@ export type VCSPushEvent = {
   fileCommit: VCSFileCommit;
 };
 
+// GitHub.com and GitHub Enterprise Server share the same OAuth flow, APIs and webhooks.
+export function isGitHubVCSType(vcsType: VCSType): boolean {
+  return vcsType == "GITHUB_COM" || vcsType == "GITHUB_ENTERPRISE";
+}
+
 export function isValidVCSApplicationIdOrSecret(
   vcsType: VCSType,
   str: string
 ): boolean {
   if (vcsType == "GITLAB_SELF_HOST") {
     return /^[a-zA-Z0-9_]{64}$/.test(str);
-  } else if (vcsType == "GITHUB_COM") {
+  } else if (isGitHubVCSType(vcsType)) {
     return /^[a-zA-Z0-9_]{20}$|^[a-zA-Z0-9_]{40}$/.test(str);
+  } else if (vcsType == "GITEA") {
+    // Gitea client ids are UUIDs and the secrets are base64 encoded strings.
+    return /^[a-zA-Z0-9_\-=]{20,}$/.test(str);
+  } else if (vcsType == "BITBUCKET") {
+    // Bitbucket Cloud consumer keys are 18 characters, while the Data Center client ids are longer.
+    return /^[a-zA-Z0-9_\-]{18,}$/.test(str);
   }
   return false;
 }
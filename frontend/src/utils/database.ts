@@ -71,6 +71,10 @@ export function allowGhostMigration(databaseList: Database[]): boolean {
   }
 
   return databaseList.every((db) => {
+    // Postgres uses the trigger-based online schema change in place of gh-ost.
+    if (db.instance.engine === "POSTGRES") {
+      return true;
+    }
     return (
       db.instance.engine === "MYSQL" &&
       semverCompare(
@@ -147,8 +147,12 @@ export const isDatabaseMatchesSelector = (
     switch (rule.operator) {
       case "In":
         return checkLabelIn(database, rule);
+      case "NotIn":
+        return !checkLabelIn(database, rule);
       case "Exists":
         return checkLabelExists(database, rule);
+      case "DoesNotExist":
+        return !checkLabelExists(database, rule);
       default:
         // unknown operators are taken as mismatch
         console.warn(`known operator "${rule.operator}"`);
@@ -203,7 +203,7 @@ const TASK_STATUS_TRANSITION_LIST: Map<
     "CANCEL",
     {
       type: "CANCEL",
-      to: "PENDING",
+      to: "CANCELED",
       buttonName: "common.cancel",
       buttonClass: "btn-primary",
     },
@@ -217,9 +217,10 @@ const APPLICABLE_TASK_TRANSITION_LIST: Map<
 > = new Map([
   ["PENDING", []],
   ["PENDING_APPROVAL", ["APPROVE"]],
-  ["RUNNING", []],
+  ["RUNNING", ["CANCEL"]],
   ["DONE", []],
   ["FAILED", ["RETRY"]],
+  ["CANCELED", ["RETRY"]],
 ]);
 
 export function applicableTaskTransition(
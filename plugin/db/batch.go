@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchController controls the batched execution of data updates, where the eligible UPDATE / DELETE statements are executed
+// in batches of primary key ranges, each committed on its own.
+// It's passed to the driver via the context, and the execution can be paused and resumed between batches.
+type BatchController struct {
+	// BatchSize is the size of the primary key range of a batch.
+	BatchSize int64
+	// SleepInterval is the interval to sleep between two batches.
+	SleepInterval time.Duration
+
+	mu sync.Mutex
+	// resumeCh is closed on resuming, and it's nil if the execution isn't paused.
+	resumeCh chan struct{}
+	progress BatchProgress
+}
+
+// BatchProgress is the progress of the batched execution.
+type BatchProgress struct {
+	// TotalBatches grows as the primary key range of each eligible statement is found.
+	TotalBatches     int64
+	CompletedBatches int64
+	RowsAffected     int64
+	Paused           bool
+}
+
+type batchControllerKey struct{}
+
+// WithBatchController returns a copy of ctx carrying the batch controller.
+func WithBatchController(ctx context.Context, controller *BatchController) context.Context {
+	return context.WithValue(ctx, batchControllerKey{}, controller)
+}
+
+// GetBatchController returns the batch controller carried by ctx, or nil if there is none.
+func GetBatchController(ctx context.Context) *BatchController {
+	controller, _ := ctx.Value(batchControllerKey{}).(*BatchController)
+	return controller
+}
+
+// Pause pauses the execution before the next batch.
+func (c *BatchController) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resumeCh == nil {
+		c.resumeCh = make(chan struct{})
+	}
+}
+
+// Resume resumes the paused execution.
+func (c *BatchController) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resumeCh != nil {
+		close(c.resumeCh)
+		c.resumeCh = nil
+	}
+}
+
+// WaitIfPaused blocks until the execution is resumed or ctx is done.
+func (c *BatchController) WaitIfPaused(ctx context.Context) error {
+	c.mu.Lock()
+	resumeCh := c.resumeCh
+	c.mu.Unlock()
+	if resumeCh == nil {
+		return nil
+	}
+	select {
+	case <-resumeCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// AddTotalBatches adds n batches to the total.
+func (c *BatchController) AddTotalBatches(n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.progress.TotalBatches += n
+}
+
+// CompleteBatch records a committed batch.
+func (c *BatchController) CompleteBatch(rowsAffected int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.progress.CompletedBatches++
+	c.progress.RowsAffected += rowsAffected
+}
+
+// Progress returns the progress of the execution.
+func (c *BatchController) Progress() BatchProgress {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	progress := c.progress
+	progress.Paused = c.resumeCh != nil
+	return progress
+}
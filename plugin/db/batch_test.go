@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchControllerPauseResume(t *testing.T) {
+	c := &BatchController{BatchSize: 100}
+	require.NoError(t, c.WaitIfPaused(context.Background()))
+
+	c.Pause()
+	require.True(t, c.Progress().Paused)
+	done := make(chan error)
+	go func() {
+		done <- c.WaitIfPaused(context.Background())
+	}()
+	select {
+	case <-done:
+		t.Fatal("WaitIfPaused returned while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	c.Resume()
+	require.NoError(t, <-done)
+	require.False(t, c.Progress().Paused)
+
+	// Canceling the context stops waiting.
+	c.Pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, c.WaitIfPaused(ctx), context.Canceled)
+}
+
+func TestBatchControllerProgress(t *testing.T) {
+	c := &BatchController{BatchSize: 100}
+	c.AddTotalBatches(3)
+	c.CompleteBatch(100)
+	c.CompleteBatch(42)
+	require.Equal(t, BatchProgress{TotalBatches: 3, CompletedBatches: 2, RowsAffected: 142}, c.Progress())
+}
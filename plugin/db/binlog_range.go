@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"sync"
+)
+
+// BinlogRange is the range of the MySQL binlog written by the data update, used to generate the reverse statements of the data update.
+type BinlogRange struct {
+	// ThreadID is the ID of the connection executing the data update, telling its binlog events from the others.
+	ThreadID int64 `json:"threadId"`
+	// StartFileName and StartPosition are the binlog coordinate before executing the data update.
+	StartFileName string `json:"startFileName"`
+	StartPosition int64  `json:"startPosition"`
+	// EndFileName and EndPosition are the binlog coordinate after executing the data update.
+	EndFileName string `json:"endFileName"`
+	EndPosition int64  `json:"endPosition"`
+}
+
+// BinlogRangeRecorder asks the driver to record the binlog range written by the data update.
+// It's passed to the driver via the context, like StatementRecorder.
+type BinlogRangeRecorder struct {
+	mu          sync.Mutex
+	binlogRange *BinlogRange
+}
+
+type binlogRangeRecorderKey struct{}
+
+// WithBinlogRangeRecorder returns a copy of ctx carrying the binlog range recorder.
+func WithBinlogRangeRecorder(ctx context.Context, recorder *BinlogRangeRecorder) context.Context {
+	return context.WithValue(ctx, binlogRangeRecorderKey{}, recorder)
+}
+
+// GetBinlogRangeRecorder returns the binlog range recorder carried by ctx, or nil if there is none.
+func GetBinlogRangeRecorder(ctx context.Context) *BinlogRangeRecorder {
+	recorder, _ := ctx.Value(binlogRangeRecorderKey{}).(*BinlogRangeRecorder)
+	return recorder
+}
+
+// Record records the binlog range.
+func (r *BinlogRangeRecorder) Record(binlogRange *BinlogRange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.binlogRange = binlogRange
+}
+
+// BinlogRange returns the recorded binlog range, or nil if there is none.
+func (r *BinlogRangeRecorder) BinlogRange() *BinlogRange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.binlogRange
+}
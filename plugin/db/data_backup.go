@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DataBackupDatabase is the database (MySQL, TiDB) or the schema (Postgres) holding the pre-change backup tables.
+	DataBackupDatabase = "bbdataarchive"
+	// DataBackupRetentionPeriod is how long the pre-change backup tables are kept.
+	// The expired backup tables are dropped when taking the next pre-change backup in the same database.
+	DataBackupRetentionPeriod = 7 * 24 * time.Hour
+)
+
+// DataBackupTable is the backup of the rows touched by an UPDATE / DELETE statement, taken before executing the statement.
+type DataBackupTable struct {
+	// Index is the index of the statement in the statement list.
+	Index int `json:"index"`
+	// Table is the table updated by the statement as it's written in the statement.
+	Table string `json:"table"`
+	// BackupTable is the qualified and quoted backup table.
+	BackupTable string `json:"backupTable"`
+	// PrimaryKeyList is the quoted primary key columns of the table.
+	PrimaryKeyList []string `json:"primaryKeyList"`
+	// ColumnList is the quoted columns to restore, excluding the generated columns.
+	ColumnList []string `json:"columnList"`
+	// RowCount is the number of rows backed up.
+	RowCount int64 `json:"rowCount"`
+	// CreatedTs is when the backup is taken, and the backup expires after DataBackupRetentionPeriod.
+	CreatedTs int64 `json:"createdTs"`
+}
+
+// IsExpired returns true if the backup table has expired and may have been dropped.
+func (t *DataBackupTable) IsExpired(now time.Time) bool {
+	return !now.Before(time.Unix(t.CreatedTs, 0).Add(DataBackupRetentionPeriod))
+}
+
+// DataBackupRecorder asks the driver to back up the rows touched by the UPDATE / DELETE statements before executing them,
+// and records the backup tables.
+// It's passed to the driver via the context, like StatementRecorder.
+type DataBackupRecorder struct {
+	// TablePrefix is the prefix of the backup table names, which should be unique to the task run.
+	// It must be in the form of _<unix timestamp>_..._, so that the expired backup tables are told by their names.
+	TablePrefix string
+
+	mu        sync.Mutex
+	tableList []*DataBackupTable
+}
+
+type dataBackupRecorderKey struct{}
+
+// WithDataBackupRecorder returns a copy of ctx carrying the data backup recorder.
+func WithDataBackupRecorder(ctx context.Context, recorder *DataBackupRecorder) context.Context {
+	return context.WithValue(ctx, dataBackupRecorderKey{}, recorder)
+}
+
+// GetDataBackupRecorder returns the data backup recorder carried by ctx, or nil if there is none.
+func GetDataBackupRecorder(ctx context.Context) *DataBackupRecorder {
+	recorder, _ := ctx.Value(dataBackupRecorderKey{}).(*DataBackupRecorder)
+	return recorder
+}
+
+// Record records the backup table.
+func (r *DataBackupRecorder) Record(table *DataBackupTable) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tableList = append(r.tableList, table)
+}
+
+// TableList returns the recorded backup tables.
+func (r *DataBackupRecorder) TableList() []*DataBackupTable {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*DataBackupTable(nil), r.tableList...)
+}
@@ -3,13 +3,18 @@ package db
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"regexp"
 	"strings"
 	"sync"
 
+	"github.com/blang/semver/v4"
+
 	"github.com/bytebase/bytebase/plugin/vcs"
 )
 
@@ -169,6 +174,12 @@ const (
 	VCS MigrationSource = "VCS"
 	// LIBRARY is the migration source type for LIBRARY.
 	LIBRARY MigrationSource = "LIBRARY"
+	// FLYWAY is the migration source type for the migration history imported from Flyway.
+	FLYWAY MigrationSource = "FLYWAY"
+	// LIQUIBASE is the migration source type for the migration history imported from Liquibase.
+	LIQUIBASE MigrationSource = "LIQUIBASE"
+	// GolangMigrate is the migration source type for the migration history imported from golang-migrate.
+	GolangMigrate MigrationSource = "GOLANG_MIGRATE"
 )
 
 // MigrationType is the type of a migration.
@@ -189,6 +200,9 @@ const (
 	// Data is the migration type for DATA.
 	// Used for DML change.
 	Data MigrationType = "DATA"
+	// MigrateSDL is the migration type for MIGRATE_SDL.
+	// Used for the state-based DDL change, whose statement is generated from the difference between the desired schema and the current schema.
+	MigrateSDL MigrationType = "MIGRATE_SDL"
 )
 
 // MigrationStatus is the status of migration.
@@ -203,9 +217,153 @@ const (
 	Failed MigrationStatus = "FAILED"
 )
 
+// ErrorClass is the class of a database error, which is used to decide whether a failed task can be retried.
+type ErrorClass string
+
+const (
+	// ErrorClassUnknown is the error class for errors not classified.
+	ErrorClassUnknown ErrorClass = "UNKNOWN"
+	// ErrorClassLockTimeout is the error class for lock wait timeout.
+	ErrorClassLockTimeout ErrorClass = "LOCK_TIMEOUT"
+	// ErrorClassDeadlock is the error class for deadlock.
+	ErrorClassDeadlock ErrorClass = "DEADLOCK"
+	// ErrorClassSerialization is the error class for serialization failure.
+	ErrorClassSerialization ErrorClass = "SERIALIZATION_FAILURE"
+	// ErrorClassConnection is the error class for broken connections.
+	ErrorClassConnection ErrorClass = "CONNECTION"
+)
+
+// IsRolledBack returns whether the error of the class is known to roll back the failed statement, so that it leaves no
+// change behind. The outcome is unknown for a broken connection, which may happen after the change is committed.
+func (c ErrorClass) IsRolledBack() bool {
+	switch c {
+	case ErrorClassLockTimeout, ErrorClassDeadlock, ErrorClassSerialization:
+		return true
+	}
+	return false
+}
+
+// StatementStatus is the execution status of a statement.
+type StatementStatus string
+
+const (
+	// StatementDone is the statement status for DONE.
+	StatementDone StatementStatus = "DONE"
+	// StatementFailed is the statement status for FAILED.
+	StatementFailed StatementStatus = "FAILED"
+	// StatementSkipped is the statement status for SKIPPED, i.e. not executed because a previous statement failed.
+	StatementSkipped StatementStatus = "SKIPPED"
+)
+
+// StatementResult is the execution detail of a statement.
+type StatementResult struct {
+	// Index is the 0-based index of the statement.
+	Index int `json:"index"`
+	// Statement is the statement text, truncated if it's too long.
+	Statement  string `json:"statement"`
+	DurationNs int64  `json:"durationNs"`
+	// RowsAffected is -1 if unknown.
+	RowsAffected int64           `json:"rowsAffected"`
+	Status       StatementStatus `json:"status"`
+	Error        string          `json:"error,omitempty"`
+	// Committed is whether the change of the statement has been committed.
+	Committed bool `json:"committed"`
+}
+
+// StatementRecorder records the execution detail of each statement.
+// It's passed to the driver via the context, and drivers not supporting it simply record nothing.
+type StatementRecorder struct {
+	// OnStart and OnEnd are called when the driver starts and finishes executing each statement, if set.
+	// The result passed to OnEnd carries the execution detail of the statement.
+	OnStart func(result *StatementResult)
+	OnEnd   func(result *StatementResult)
+
+	mu         sync.Mutex
+	resultList []*StatementResult
+}
+
+type statementRecorderKey struct{}
+
+// WithStatementRecorder returns a copy of ctx carrying the statement recorder.
+func WithStatementRecorder(ctx context.Context, recorder *StatementRecorder) context.Context {
+	return context.WithValue(ctx, statementRecorderKey{}, recorder)
+}
+
+// GetStatementRecorder returns the statement recorder carried by ctx, or nil if there is none.
+func GetStatementRecorder(ctx context.Context) *StatementRecorder {
+	recorder, _ := ctx.Value(statementRecorderKey{}).(*StatementRecorder)
+	return recorder
+}
+
+// Record records the statement results. It's a no-op on a nil recorder.
+func (r *StatementRecorder) Record(resultList ...*StatementResult) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resultList = append(r.resultList, resultList...)
+}
+
+// Start notifies that the driver starts executing the statement. It's a no-op on a nil recorder.
+func (r *StatementRecorder) Start(result *StatementResult) {
+	if r == nil || r.OnStart == nil {
+		return
+	}
+	r.OnStart(result)
+}
+
+// End notifies that the driver finishes executing the statement. It's a no-op on a nil recorder.
+func (r *StatementRecorder) End(result *StatementResult) {
+	if r == nil || r.OnEnd == nil {
+		return
+	}
+	r.OnEnd(result)
+}
+
+// ResultList returns the recorded statement results.
+func (r *StatementRecorder) ResultList() []*StatementResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*StatementResult(nil), r.resultList...)
+}
+
 // MigrationInfoPayload is the API message for migration info payload.
 type MigrationInfoPayload struct {
 	VCSPushEvent *vcs.PushEvent `json:"pushEvent,omitempty"`
+	// RowsAffected is the total rows affected by the batched data update, including the committed batches of a failed one.
+	RowsAffected *int64 `json:"rowsAffected,omitempty"`
+	// RollbackStatement is the statement rolling back the schema migration, provided by the user or generated for the purely additive migration.
+	RollbackStatement string `json:"rollbackStatement,omitempty"`
+	// DesiredSchema is the desired schema of the state-based migration, from which the statement is generated.
+	DesiredSchema string `json:"desiredSchema,omitempty"`
+	// Checksum is the checksum of the migration statement, which detects the migration file modified after being applied.
+	// It's stored in the payload so that the migration history table of the existing instances doesn't need to be altered.
+	Checksum string `json:"checksum,omitempty"`
+	// SourceChecksum is the checksum recorded by the migration tool which the migration history is imported from.
+	// It's kept apart from Checksum since the migration tools compute the checksum in their own ways.
+	SourceChecksum string `json:"sourceChecksum,omitempty"`
+}
+
+// GetMigrationChecksum returns the checksum of the migration statement, which is the hex encoded SHA-256 digest.
+// The statement is trimmed as the one being applied, so that the checksum of the migration file read from the
+// repository matches the one recorded in the migration history.
+func GetMigrationChecksum(statement string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(statement)))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetMigrationHistoryChecksum returns the checksum recorded in the migration history payload.
+// It returns an empty string if the migration history was recorded before the checksum was introduced.
+func GetMigrationHistoryChecksum(history *MigrationHistory) (string, error) {
+	if history.Payload == "" {
+		return "", nil
+	}
+	payload := &MigrationInfoPayload{}
+	if err := json.Unmarshal([]byte(history.Payload), payload); err != nil {
+		return "", fmt.Errorf("failed to unmarshal migration history payload, error: %w", err)
+	}
+	return payload.Checksum, nil
 }
 
 // MigrationInfo is the API message for migration info.
@@ -320,6 +478,105 @@ func ParseMigrationInfo(filePath string, filePathTemplate string) (*MigrationInf
 	return mi, nil
 }
 
+// ParseSchemaFileInfo matches filePath against schemaPathTemplate and returns the migration info of the state-based migration
+// applying the desired schema in the file. The version is left empty, which is assigned when the migration is created.
+func ParseSchemaFileInfo(filePath string, schemaPathTemplate string) (*MigrationInfo, error) {
+	placeholderList := []string{
+		"ENV_NAME",
+		"DB_NAME",
+	}
+	filePathRegex := schemaPathTemplate
+	for _, placeholder := range placeholderList {
+		filePathRegex = strings.ReplaceAll(filePathRegex, fmt.Sprintf("{{%s}}", placeholder), fmt.Sprintf("(?P<%s>[a-zA-Z0-9+-=/_#?!$. ]+)", placeholder))
+	}
+	myRegex, err := regexp.Compile(filePathRegex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema path template: %q", schemaPathTemplate)
+	}
+	if !myRegex.MatchString(filePath) {
+		return nil, fmt.Errorf("file path %q does not match schema path template %q", filePath, schemaPathTemplate)
+	}
+
+	mi := &MigrationInfo{
+		Source: VCS,
+		Type:   MigrateSDL,
+	}
+	matchList := myRegex.FindStringSubmatch(filePath)
+	if index := myRegex.SubexpIndex("ENV_NAME"); index >= 0 {
+		mi.Environment = matchList[index]
+	}
+	if index := myRegex.SubexpIndex("DB_NAME"); index >= 0 {
+		mi.Namespace = matchList[index]
+		mi.Database = matchList[index]
+	}
+	if mi.Namespace == "" {
+		return nil, fmt.Errorf("file path %q does not contain {{DB_NAME}}, configured schema path template %q", filePath, schemaPathTemplate)
+	}
+	mi.Description = fmt.Sprintf("Apply %s desired schema", mi.Database)
+	return mi, nil
+}
+
+// CompareMigrationVersion compares two migration versions, and returns a negative number if a < b, zero if a == b,
+// or a positive number if a > b. Versions made of dot-separated numbers are compared segment by segment numerically,
+// where the missing segments are taken as 0, e.g. "1.10" > "1.9", "1.2.3.10" > "1.2.3.4" and timestamps
+// "20220801120000" > "20220730000000". Otherwise, they are compared as semantic versions if both of them are,
+// e.g. "1.0.0-alpha" < "1.0.0", or lexicographically.
+func CompareMigrationVersion(a, b string) int {
+	segmentListA, okA := getNumericVersionSegmentList(a)
+	segmentListB, okB := getNumericVersionSegmentList(b)
+	if okA && okB {
+		for i := 0; i < len(segmentListA) || i < len(segmentListB); i++ {
+			segmentA, segmentB := "0", "0"
+			if i < len(segmentListA) {
+				segmentA = segmentListA[i]
+			}
+			if i < len(segmentListB) {
+				segmentB = segmentListB[i]
+			}
+			if c := compareNumericString(segmentA, segmentB); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+
+	va, errA := semver.ParseTolerant(a)
+	vb, errB := semver.ParseTolerant(b)
+	if errA == nil && errB == nil {
+		return va.Compare(vb)
+	}
+	return strings.Compare(a, b)
+}
+
+// getNumericVersionSegmentList returns the dot-separated segments of the version with an optional "v" prefix, and
+// false if any segment isn't a number.
+func getNumericVersionSegmentList(version string) ([]string, bool) {
+	segmentList := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	for _, segment := range segmentList {
+		if segment == "" {
+			return nil, false
+		}
+		for _, r := range segment {
+			if r < '0' || r > '9' {
+				return nil, false
+			}
+		}
+	}
+	return segmentList, true
+}
+
+// compareNumericString compares two non-negative numbers of arbitrary length in decimal strings.
+func compareNumericString(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
 // MigrationHistory is the API message for migration history.
 type MigrationHistory struct {
 	ID int
@@ -425,6 +682,14 @@ type Driver interface {
 	RestoreTx(ctx context.Context, tx *sql.Tx, sc *bufio.Scanner) error
 }
 
+// MigrationHistoryImporter is implemented by the drivers supporting importing the migration history recorded by other migration tools.
+type MigrationHistoryImporter interface {
+	// ImportMigrationHistory imports the migration history of the database recorded by the migration tool of the source,
+	// e.g. the flyway_schema_history table of Flyway, into the migration history. The versions already in the migration
+	// history are skipped, so that importing again is a no-op. It returns the number of the imported migration histories.
+	ImportMigrationHistory(ctx context.Context, database string, source MigrationSource, creator string) (int, error)
+}
+
 // Register makes a database driver available by the provided type.
 // If Register is called twice with the same name or if driver is nil,
 // it panics.
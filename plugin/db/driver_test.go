@@ -206,3 +206,47 @@ func TestParseMigrationInfo(t *testing.T) {
 		require.Equal(t, tc.want, *mi)
 	}
 }
+
+func TestParseSchemaFileInfo(t *testing.T) {
+	mi, err := ParseSchemaFileInfo("bytebase/prod/db1__LATEST.sql", "bytebase/{{ENV_NAME}}/{{DB_NAME}}__LATEST.sql")
+	require.NoError(t, err)
+	require.Equal(t, MigrationInfo{
+		Namespace:   "db1",
+		Database:    "db1",
+		Environment: "prod",
+		Source:      VCS,
+		Type:        MigrateSDL,
+		Description: "Apply db1 desired schema",
+	}, *mi)
+
+	_, err = ParseSchemaFileInfo("bytebase/prod/db1__001.sql", "bytebase/{{ENV_NAME}}/{{DB_NAME}}__LATEST.sql")
+	require.Contains(t, err.Error(), "does not match schema path template")
+
+	_, err = ParseSchemaFileInfo("bytebase/prod/LATEST.sql", "bytebase/{{ENV_NAME}}/LATEST.sql")
+	require.Contains(t, err.Error(), "does not contain {{DB_NAME}}")
+}
+
+func TestCompareMigrationVersion(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"1.2", "1.5", -1},
+		{"1.10", "1.9", 1},
+		{"1.2.0", "1.2", 0},
+		{"v2", "1.9.9", 1},
+		{"0002", "0010", -1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"20220801120000", "20220730000000", 1},
+		{"20220801120000", "20220801120000", 0},
+		{"001foo", "002bar", -1},
+		{"1.2.3.4", "1.2.3.10", -1},
+		{"1.2.3.0", "1.2.3", 0},
+		{"1.2.3.1", "1.2.3", 1},
+		{"99999999999999999999", "100000000000000000000", -1},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.want, CompareMigrationVersion(test.a, test.b), "CompareMigrationVersion(%q, %q)", test.a, test.b)
+	}
+}
@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
+
+	// Register the value expression of the parser.
+	_ "github.com/pingcap/tidb/types/parser_driver"
+)
+
+// GenerateDownStatement generates the statement rolling back the migration if it's purely additive, or returns "" otherwise.
+// The migration is purely additive if it only creates tables, views and indexes, or adds columns and indexes,
+// in which case it's rolled back by dropping the created objects in the reverse order.
+func GenerateDownStatement(statement string) (string, error) {
+	stmtList, _, err := parser.New().Parse(statement, "", "")
+	if err != nil {
+		return "", err
+	}
+	var downList []string
+	for i := len(stmtList) - 1; i >= 0; i-- {
+		down, ok := getDownStatement(stmtList[i])
+		if !ok {
+			return "", nil
+		}
+		downList = append(downList, down)
+	}
+	return strings.Join(downList, "\n"), nil
+}
+
+// getDownStatement returns the statement dropping the objects created by the additive statement, or false if the statement is not additive.
+// The statements with IF NOT EXISTS are not additive, since the objects may exist before.
+func getDownStatement(node ast.StmtNode) (string, bool) {
+	switch stmt := node.(type) {
+	case *ast.CreateTableStmt:
+		if stmt.IfNotExists || stmt.TemporaryKeyword != ast.TemporaryNone {
+			return "", false
+		}
+		return fmt.Sprintf("DROP TABLE %s;", quoteTableName(stmt.Table)), true
+	case *ast.CreateViewStmt:
+		if stmt.OrReplace {
+			return "", false
+		}
+		return fmt.Sprintf("DROP VIEW %s;", quoteTableName(stmt.ViewName)), true
+	case *ast.CreateIndexStmt:
+		if stmt.IfNotExists {
+			return "", false
+		}
+		return fmt.Sprintf("DROP INDEX %s ON %s;", quoteReverseIdentifier(stmt.IndexName), quoteTableName(stmt.Table)), true
+	case *ast.AlterTableStmt:
+		var dropList []string
+		for _, spec := range stmt.Specs {
+			if spec.IfNotExists {
+				return "", false
+			}
+			switch spec.Tp {
+			case ast.AlterTableAddColumns:
+				for _, column := range spec.NewColumns {
+					dropList = append(dropList, fmt.Sprintf("DROP COLUMN %s", quoteReverseIdentifier(column.Name.Name.O)))
+				}
+			case ast.AlterTableAddConstraint:
+				switch spec.Constraint.Tp {
+				case ast.ConstraintKey, ast.ConstraintIndex, ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex, ast.ConstraintFulltext:
+				default:
+					return "", false
+				}
+				if spec.Constraint.Name == "" {
+					return "", false
+				}
+				dropList = append(dropList, fmt.Sprintf("DROP INDEX %s", quoteReverseIdentifier(spec.Constraint.Name)))
+			case ast.AlterTableAlgorithm, ast.AlterTableLock:
+				// The online DDL options don't change the table.
+			default:
+				return "", false
+			}
+		}
+		if len(dropList) == 0 {
+			return "", false
+		}
+		for i, j := 0, len(dropList)-1; i < j; i, j = i+1, j-1 {
+			dropList[i], dropList[j] = dropList[j], dropList[i]
+		}
+		return fmt.Sprintf("ALTER TABLE %s %s;", quoteTableName(stmt.Table), strings.Join(dropList, ", ")), true
+	}
+	return "", false
+}
+
+func quoteTableName(table *ast.TableName) string {
+	if table.Schema.O == "" {
+		return quoteReverseIdentifier(table.Name.O)
+	}
+	return fmt.Sprintf("%s.%s", quoteReverseIdentifier(table.Schema.O), quoteReverseIdentifier(table.Name.O))
+}
@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDownStatement(t *testing.T) {
+	a := require.New(t)
+	tests := []struct {
+		statement string
+		want      string
+	}{
+		{
+			statement: "CREATE TABLE t (id INT PRIMARY KEY, name VARCHAR(20) DEFAULT '');\nCREATE INDEX idx_name ON t (name);",
+			want:      "DROP INDEX `idx_name` ON `t`;\nDROP TABLE `t`;",
+		},
+		{
+			statement: "ALTER TABLE db.t ADD COLUMN a INT DEFAULT 0, ADD COLUMN b INT, ADD UNIQUE KEY uk_a (a), ALGORITHM=INPLACE;",
+			want:      "ALTER TABLE `db`.`t` DROP INDEX `uk_a`, DROP COLUMN `b`, DROP COLUMN `a`;",
+		},
+		{
+			statement: "CREATE VIEW v AS SELECT 1;",
+			want:      "DROP VIEW `v`;",
+		},
+		// The objects may exist before.
+		{
+			statement: "CREATE TABLE IF NOT EXISTS t (id INT);",
+			want:      "",
+		},
+		// The index name is generated.
+		{
+			statement: "ALTER TABLE t ADD INDEX (a);",
+			want:      "",
+		},
+		// Not purely additive.
+		{
+			statement: "CREATE TABLE t (id INT);\nALTER TABLE t MODIFY COLUMN id BIGINT;",
+			want:      "",
+		},
+	}
+
+	for _, test := range tests {
+		got, err := GenerateDownStatement(test.statement)
+		a.NoError(err)
+		a.Equal(test.want, got, test.statement)
+	}
+}
@@ -20,7 +20,8 @@ var (
 	//go:embed mysql_migration_schema.sql
 	migrationSchema string
 
-	_ util.MigrationExecutor = (*Driver)(nil)
+	_ util.MigrationHistoryImportExecutor = (*Driver)(nil)
+	_ db.MigrationHistoryImporter         = (*Driver)(nil)
 )
 
 // NeedsSetupMigration returns whether it needs to setup migration.
@@ -197,11 +198,92 @@ func (Driver) UpdateHistoryAsFailed(ctx context.Context, tx *sql.Tx, migrationDu
 	return err
 }
 
+// UpdateHistoryPayload will update the payload of the migration record.
+func (Driver) UpdateHistoryPayload(ctx context.Context, tx *sql.Tx, payload string, insertedID int64) error {
+	const updateHistoryPayloadQuery = `
+		UPDATE
+			bytebase.migration_history
+		SET
+			payload = ?
+		WHERE id = ?
+		`
+	_, err := tx.ExecContext(ctx, updateHistoryPayloadQuery, payload, insertedID)
+	return err
+}
+
+// FormatImportTable returns the table reference of the migration tool table in the database.
+func (Driver) FormatImportTable(database, table string) string {
+	return fmt.Sprintf("`%s`.%s", database, table)
+}
+
+// FormatUnixTimestamp returns the expression converting the timestamp column to the unix timestamp in seconds.
+func (Driver) FormatUnixTimestamp(column string) string {
+	return fmt.Sprintf("CAST(UNIX_TIMESTAMP(%s) AS SIGNED)", column)
+}
+
+// InsertImportedHistory will insert the migration record imported from the migration tool and return the inserted ID.
+func (Driver) InsertImportedHistory(ctx context.Context, tx *sql.Tx, sequence int, m *db.MigrationInfo, storedVersion, schema string, createdTs int64, executionDurationNs int64) (int64, error) {
+	const insertHistoryQuery = `
+		INSERT INTO bytebase.migration_history (
+			created_by,
+			created_ts,
+			updated_by,
+			updated_ts,
+			release_version,
+			namespace,
+			sequence,
+			source,
+			type,
+			status,
+			version,
+			description,
+			statement,
+			` + "`schema`," + `
+			schema_prev,
+			execution_duration_ns,
+			issue_id,
+			payload
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, '', ?, '', ?, '', ?)
+		`
+	res, err := tx.ExecContext(ctx, insertHistoryQuery,
+		m.Creator,
+		createdTs,
+		m.Creator,
+		createdTs,
+		m.ReleaseVersion,
+		m.Namespace,
+		sequence,
+		m.Source,
+		m.Type,
+		m.Status,
+		storedVersion,
+		m.Description,
+		schema,
+		executionDurationNs,
+		m.Payload,
+	)
+	if err != nil {
+		return int64(0), util.FormatErrorWithQuery(err, insertHistoryQuery)
+	}
+
+	insertedID, err := res.LastInsertId()
+	if err != nil {
+		return int64(0), util.FormatErrorWithQuery(err, insertHistoryQuery)
+	}
+	return insertedID, nil
+}
+
 // ExecuteMigration will execute the migration.
 func (driver *Driver) ExecuteMigration(ctx context.Context, m *db.MigrationInfo, statement string) (int64, string, error) {
 	return util.ExecuteMigration(ctx, driver, m, statement, db.BytebaseDatabase)
 }
 
+// ImportMigrationHistory imports the migration history of the database recorded by the migration tool of the source.
+func (driver *Driver) ImportMigrationHistory(ctx context.Context, database string, source db.MigrationSource, creator string) (int, error) {
+	return util.ImportMigrationHistory(ctx, driver, database, source, creator, db.BytebaseDatabase)
+}
+
 // FindMigrationHistoryList finds the migration history.
 func (driver *Driver) FindMigrationHistoryList(ctx context.Context, find *db.MigrationHistoryFind) ([]*db.MigrationHistory, error) {
 	baseQuery := `
@@ -12,6 +12,7 @@ import (
 	"github.com/bytebase/bytebase/plugin/db/util"
 	"github.com/bytebase/bytebase/resources/mysqlutil"
 	"github.com/go-sql-driver/mysql"
+	"github.com/pingcap/tidb/parser"
 	"go.uber.org/zap"
 )
 
@@ -20,6 +21,10 @@ var (
 	viewTableType = "VIEW"
 
 	_ db.Driver = (*Driver)(nil)
+
+	// implicitCommitStatementPrefixList is the prefix list of the statements causing an implicit commit.
+	// https://dev.mysql.com/doc/refman/8.0/en/implicit-commit.html
+	implicitCommitStatementPrefixList = []string{"CREATE ", "ALTER ", "DROP ", "RENAME ", "TRUNCATE "}
 )
 
 func init() {
@@ -147,22 +152,142 @@ func (driver *Driver) getVersion(ctx context.Context) (string, error) {
 }
 
 // Execute executes a SQL statement.
+// The running query is killed on the server if ctx is canceled.
 func (driver *Driver) Execute(ctx context.Context, statement string) error {
-	tx, err := driver.db.BeginTx(ctx, nil)
+	statementList := splitMultiStatements(statement)
+	resultList := make([]*db.StatementResult, len(statementList))
+	for i, stmt := range statementList {
+		resultList[i] = util.NewStatementResult(i, stmt)
+	}
+	defer func() {
+		db.GetStatementRecorder(ctx).Record(resultList...)
+	}()
+
+	// Use a dedicated connection so that we know which connection to kill.
+	conn, err := driver.db.Conn(ctx)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	defer conn.Close()
 
-	_, err = tx.ExecContext(ctx, statement)
+	var connectionID int64
+	if err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&connectionID); err != nil {
+		return err
+	}
+	stop := util.WatchCancel(ctx, func(killCtx context.Context) error {
+		return driver.killQuery(killCtx, connectionID)
+	})
+	defer stop()
 
-	if err == nil {
-		if err := tx.Commit(); err != nil {
+	// Take the pre-change backup before the transaction, since creating the backup tables commits implicitly.
+	if recorder := db.GetDataBackupRecorder(ctx); recorder != nil {
+		if err := util.BackupData(ctx, conn, driver.dbType, statementList, recorder); err != nil {
+			return fmt.Errorf("failed to back up the data before the change, error: %w", err)
+		}
+	}
+
+	// Record the binlog range written by the statements on the connection, so that the row changes can be reversed later.
+	// It's best effort and doesn't fail the execution.
+	if recorder := db.GetBinlogRangeRecorder(ctx); recorder != nil {
+		binlogRange, err := driver.getBinlogRangeStart(ctx, conn, connectionID)
+		if err != nil {
+			log.Warn("Failed to get the binlog coordinate before the change", zap.Error(err))
+		}
+		if binlogRange != nil {
+			defer func() {
+				// The context may have been canceled, while the binlog range is still needed to reverse the committed changes.
+				if err := setBinlogRangeEnd(context.Background(), conn, binlogRange); err != nil {
+					log.Warn("Failed to get the binlog coordinate after the change", zap.Error(err))
+					return
+				}
+				recorder.Record(binlogRange)
+			}()
+		}
+	}
+
+	// The batched data update executes the statements in batches of primary key ranges instead of a single transaction.
+	if controller := db.GetBatchController(ctx); controller != nil {
+		return util.ExecuteInBatches(ctx, conn, driver.dbType, statementList, resultList, controller)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, stmt := range statementList {
+		if err := util.ExecuteStatement(ctx, tx, resultList[i], stmt); err != nil {
+			markImplicitlyCommitted(resultList)
 			return err
 		}
 	}
+	if err := tx.Commit(); err != nil {
+		markImplicitlyCommitted(resultList)
+		return err
+	}
+	for _, result := range resultList {
+		result.Committed = true
+	}
+	return nil
+}
+
+// splitMultiStatements splits the statement into single statements with the TiDB parser, which understands the string literals and comments.
+// The statement is executed as a whole if it can't be parsed, e.g. for the stored procedures with BEGIN...END bodies.
+func splitMultiStatements(statement string) []string {
+	nodeList, _, err := parser.New().Parse(statement, "", "")
+	if err != nil || len(nodeList) == 0 {
+		return []string{statement}
+	}
+	var statementList []string
+	for _, node := range nodeList {
+		statementList = append(statementList, node.Text())
+	}
+	return statementList
+}
+
+// markImplicitlyCommitted marks the statements committed by the implicit commit after the transaction fails.
+// A statement causing an implicit commit commits the preceding statements before it executes, and commits itself on success.
+func markImplicitlyCommitted(resultList []*db.StatementResult) {
+	for i, result := range resultList {
+		if result.Status == db.StatementSkipped {
+			break
+		}
+		if !isImplicitCommitStatement(result.Statement) {
+			continue
+		}
+		for _, preceding := range resultList[:i] {
+			preceding.Committed = true
+		}
+		result.Committed = result.Status == db.StatementDone
+	}
+}
+
+func isImplicitCommitStatement(statement string) bool {
+	statement = strings.ToUpper(strings.TrimSpace(statement))
+	// Creating temporary tables doesn't cause an implicit commit.
+	if strings.HasPrefix(statement, "CREATE TEMPORARY ") {
+		return false
+	}
+	for _, prefix := range implicitCommitStatementPrefixList {
+		if strings.HasPrefix(statement, prefix) {
+			return true
+		}
+	}
+	return false
+}
 
-	return err
+// killQuery kills the query running on the connection.
+func (driver *Driver) killQuery(ctx context.Context, connectionID int64) error {
+	// TiDB doesn't support killing the query only, so we kill the connection instead.
+	query := fmt.Sprintf("KILL QUERY %d", connectionID)
+	if driver.dbType == db.TiDB {
+		query = fmt.Sprintf("KILL TIDB %d", connectionID)
+	}
+	if _, err := driver.db.ExecContext(ctx, query); err != nil {
+		return util.FormatErrorWithQuery(err, query)
+	}
+	return nil
 }
 
 // Query queries a SQL statement.
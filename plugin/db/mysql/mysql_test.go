@@ -0,0 +1,85 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+func TestMarkImplicitlyCommitted(t *testing.T) {
+	tests := []struct {
+		name          string
+		statementList []string
+		// statusList is the status list of the statements, and the remaining statements are skipped.
+		statusList []db.StatementStatus
+		want       []bool
+	}{
+		{
+			name:          "DML only",
+			statementList: []string{"INSERT INTO t VALUES (1);", "UPDATE t SET a = 1;", "DELETE FROM t;"},
+			statusList:    []db.StatementStatus{db.StatementDone, db.StatementFailed},
+			want:          []bool{false, false, false},
+		},
+		{
+			name:          "DDL commits the preceding statements",
+			statementList: []string{"INSERT INTO t VALUES (1);", "ALTER TABLE t ADD COLUMN b INT;", "UPDATE t SET b = 1;", "DELETE FROM t;"},
+			statusList:    []db.StatementStatus{db.StatementDone, db.StatementDone, db.StatementDone, db.StatementFailed},
+			want:          []bool{true, true, false, false},
+		},
+		{
+			name:          "failed DDL commits the preceding statements only",
+			statementList: []string{"INSERT INTO t VALUES (1);", "create table t2 (id INT);", "DROP TABLE t3;"},
+			statusList:    []db.StatementStatus{db.StatementDone, db.StatementFailed},
+			want:          []bool{true, false, false},
+		},
+		{
+			name:          "temporary table",
+			statementList: []string{"INSERT INTO t VALUES (1);", "CREATE TEMPORARY TABLE t2 (id INT);", "UPDATE t SET a = 1;"},
+			statusList:    []db.StatementStatus{db.StatementDone, db.StatementDone, db.StatementFailed},
+			want:          []bool{false, false, false},
+		},
+	}
+
+	for _, test := range tests {
+		var resultList []*db.StatementResult
+		for i, stmt := range test.statementList {
+			result := &db.StatementResult{Index: i, Statement: stmt, Status: db.StatementSkipped}
+			if i < len(test.statusList) {
+				result.Status = test.statusList[i]
+			}
+			resultList = append(resultList, result)
+		}
+		markImplicitlyCommitted(resultList)
+		var got []bool
+		for _, result := range resultList {
+			got = append(got, result.Committed)
+		}
+		require.Equal(t, test.want, got, test.name)
+	}
+}
+
+func TestSplitMultiStatements(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      []string
+	}{
+		{
+			statement: "INSERT INTO t VALUES ('a;\nb');\nUPDATE t SET a = 1;",
+			want:      []string{"INSERT INTO t VALUES ('a;\nb');", "UPDATE t SET a = 1;"},
+		},
+		{
+			statement: "/*!40101 SET NAMES utf8 */;\nDELETE FROM t;",
+			want:      []string{"/*!40101 SET NAMES utf8 */;", "DELETE FROM t;"},
+		},
+		{
+			// The statement is executed as a whole if it can't be parsed.
+			statement: "CREATE PROCEDURE p()\nBEGIN\n  UPDATE t SET a = 1;\nEND;",
+			want:      []string{"CREATE PROCEDURE p()\nBEGIN\n  UPDATE t SET a = 1;\nEND;"},
+		},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.want, splitMultiStatements(test.statement), test.statement)
+	}
+}
@@ -0,0 +1,478 @@
+package mysql
+
+// This file implements generating the reverse statements of a data update from the binlog.
+// The binlog range of the data update is recorded around its execution, and the row events written by its connection
+// are decoded by mysqlbinlog and reversed in the reverse order:
+// 1. INSERT is reversed by DELETE.
+// 2. DELETE is reversed by INSERT with the before-image.
+// 3. UPDATE is reversed by UPDATE setting the changed columns back to the before-image.
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+	"github.com/bytebase/bytebase/resources/mysqlutil"
+)
+
+var (
+	// The header line of the query event, e.g. "#220601 12:00:00 server id 1  end_log_pos 1300 CRC32 0x2e7a5bc1 	Query	thread_id=8	exec_time=0	error_code=0".
+	binlogQueryEventRegexp = regexp.MustCompile(`\tQuery\tthread_id=(\d+)`)
+	// The table of the row, e.g. "`db`.`tbl`".
+	binlogTableRegexp = regexp.MustCompile("^`((?:[^`]|``)+)`\\.`((?:[^`]|``)+)`$")
+	// The column value of the row, e.g. "@1=10".
+	binlogColumnValueRegexp = regexp.MustCompile(`^@(\d+)=(.*)$`)
+)
+
+type binlogRowType string
+
+const (
+	binlogRowInsert binlogRowType = "INSERT"
+	binlogRowUpdate binlogRowType = "UPDATE"
+	binlogRowDelete binlogRowType = "DELETE"
+)
+
+// binlogValue is a column value decoded by mysqlbinlog.
+type binlogValue struct {
+	isNull bool
+	// isString is true for the quoted values, e.g. the strings, the dates and the datetimes.
+	isString bool
+	// text is the unquoted string for the quoted values, and the literal for the others.
+	text string
+	// unsignedText is the value as an unsigned integer, which mysqlbinlog prints in parentheses after the negative integers.
+	unsignedText string
+}
+
+// binlogRow is a row changed by a row event, in the order of the binlog.
+type binlogRow struct {
+	rowType  binlogRowType
+	database string
+	table    string
+	// before is the before-image of UPDATE and DELETE, indexed by the ordinal position of the column starting from 0.
+	before []*binlogValue
+	// after is the after-image of INSERT and UPDATE, indexed by the ordinal position of the column starting from 0.
+	after []*binlogValue
+}
+
+// reverseColumn is a column of the table to reverse the row changes of.
+type reverseColumn struct {
+	name       string
+	dataType   string
+	unsigned   bool
+	generated  bool
+	primaryKey bool
+}
+
+// getBinlogRangeStart returns the binlog range starting at the current binlog coordinate, or nil if the row changes cannot be reversed from the binlog.
+// Reversing requires the row-based binlog with the full row images.
+func (driver *Driver) getBinlogRangeStart(ctx context.Context, conn *sql.Conn, connectionID int64) (*db.BinlogRange, error) {
+	if driver.dbType != db.MySQL {
+		return nil, nil
+	}
+	var logBin, binlogFormat, binlogRowImage string
+	query := "SELECT @@log_bin, @@binlog_format, @@binlog_row_image"
+	if err := conn.QueryRowContext(ctx, query).Scan(&logBin, &binlogFormat, &binlogRowImage); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	if logBin != "1" || !strings.EqualFold(binlogFormat, "ROW") || !strings.EqualFold(binlogRowImage, "FULL") {
+		return nil, nil
+	}
+	binlogInfo, err := GetBinlogInfo(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	return &db.BinlogRange{
+		ThreadID:      connectionID,
+		StartFileName: binlogInfo.FileName,
+		StartPosition: binlogInfo.Position,
+	}, nil
+}
+
+// setBinlogRangeEnd sets the end of the binlog range to the current binlog coordinate.
+func setBinlogRangeEnd(ctx context.Context, conn *sql.Conn, binlogRange *db.BinlogRange) error {
+	binlogInfo, err := GetBinlogInfo(ctx, conn)
+	if err != nil {
+		return err
+	}
+	binlogRange.EndFileName = binlogInfo.FileName
+	binlogRange.EndPosition = binlogInfo.Position
+	return nil
+}
+
+// GenerateReverseStatement generates the statements reversing the row changes made by the data update on the database in the binlog range.
+// The binlog files are downloaded to the binlog directory set up by SetUpForPITR.
+func (driver *Driver) GenerateReverseStatement(ctx context.Context, database string, binlogRange *db.BinlogRange) (string, error) {
+	if err := driver.FetchAllBinlogFiles(ctx, true /* downloadLatestBinlogFile */); err != nil {
+		return "", fmt.Errorf("failed to download binlog files, error: %w", err)
+	}
+	pathList, err := getBinlogReverseList(binlogRange, driver.binlogDir)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{
+		// Tell mysqlbinlog to suppress the BINLOG statements for row events, and decode the row events as pseudo-SQL statements instead.
+		"--base64-output=DECODE-ROWS",
+		"--verbose",
+		// Start decoding the binary log at the log position, this option applies to the first log file named on the command line.
+		"--start-position", fmt.Sprintf("%d", binlogRange.StartPosition),
+		// Stop decoding the binary log at the log position, this option applies to the last log file named on the command line.
+		"--stop-position", fmt.Sprintf("%d", binlogRange.EndPosition),
+	}
+	args = append(args, pathList...)
+	cmd := exec.CommandContext(ctx, driver.mysqlutil.GetPath(mysqlutil.MySQLBinlog), args...)
+	cmd.Stderr = os.Stderr
+	pr, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	log.Debug("Decoding binlog files using mysqlbinlog", zap.String("cmd", cmd.String()))
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	rowList, err := parseBinlogRowList(pr, binlogRange.ThreadID, database)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return "", fmt.Errorf("failed to parse mysqlbinlog output, error: %w", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return "", fmt.Errorf("executing mysqlbinlog fails, error: %w", err)
+	}
+
+	tableMap := make(map[string][]*reverseColumn)
+	for _, row := range rowList {
+		if _, ok := tableMap[row.table]; ok {
+			continue
+		}
+		columnList, err := driver.getReverseColumnList(ctx, database, row.table)
+		if err != nil {
+			return "", err
+		}
+		tableMap[row.table] = columnList
+	}
+	return buildReverseStatement(rowList, tableMap)
+}
+
+// getBinlogReverseList returns the path list of the binlog files in the binlog range.
+func getBinlogReverseList(binlogRange *db.BinlogRange, binlogDir string) ([]string, error) {
+	endBinlogSeq, err := GetBinlogNameSeq(binlogRange.EndFileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the end binlog file name %q, error: %w", binlogRange.EndFileName, err)
+	}
+	pathList, err := getBinlogReplayList(api.BinlogInfo{FileName: binlogRange.StartFileName, Position: binlogRange.StartPosition}, binlogDir)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, binlogPath := range pathList {
+		seq, err := GetBinlogNameSeq(filepath.Base(binlogPath))
+		if err != nil {
+			return nil, err
+		}
+		if seq > endBinlogSeq {
+			break
+		}
+		result = append(result, binlogPath)
+	}
+	return result, nil
+}
+
+// getReverseColumnList returns the columns of the table in the ordinal position order.
+// The columns must not have changed since the data update, since the binlog identifies the columns by their ordinal positions.
+func (driver *Driver) getReverseColumnList(ctx context.Context, database, table string) ([]*reverseColumn, error) {
+	query := `
+		SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, COLUMN_KEY, EXTRA
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`
+	rows, err := driver.db.QueryContext(ctx, query, database, table)
+	if err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var columnList []*reverseColumn
+	for rows.Next() {
+		var columnType, columnKey, extra string
+		column := &reverseColumn{}
+		if err := rows.Scan(&column.name, &column.dataType, &columnType, &columnKey, &extra); err != nil {
+			return nil, err
+		}
+		column.dataType = strings.ToLower(column.dataType)
+		column.unsigned = strings.Contains(strings.ToLower(columnType), "unsigned")
+		column.generated = strings.Contains(strings.ToUpper(extra), "GENERATED")
+		column.primaryKey = columnKey == "PRI"
+		columnList = append(columnList, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, util.FormatErrorWithQuery(err, query)
+	}
+	if len(columnList) == 0 {
+		return nil, common.Errorf(common.NotFound, "table %q not found in database %q", table, database)
+	}
+	return columnList, nil
+}
+
+// parseBinlogRowList parses the rows changed by the connection with threadID on the database from the mysqlbinlog output decoded with --verbose.
+//
+// The rows are decoded as the pseudo-SQL statements like:
+//
+//	### UPDATE `db`.`tbl`
+//	### WHERE
+//	###   @1=1
+//	###   @2='foo'
+//	### SET
+//	###   @1=1
+//	###   @2='bar'
+func parseBinlogRowList(r io.Reader, threadID int64, database string) ([]*binlogRow, error) {
+	var rowList []*binlogRow
+	var currentThreadID int64
+	var row *binlogRow
+	// image is the image of the row which the column values belong to.
+	var image *[]*binlogValue
+
+	s := bufio.NewScanner(r)
+	// The decoded rows with large values, e.g. BLOB, may exceed the default buffer size.
+	s.Buffer(make([]byte, bufio.MaxScanTokenSize), 1024*1024*1024)
+	for s.Scan() {
+		line := s.Text()
+		if match := binlogQueryEventRegexp.FindStringSubmatch(line); match != nil {
+			id, err := strconv.ParseInt(match[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid thread id in line %q, error: %w", line, err)
+			}
+			currentThreadID = id
+			continue
+		}
+		if !strings.HasPrefix(line, "### ") {
+			continue
+		}
+		content := strings.TrimPrefix(line, "### ")
+
+		var rowType binlogRowType
+		var qualifiedTable string
+		switch {
+		case strings.HasPrefix(content, "INSERT INTO "):
+			rowType, qualifiedTable = binlogRowInsert, strings.TrimPrefix(content, "INSERT INTO ")
+		case strings.HasPrefix(content, "UPDATE "):
+			rowType, qualifiedTable = binlogRowUpdate, strings.TrimPrefix(content, "UPDATE ")
+		case strings.HasPrefix(content, "DELETE FROM "):
+			rowType, qualifiedTable = binlogRowDelete, strings.TrimPrefix(content, "DELETE FROM ")
+		case content == "SET":
+			if row != nil {
+				image = &row.after
+			}
+			continue
+		case content == "WHERE":
+			if row != nil {
+				image = &row.before
+			}
+			continue
+		default:
+			if row == nil || image == nil {
+				continue
+			}
+			match := binlogColumnValueRegexp.FindStringSubmatch(strings.TrimSpace(content))
+			if match == nil {
+				return nil, fmt.Errorf("invalid column value in line %q", line)
+			}
+			position, err := strconv.Atoi(match[1])
+			if err != nil || position != len(*image)+1 {
+				return nil, fmt.Errorf("unexpected column position in line %q", line)
+			}
+			value, err := parseBinlogValue(match[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid column value in line %q, error: %w", line, err)
+			}
+			*image = append(*image, value)
+			continue
+		}
+
+		row, image = nil, nil
+		if currentThreadID != threadID {
+			continue
+		}
+		match := binlogTableRegexp.FindStringSubmatch(qualifiedTable)
+		if match == nil {
+			return nil, fmt.Errorf("invalid table in line %q", line)
+		}
+		rowDatabase, rowTable := strings.ReplaceAll(match[1], "``", "`"), strings.ReplaceAll(match[2], "``", "`")
+		if rowDatabase != database {
+			continue
+		}
+		row = &binlogRow{
+			rowType:  rowType,
+			database: rowDatabase,
+			table:    rowTable,
+		}
+		rowList = append(rowList, row)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return rowList, nil
+}
+
+// parseBinlogValue parses the column value printed by mysqlbinlog.
+// The quoted values print the bytes below 0x20, the single quote and the backslash as "\xNN".
+// The negative integers are followed by the value as an unsigned integer in parentheses, e.g. "-1 (4294967295)".
+func parseBinlogValue(text string) (*binlogValue, error) {
+	text = strings.TrimSpace(text)
+	if text == "NULL" {
+		return &binlogValue{isNull: true}, nil
+	}
+	if strings.HasPrefix(text, "'") {
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("unterminated quoted value %q", text)
+		}
+		quoted := text[1 : len(text)-1]
+		var b strings.Builder
+		for i := 0; i < len(quoted); i++ {
+			if quoted[i] == '\\' && i+3 < len(quoted) && quoted[i+1] == 'x' {
+				n, err := strconv.ParseUint(quoted[i+2:i+4], 16, 8)
+				if err != nil {
+					return nil, fmt.Errorf("invalid escape in quoted value %q", text)
+				}
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+			b.WriteByte(quoted[i])
+		}
+		return &binlogValue{isString: true, text: b.String()}, nil
+	}
+	value := &binlogValue{text: text}
+	if i := strings.Index(text, " ("); i >= 0 && strings.HasSuffix(text, ")") {
+		value.text = text[:i]
+		value.unsignedText = text[i+2 : len(text)-1]
+	}
+	return value, nil
+}
+
+// buildReverseStatement builds the statements reversing the rows in the reverse order, one statement per line.
+// The rows are located by the primary key, or by all the columns if the table has no primary key.
+func buildReverseStatement(rowList []*binlogRow, tableMap map[string][]*reverseColumn) (string, error) {
+	var statementList []string
+	for i := len(rowList) - 1; i >= 0; i-- {
+		row := rowList[i]
+		columnList := tableMap[row.table]
+		for _, image := range [][]*binlogValue{row.before, row.after} {
+			if image != nil && len(image) != len(columnList) {
+				return "", fmt.Errorf("table %q has %d columns but the binlog row has %d columns, the table may have changed since the data update", row.table, len(columnList), len(image))
+			}
+		}
+		table := quoteReverseIdentifier(row.table)
+		switch row.rowType {
+		case binlogRowInsert:
+			where, limit := buildReverseCondition(columnList, row.after)
+			statementList = append(statementList, fmt.Sprintf("DELETE FROM %s WHERE %s%s;", table, where, limit))
+		case binlogRowDelete:
+			var nameList, valueList []string
+			for i, column := range columnList {
+				if column.generated {
+					continue
+				}
+				nameList = append(nameList, quoteReverseIdentifier(column.name))
+				valueList = append(valueList, formatBinlogValue(column, row.before[i]))
+			}
+			statementList = append(statementList, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);", table, strings.Join(nameList, ", "), strings.Join(valueList, ", ")))
+		case binlogRowUpdate:
+			var setList []string
+			for i, column := range columnList {
+				if column.generated || *row.before[i] == *row.after[i] {
+					continue
+				}
+				setList = append(setList, fmt.Sprintf("%s = %s", quoteReverseIdentifier(column.name), formatBinlogValue(column, row.before[i])))
+			}
+			if len(setList) == 0 {
+				continue
+			}
+			where, limit := buildReverseCondition(columnList, row.after)
+			statementList = append(statementList, fmt.Sprintf("UPDATE %s SET %s WHERE %s%s;", table, strings.Join(setList, ", "), where, limit))
+		}
+	}
+	return strings.Join(statementList, "\n"), nil
+}
+
+// buildReverseCondition builds the condition locating the row with the image, and the limit for the tables without the primary key.
+func buildReverseCondition(columnList []*reverseColumn, image []*binlogValue) (string, string) {
+	var conditionList []string
+	for i, column := range columnList {
+		if column.primaryKey {
+			conditionList = append(conditionList, fmt.Sprintf("%s = %s", quoteReverseIdentifier(column.name), formatBinlogValue(column, image[i])))
+		}
+	}
+	if len(conditionList) > 0 {
+		return strings.Join(conditionList, " AND "), ""
+	}
+	for i, column := range columnList {
+		if column.generated {
+			continue
+		}
+		conditionList = append(conditionList, fmt.Sprintf("%s <=> %s", quoteReverseIdentifier(column.name), formatBinlogValue(column, image[i])))
+	}
+	return strings.Join(conditionList, " AND "), " LIMIT 1"
+}
+
+// formatBinlogValue formats the column value as a SQL literal.
+func formatBinlogValue(column *reverseColumn, value *binlogValue) string {
+	switch {
+	case value.isNull:
+		return "NULL"
+	case value.isString:
+		return quoteReverseString(value.text)
+	case column.dataType == "timestamp":
+		// mysqlbinlog prints the TIMESTAMP values as the seconds since the epoch.
+		return fmt.Sprintf("FROM_UNIXTIME(%s)", value.text)
+	case column.unsigned && value.unsignedText != "":
+		return value.unsignedText
+	default:
+		return value.text
+	}
+}
+
+func quoteReverseIdentifier(identifier string) string {
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(identifier, "`", "``"))
+}
+
+// quoteReverseString quotes the string, escaping the line breaks so that each statement stays in a single line.
+func quoteReverseString(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case 0:
+			b.WriteString(`\0`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case 0x1a:
+			b.WriteString(`\Z`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package mysql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBinlogValue(t *testing.T) {
+	a := require.New(t)
+	tests := []struct {
+		text     string
+		expected binlogValue
+	}{
+		{
+			text:     "NULL",
+			expected: binlogValue{isNull: true},
+		},
+		{
+			text:     "10",
+			expected: binlogValue{text: "10"},
+		},
+		{
+			text:     "-1 (4294967295)",
+			expected: binlogValue{text: "-1", unsignedText: "4294967295"},
+		},
+		{
+			text:     "'it\\x27s a\\x0aline\\x5c'",
+			expected: binlogValue{isString: true, text: "it's a\nline\\"},
+		},
+		{
+			text:     "'2022:06:01'",
+			expected: binlogValue{isString: true, text: "2022:06:01"},
+		},
+	}
+
+	for _, test := range tests {
+		value, err := parseBinlogValue(test.text)
+		a.NoError(err)
+		a.Equal(test.expected, *value, test.text)
+	}
+
+	_, err := parseBinlogValue("'unterminated")
+	a.Error(err)
+}
+
+func TestReverseStatement(t *testing.T) {
+	a := require.New(t)
+	output := strings.Join([]string{
+		"# at 4",
+		"#220601 12:00:00 server id 1  end_log_pos 300 CRC32 0x2e7a5bc1 \tQuery\tthread_id=8\texec_time=0\terror_code=0",
+		"BEGIN",
+		"/*!*/;",
+		"#220601 12:00:00 server id 1  end_log_pos 350 CRC32 0x2e7a5bc1 \tTable_map: `db`.`tbl` mapped to number 90",
+		"### INSERT INTO `db`.`tbl`",
+		"### SET",
+		"###   @1=1",
+		"###   @2='foo'",
+		"###   @3=-1 (4294967295)",
+		"### UPDATE `db`.`tbl`",
+		"### WHERE",
+		"###   @1=2",
+		"###   @2='bar'",
+		"###   @3=3",
+		"### SET",
+		"###   @1=2",
+		"###   @2='baz'",
+		"###   @3=3",
+		// The rows of the backup database are skipped.
+		"### INSERT INTO `bbdataarchive`.`tbl`",
+		"### SET",
+		"###   @1=3",
+		"### DELETE FROM `db`.`log`",
+		"### WHERE",
+		"###   @1='it\\x27s'",
+		"###   @2=NULL",
+		"###   @3=1654041600",
+		"COMMIT/*!*/;",
+		// The rows of the other connections are skipped.
+		"#220601 12:00:01 server id 1  end_log_pos 500 CRC32 0x2e7a5bc1 \tQuery\tthread_id=9\texec_time=0\terror_code=0",
+		"BEGIN",
+		"### DELETE FROM `db`.`tbl`",
+		"### WHERE",
+		"###   @1=4",
+		"###   @2='qux'",
+		"###   @3=4",
+		"COMMIT/*!*/;",
+	}, "\n")
+
+	rowList, err := parseBinlogRowList(strings.NewReader(output), 8, "db")
+	a.NoError(err)
+	a.Len(rowList, 3)
+
+	tableMap := map[string][]*reverseColumn{
+		"tbl": {
+			{name: "id", dataType: "int", primaryKey: true},
+			{name: "name", dataType: "varchar"},
+			{name: "count", dataType: "int", unsigned: true},
+		},
+		"log": {
+			{name: "message", dataType: "text"},
+			{name: "length", dataType: "int", generated: true},
+			{name: "created_ts", dataType: "timestamp"},
+		},
+	}
+	statement, err := buildReverseStatement(rowList, tableMap)
+	a.NoError(err)
+	a.Equal(strings.Join([]string{
+		"INSERT INTO `log` (`message`, `created_ts`) VALUES ('it\\'s', FROM_UNIXTIME(1654041600));",
+		"UPDATE `tbl` SET `name` = 'bar' WHERE `id` = 2;",
+		"DELETE FROM `tbl` WHERE `id` = 1;",
+	}, "\n"), statement)
+
+	// The rows are located by all the columns without the primary key.
+	tableMap["tbl"][0].primaryKey = false
+	statement, err = buildReverseStatement(rowList[:1], tableMap)
+	a.NoError(err)
+	a.Equal("DELETE FROM `tbl` WHERE `id` <=> 1 AND `name` <=> 'foo' AND `count` <=> 4294967295 LIMIT 1;", statement)
+
+	// The table has changed since the data update.
+	tableMap["tbl"] = tableMap["tbl"][:2]
+	_, err = buildReverseStatement(rowList[:1], tableMap)
+	a.Error(err)
+}
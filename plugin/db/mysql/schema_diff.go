@@ -0,0 +1,413 @@
+package mysql
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/format"
+	parsermysql "github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/types"
+)
+
+// schemaState is the tables and views declared by a schema.
+type schemaState struct {
+	tableList []*ast.CreateTableStmt
+	tableMap  map[string]*ast.CreateTableStmt
+	viewList  []*ast.CreateViewStmt
+	viewMap   map[string]*ast.CreateViewStmt
+}
+
+// GenerateSchemaDiff generates the statement migrating the database from the old schema to the new schema, or returns "" if they're the same.
+// The tables, columns, indexes, constraints and views are compared, while the routines, events and triggers are not managed.
+// A renamed column or table is dropped and recreated, since a rename can't be told from the schemas.
+func GenerateSchemaDiff(oldSchema, newSchema string) (string, error) {
+	oldState, err := parseSchemaState(oldSchema)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the current schema, error: %w", err)
+	}
+	newState, err := parseSchemaState(newSchema)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the desired schema, error: %w", err)
+	}
+
+	var statementList []string
+	// Drop the views first since they may depend on the tables to drop.
+	for _, view := range oldState.viewList {
+		if _, ok := newState.viewMap[view.ViewName.Name.O]; !ok {
+			statementList = append(statementList, fmt.Sprintf("DROP VIEW %s;", quoteTableName(view.ViewName)))
+		}
+	}
+	for _, table := range oldState.tableList {
+		if _, ok := newState.tableMap[table.Table.Name.O]; !ok {
+			statementList = append(statementList, fmt.Sprintf("DROP TABLE %s;", quoteTableName(table.Table)))
+		}
+	}
+	// Create the tables before altering the others, since the foreign keys to add may reference them.
+	for _, table := range newState.tableList {
+		if _, ok := oldState.tableMap[table.Table.Name.O]; ok {
+			continue
+		}
+		text, err := restoreNode(table)
+		if err != nil {
+			return "", err
+		}
+		statementList = append(statementList, text+";")
+	}
+	for _, table := range newState.tableList {
+		oldTable, ok := oldState.tableMap[table.Table.Name.O]
+		if !ok {
+			continue
+		}
+		specList, err := getAlterTableSpecList(oldTable, table)
+		if err != nil {
+			return "", err
+		}
+		if len(specList) > 0 {
+			statementList = append(statementList, fmt.Sprintf("ALTER TABLE %s %s;", quoteTableName(table.Table), strings.Join(specList, ", ")))
+		}
+	}
+	for _, view := range newState.viewList {
+		oldView, ok := oldState.viewMap[view.ViewName.Name.O]
+		if ok {
+			oldSelect, err := restoreNode(oldView.Select)
+			if err != nil {
+				return "", err
+			}
+			newSelect, err := restoreNode(view.Select)
+			if err != nil {
+				return "", err
+			}
+			if oldSelect == newSelect {
+				continue
+			}
+		}
+		view.OrReplace = ok
+		text, err := restoreNode(view)
+		if err != nil {
+			return "", err
+		}
+		statementList = append(statementList, text+";")
+	}
+	return strings.Join(statementList, "\n"), nil
+}
+
+// parseSchemaState parses the tables and views of the schema.
+// The CREATE INDEX statements are folded into the tables, and the routines, events and triggers in DELIMITER blocks are skipped.
+func parseSchemaState(schema string) (*schemaState, error) {
+	stmtList, _, err := parser.New().Parse(removeDelimiterBlocks(schema), "", "")
+	if err != nil {
+		return nil, err
+	}
+	state := &schemaState{
+		tableMap: make(map[string]*ast.CreateTableStmt),
+		viewMap:  make(map[string]*ast.CreateViewStmt),
+	}
+	for _, stmt := range stmtList {
+		switch stmt := stmt.(type) {
+		case *ast.CreateTableStmt:
+			if _, ok := state.tableMap[stmt.Table.Name.O]; ok {
+				return nil, fmt.Errorf("table %q is declared more than once", stmt.Table.Name.O)
+			}
+			stmt.IfNotExists = false
+			liftColumnConstraints(stmt)
+			state.tableList = append(state.tableList, stmt)
+			state.tableMap[stmt.Table.Name.O] = stmt
+		case *ast.CreateIndexStmt:
+			table, ok := state.tableMap[stmt.Table.Name.O]
+			if !ok {
+				return nil, fmt.Errorf("index %q is declared before its table %q", stmt.IndexName, stmt.Table.Name.O)
+			}
+			constraint := &ast.Constraint{Tp: ast.ConstraintIndex, Name: stmt.IndexName, Keys: stmt.IndexPartSpecifications, Option: stmt.IndexOption}
+			switch stmt.KeyType {
+			case ast.IndexKeyTypeUnique:
+				constraint.Tp = ast.ConstraintUniq
+			case ast.IndexKeyTypeFullText:
+				constraint.Tp = ast.ConstraintFulltext
+			}
+			table.Constraints = append(table.Constraints, constraint)
+		case *ast.CreateViewStmt:
+			if _, ok := state.viewMap[stmt.ViewName.Name.O]; ok {
+				return nil, fmt.Errorf("view %q is declared more than once", stmt.ViewName.Name.O)
+			}
+			stmt.OrReplace = false
+			state.viewList = append(state.viewList, stmt)
+			state.viewMap[stmt.ViewName.Name.O] = stmt
+		case *ast.SetStmt, *ast.UseStmt:
+		default:
+			return nil, fmt.Errorf("unsupported statement %q, only CREATE TABLE, CREATE INDEX and CREATE VIEW are supported", stmt.Text())
+		}
+	}
+	return state, nil
+}
+
+// removeDelimiterBlocks removes the statements between "DELIMITER ;;" and "DELIMITER ;", which are the routines, events and triggers in the dump.
+func removeDelimiterBlocks(schema string) string {
+	var sb strings.Builder
+	inBlock := false
+	scanner := bufio.NewScanner(strings.NewReader(schema))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(schema)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(strings.ToUpper(trimmed), "DELIMITER ") {
+			inBlock = strings.TrimSpace(trimmed[len("DELIMITER "):]) != ";"
+			continue
+		}
+		if inBlock {
+			continue
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// liftColumnConstraints moves the column level PRIMARY KEY and UNIQUE to the table constraints, the same way as SHOW CREATE TABLE shows them.
+func liftColumnConstraints(table *ast.CreateTableStmt) {
+	for _, column := range table.Cols {
+		var optionList []*ast.ColumnOption
+		notNull := false
+		primaryKey := false
+		for _, option := range column.Options {
+			switch option.Tp {
+			case ast.ColumnOptionPrimaryKey:
+				primaryKey = true
+				table.Constraints = append(table.Constraints, &ast.Constraint{
+					Tp:   ast.ConstraintPrimaryKey,
+					Keys: []*ast.IndexPartSpecification{{Column: column.Name, Length: types.UnspecifiedLength}},
+				})
+				continue
+			case ast.ColumnOptionUniqKey:
+				table.Constraints = append(table.Constraints, &ast.Constraint{
+					Tp:   ast.ConstraintUniq,
+					Name: column.Name.Name.O,
+					Keys: []*ast.IndexPartSpecification{{Column: column.Name, Length: types.UnspecifiedLength}},
+				})
+				continue
+			case ast.ColumnOptionNotNull:
+				notNull = true
+			}
+			optionList = append(optionList, option)
+		}
+		// The primary key columns are NOT NULL implicitly.
+		if primaryKey && !notNull {
+			optionList = append([]*ast.ColumnOption{{Tp: ast.ColumnOptionNotNull}}, optionList...)
+		}
+		column.Options = optionList
+	}
+}
+
+// getAlterTableSpecList returns the ALTER TABLE specifications migrating the old table to the new table.
+func getAlterTableSpecList(oldTable, newTable *ast.CreateTableStmt) ([]string, error) {
+	oldConstraintMap := make(map[string]*ast.Constraint)
+	for _, constraint := range oldTable.Constraints {
+		oldConstraintMap[getConstraintKey(constraint)] = constraint
+	}
+	newConstraintMap := make(map[string]*ast.Constraint)
+	for _, constraint := range newTable.Constraints {
+		newConstraintMap[getConstraintKey(constraint)] = constraint
+	}
+
+	var specList []string
+	// Drop the constraints first since they may reference the columns to drop.
+	for _, constraint := range oldTable.Constraints {
+		newConstraint, ok := newConstraintMap[getConstraintKey(constraint)]
+		if ok {
+			same, err := isSameConstraint(constraint, newConstraint)
+			if err != nil {
+				return nil, err
+			}
+			if same {
+				continue
+			}
+		}
+		specList = append(specList, getDropConstraintSpec(constraint))
+	}
+
+	oldColumnMap := make(map[string]*ast.ColumnDef)
+	for _, column := range oldTable.Cols {
+		oldColumnMap[column.Name.Name.L] = column
+	}
+	newColumnMap := make(map[string]*ast.ColumnDef)
+	for _, column := range newTable.Cols {
+		newColumnMap[column.Name.Name.L] = column
+	}
+	for _, column := range oldTable.Cols {
+		if _, ok := newColumnMap[column.Name.Name.L]; !ok {
+			specList = append(specList, fmt.Sprintf("DROP COLUMN %s", quoteReverseIdentifier(column.Name.Name.O)))
+		}
+	}
+	for i, column := range newTable.Cols {
+		text, err := restoreNode(column)
+		if err != nil {
+			return nil, err
+		}
+		oldColumn, ok := oldColumnMap[column.Name.Name.L]
+		if !ok {
+			position := "FIRST"
+			if i > 0 {
+				position = fmt.Sprintf("AFTER %s", quoteReverseIdentifier(newTable.Cols[i-1].Name.Name.O))
+			}
+			specList = append(specList, fmt.Sprintf("ADD COLUMN %s %s", text, position))
+			continue
+		}
+		oldKey, err := getColumnKey(oldColumn)
+		if err != nil {
+			return nil, err
+		}
+		newKey, err := getColumnKey(column)
+		if err != nil {
+			return nil, err
+		}
+		if oldKey != newKey {
+			specList = append(specList, fmt.Sprintf("MODIFY COLUMN %s", text))
+		}
+	}
+
+	for _, constraint := range newTable.Constraints {
+		oldConstraint, ok := oldConstraintMap[getConstraintKey(constraint)]
+		if ok {
+			same, err := isSameConstraint(oldConstraint, constraint)
+			if err != nil {
+				return nil, err
+			}
+			if same {
+				continue
+			}
+		}
+		text, err := restoreNode(constraint)
+		if err != nil {
+			return nil, err
+		}
+		specList = append(specList, fmt.Sprintf("ADD %s", text))
+	}
+
+	// Only the table options declared in the new table are applied, so that the defaults shown in the dump are kept.
+	oldOptionMap := make(map[ast.TableOptionType]string)
+	for _, option := range oldTable.Options {
+		text, err := restoreNode(option)
+		if err != nil {
+			return nil, err
+		}
+		oldOptionMap[option.Tp] = text
+	}
+	for _, option := range newTable.Options {
+		if option.Tp == ast.TableOptionAutoIncrement {
+			continue
+		}
+		text, err := restoreNode(option)
+		if err != nil {
+			return nil, err
+		}
+		if oldOptionMap[option.Tp] != text {
+			specList = append(specList, text)
+		}
+	}
+	return specList, nil
+}
+
+// getConstraintKey returns the key identifying the constraint. The index and the foreign key may share the same name.
+func getConstraintKey(constraint *ast.Constraint) string {
+	switch constraint.Tp {
+	case ast.ConstraintPrimaryKey:
+		return "PRIMARY"
+	case ast.ConstraintForeignKey:
+		return "FOREIGN KEY " + strings.ToLower(constraint.Name)
+	case ast.ConstraintCheck:
+		return "CHECK " + strings.ToLower(constraint.Name)
+	}
+	return "INDEX " + strings.ToLower(constraint.Name)
+}
+
+func getDropConstraintSpec(constraint *ast.Constraint) string {
+	switch constraint.Tp {
+	case ast.ConstraintPrimaryKey:
+		return "DROP PRIMARY KEY"
+	case ast.ConstraintForeignKey:
+		return fmt.Sprintf("DROP FOREIGN KEY %s", quoteReverseIdentifier(constraint.Name))
+	case ast.ConstraintCheck:
+		return fmt.Sprintf("DROP CHECK %s", quoteReverseIdentifier(constraint.Name))
+	}
+	return fmt.Sprintf("DROP INDEX %s", quoteReverseIdentifier(constraint.Name))
+}
+
+func isSameConstraint(oldConstraint, newConstraint *ast.Constraint) (bool, error) {
+	oldText, err := restoreNode(normalizeConstraint(oldConstraint))
+	if err != nil {
+		return false, err
+	}
+	newText, err := restoreNode(normalizeConstraint(newConstraint))
+	if err != nil {
+		return false, err
+	}
+	return oldText == newText, nil
+}
+
+// normalizeConstraint returns a copy of the constraint without the redundant parentheses around the check expression,
+// which are added by SHOW CREATE TABLE.
+func normalizeConstraint(constraint *ast.Constraint) *ast.Constraint {
+	if constraint.Tp != ast.ConstraintCheck {
+		return constraint
+	}
+	normalized := *constraint
+	for {
+		paren, ok := normalized.Expr.(*ast.ParenthesesExpr)
+		if !ok {
+			break
+		}
+		normalized.Expr = paren.Expr
+	}
+	return &normalized
+}
+
+// getColumnKey returns the text comparing the column definitions, which ignores the differences between
+// how the column is declared and how SHOW CREATE TABLE shows it, i.e. the integer display width, the NULL option and DEFAULT NULL.
+func getColumnKey(column *ast.ColumnDef) (string, error) {
+	tp := *column.Tp
+	if parsermysql.IsIntegerType(tp.Tp) {
+		tp.Flen = types.UnspecifiedLength
+	}
+	normalized := &ast.ColumnDef{Name: column.Name, Tp: &tp}
+	defaultValue := ""
+	for _, option := range column.Options {
+		switch option.Tp {
+		case ast.ColumnOptionNull:
+			continue
+		case ast.ColumnOptionDefaultValue:
+			if value, ok := option.Expr.(ast.ValueExpr); ok {
+				if value.GetValue() != nil {
+					defaultValue = fmt.Sprintf("DEFAULT %v", value.GetValue())
+				}
+			} else {
+				text, err := restoreNode(option.Expr)
+				if err != nil {
+					return "", err
+				}
+				defaultValue = fmt.Sprintf("DEFAULT %s", text)
+			}
+			continue
+		}
+		normalized.Options = append(normalized.Options, option)
+	}
+	text, err := restoreNode(normalized)
+	if err != nil {
+		return "", err
+	}
+	return text + " " + defaultValue, nil
+}
+
+// restorer is the AST node that can be restored to text, including the table options which aren't ast.Node.
+type restorer interface {
+	Restore(ctx *format.RestoreCtx) error
+}
+
+func restoreNode(node restorer) (string, error) {
+	var sb strings.Builder
+	if err := node.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		return "", fmt.Errorf("failed to restore the statement, error: %w", err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
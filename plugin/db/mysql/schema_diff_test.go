@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSchemaDiff(t *testing.T) {
+	a := require.New(t)
+	// The current schema is in the format of the dump.
+	oldSchema := strings.Join([]string{
+		"SET character_set_client  = utf8mb4;",
+		"--",
+		"-- Table structure for `t`",
+		"--",
+		"CREATE TABLE `t` (",
+		"  `id` int(11) NOT NULL AUTO_INCREMENT,",
+		"  `name` varchar(255) DEFAULT NULL,",
+		"  `count` int NOT NULL DEFAULT '0',",
+		"  `legacy` text,",
+		"  PRIMARY KEY (`id`),",
+		"  KEY `idx_legacy` (`legacy`(10)),",
+		"  CONSTRAINT `chk_count` CHECK ((`count` >= 0))",
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='old';",
+		"--",
+		"-- Table structure for `dropped`",
+		"--",
+		"CREATE TABLE `dropped` (",
+		"  `id` int NOT NULL",
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+		"--",
+		"-- View structure for `v`",
+		"--",
+		"CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `v` AS select `t`.`id` AS `id` from `t`;",
+		"--",
+		"-- Trigger structure for `trg`",
+		"--",
+		"SET character_set_client  = utf8mb4;",
+		"DELIMITER ;;",
+		"CREATE TRIGGER `trg` BEFORE INSERT ON `t` FOR EACH ROW BEGIN SET NEW.count = 0; END ;;",
+		"DELIMITER ;",
+	}, "\n")
+	newSchema := strings.Join([]string{
+		"CREATE TABLE t (",
+		"  id INT PRIMARY KEY AUTO_INCREMENT,",
+		"  name VARCHAR(255),",
+		"  count INT NOT NULL DEFAULT 0,",
+		"  email VARCHAR(255) NOT NULL DEFAULT '',",
+		"  CONSTRAINT chk_count CHECK (count >= 0)",
+		") COMMENT 'new';",
+		"CREATE UNIQUE INDEX uk_email ON t (email);",
+		"CREATE TABLE added (id INT PRIMARY KEY);",
+		"CREATE VIEW v AS SELECT id, name FROM t;",
+	}, "\n")
+
+	statement, err := GenerateSchemaDiff(oldSchema, newSchema)
+	a.NoError(err)
+	a.Equal(strings.Join([]string{
+		"DROP TABLE `dropped`;",
+		"CREATE TABLE `added` (`id` INT NOT NULL,PRIMARY KEY(`id`));",
+		"ALTER TABLE `t` DROP INDEX `idx_legacy`, DROP COLUMN `legacy`, ADD COLUMN `email` VARCHAR(255) NOT NULL DEFAULT _UTF8MB4'' AFTER `count`, ADD UNIQUE `uk_email`(`email`), COMMENT = 'new';",
+		"CREATE OR REPLACE ALGORITHM = UNDEFINED DEFINER = CURRENT_USER SQL SECURITY DEFINER VIEW `v` AS SELECT `id`,`name` FROM `t`;",
+	}, "\n"), statement)
+
+	// The same schema has no difference.
+	statement, err = GenerateSchemaDiff(newSchema, newSchema)
+	a.NoError(err)
+	a.Equal("", statement)
+
+	// The desired schema must be declarative.
+	_, err = GenerateSchemaDiff(oldSchema, "ALTER TABLE t ADD COLUMN a INT;")
+	a.Error(err)
+}
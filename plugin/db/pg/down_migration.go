@@ -0,0 +1,101 @@
+package pg
+
+import (
+	"fmt"
+	"strings"
+
+	pgquery "github.com/pganalyze/pg_query_go/v2"
+)
+
+// GenerateDownStatement generates the statement rolling back the migration if it's purely additive, or returns "" otherwise.
+// The migration is purely additive if it only creates tables, views, sequences and named indexes, or adds columns and named constraints,
+// in which case it's rolled back by dropping the created objects in the reverse order.
+func GenerateDownStatement(statement string) (string, error) {
+	res, err := pgquery.Parse(statement)
+	if err != nil {
+		return "", err
+	}
+	var downList []string
+	for i := len(res.Stmts) - 1; i >= 0; i-- {
+		down, ok := getDownStatement(res.Stmts[i].Stmt)
+		if !ok {
+			return "", nil
+		}
+		downList = append(downList, down)
+	}
+	return strings.Join(downList, "\n"), nil
+}
+
+// getDownStatement returns the statement dropping the objects created by the additive statement, or false if the statement is not additive.
+// The statements with IF NOT EXISTS are not additive, since the objects may exist before.
+func getDownStatement(node *pgquery.Node) (string, bool) {
+	switch in := node.Node.(type) {
+	case *pgquery.Node_CreateStmt:
+		if in.CreateStmt.IfNotExists {
+			return "", false
+		}
+		return fmt.Sprintf("DROP TABLE %s;", quoteRangeVar(in.CreateStmt.Relation)), true
+	case *pgquery.Node_ViewStmt:
+		if in.ViewStmt.Replace {
+			return "", false
+		}
+		return fmt.Sprintf("DROP VIEW %s;", quoteRangeVar(in.ViewStmt.View)), true
+	case *pgquery.Node_CreateSeqStmt:
+		if in.CreateSeqStmt.IfNotExists {
+			return "", false
+		}
+		return fmt.Sprintf("DROP SEQUENCE %s;", quoteRangeVar(in.CreateSeqStmt.Sequence)), true
+	case *pgquery.Node_IndexStmt:
+		if in.IndexStmt.IfNotExists || in.IndexStmt.Idxname == "" {
+			return "", false
+		}
+		// The index is created in the schema of its table.
+		index := quoteIdentifier(in.IndexStmt.Idxname)
+		if schema := in.IndexStmt.Relation.Schemaname; schema != "" {
+			index = fmt.Sprintf("%s.%s", quoteIdentifier(schema), index)
+		}
+		return fmt.Sprintf("DROP INDEX %s;", index), true
+	case *pgquery.Node_AlterTableStmt:
+		if in.AlterTableStmt.Relkind != pgquery.ObjectType_OBJECT_TABLE {
+			return "", false
+		}
+		var dropList []string
+		for _, cmd := range in.AlterTableStmt.Cmds {
+			cmdNode, ok := cmd.Node.(*pgquery.Node_AlterTableCmd)
+			if !ok || cmdNode.AlterTableCmd.MissingOk {
+				return "", false
+			}
+			switch cmdNode.AlterTableCmd.Subtype {
+			case pgquery.AlterTableType_AT_AddColumn:
+				def, ok := cmdNode.AlterTableCmd.Def.Node.(*pgquery.Node_ColumnDef)
+				if !ok {
+					return "", false
+				}
+				dropList = append(dropList, fmt.Sprintf("DROP COLUMN %s", quoteIdentifier(def.ColumnDef.Colname)))
+			case pgquery.AlterTableType_AT_AddConstraint:
+				def, ok := cmdNode.AlterTableCmd.Def.Node.(*pgquery.Node_Constraint)
+				if !ok || def.Constraint.Conname == "" {
+					return "", false
+				}
+				dropList = append(dropList, fmt.Sprintf("DROP CONSTRAINT %s", quoteIdentifier(def.Constraint.Conname)))
+			default:
+				return "", false
+			}
+		}
+		if len(dropList) == 0 {
+			return "", false
+		}
+		for i, j := 0, len(dropList)-1; i < j; i, j = i+1, j-1 {
+			dropList[i], dropList[j] = dropList[j], dropList[i]
+		}
+		return fmt.Sprintf("ALTER TABLE %s %s;", quoteRangeVar(in.AlterTableStmt.Relation), strings.Join(dropList, ", ")), true
+	}
+	return "", false
+}
+
+func quoteRangeVar(rangeVar *pgquery.RangeVar) string {
+	if rangeVar.Schemaname == "" {
+		return quoteIdentifier(rangeVar.Relname)
+	}
+	return fmt.Sprintf("%s.%s", quoteIdentifier(rangeVar.Schemaname), quoteIdentifier(rangeVar.Relname))
+}
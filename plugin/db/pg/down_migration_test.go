@@ -0,0 +1,49 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDownStatement(t *testing.T) {
+	a := require.New(t)
+	tests := []struct {
+		statement string
+		want      string
+	}{
+		{
+			statement: "CREATE TABLE t (id SERIAL PRIMARY KEY, name TEXT);\nCREATE INDEX idx_name ON public.t (name);",
+			want:      `DROP INDEX "public"."idx_name";` + "\n" + `DROP TABLE "t";`,
+		},
+		{
+			statement: "ALTER TABLE public.t ADD COLUMN a INT DEFAULT 0, ADD CONSTRAINT uk_a UNIQUE (a);",
+			want:      `ALTER TABLE "public"."t" DROP CONSTRAINT "uk_a", DROP COLUMN "a";`,
+		},
+		{
+			statement: "CREATE SEQUENCE s;\nCREATE VIEW v AS SELECT 1;",
+			want:      `DROP VIEW "v";` + "\n" + `DROP SEQUENCE "s";`,
+		},
+		// The objects may exist before.
+		{
+			statement: "ALTER TABLE t ADD COLUMN IF NOT EXISTS a INT;",
+			want:      "",
+		},
+		// The index name is generated.
+		{
+			statement: "CREATE INDEX ON t (a);",
+			want:      "",
+		},
+		// Not purely additive.
+		{
+			statement: "ALTER TABLE t ADD COLUMN a INT, ALTER COLUMN b TYPE TEXT;",
+			want:      "",
+		},
+	}
+
+	for _, test := range tests {
+		got, err := GenerateDownStatement(test.statement)
+		a.NoError(err)
+		a.Equal(test.want, got, test.statement)
+	}
+}
@@ -19,7 +19,8 @@ var (
 	//go:embed pg_migration_schema.sql
 	migrationSchema string
 
-	_ util.MigrationExecutor = (*Driver)(nil)
+	_ util.MigrationHistoryImportExecutor = (*Driver)(nil)
+	_ db.MigrationHistoryImporter         = (*Driver)(nil)
 )
 
 // NeedsSetupMigration returns whether it needs to setup migration.
@@ -240,6 +241,79 @@ func (Driver) UpdateHistoryAsFailed(ctx context.Context, tx *sql.Tx, migrationDu
 	return err
 }
 
+// UpdateHistoryPayload will update the payload of the migration record.
+func (Driver) UpdateHistoryPayload(ctx context.Context, tx *sql.Tx, payload string, insertedID int64) error {
+	const updateHistoryPayloadQuery = `
+	UPDATE
+		migration_history
+	SET
+		payload = $1
+	WHERE id = $2
+	`
+	_, err := tx.ExecContext(ctx, updateHistoryPayloadQuery, payload, insertedID)
+	return err
+}
+
+// FormatImportTable returns the table reference of the migration tool table in the database.
+// The table is looked up in the search path of the connection to the database.
+func (Driver) FormatImportTable(_, table string) string {
+	return table
+}
+
+// FormatUnixTimestamp returns the expression converting the timestamp column to the unix timestamp in seconds.
+func (Driver) FormatUnixTimestamp(column string) string {
+	return fmt.Sprintf("CAST(EXTRACT(epoch FROM %s) AS BIGINT)", column)
+}
+
+// InsertImportedHistory will insert the migration record imported from the migration tool and return the inserted ID.
+func (Driver) InsertImportedHistory(ctx context.Context, tx *sql.Tx, sequence int, m *db.MigrationInfo, storedVersion, schema string, createdTs int64, executionDurationNs int64) (int64, error) {
+	const insertHistoryQuery = `
+	INSERT INTO migration_history (
+		created_by,
+		created_ts,
+		updated_by,
+		updated_ts,
+		release_version,
+		namespace,
+		sequence,
+		source,
+		type,
+		status,
+		version,
+		description,
+		statement,
+		` + `"schema",` + `
+		schema_prev,
+		execution_duration_ns,
+		issue_id,
+		payload
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, '', $13, '', $14, '', $15)
+	RETURNING id
+	`
+	var insertedID int64
+	if err := tx.QueryRowContext(ctx, insertHistoryQuery,
+		m.Creator,
+		createdTs,
+		m.Creator,
+		createdTs,
+		m.ReleaseVersion,
+		m.Namespace,
+		sequence,
+		m.Source,
+		m.Type,
+		m.Status,
+		storedVersion,
+		m.Description,
+		schema,
+		executionDurationNs,
+		m.Payload,
+	).Scan(&insertedID); err != nil {
+		return 0, err
+	}
+	return insertedID, nil
+}
+
 // ExecuteMigration will execute the migration.
 func (driver *Driver) ExecuteMigration(ctx context.Context, m *db.MigrationInfo, statement string) (int64, string, error) {
 	if driver.strictUseDb() {
@@ -248,6 +322,14 @@ func (driver *Driver) ExecuteMigration(ctx context.Context, m *db.MigrationInfo,
 	return util.ExecuteMigration(ctx, driver, m, statement, db.BytebaseDatabase)
 }
 
+// ImportMigrationHistory imports the migration history of the database recorded by the migration tool of the source.
+func (driver *Driver) ImportMigrationHistory(ctx context.Context, database string, source db.MigrationSource, creator string) (int, error) {
+	if driver.strictUseDb() {
+		return util.ImportMigrationHistory(ctx, driver, database, source, creator, driver.strictDatabase)
+	}
+	return util.ImportMigrationHistory(ctx, driver, database, source, creator, db.BytebaseDatabase)
+}
+
 // FindMigrationHistoryList finds the migration history.
 func (driver *Driver) FindMigrationHistoryList(ctx context.Context, find *db.MigrationHistoryFind) ([]*db.MigrationHistory, error) {
 	baseQuery := `
@@ -0,0 +1,577 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db/util"
+)
+
+// The online schema change for Postgres is the counterpart of gh-ost for MySQL, and it runs in two steps.
+// 1. Sync: create the shadow table after the original table, apply the ALTER TABLE statement to the shadow table,
+// install the trigger replaying the changes of the original table to the shadow table, and copy the existing rows in batches.
+// 2. Cutover: rename the original table away and rename the shadow table to the original name in a short transaction.
+// The trigger keeps the shadow table in sync between the two steps, so there is no process to keep alive in between.
+// The original table is kept after the cutover, and it's up to the user to drop it.
+
+const (
+	// oscCutoverLockTimeout is the lock timeout of the cutover, so that the cutover doesn't block the queries on the table for long.
+	oscCutoverLockTimeout = 3 * time.Second
+	// oscMinServerVersionNum is the min server version supporting the online schema change, because of OVERRIDING SYSTEM VALUE.
+	oscMinServerVersionNum = 100000
+	// oscMaxIdentifierLength is the max identifier length of Postgres.
+	oscMaxIdentifierLength = 63
+)
+
+var (
+	oscIdentifierPattern    = `(?:"(?:[^"]|"")+"|[\w$]+)`
+	oscAlterTableRegexp     = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:ONLY\s+)?(` + oscIdentifierPattern + `(?:\.` + oscIdentifierPattern + `)?)\s+(.+?)\s*;?$`)
+	oscUnsupportedRegexp    = regexp.MustCompile(`(?i)\b(RENAME|SET\s+SCHEMA)\b`)
+	oscMultiStatementRegexp = regexp.MustCompile(`;\s*\S`)
+)
+
+// OnlineSchemaChange is the online schema change of a table.
+type OnlineSchemaChange struct {
+	// schema is empty if the statement doesn't specify it, in which case the current schema is used.
+	schema string
+	table  string
+	// action is the ALTER TABLE action list, e.g. ADD COLUMN a INT.
+	action string
+}
+
+// NewOnlineSchemaChange parses the ALTER TABLE statement into an online schema change.
+func NewOnlineSchemaChange(statement string) (*OnlineSchemaChange, error) {
+	statement = strings.TrimSpace(statement)
+	if oscMultiStatementRegexp.MatchString(statement) {
+		return nil, common.Errorf(common.Invalid, "online schema change only supports a single ALTER TABLE statement")
+	}
+	matches := oscAlterTableRegexp.FindStringSubmatch(statement)
+	if matches == nil {
+		return nil, common.Errorf(common.Invalid, "online schema change only supports the ALTER TABLE statement, got %q", statement)
+	}
+	if oscUnsupportedRegexp.MatchString(matches[2]) {
+		return nil, common.Errorf(common.Invalid, "online schema change doesn't support renaming or moving the table or its columns")
+	}
+
+	osc := &OnlineSchemaChange{action: matches[2]}
+	nameList := splitQualifiedName(matches[1])
+	if len(nameList) == 2 {
+		osc.schema = nameList[0]
+	}
+	osc.table = nameList[len(nameList)-1]
+	// The shadow table and the old table are named after the original table with a 5-byte affix.
+	if len(osc.table)+5 > oscMaxIdentifierLength {
+		return nil, common.Errorf(common.Invalid, "table name %q is too long for online schema change", osc.table)
+	}
+	return osc, nil
+}
+
+// Table returns the name of the table to change.
+func (osc *OnlineSchemaChange) Table() string {
+	return osc.table
+}
+
+// ShadowTable returns the name of the shadow table.
+func (osc *OnlineSchemaChange) ShadowTable() string {
+	return fmt.Sprintf("_%s_gho", osc.table)
+}
+
+// OldTable returns the name of the original table after the cutover.
+func (osc *OnlineSchemaChange) OldTable() string {
+	return fmt.Sprintf("_%s_del", osc.table)
+}
+
+// triggerName returns the name of both the trigger and the trigger function.
+func (osc *OnlineSchemaChange) triggerName() string {
+	return fmt.Sprintf("_%s_ghc", osc.table)
+}
+
+// oscTable is the resolved online schema change target.
+type oscTable struct {
+	schema string
+	// primaryKey is the primary key column.
+	primaryKey string
+	// primaryKeyType is the type of the primary key column, e.g. bigint.
+	primaryKeyType string
+}
+
+func (t *oscTable) qualify(name string) string {
+	return fmt.Sprintf("%s.%s", quoteIdentifier(t.schema), quoteIdentifier(name))
+}
+
+// CheckOnlineSchemaChange checks whether the online schema change can run on the database, without changing anything.
+func (driver *Driver) CheckOnlineSchemaChange(ctx context.Context, database string, osc *OnlineSchemaChange) error {
+	sqldb, err := driver.GetDBConnection(ctx, database)
+	if err != nil {
+		return err
+	}
+	if _, err := resolveOnlineSchemaChange(ctx, sqldb, osc); err != nil {
+		return err
+	}
+	var shadowExists bool
+	if err := sqldb.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", fmt.Sprintf("%s.%s", quoteIdentifier(osc.schema), quoteIdentifier(osc.ShadowTable()))).Scan(&shadowExists); err != nil {
+		return err
+	}
+	if shadowExists {
+		return common.Errorf(common.Invalid, "shadow table %q already exists, please drop it if it's left by a previous online schema change", osc.ShadowTable())
+	}
+	return nil
+}
+
+// SyncOnlineSchemaChange creates the shadow table and keeps it in sync with the original table, copying the existing rows in batches.
+// progress is called after each batch with the rows copied so far and the estimated total rows.
+// Everything created is dropped if the sync fails or ctx is canceled.
+func (driver *Driver) SyncOnlineSchemaChange(ctx context.Context, database string, osc *OnlineSchemaChange, batchSize int, progress func(copied, total int64)) (resErr error) {
+	if err := driver.CheckOnlineSchemaChange(ctx, database, osc); err != nil {
+		return err
+	}
+	sqldb, err := driver.GetDBConnection(ctx, database)
+	if err != nil {
+		return err
+	}
+	t, err := resolveOnlineSchemaChange(ctx, sqldb, osc)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if resErr == nil {
+			return
+		}
+		// Use a new context since ctx may have been canceled.
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := cleanupOnlineSchemaChange(cleanupCtx, sqldb, osc, t); err != nil {
+			resErr = fmt.Errorf("%w; failed to clean up the online schema change, please drop the shadow table %q and the trigger %q manually, error: %v", resErr, osc.ShadowTable(), osc.triggerName(), err)
+		}
+	}()
+
+	columnList, err := createShadowTable(ctx, sqldb, osc, t)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	if err := sqldb.QueryRowContext(ctx, "SELECT GREATEST(reltuples, 0)::bigint FROM pg_class WHERE oid = $1::regclass", t.qualify(osc.table)).Scan(&total); err != nil {
+		return err
+	}
+
+	var quotedColumnList []string
+	for _, column := range columnList {
+		quotedColumnList = append(quotedColumnList, quoteIdentifier(column))
+	}
+	columns := strings.Join(quotedColumnList, ", ")
+	primaryKey := quoteIdentifier(t.primaryKey)
+	copyQuery := func(condition string) string {
+		return fmt.Sprintf(`
+			WITH batch AS (
+				SELECT %s FROM %s %s ORDER BY %s LIMIT %d FOR SHARE
+			), copied AS (
+				INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE SELECT %s FROM batch ON CONFLICT DO NOTHING
+			)
+			SELECT COUNT(*), (SELECT %s::text FROM batch ORDER BY %s DESC LIMIT 1) FROM batch`,
+			columns, t.qualify(osc.table), condition, primaryKey, batchSize,
+			t.qualify(osc.ShadowTable()), columns, columns,
+			primaryKey, primaryKey)
+	}
+	firstQuery := copyQuery("")
+	nextQuery := copyQuery(fmt.Sprintf("WHERE %s > $1::%s", primaryKey, t.primaryKeyType))
+
+	var copied int64
+	var lastKey sql.NullString
+	for {
+		var count int64
+		var err error
+		if lastKey.Valid {
+			err = sqldb.QueryRowContext(ctx, nextQuery, lastKey.String).Scan(&count, &lastKey)
+		} else {
+			err = sqldb.QueryRowContext(ctx, firstQuery).Scan(&count, &lastKey)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to copy rows to the shadow table, error: %w", err)
+		}
+		copied += count
+		if copied > total {
+			total = copied
+		}
+		progress(copied, total)
+		if count < int64(batchSize) || !lastKey.Valid {
+			return nil
+		}
+	}
+}
+
+// CutoverOnlineSchemaChange swaps the shadow table and the original table, and the original table is kept as the old table.
+func (driver *Driver) CutoverOnlineSchemaChange(ctx context.Context, database string, osc *OnlineSchemaChange) error {
+	sqldb, err := driver.GetDBConnection(ctx, database)
+	if err != nil {
+		return err
+	}
+	t, err := resolveOnlineSchemaChange(ctx, sqldb, osc)
+	if err != nil {
+		return err
+	}
+
+	tx, err := sqldb.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL lock_timeout = '%dms'", oscCutoverLockTimeout.Milliseconds())); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("LOCK TABLE %s IN ACCESS EXCLUSIVE MODE", t.qualify(osc.table))); err != nil {
+		return fmt.Errorf("failed to lock table %q for cutover, error: %w", osc.table, err)
+	}
+	var shadowExists bool
+	if err := tx.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", t.qualify(osc.ShadowTable())).Scan(&shadowExists); err != nil {
+		return err
+	}
+	if !shadowExists {
+		return common.Errorf(common.Invalid, "shadow table %q not found, the sync task must be done before the cutover", osc.ShadowTable())
+	}
+	// The views or rules may have been created since the sync.
+	if err := checkDependentRuleList(ctx, tx, osc, t.qualify(osc.table)); err != nil {
+		return err
+	}
+
+	var stmtList []string
+	stmtList = append(stmtList,
+		fmt.Sprintf("DROP TRIGGER %s ON %s", quoteIdentifier(osc.triggerName()), t.qualify(osc.table)),
+		fmt.Sprintf("DROP FUNCTION %s()", t.qualify(osc.triggerName())),
+	)
+
+	// The serial columns of the shadow table use the sequences owned by the original table, so we hand them over to the shadow table.
+	ownedSequenceList, err := getOwnedSequenceList(ctx, tx, t.qualify(osc.table))
+	if err != nil {
+		return err
+	}
+	shadowColumnList, err := getColumnList(ctx, tx, t.schema, osc.ShadowTable())
+	if err != nil {
+		return err
+	}
+	for sequence, column := range ownedSequenceList {
+		if !containsString(shadowColumnList, column) {
+			continue
+		}
+		stmtList = append(stmtList, fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s.%s", sequence, t.qualify(osc.ShadowTable()), quoteIdentifier(column)))
+	}
+
+	// The identity columns of the shadow table have their own sequences, which must continue after the copied rows.
+	identityColumnList, err := getIdentityColumnList(ctx, tx, t.qualify(osc.ShadowTable()))
+	if err != nil {
+		return err
+	}
+	for _, column := range identityColumnList {
+		stmtList = append(stmtList, fmt.Sprintf("SELECT setval(pg_get_serial_sequence(%s, %s), COALESCE(MAX(%s), 0) + 1, false) FROM %s",
+			quoteLiteral(t.qualify(osc.ShadowTable())), quoteLiteral(column), quoteIdentifier(column), t.qualify(osc.ShadowTable())))
+	}
+
+	stmtList = append(stmtList,
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", t.qualify(osc.table), quoteIdentifier(osc.OldTable())),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", t.qualify(osc.ShadowTable()), quoteIdentifier(osc.table)),
+	)
+	for _, stmt := range stmtList {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return util.FormatErrorWithQuery(err, stmt)
+		}
+	}
+	return tx.Commit()
+}
+
+// resolveOnlineSchemaChange resolves the schema and the primary key of the table, and checks the table is eligible for the online schema change.
+func resolveOnlineSchemaChange(ctx context.Context, sqldb *sql.DB, osc *OnlineSchemaChange) (*oscTable, error) {
+	var versionNum int
+	if err := sqldb.QueryRowContext(ctx, "SELECT current_setting('server_version_num')::int").Scan(&versionNum); err != nil {
+		return nil, err
+	}
+	if versionNum < oscMinServerVersionNum {
+		return nil, common.Errorf(common.Invalid, "online schema change requires Postgres 10 or later")
+	}
+
+	if osc.schema == "" {
+		if err := sqldb.QueryRowContext(ctx, "SELECT current_schema()").Scan(&osc.schema); err != nil {
+			return nil, err
+		}
+	}
+	t := &oscTable{schema: osc.schema}
+	table := t.qualify(osc.table)
+
+	var exists bool
+	if err := sqldb.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, common.Errorf(common.Invalid, "table %q not found", osc.table)
+	}
+
+	rows, err := sqldb.QueryContext(ctx, `
+		SELECT a.attname, format_type(a.atttypid, a.atttypmod)
+		FROM pg_index i
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		WHERE i.indrelid = $1::regclass AND i.indisprimary`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(&t.primaryKey, &t.primaryKeyType); err != nil {
+			return nil, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if count != 1 {
+		return nil, common.Errorf(common.Invalid, "online schema change requires table %q to have a single-column primary key", osc.table)
+	}
+
+	var referenced bool
+	if err := sqldb.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_constraint WHERE confrelid = $1::regclass AND contype = 'f')", table).Scan(&referenced); err != nil {
+		return nil, err
+	}
+	if referenced {
+		return nil, common.Errorf(common.Invalid, "online schema change doesn't support table %q referenced by foreign keys, since they would still reference the original table after the cutover", osc.table)
+	}
+	if err := checkDependentRuleList(ctx, sqldb, osc, table); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// checkDependentRuleList checks the table has no dependent views or rules, which would still reference the original table after the cutover.
+// The views are implemented as rules, so both are found in pg_rewrite.
+func checkDependentRuleList(ctx context.Context, q queryer, osc *OnlineSchemaChange, table string) error {
+	ruleList, err := queryStringList(ctx, q, `
+		SELECT DISTINCT CASE WHEN r.rulename = '_RETURN' THEN format('view %s', r.ev_class::regclass) ELSE format('rule %I on %s', r.rulename, r.ev_class::regclass) END
+		FROM pg_depend d
+		JOIN pg_rewrite r ON r.oid = d.objid
+		WHERE d.classid = 'pg_rewrite'::regclass AND d.refclassid = 'pg_class'::regclass AND d.refobjid = $1::regclass`, table)
+	if err != nil {
+		return err
+	}
+	if len(ruleList) > 0 {
+		return common.Errorf(common.Invalid, "online schema change doesn't support table %q with dependent %s, since they would still reference the original table after the cutover", osc.table, strings.Join(ruleList, ", "))
+	}
+	return nil
+}
+
+// createShadowTable creates the shadow table and the trigger syncing the changes to it, returning the columns to sync.
+func createShadowTable(ctx context.Context, sqldb *sql.DB, osc *OnlineSchemaChange, t *oscTable) ([]string, error) {
+	tx, err := sqldb.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	shadowTable := t.qualify(osc.ShadowTable())
+	for _, stmt := range []string{
+		fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING ALL)", shadowTable, t.qualify(osc.table)),
+		fmt.Sprintf("ALTER TABLE %s %s", shadowTable, osc.action),
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return nil, util.FormatErrorWithQuery(err, stmt)
+		}
+	}
+
+	// Sync the columns in both tables, so that the dropped columns are left out and the added columns get their defaults.
+	originalColumnList, err := getColumnList(ctx, tx, t.schema, osc.table)
+	if err != nil {
+		return nil, err
+	}
+	shadowColumnList, err := getColumnList(ctx, tx, t.schema, osc.ShadowTable())
+	if err != nil {
+		return nil, err
+	}
+	var columnList []string
+	for _, column := range originalColumnList {
+		if containsString(shadowColumnList, column) {
+			columnList = append(columnList, column)
+		}
+	}
+	if !containsString(columnList, t.primaryKey) {
+		return nil, common.Errorf(common.Invalid, "online schema change doesn't support changing the primary key column %q", t.primaryKey)
+	}
+	// The sequences owned by the columns are handed over to the shadow table at the cutover, so the columns must be kept.
+	ownedSequenceList, err := getOwnedSequenceList(ctx, tx, t.qualify(osc.table))
+	if err != nil {
+		return nil, err
+	}
+	for sequence, column := range ownedSequenceList {
+		if !containsString(columnList, column) {
+			return nil, common.Errorf(common.Invalid, "online schema change doesn't support dropping column %q owning sequence %s", column, sequence)
+		}
+	}
+
+	var quotedColumnList, newColumnList []string
+	for _, column := range columnList {
+		quotedColumnList = append(quotedColumnList, quoteIdentifier(column))
+		newColumnList = append(newColumnList, "NEW."+quoteIdentifier(column))
+	}
+	primaryKey := quoteIdentifier(t.primaryKey)
+	for _, stmt := range []string{
+		// Replay the changes to the shadow table. An update is replayed as a delete and an insert in case the primary key changes.
+		fmt.Sprintf(`CREATE FUNCTION %s() RETURNS TRIGGER LANGUAGE plpgsql AS $$
+BEGIN
+	IF TG_OP = 'DELETE' OR TG_OP = 'UPDATE' THEN
+		DELETE FROM %s WHERE %s = OLD.%s;
+	END IF;
+	IF TG_OP = 'INSERT' OR TG_OP = 'UPDATE' THEN
+		INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE VALUES (%s) ON CONFLICT DO NOTHING;
+	END IF;
+	RETURN NULL;
+END;
+$$`, t.qualify(osc.triggerName()), shadowTable, primaryKey, primaryKey, shadowTable, strings.Join(quotedColumnList, ", "), strings.Join(newColumnList, ", ")),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER INSERT OR UPDATE OR DELETE ON %s FOR EACH ROW EXECUTE PROCEDURE %s()", quoteIdentifier(osc.triggerName()), t.qualify(osc.table), t.qualify(osc.triggerName())),
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return nil, util.FormatErrorWithQuery(err, stmt)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return columnList, nil
+}
+
+// cleanupOnlineSchemaChange drops the trigger, the trigger function and the shadow table.
+func cleanupOnlineSchemaChange(ctx context.Context, sqldb *sql.DB, osc *OnlineSchemaChange, t *oscTable) error {
+	for _, stmt := range []string{
+		fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", quoteIdentifier(osc.triggerName()), t.qualify(osc.table)),
+		fmt.Sprintf("DROP FUNCTION IF EXISTS %s()", t.qualify(osc.triggerName())),
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", t.qualify(osc.ShadowTable())),
+	} {
+		if _, err := sqldb.ExecContext(ctx, stmt); err != nil {
+			return util.FormatErrorWithQuery(err, stmt)
+		}
+	}
+	return nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// getColumnList returns the non-generated columns of the table in order.
+func getColumnList(ctx context.Context, q queryer, schema, table string) ([]string, error) {
+	return queryStringList(ctx, q, `
+		SELECT column_name FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2 AND is_generated = 'NEVER'
+		ORDER BY ordinal_position`, schema, table)
+}
+
+// getIdentityColumnList returns the identity columns of the table.
+func getIdentityColumnList(ctx context.Context, q queryer, table string) ([]string, error) {
+	return queryStringList(ctx, q, `
+		SELECT attname FROM pg_attribute
+		WHERE attrelid = $1::regclass AND attnum > 0 AND NOT attisdropped AND attidentity <> ''`, table)
+}
+
+// getOwnedSequenceList returns the map from the sequences owned by the columns of the table to the columns.
+func getOwnedSequenceList(ctx context.Context, q queryer, table string) (map[string]string, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT d.objid::regclass::text, a.attname
+		FROM pg_depend d
+		JOIN pg_class s ON s.oid = d.objid AND s.relkind = 'S'
+		JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+		WHERE d.refobjid = $1::regclass AND d.deptype = 'a'`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	sequenceMap := make(map[string]string)
+	for rows.Next() {
+		var sequence, column string
+		if err := rows.Scan(&sequence, &column); err != nil {
+			return nil, err
+		}
+		sequenceMap[sequence] = column
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sequenceMap, nil
+}
+
+func queryStringList(ctx context.Context, q queryer, query string, args ...interface{}) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// splitQualifiedName splits the possibly qualified and quoted name into the unquoted parts.
+// The unquoted identifiers are folded to lower case as Postgres does.
+func splitQualifiedName(name string) []string {
+	var nameList []string
+	for len(name) > 0 {
+		var part string
+		if name[0] == '"' {
+			end := 1
+			for end < len(name) {
+				if name[end] == '"' {
+					if end+1 < len(name) && name[end+1] == '"' {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			part = strings.ReplaceAll(name[1:end], `""`, `"`)
+			if end < len(name) {
+				end++
+			}
+			name = name[end:]
+		} else {
+			end := strings.Index(name, ".")
+			if end < 0 {
+				end = len(name)
+			}
+			part = strings.ToLower(name[:end])
+			name = name[end:]
+		}
+		nameList = append(nameList, part)
+		name = strings.TrimPrefix(name, ".")
+	}
+	return nameList
+}
+
+func quoteIdentifier(s string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(s, `"`, `""`))
+}
+
+func quoteLiteral(s string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", "''"))
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
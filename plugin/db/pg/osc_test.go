@@ -0,0 +1,67 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOnlineSchemaChange(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      *OnlineSchemaChange
+		wantErr   bool
+	}{
+		{
+			statement: "ALTER TABLE t ADD COLUMN a INT;",
+			want:      &OnlineSchemaChange{table: "t", action: "ADD COLUMN a INT"},
+		},
+		{
+			statement: "alter table only Public.Users alter column name type text",
+			want:      &OnlineSchemaChange{schema: "public", table: "users", action: "alter column name type text"},
+		},
+		{
+			statement: `ALTER TABLE "My Schema"."Some""Table" DROP COLUMN b`,
+			want:      &OnlineSchemaChange{schema: "My Schema", table: `Some"Table`, action: "DROP COLUMN b"},
+		},
+		{
+			statement: "ALTER TABLE t RENAME COLUMN a TO b",
+			wantErr:   true,
+		},
+		{
+			statement: "ALTER TABLE t SET SCHEMA s",
+			wantErr:   true,
+		},
+		{
+			statement: "ALTER TABLE t ADD COLUMN a INT; ALTER TABLE t ADD COLUMN b INT;",
+			wantErr:   true,
+		},
+		{
+			statement: "CREATE TABLE t (id INT)",
+			wantErr:   true,
+		},
+		{
+			statement: "ALTER TABLE a123456789a123456789a123456789a123456789a123456789a123456789 ADD COLUMN a INT",
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := NewOnlineSchemaChange(test.statement)
+		if test.wantErr {
+			require.Error(t, err, test.statement)
+			continue
+		}
+		require.NoError(t, err, test.statement)
+		require.Equal(t, test.want, got, test.statement)
+	}
+}
+
+func TestOnlineSchemaChangeTableName(t *testing.T) {
+	osc, err := NewOnlineSchemaChange("ALTER TABLE public.orders ADD COLUMN note TEXT")
+	require.NoError(t, err)
+	require.Equal(t, "orders", osc.Table())
+	require.Equal(t, "_orders_gho", osc.ShadowTable())
+	require.Equal(t, "_orders_del", osc.OldTable())
+	require.Equal(t, "_orders_ghc", osc.triggerName())
+}
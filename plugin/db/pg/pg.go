@@ -1,7 +1,6 @@
 package pg
 
 import (
-	"bufio"
 	"context"
 	"database/sql"
 	"fmt"
@@ -212,15 +211,36 @@ func (driver *Driver) getVersion(ctx context.Context) (string, error) {
 }
 
 // Execute executes a SQL statement.
+// The running query is canceled on the server if ctx is canceled.
 func (driver *Driver) Execute(ctx context.Context, statement string) error {
 	owner, err := driver.getCurrentDatabaseOwner()
 	if err != nil {
 		return err
 	}
 
-	var remainingStmts []string
-	f := func(stmt string) error {
+	statementList, err := util.SplitMultiStatements(statement)
+	if err != nil {
+		return err
+	}
+	resultList := make([]*db.StatementResult, len(statementList))
+	for i, stmt := range statementList {
+		resultList[i] = util.NewStatementResult(i, stmt)
+	}
+	defer func() {
+		db.GetStatementRecorder(ctx).Record(resultList...)
+	}()
+
+	// The batched data update executes the statements in batches of primary key ranges instead of a single transaction.
+	if controller := db.GetBatchController(ctx); controller != nil {
+		return driver.executeInBatches(ctx, statementList, resultList, controller)
+	}
+
+	// The statements executed in the transaction and the actual queries to execute.
+	var txResultList []*db.StatementResult
+	var txQueryList []string
+	for i, stmt := range statementList {
 		stmt = strings.TrimLeft(stmt, " \t")
+		result := resultList[i]
 		// We don't use transaction for creating / altering databases in Postgres.
 		// https://github.com/bytebase/bytebase/issues/202
 		if strings.HasPrefix(stmt, "CREATE DATABASE ") {
@@ -240,15 +260,17 @@ func (driver *Driver) Execute(ctx context.Context, statement string) error {
 				}
 			}
 
-			if !exist {
-				if _, err := driver.db.ExecContext(ctx, stmt); err != nil {
-					return err
-				}
+			if exist {
+				result.Status = db.StatementDone
+			} else if err := util.ExecuteStatement(ctx, driver.db, result, stmt); err != nil {
+				return fmt.Errorf("execute query %q failed: %w", stmt, err)
 			}
+			result.Committed = true
 		} else if strings.HasPrefix(stmt, "ALTER DATABASE") && strings.Contains(stmt, " OWNER TO ") {
-			if _, err := driver.db.ExecContext(ctx, stmt); err != nil {
-				return err
+			if err := util.ExecuteStatement(ctx, driver.db, result, stmt); err != nil {
+				return fmt.Errorf("execute query %q failed: %w", stmt, err)
 			}
+			result.Committed = true
 		} else if strings.HasPrefix(stmt, "\\connect ") {
 			// For the case of `\connect "dbname";`, we need to use GetDBConnection() instead of executing the statement.
 			parts := strings.Split(stmt, `"`)
@@ -262,43 +284,104 @@ func (driver *Driver) Execute(ctx context.Context, statement string) error {
 			if owner, err = driver.getCurrentDatabaseOwner(); err != nil {
 				return err
 			}
+			result.Status = db.StatementDone
+			result.Committed = true
 		} else if isSuperuserStatement(stmt) {
 			// Use superuser privilege to run privileged statements.
-			remainingStmts = append(remainingStmts, "SET LOCAL ROLE NONE;")
-			remainingStmts = append(remainingStmts, stmt)
-			remainingStmts = append(remainingStmts, fmt.Sprintf("SET LOCAL ROLE %s;", owner))
+			txResultList = append(txResultList, result)
+			txQueryList = append(txQueryList, strings.Join([]string{"SET LOCAL ROLE NONE;", stmt, fmt.Sprintf("SET LOCAL ROLE %s;", owner)}, "\n"))
 		} else {
-			remainingStmts = append(remainingStmts, stmt)
+			txResultList = append(txResultList, result)
+			txQueryList = append(txQueryList, stmt)
 		}
+	}
+
+	if len(txResultList) == 0 {
 		return nil
 	}
-	sc := bufio.NewScanner(strings.NewReader(statement))
-	if err := util.ApplyMultiStatements(sc, f); err != nil {
+
+	// Use a dedicated connection so that we know which backend to cancel.
+	conn, err := driver.db.Conn(ctx)
+	if err != nil {
 		return err
 	}
+	defer conn.Close()
 
-	if len(remainingStmts) == 0 {
-		return nil
+	var pid int
+	if err := conn.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid); err != nil {
+		return err
 	}
+	stop := util.WatchCancel(ctx, func(killCtx context.Context) error {
+		return driver.cancelBackend(killCtx, pid)
+	})
+	defer stop()
 
-	tx, err := driver.db.BeginTx(ctx, nil)
+	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	// Take the pre-change backup in the same transaction, so that the backup is consistent with the change.
+	// It's taken before switching to the database owner role, which may not be able to create the backup schema.
+	if recorder := db.GetDataBackupRecorder(ctx); recorder != nil {
+		if err := util.BackupData(ctx, tx, db.Postgres, statementList, recorder); err != nil {
+			return fmt.Errorf("failed to back up the data before the change, error: %w", err)
+		}
+	}
+
 	// Set the current transaction role to the database owner so that the owner of created database will be the same as the database owner.
 	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL ROLE %s", owner)); err != nil {
 		return err
 	}
 
-	if _, err := tx.ExecContext(ctx, strings.Join(remainingStmts, "\n")); err != nil {
-		return err
+	for i, result := range txResultList {
+		if err := util.ExecuteStatement(ctx, tx, result, txQueryList[i]); err != nil {
+			return err
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
 		return err
 	}
+	for _, result := range txResultList {
+		result.Committed = true
+	}
+	return nil
+}
+
+// executeInBatches executes the statements in batches for the batched data update.
+func (driver *Driver) executeInBatches(ctx context.Context, statementList []string, resultList []*db.StatementResult, controller *db.BatchController) error {
+	// Use a dedicated connection so that we know which backend to cancel.
+	conn, err := driver.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var pid int
+	if err := conn.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid); err != nil {
+		return err
+	}
+	stop := util.WatchCancel(ctx, func(killCtx context.Context) error {
+		return driver.cancelBackend(killCtx, pid)
+	})
+	defer stop()
+
+	if recorder := db.GetDataBackupRecorder(ctx); recorder != nil {
+		if err := util.BackupData(ctx, conn, db.Postgres, statementList, recorder); err != nil {
+			return fmt.Errorf("failed to back up the data before the change, error: %w", err)
+		}
+	}
+	return util.ExecuteInBatches(ctx, conn, db.Postgres, statementList, resultList, controller)
+}
+
+// cancelBackend cancels the query running on the backend.
+func (driver *Driver) cancelBackend(ctx context.Context, pid int) error {
+	query := "SELECT pg_cancel_backend($1)"
+	if _, err := driver.db.ExecContext(ctx, query, pid); err != nil {
+		return util.FormatErrorWithQuery(err, query)
+	}
 	return nil
 }
 
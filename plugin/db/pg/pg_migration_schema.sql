@@ -20,9 +20,9 @@ CREATE TABLE migration_history (
     -- Used to detect out of order migration together with 'namespace' and 'version' column.
     sequence BIGINT NOT NULL CHECK (sequence >= 0),
     -- We call it source because maybe we could load history from other migration tool.
-    -- Current allowed values are UI, VCS, LIBRARY.
+    -- Current allowed values are UI, VCS, LIBRARY, FLYWAY, LIQUIBASE, GOLANG_MIGRATE.
     source TEXT NOT NULL,
-    -- Current allowed values are BASELINE, MIGRATE, BRANCH, DATA.
+    -- Current allowed values are BASELINE, MIGRATE, MIGRATE_SDL, BRANCH, DATA.
     type TEXT NOT NULL,
     -- Current allowed values are PENDING, DONE, FAILED.
     -- PostgreSQL can't do cross database transaction, so we can't record DDL and migration_history into a single transaction.
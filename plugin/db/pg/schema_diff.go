@@ -0,0 +1,596 @@
+package pg
+
+import (
+	"fmt"
+	"strings"
+
+	pgquery "github.com/pganalyze/pg_query_go/v2"
+)
+
+const defaultSchemaName = "public"
+
+// serialTypeMap maps the serial types to their underlying integer types.
+var serialTypeMap = map[string]string{
+	"smallserial": "int2",
+	"serial2":     "int2",
+	"serial":      "int4",
+	"serial4":     "int4",
+	"bigserial":   "int8",
+	"serial8":     "int8",
+}
+
+// diffSchemaState is the tables, indexes and views declared by a schema.
+type diffSchemaState struct {
+	tableList []*diffTable
+	tableMap  map[string]*diffTable
+	indexList []*pgquery.IndexStmt
+	indexMap  map[string]*pgquery.IndexStmt
+	viewList  []*pgquery.ViewStmt
+	viewMap   map[string]*pgquery.ViewStmt
+}
+
+type diffTable struct {
+	relation       *pgquery.RangeVar
+	columnList     []*diffColumn
+	columnMap      map[string]*diffColumn
+	constraintList []*pgquery.Constraint
+	constraintMap  map[string]*pgquery.Constraint
+}
+
+type diffColumn struct {
+	// def is the column definition without the PRIMARY KEY, UNIQUE, CHECK and REFERENCES constraints, which are table constraints.
+	def *pgquery.ColumnDef
+	// typeName is the type with the serial type replaced by its integer type.
+	typeName    *pgquery.TypeName
+	notNull     bool
+	defaultExpr *pgquery.Node
+	// serialDefault is the default of the serial column, i.e. the next value of its sequence.
+	serialDefault string
+}
+
+// GenerateSchemaDiff generates the statement migrating the database from the old schema to the new schema, or returns "" if they're the same.
+// The tables, columns, constraints, indexes and views are compared, while the other objects such as the functions and triggers are not managed.
+// A renamed column or table is dropped and recreated, since a rename can't be told from the schemas.
+func GenerateSchemaDiff(oldSchema, newSchema string) (string, error) {
+	oldState, err := parseDiffSchemaState(oldSchema)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the current schema, error: %w", err)
+	}
+	newState, err := parseDiffSchemaState(newSchema)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the desired schema, error: %w", err)
+	}
+
+	var statementList []string
+	// Drop the views first since they may depend on the tables to drop.
+	for _, view := range oldState.viewList {
+		if _, ok := newState.viewMap[getRangeVarKey(view.View)]; !ok {
+			statementList = append(statementList, fmt.Sprintf("DROP VIEW %s;", quoteRangeVar(view.View)))
+		}
+	}
+	// The indexes of the dropped tables are dropped along with the tables.
+	for _, index := range oldState.indexList {
+		if _, ok := newState.tableMap[getRangeVarKey(index.Relation)]; !ok {
+			continue
+		}
+		newIndex, ok := newState.indexMap[getIndexKey(index)]
+		if ok {
+			same, err := isSameNode(indexStmtNode(index), indexStmtNode(newIndex))
+			if err != nil {
+				return "", err
+			}
+			if same {
+				continue
+			}
+		}
+		statementList = append(statementList, fmt.Sprintf("DROP INDEX %s.%s;", quoteIdentifier(index.Relation.Schemaname), quoteIdentifier(index.Idxname)))
+	}
+	for _, table := range oldState.tableList {
+		if _, ok := newState.tableMap[getRangeVarKey(table.relation)]; !ok {
+			statementList = append(statementList, fmt.Sprintf("DROP TABLE %s;", quoteRangeVar(table.relation)))
+		}
+	}
+	// Create the tables before altering the others, since the foreign keys to add may reference them.
+	for _, table := range newState.tableList {
+		if _, ok := oldState.tableMap[getRangeVarKey(table.relation)]; ok {
+			continue
+		}
+		var eltList []*pgquery.Node
+		for _, column := range table.columnList {
+			eltList = append(eltList, &pgquery.Node{Node: &pgquery.Node_ColumnDef{ColumnDef: column.def}})
+		}
+		for _, constraint := range table.constraintList {
+			eltList = append(eltList, constraintNode(constraint))
+		}
+		text, err := deparseNode(&pgquery.Node{Node: &pgquery.Node_CreateStmt{CreateStmt: &pgquery.CreateStmt{
+			Relation:  table.relation,
+			TableElts: eltList,
+			Oncommit:  pgquery.OnCommitAction_ONCOMMIT_NOOP,
+		}}})
+		if err != nil {
+			return "", err
+		}
+		statementList = append(statementList, text+";")
+	}
+	for _, table := range newState.tableList {
+		oldTable, ok := oldState.tableMap[getRangeVarKey(table.relation)]
+		if !ok {
+			continue
+		}
+		cmdList, err := getAlterTableCmdList(oldTable, table)
+		if err != nil {
+			return "", err
+		}
+		if len(cmdList) == 0 {
+			continue
+		}
+		text, err := deparseNode(&pgquery.Node{Node: &pgquery.Node_AlterTableStmt{AlterTableStmt: &pgquery.AlterTableStmt{
+			Relation: table.relation,
+			Cmds:     cmdList,
+			Relkind:  pgquery.ObjectType_OBJECT_TABLE,
+		}}})
+		if err != nil {
+			return "", err
+		}
+		statementList = append(statementList, text+";")
+	}
+	for _, index := range newState.indexList {
+		if oldIndex, ok := oldState.indexMap[getIndexKey(index)]; ok {
+			if _, ok := newState.tableMap[getRangeVarKey(oldIndex.Relation)]; ok {
+				same, err := isSameNode(indexStmtNode(oldIndex), indexStmtNode(index))
+				if err != nil {
+					return "", err
+				}
+				if same {
+					continue
+				}
+			}
+		}
+		text, err := deparseNode(indexStmtNode(index))
+		if err != nil {
+			return "", err
+		}
+		statementList = append(statementList, text+";")
+	}
+	for _, view := range newState.viewList {
+		oldView, ok := oldState.viewMap[getRangeVarKey(view.View)]
+		if ok {
+			same, err := isSameNode(oldView.Query, view.Query)
+			if err != nil {
+				return "", err
+			}
+			if same {
+				continue
+			}
+		}
+		view.Replace = ok
+		text, err := deparseNode(&pgquery.Node{Node: &pgquery.Node_ViewStmt{ViewStmt: view}})
+		if err != nil {
+			return "", err
+		}
+		statementList = append(statementList, text+";")
+	}
+	return strings.Join(statementList, "\n"), nil
+}
+
+// parseDiffSchemaState parses the tables, indexes and views of the schema.
+// The constraints and column defaults added by ALTER TABLE, which pg_dump generates, are folded into the tables.
+// The other statements, e.g. SET and the ownership changes in the dump, are skipped.
+func parseDiffSchemaState(schema string) (*diffSchemaState, error) {
+	res, err := pgquery.Parse(schema)
+	if err != nil {
+		return nil, err
+	}
+	state := &diffSchemaState{
+		tableMap: make(map[string]*diffTable),
+		indexMap: make(map[string]*pgquery.IndexStmt),
+		viewMap:  make(map[string]*pgquery.ViewStmt),
+	}
+	for _, stmt := range res.Stmts {
+		switch in := stmt.Stmt.Node.(type) {
+		case *pgquery.Node_CreateStmt:
+			normalizeRangeVar(in.CreateStmt.Relation)
+			key := getRangeVarKey(in.CreateStmt.Relation)
+			if _, ok := state.tableMap[key]; ok {
+				return nil, fmt.Errorf("table %q is declared more than once", key)
+			}
+			table, err := newDiffTable(in.CreateStmt)
+			if err != nil {
+				return nil, err
+			}
+			state.tableList = append(state.tableList, table)
+			state.tableMap[key] = table
+		case *pgquery.Node_AlterTableStmt:
+			if in.AlterTableStmt.Relkind != pgquery.ObjectType_OBJECT_TABLE {
+				continue
+			}
+			normalizeRangeVar(in.AlterTableStmt.Relation)
+			table, ok := state.tableMap[getRangeVarKey(in.AlterTableStmt.Relation)]
+			if !ok {
+				continue
+			}
+			for _, cmd := range in.AlterTableStmt.Cmds {
+				if err := table.applyAlterTableCmd(cmd.GetAlterTableCmd()); err != nil {
+					return nil, err
+				}
+			}
+		case *pgquery.Node_IndexStmt:
+			if in.IndexStmt.Idxname == "" {
+				return nil, fmt.Errorf("the index on table %q must be named", in.IndexStmt.Relation.Relname)
+			}
+			normalizeRangeVar(in.IndexStmt.Relation)
+			if in.IndexStmt.AccessMethod == "" {
+				in.IndexStmt.AccessMethod = "btree"
+			}
+			key := getIndexKey(in.IndexStmt)
+			if _, ok := state.indexMap[key]; ok {
+				return nil, fmt.Errorf("index %q is declared more than once", key)
+			}
+			state.indexList = append(state.indexList, in.IndexStmt)
+			state.indexMap[key] = in.IndexStmt
+		case *pgquery.Node_ViewStmt:
+			normalizeRangeVar(in.ViewStmt.View)
+			key := getRangeVarKey(in.ViewStmt.View)
+			if _, ok := state.viewMap[key]; ok {
+				return nil, fmt.Errorf("view %q is declared more than once", key)
+			}
+			in.ViewStmt.Replace = false
+			state.viewList = append(state.viewList, in.ViewStmt)
+			state.viewMap[key] = in.ViewStmt
+		}
+	}
+	return state, nil
+}
+
+// newDiffTable creates the table from the CREATE TABLE statement, where the column constraints other than NOT NULL and DEFAULT
+// are moved to the table constraints, and the unnamed constraints are named in the same way as Postgres does.
+func newDiffTable(stmt *pgquery.CreateStmt) (*diffTable, error) {
+	table := &diffTable{
+		relation:      stmt.Relation,
+		columnMap:     make(map[string]*diffColumn),
+		constraintMap: make(map[string]*pgquery.Constraint),
+	}
+	for _, elt := range stmt.TableElts {
+		switch elt := elt.Node.(type) {
+		case *pgquery.Node_ColumnDef:
+			def := elt.ColumnDef
+			column := &diffColumn{def: def, typeName: def.TypeName}
+			if len(def.TypeName.Names) == 1 && len(def.TypeName.ArrayBounds) == 0 {
+				if intType, ok := serialTypeMap[def.TypeName.Names[0].GetString_().Str]; ok {
+					column.typeName = &pgquery.TypeName{Names: []*pgquery.Node{pgquery.MakeStrNode("pg_catalog"), pgquery.MakeStrNode(intType)}, Typemod: -1}
+					column.notNull = true
+					column.serialDefault = fmt.Sprintf("nextval('%s.%s_%s_seq'::regclass)", stmt.Relation.Schemaname, stmt.Relation.Relname, def.Colname)
+				}
+			}
+			var constraintList []*pgquery.Node
+			for _, node := range def.Constraints {
+				constraint := node.GetConstraint()
+				if constraint == nil {
+					continue
+				}
+				switch constraint.Contype {
+				case pgquery.ConstrType_CONSTR_NOTNULL:
+					column.notNull = true
+				case pgquery.ConstrType_CONSTR_DEFAULT:
+					column.defaultExpr = constraint.RawExpr
+				case pgquery.ConstrType_CONSTR_NULL:
+				case pgquery.ConstrType_CONSTR_PRIMARY, pgquery.ConstrType_CONSTR_UNIQUE:
+					constraint.Keys = []*pgquery.Node{pgquery.MakeStrNode(def.Colname)}
+					if err := table.addConstraint(constraint, def.Colname); err != nil {
+						return nil, err
+					}
+					continue
+				case pgquery.ConstrType_CONSTR_FOREIGN:
+					constraint.FkAttrs = []*pgquery.Node{pgquery.MakeStrNode(def.Colname)}
+					if err := table.addConstraint(constraint, def.Colname); err != nil {
+						return nil, err
+					}
+					continue
+				case pgquery.ConstrType_CONSTR_CHECK:
+					if err := table.addConstraint(constraint, def.Colname); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				constraintList = append(constraintList, node)
+			}
+			def.Constraints = constraintList
+			if _, ok := table.columnMap[def.Colname]; ok {
+				return nil, fmt.Errorf("column %q of table %q is declared more than once", def.Colname, stmt.Relation.Relname)
+			}
+			table.columnList = append(table.columnList, column)
+			table.columnMap[def.Colname] = column
+		case *pgquery.Node_Constraint:
+			if err := table.addConstraint(elt.Constraint, ""); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported element in table %q", stmt.Relation.Relname)
+		}
+	}
+	return table, nil
+}
+
+// applyAlterTableCmd applies the constraints and the column defaults added by ALTER TABLE. The other commands are ignored.
+func (t *diffTable) applyAlterTableCmd(cmd *pgquery.AlterTableCmd) error {
+	if cmd == nil {
+		return nil
+	}
+	switch cmd.Subtype {
+	case pgquery.AlterTableType_AT_AddConstraint:
+		return t.addConstraint(cmd.Def.GetConstraint(), "")
+	case pgquery.AlterTableType_AT_ColumnDefault:
+		if column, ok := t.columnMap[cmd.Name]; ok {
+			column.defaultExpr = cmd.Def
+		}
+	case pgquery.AlterTableType_AT_SetNotNull:
+		if column, ok := t.columnMap[cmd.Name]; ok {
+			column.notNull = true
+		}
+	}
+	return nil
+}
+
+func (t *diffTable) addConstraint(constraint *pgquery.Constraint, column string) error {
+	if constraint == nil {
+		return nil
+	}
+	if constraint.Conname == "" {
+		constraint.Conname = getDefaultConstraintName(t.relation.Relname, column, constraint)
+	}
+	if _, ok := t.constraintMap[constraint.Conname]; ok {
+		return fmt.Errorf("constraint %q of table %q is declared more than once", constraint.Conname, t.relation.Relname)
+	}
+	// The primary key columns are NOT NULL implicitly.
+	if constraint.Contype == pgquery.ConstrType_CONSTR_PRIMARY {
+		for _, key := range constraint.Keys {
+			if c, ok := t.columnMap[key.GetString_().Str]; ok {
+				c.notNull = true
+			}
+		}
+		if c, ok := t.columnMap[column]; ok {
+			c.notNull = true
+		}
+	}
+	t.constraintList = append(t.constraintList, constraint)
+	t.constraintMap[constraint.Conname] = constraint
+	return nil
+}
+
+// getDefaultConstraintName returns the name Postgres generates for the unnamed constraint.
+func getDefaultConstraintName(table, column string, constraint *pgquery.Constraint) string {
+	var columnList []string
+	switch constraint.Contype {
+	case pgquery.ConstrType_CONSTR_PRIMARY:
+		return fmt.Sprintf("%s_pkey", table)
+	case pgquery.ConstrType_CONSTR_UNIQUE:
+		for _, key := range constraint.Keys {
+			columnList = append(columnList, key.GetString_().Str)
+		}
+		return strings.Join(append([]string{table}, append(columnList, "key")...), "_")
+	case pgquery.ConstrType_CONSTR_FOREIGN:
+		for _, key := range constraint.FkAttrs {
+			columnList = append(columnList, key.GetString_().Str)
+		}
+		return strings.Join(append([]string{table}, append(columnList, "fkey")...), "_")
+	}
+	if column != "" {
+		return fmt.Sprintf("%s_%s_check", table, column)
+	}
+	return fmt.Sprintf("%s_check", table)
+}
+
+// getAlterTableCmdList returns the ALTER TABLE commands migrating the old table to the new table.
+func getAlterTableCmdList(oldTable, newTable *diffTable) ([]*pgquery.Node, error) {
+	var cmdList []*pgquery.Node
+	// Drop the constraints first since they may reference the columns to drop.
+	for _, constraint := range oldTable.constraintList {
+		if newConstraint, ok := newTable.constraintMap[constraint.Conname]; ok {
+			same, err := isSameConstraint(constraint, newConstraint)
+			if err != nil {
+				return nil, err
+			}
+			if same {
+				continue
+			}
+		}
+		cmdList = append(cmdList, alterTableCmdNode(&pgquery.AlterTableCmd{
+			Subtype:  pgquery.AlterTableType_AT_DropConstraint,
+			Name:     constraint.Conname,
+			Behavior: pgquery.DropBehavior_DROP_RESTRICT,
+		}))
+	}
+	for _, column := range oldTable.columnList {
+		if _, ok := newTable.columnMap[column.def.Colname]; !ok {
+			cmdList = append(cmdList, alterTableCmdNode(&pgquery.AlterTableCmd{
+				Subtype:  pgquery.AlterTableType_AT_DropColumn,
+				Name:     column.def.Colname,
+				Behavior: pgquery.DropBehavior_DROP_RESTRICT,
+			}))
+		}
+	}
+	for _, column := range newTable.columnList {
+		oldColumn, ok := oldTable.columnMap[column.def.Colname]
+		if !ok {
+			cmdList = append(cmdList, alterTableCmdNode(&pgquery.AlterTableCmd{
+				Subtype:  pgquery.AlterTableType_AT_AddColumn,
+				Def:      &pgquery.Node{Node: &pgquery.Node_ColumnDef{ColumnDef: column.def}},
+				Behavior: pgquery.DropBehavior_DROP_RESTRICT,
+			}))
+			continue
+		}
+		columnCmdList, err := getAlterColumnCmdList(oldColumn, column)
+		if err != nil {
+			return nil, err
+		}
+		cmdList = append(cmdList, columnCmdList...)
+	}
+	for _, constraint := range newTable.constraintList {
+		if oldConstraint, ok := oldTable.constraintMap[constraint.Conname]; ok {
+			same, err := isSameConstraint(oldConstraint, constraint)
+			if err != nil {
+				return nil, err
+			}
+			if same {
+				continue
+			}
+		}
+		cmdList = append(cmdList, alterTableCmdNode(&pgquery.AlterTableCmd{
+			Subtype:  pgquery.AlterTableType_AT_AddConstraint,
+			Def:      constraintNode(constraint),
+			Behavior: pgquery.DropBehavior_DROP_RESTRICT,
+		}))
+	}
+	return cmdList, nil
+}
+
+// getAlterColumnCmdList returns the ALTER TABLE commands changing the type, NOT NULL and the default of the column.
+func getAlterColumnCmdList(oldColumn, newColumn *diffColumn) ([]*pgquery.Node, error) {
+	var cmdList []*pgquery.Node
+	name := newColumn.def.Colname
+	oldType, err := deparseTypeName(oldColumn.typeName)
+	if err != nil {
+		return nil, err
+	}
+	newType, err := deparseTypeName(newColumn.typeName)
+	if err != nil {
+		return nil, err
+	}
+	if oldType != newType {
+		cmdList = append(cmdList, alterTableCmdNode(&pgquery.AlterTableCmd{
+			Subtype:  pgquery.AlterTableType_AT_AlterColumnType,
+			Name:     name,
+			Def:      &pgquery.Node{Node: &pgquery.Node_ColumnDef{ColumnDef: &pgquery.ColumnDef{TypeName: newColumn.typeName}}},
+			Behavior: pgquery.DropBehavior_DROP_RESTRICT,
+		}))
+	}
+	oldDefault, err := oldColumn.getDefault()
+	if err != nil {
+		return nil, err
+	}
+	newDefault, err := newColumn.getDefault()
+	if err != nil {
+		return nil, err
+	}
+	if oldDefault != newDefault {
+		defaultExpr := newColumn.defaultExpr
+		if defaultExpr == nil && newColumn.serialDefault != "" {
+			res, err := pgquery.Parse(fmt.Sprintf("SELECT %s", newColumn.serialDefault))
+			if err != nil {
+				return nil, err
+			}
+			defaultExpr = res.Stmts[0].Stmt.GetSelectStmt().TargetList[0].GetResTarget().Val
+		}
+		cmdList = append(cmdList, alterTableCmdNode(&pgquery.AlterTableCmd{
+			Subtype:  pgquery.AlterTableType_AT_ColumnDefault,
+			Name:     name,
+			Def:      defaultExpr,
+			Behavior: pgquery.DropBehavior_DROP_RESTRICT,
+		}))
+	}
+	if oldColumn.notNull != newColumn.notNull {
+		subtype := pgquery.AlterTableType_AT_DropNotNull
+		if newColumn.notNull {
+			subtype = pgquery.AlterTableType_AT_SetNotNull
+		}
+		cmdList = append(cmdList, alterTableCmdNode(&pgquery.AlterTableCmd{
+			Subtype:  subtype,
+			Name:     name,
+			Behavior: pgquery.DropBehavior_DROP_RESTRICT,
+		}))
+	}
+	return cmdList, nil
+}
+
+// getDefault returns the text comparing the column defaults, where the type cast of a constant is ignored,
+// since pg_dump shows the default 'a' of a varchar column as 'a'::character varying.
+func (c *diffColumn) getDefault() (string, error) {
+	if c.defaultExpr == nil {
+		return c.serialDefault, nil
+	}
+	expr := c.defaultExpr
+	if typeCast := expr.GetTypeCast(); typeCast != nil && typeCast.Arg.GetAConst() != nil {
+		expr = typeCast.Arg
+	}
+	text, err := deparseNode(&pgquery.Node{Node: &pgquery.Node_SelectStmt{SelectStmt: &pgquery.SelectStmt{
+		TargetList: []*pgquery.Node{pgquery.MakeResTargetNodeWithVal(expr, 0)},
+		Op:         pgquery.SetOperation_SETOP_NONE,
+	}}})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(text, "SELECT "), nil
+}
+
+func deparseTypeName(typeName *pgquery.TypeName) (string, error) {
+	return deparseNode(&pgquery.Node{Node: &pgquery.Node_CreateStmt{CreateStmt: &pgquery.CreateStmt{
+		Relation:  &pgquery.RangeVar{Relname: "t", Inh: true, Relpersistence: "p"},
+		TableElts: []*pgquery.Node{pgquery.MakeSimpleColumnDefNode("c", typeName, nil, 0)},
+		Oncommit:  pgquery.OnCommitAction_ONCOMMIT_NOOP,
+	}}})
+}
+
+func constraintNode(constraint *pgquery.Constraint) *pgquery.Node {
+	return &pgquery.Node{Node: &pgquery.Node_Constraint{Constraint: constraint}}
+}
+
+func alterTableCmdNode(cmd *pgquery.AlterTableCmd) *pgquery.Node {
+	return &pgquery.Node{Node: &pgquery.Node_AlterTableCmd{AlterTableCmd: cmd}}
+}
+
+func indexStmtNode(index *pgquery.IndexStmt) *pgquery.Node {
+	return &pgquery.Node{Node: &pgquery.Node_IndexStmt{IndexStmt: index}}
+}
+
+// isSameConstraint compares the constraints by deparsing them in ALTER TABLE ADD CONSTRAINT, since only the statements can be deparsed.
+func isSameConstraint(a, b *pgquery.Constraint) (bool, error) {
+	wrap := func(constraint *pgquery.Constraint) *pgquery.Node {
+		return &pgquery.Node{Node: &pgquery.Node_AlterTableStmt{AlterTableStmt: &pgquery.AlterTableStmt{
+			Relation: &pgquery.RangeVar{Relname: "t", Inh: true},
+			Cmds: []*pgquery.Node{alterTableCmdNode(&pgquery.AlterTableCmd{
+				Subtype:  pgquery.AlterTableType_AT_AddConstraint,
+				Def:      constraintNode(constraint),
+				Behavior: pgquery.DropBehavior_DROP_RESTRICT,
+			})},
+			Relkind: pgquery.ObjectType_OBJECT_TABLE,
+		}}}
+	}
+	return isSameNode(wrap(a), wrap(b))
+}
+
+func isSameNode(a, b *pgquery.Node) (bool, error) {
+	aText, err := deparseNode(a)
+	if err != nil {
+		return false, err
+	}
+	bText, err := deparseNode(b)
+	if err != nil {
+		return false, err
+	}
+	return aText == bText, nil
+}
+
+func deparseNode(node *pgquery.Node) (string, error) {
+	text, err := pgquery.Deparse(&pgquery.ParseResult{Stmts: []*pgquery.RawStmt{{Stmt: node}}})
+	if err != nil {
+		return "", fmt.Errorf("failed to deparse the statement, error: %w", err)
+	}
+	return text, nil
+}
+
+// normalizeRangeVar sets the schema to the default schema if it's not specified.
+func normalizeRangeVar(rangeVar *pgquery.RangeVar) {
+	if rangeVar.Schemaname == "" {
+		rangeVar.Schemaname = defaultSchemaName
+	}
+	rangeVar.Location = 0
+}
+
+func getRangeVarKey(rangeVar *pgquery.RangeVar) string {
+	return fmt.Sprintf("%s.%s", rangeVar.Schemaname, rangeVar.Relname)
+}
+
+// getIndexKey returns the key of the index, which is in the same schema as its table.
+func getIndexKey(index *pgquery.IndexStmt) string {
+	return fmt.Sprintf("%s.%s", index.Relation.Schemaname, index.Idxname)
+}
@@ -0,0 +1,65 @@
+package pg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSchemaDiff(t *testing.T) {
+	a := require.New(t)
+	// The current schema is in the format of pg_dump.
+	oldSchema := strings.Join([]string{
+		"SET statement_timeout = 0;",
+		"SELECT pg_catalog.set_config('search_path', '', false);",
+		"CREATE TABLE public.t (",
+		"    id integer NOT NULL,",
+		"    name character varying(255) DEFAULT 'x'::character varying,",
+		"    count integer DEFAULT 0 NOT NULL,",
+		"    legacy text",
+		");",
+		"ALTER TABLE public.t OWNER TO postgres;",
+		"CREATE SEQUENCE public.t_id_seq AS integer START WITH 1 INCREMENT BY 1 NO MINVALUE NO MAXVALUE CACHE 1;",
+		"ALTER SEQUENCE public.t_id_seq OWNED BY public.t.id;",
+		"CREATE TABLE public.dropped (",
+		"    id integer",
+		");",
+		"CREATE VIEW public.v AS",
+		" SELECT t.id",
+		"   FROM public.t;",
+		"ALTER TABLE ONLY public.t ALTER COLUMN id SET DEFAULT nextval('public.t_id_seq'::regclass);",
+		"ALTER TABLE ONLY public.t ADD CONSTRAINT t_pkey PRIMARY KEY (id);",
+		"ALTER TABLE public.t ADD CONSTRAINT t_count_check CHECK ((count >= 0));",
+		"CREATE INDEX idx_legacy ON public.t USING btree (legacy);",
+		"CREATE INDEX idx_name ON public.t USING btree (name);",
+	}, "\n")
+	newSchema := strings.Join([]string{
+		"CREATE TABLE t (",
+		"  id serial PRIMARY KEY,",
+		"  name varchar(255) NOT NULL DEFAULT 'x',",
+		"  count bigint NOT NULL DEFAULT 0 CHECK (count >= 0),",
+		"  email text UNIQUE",
+		");",
+		"CREATE INDEX idx_name ON t (lower(name));",
+		"CREATE TABLE added (id int PRIMARY KEY, t_id int REFERENCES t (id));",
+		"CREATE VIEW v AS SELECT id, name FROM t;",
+	}, "\n")
+
+	statement, err := GenerateSchemaDiff(oldSchema, newSchema)
+	a.NoError(err)
+	a.Equal(strings.Join([]string{
+		`DROP INDEX "public"."idx_legacy";`,
+		`DROP INDEX "public"."idx_name";`,
+		`DROP TABLE "public"."dropped";`,
+		"CREATE TABLE public.added (id int, t_id int, CONSTRAINT added_pkey PRIMARY KEY (id), CONSTRAINT added_t_id_fkey FOREIGN KEY (t_id) REFERENCES t (id));",
+		"ALTER TABLE public.t DROP legacy, ALTER COLUMN name SET NOT NULL, ALTER COLUMN count TYPE bigint, ADD COLUMN email text, ADD CONSTRAINT t_email_key UNIQUE (email);",
+		"CREATE INDEX idx_name ON public.t USING btree (lower(name));",
+		"CREATE OR REPLACE VIEW public.v AS SELECT id, name FROM t;",
+	}, "\n"), statement)
+
+	// The same schema has no difference.
+	statement, err = GenerateSchemaDiff(newSchema, newSchema)
+	a.NoError(err)
+	a.Equal("", statement)
+}
@@ -19,7 +19,8 @@ var (
 	//go:embed sqlite_migration_schema.sql
 	migrationSchema string
 
-	_ util.MigrationExecutor = (*Driver)(nil)
+	_ util.MigrationHistoryImportExecutor = (*Driver)(nil)
+	_ db.MigrationHistoryImporter         = (*Driver)(nil)
 )
 
 // NeedsSetupMigration returns whether it needs to setup migration.
@@ -215,11 +216,80 @@ func (Driver) UpdateHistoryAsFailed(ctx context.Context, tx *sql.Tx, migrationDu
 	return err
 }
 
+// FormatImportTable returns the table reference of the migration tool table in the database.
+// Each SQLite database is a separate file connected on its own.
+func (Driver) FormatImportTable(_, table string) string {
+	return table
+}
+
+// FormatUnixTimestamp returns the expression converting the timestamp column to the unix timestamp in seconds.
+func (Driver) FormatUnixTimestamp(column string) string {
+	return fmt.Sprintf("CAST(strftime('%%s', %s) AS INTEGER)", column)
+}
+
+// InsertImportedHistory will insert the migration record imported from the migration tool and return the inserted ID.
+func (Driver) InsertImportedHistory(ctx context.Context, tx *sql.Tx, sequence int, m *db.MigrationInfo, storedVersion, schema string, createdTs int64, executionDurationNs int64) (int64, error) {
+	const insertHistoryQuery = `
+	INSERT INTO bytebase_migration_history (
+		created_by,
+		created_ts,
+		updated_by,
+		updated_ts,
+		release_version,
+		namespace,
+		sequence,
+		source,
+		type,
+		status,
+		version,
+		description,
+		statement,
+		schema,
+		schema_prev,
+		execution_duration_ns,
+		issue_id,
+		payload
+	)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, '', ?, '', ?, '', ?)
+	`
+	res, err := tx.ExecContext(ctx, insertHistoryQuery,
+		m.Creator,
+		createdTs,
+		m.Creator,
+		createdTs,
+		m.ReleaseVersion,
+		m.Namespace,
+		sequence,
+		m.Source,
+		m.Type,
+		m.Status,
+		storedVersion,
+		m.Description,
+		schema,
+		executionDurationNs,
+		m.Payload,
+	)
+	if err != nil {
+		return int64(0), util.FormatErrorWithQuery(err, insertHistoryQuery)
+	}
+
+	insertedID, err := res.LastInsertId()
+	if err != nil {
+		return int64(0), util.FormatErrorWithQuery(err, insertHistoryQuery)
+	}
+	return insertedID, nil
+}
+
 // ExecuteMigration will execute the migration.
 func (driver *Driver) ExecuteMigration(ctx context.Context, m *db.MigrationInfo, statement string) (int64, string, error) {
 	return util.ExecuteMigration(ctx, driver, m, statement, bytebaseDatabase)
 }
 
+// ImportMigrationHistory imports the migration history of the database recorded by the migration tool of the source.
+func (driver *Driver) ImportMigrationHistory(ctx context.Context, database string, source db.MigrationSource, creator string) (int, error) {
+	return util.ImportMigrationHistory(ctx, driver, database, source, creator, bytebaseDatabase)
+}
+
 // FindMigrationHistoryList finds the migration history.
 func (driver *Driver) FindMigrationHistoryList(ctx context.Context, find *db.MigrationHistoryFind) ([]*db.MigrationHistory, error) {
 	baseQuery := `
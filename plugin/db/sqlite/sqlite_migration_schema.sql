@@ -14,9 +14,9 @@ CREATE TABLE bytebase_migration_history (
     -- Used to detect out of order migration together with 'namespace' and 'version' column.
     sequence INTEGER UNSIGNED NOT NULL,
     -- We call it source because maybe we could load history from other migration tool.
-    -- Current allowed values are UI, VCS, LIBRARY.
+    -- Current allowed values are UI, VCS, LIBRARY, FLYWAY, LIQUIBASE, GOLANG_MIGRATE.
     source TEXT NOT NULL,
-    -- Current allowed values are BASELINE, MIGRATE, BRANCH, DATA.
+    -- Current allowed values are BASELINE, MIGRATE, MIGRATE_SDL, BRANCH, DATA.
     type TEXT NOT NULL,
     -- Current allowed values are PENDING, DONE, FAILED.
     -- We create a "PENDING" record before applying the DDL and update that record to "DONE" after applying the DDL.
@@ -0,0 +1,401 @@
+package util
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+var (
+	// batchUpdateRegexp matches the single table UPDATE statement, e.g. UPDATE t SET a = 1 WHERE b < 10.
+	batchUpdateRegexp = regexp.MustCompile(`(?is)^UPDATE\s+([\w$."` + "`" + `]+)\s+SET\s+(.+?)(?:\s+WHERE\s+(.+?))?\s*;?$`)
+	// batchDeleteRegexp matches the single table DELETE statement, e.g. DELETE FROM t WHERE b < 10.
+	batchDeleteRegexp = regexp.MustCompile(`(?is)^DELETE\s+FROM\s+([\w$."` + "`" + `]+)(?:\s+WHERE\s+(.+?))?\s*;?$`)
+	// batchIneligibleRegexp matches the clauses we can't rewrite safely, i.e. subqueries, joins, multiple-table deletes, ORDER BY, LIMIT and RETURNING.
+	batchIneligibleRegexp = regexp.MustCompile(`(?i)\b(SELECT|JOIN|USING|ORDER\s+BY|LIMIT|RETURNING)\b`)
+	batchWhereRegexp      = regexp.MustCompile(`(?i)\bWHERE\b`)
+)
+
+// batchStatement is an UPDATE / DELETE statement to execute in batches of primary key ranges.
+type batchStatement struct {
+	// table is the table name as it's written in the statement.
+	table string
+	// prefix is the statement before the WHERE clause, e.g. UPDATE t SET a = 1.
+	prefix string
+	// condition is the WHERE condition, empty if there is none.
+	condition string
+}
+
+// parseBatchStatement parses the statement into a batch statement, returning nil if the statement isn't eligible for batching.
+func parseBatchStatement(statement string) *batchStatement {
+	statement = strings.TrimSpace(statement)
+	if batchIneligibleRegexp.MatchString(statement) {
+		return nil
+	}
+	// We can't tell the WHERE clause from the string literals containing "WHERE" with the regexp.
+	if len(batchWhereRegexp.FindAllString(statement, 2)) > 1 {
+		return nil
+	}
+	if matches := batchUpdateRegexp.FindStringSubmatch(statement); matches != nil {
+		prefix := fmt.Sprintf("UPDATE %s SET %s", matches[1], matches[2])
+		// We need to know the assigned columns to tell whether the primary key is changed.
+		if _, ok := getAssignedColumnList(prefix); !ok {
+			return nil
+		}
+		return &batchStatement{
+			table:     matches[1],
+			prefix:    prefix,
+			condition: matches[3],
+		}
+	}
+	if matches := batchDeleteRegexp.FindStringSubmatch(statement); matches != nil {
+		return &batchStatement{
+			table:     matches[1],
+			prefix:    fmt.Sprintf("DELETE FROM %s", matches[1]),
+			condition: matches[2],
+		}
+	}
+	return nil
+}
+
+// getAssignedColumnList returns the columns assigned by the UPDATE statement prefix, e.g. UPDATE t SET a = 1, unquoted
+// and without the table qualifier. It returns false if the SET clause can't be parsed.
+func getAssignedColumnList(prefix string) ([]string, bool) {
+	i := strings.Index(strings.ToUpper(prefix), " SET ")
+	if i < 0 {
+		return nil, true
+	}
+	var columnList []string
+	for _, assignment := range splitTopLevel(prefix[i+len(" SET "):], ',') {
+		j := strings.Index(assignment, "=")
+		if j < 0 {
+			return nil, false
+		}
+		target := strings.TrimSpace(assignment[:j])
+		// Postgres assigns multiple columns at once, e.g. SET (a, b) = (1, 2).
+		if strings.HasPrefix(target, "(") && strings.HasSuffix(target, ")") {
+			target = target[1 : len(target)-1]
+		}
+		for _, column := range strings.Split(target, ",") {
+			column = strings.TrimSpace(column)
+			if k := strings.LastIndex(column, "."); k >= 0 {
+				column = column[k+1:]
+			}
+			column = strings.Trim(column, "`\"")
+			if column == "" || strings.ContainsAny(column, " \t\n()'") {
+				return nil, false
+			}
+			columnList = append(columnList, column)
+		}
+	}
+	return columnList, true
+}
+
+// isColumnAssigned returns true if the UPDATE statement prefix, e.g. UPDATE t SET a = 1, assigns the column.
+// The column is regarded as assigned if the SET clause can't be parsed.
+func isColumnAssigned(prefix string, column string) bool {
+	columnList, ok := getAssignedColumnList(prefix)
+	if !ok {
+		return true
+	}
+	for _, assigned := range columnList {
+		if strings.EqualFold(assigned, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTopLevel splits s by sep outside the parentheses and the quotes.
+func splitTopLevel(s string, sep byte) []string {
+	var list []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == sep && depth == 0:
+			list = append(list, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(list, s[start:])
+}
+
+// getBatchQuery returns the query updating the rows whose primary key is within [low, high].
+func (s *batchStatement) getBatchQuery(primaryKey string, low, high int64) string {
+	rangeCondition := fmt.Sprintf("%s BETWEEN %d AND %d", primaryKey, low, high)
+	if s.condition == "" {
+		return fmt.Sprintf("%s WHERE %s", s.prefix, rangeCondition)
+	}
+	return fmt.Sprintf("%s WHERE (%s) AND %s", s.prefix, s.condition, rangeCondition)
+}
+
+// batchPlan is the execution plan of a statement in the batched execution.
+type batchPlan struct {
+	statement string
+	// batch is nil if the statement is executed as it is.
+	batch *batchStatement
+	// primaryKey is the quoted primary key column.
+	primaryKey string
+	minKey     int64
+	maxKey     int64
+	// empty is true if the table has no rows, in which case there is nothing to update.
+	empty bool
+}
+
+func (p *batchPlan) batchCount(batchSize int64) int64 {
+	if p.batch == nil {
+		return 1
+	}
+	if p.empty {
+		return 0
+	}
+	// Divide before adding one to avoid overflowing.
+	return (p.maxKey-p.minKey)/batchSize + 1
+}
+
+// ExecuteInBatches executes the statements on the connection following the batch controller.
+// The eligible UPDATE / DELETE statements on the tables with a single integer primary key column are rewritten to iterate over
+// the primary key ranges, each batch committed on its own, while the other statements are executed as they are.
+// The execution detail of each statement is filled into resultList.
+func ExecuteInBatches(ctx context.Context, conn *sql.Conn, dbType db.Type, statementList []string, resultList []*db.StatementResult, controller *db.BatchController) error {
+	if controller.BatchSize <= 0 {
+		return fmt.Errorf("invalid batch size %d", controller.BatchSize)
+	}
+
+	var planList []*batchPlan
+	for _, statement := range statementList {
+		plan, err := getBatchPlan(ctx, conn, dbType, statement)
+		if err != nil {
+			return err
+		}
+		controller.AddTotalBatches(plan.batchCount(controller.BatchSize))
+		planList = append(planList, plan)
+	}
+
+	recorder := db.GetStatementRecorder(ctx)
+	for i, plan := range planList {
+		if err := executeRecordedBatchPlan(ctx, conn, plan, resultList[i], controller, recorder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func executeRecordedBatchPlan(ctx context.Context, conn *sql.Conn, plan *batchPlan, result *db.StatementResult, controller *db.BatchController, recorder *db.StatementRecorder) error {
+	recorder.Start(result)
+	defer recorder.End(result)
+	startedNs := time.Now().UnixNano()
+	err := executeBatchPlan(ctx, conn, plan, result, controller)
+	result.DurationNs = time.Now().UnixNano() - startedNs
+	if err != nil {
+		result.Status = db.StatementFailed
+		result.Error = err.Error()
+		return err
+	}
+	result.Status = db.StatementDone
+	return nil
+}
+
+func executeBatchPlan(ctx context.Context, conn *sql.Conn, plan *batchPlan, result *db.StatementResult, controller *db.BatchController) error {
+	if plan.batch == nil {
+		if err := controller.WaitIfPaused(ctx); err != nil {
+			return err
+		}
+		sqlResult, err := conn.ExecContext(ctx, plan.statement)
+		if err != nil {
+			return err
+		}
+		// Each statement commits on its own in the autocommit mode.
+		result.Committed = true
+		result.RowsAffected = getRowsAffected(sqlResult)
+		controller.CompleteBatch(result.RowsAffected)
+		return nil
+	}
+
+	result.RowsAffected = 0
+	if plan.empty {
+		result.Committed = true
+		return nil
+	}
+	for low := plan.minKey; ; {
+		if err := controller.WaitIfPaused(ctx); err != nil {
+			return err
+		}
+		high := plan.maxKey
+		if plan.maxKey-low >= controller.BatchSize {
+			high = low + controller.BatchSize - 1
+		}
+		sqlResult, err := conn.ExecContext(ctx, plan.batch.getBatchQuery(plan.primaryKey, low, high))
+		if err != nil {
+			return fmt.Errorf("failed to update the rows with %s between %d and %d, the previous batches have been committed, error: %w", plan.primaryKey, low, high, err)
+		}
+		rowsAffected := getRowsAffected(sqlResult)
+		result.RowsAffected += rowsAffected
+		// The statement is partially committed once a batch has been committed.
+		result.Committed = true
+		controller.CompleteBatch(rowsAffected)
+
+		if high == plan.maxKey {
+			return nil
+		}
+		low = high + 1
+		if controller.SleepInterval > 0 {
+			select {
+			case <-time.After(controller.SleepInterval):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// getRowsAffected returns the rows affected, or 0 if the driver doesn't report it.
+func getRowsAffected(sqlResult sql.Result) int64 {
+	rowsAffected, err := sqlResult.RowsAffected()
+	if err != nil {
+		return 0
+	}
+	return rowsAffected
+}
+
+func getBatchPlan(ctx context.Context, conn *sql.Conn, dbType db.Type, statement string) (*batchPlan, error) {
+	plan := &batchPlan{statement: statement}
+	batch := parseBatchStatement(statement)
+	if batch == nil {
+		return plan, nil
+	}
+	primaryKeyColumn, err := getIntegerPrimaryKey(ctx, conn, dbType, batch.table)
+	if err != nil {
+		return nil, err
+	}
+	if primaryKeyColumn == "" {
+		return plan, nil
+	}
+	// The rows whose primary key is changed could move into the ranges not updated yet and be updated again.
+	if isColumnAssigned(batch.prefix, primaryKeyColumn) {
+		return plan, nil
+	}
+	primaryKey := quoteIdentifier(dbType, primaryKeyColumn)
+	plan.batch = batch
+	plan.primaryKey = primaryKey
+
+	var minKey, maxKey sql.NullInt64
+	query := fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", primaryKey, primaryKey, batch.table)
+	if err := conn.QueryRowContext(ctx, query).Scan(&minKey, &maxKey); err != nil {
+		return nil, FormatErrorWithQuery(err, query)
+	}
+	if !minKey.Valid || !maxKey.Valid {
+		plan.empty = true
+		return plan, nil
+	}
+	plan.minKey = minKey.Int64
+	plan.maxKey = maxKey.Int64
+	return plan, nil
+}
+
+// getIntegerPrimaryKey returns the primary key column of the table, or empty if the table doesn't have a single integer primary key column.
+func getIntegerPrimaryKey(ctx context.Context, conn queryer, dbType db.Type, table string) (string, error) {
+	columnList, typeList, err := getPrimaryKey(ctx, conn, dbType, table)
+	if err != nil {
+		return "", err
+	}
+	if len(columnList) != 1 || !isIntegerType(typeList[0]) {
+		return "", nil
+	}
+	return columnList[0], nil
+}
+
+// queryer is the common interface of *sql.Conn and *sql.Tx for querying.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// getPrimaryKey returns the primary key columns of the table and their lower case types, or empty if the table has no primary key.
+func getPrimaryKey(ctx context.Context, conn queryer, dbType db.Type, table string) ([]string, []string, error) {
+	var query string
+	var args []interface{}
+	switch dbType {
+	case db.MySQL, db.TiDB:
+		schema, name := splitMySQLTable(table)
+		query = `
+			SELECT k.COLUMN_NAME, c.DATA_TYPE
+			FROM information_schema.KEY_COLUMN_USAGE k
+			JOIN information_schema.COLUMNS c
+				ON c.TABLE_SCHEMA = k.TABLE_SCHEMA AND c.TABLE_NAME = k.TABLE_NAME AND c.COLUMN_NAME = k.COLUMN_NAME
+			WHERE k.CONSTRAINT_NAME = 'PRIMARY' AND k.TABLE_NAME = ? AND k.TABLE_SCHEMA = ` + "IF(? = '', DATABASE(), ?)" + `
+			ORDER BY k.ORDINAL_POSITION`
+		args = []interface{}{name, schema, schema}
+	case db.Postgres:
+		query = `
+			SELECT a.attname, format_type(a.atttypid, a.atttypmod)
+			FROM pg_index i
+			JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+			WHERE i.indrelid = $1::regclass AND i.indisprimary
+			ORDER BY array_position(i.indkey, a.attnum)`
+		args = []interface{}{table}
+	default:
+		return nil, nil, fmt.Errorf("batched execution isn't supported for %s", dbType)
+	}
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var columnList, typeList []string
+	for rows.Next() {
+		var column, columnType string
+		if err := rows.Scan(&column, &columnType); err != nil {
+			return nil, nil, err
+		}
+		columnList = append(columnList, column)
+		typeList = append(typeList, strings.ToLower(columnType))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return columnList, typeList, nil
+}
+
+// splitMySQLTable splits the table as it's written in the statement into the unquoted database and table names.
+// The database is empty if the table isn't qualified.
+func splitMySQLTable(table string) (string, string) {
+	schema, name := "", table
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		schema, name = table[:i], table[i+1:]
+	}
+	return strings.Trim(schema, "`"), strings.Trim(name, "`")
+}
+
+func quoteIdentifier(dbType db.Type, identifier string) string {
+	if dbType == db.Postgres {
+		return fmt.Sprintf(`"%s"`, strings.ReplaceAll(identifier, `"`, `""`))
+	}
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(identifier, "`", "``"))
+}
+
+func isIntegerType(columnType string) bool {
+	switch columnType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBatchStatement(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      *batchStatement
+	}{
+		{
+			statement: "UPDATE t SET a = 1 WHERE created_at < '2022-01-01';",
+			want:      &batchStatement{table: "t", prefix: "UPDATE t SET a = 1", condition: "created_at < '2022-01-01'"},
+		},
+		{
+			statement: "update `db`.`t` set a = 1,\n  b = 2",
+			want:      &batchStatement{table: "`db`.`t`", prefix: "UPDATE `db`.`t` SET a = 1,\n  b = 2"},
+		},
+		{
+			statement: "DELETE FROM public.t WHERE id > 10 AND status = 'DONE'",
+			want:      &batchStatement{table: "public.t", prefix: "DELETE FROM public.t", condition: "id > 10 AND status = 'DONE'"},
+		},
+		{
+			statement: "DELETE FROM t",
+			want:      &batchStatement{table: "t", prefix: "DELETE FROM t"},
+		},
+		// Subqueries.
+		{statement: "UPDATE t SET a = (SELECT MAX(b) FROM t2) WHERE c = 1"},
+		{statement: "DELETE FROM t WHERE id IN (SELECT id FROM t2)"},
+		// Multiple tables.
+		{statement: "UPDATE t1, t2 SET t1.a = t2.a WHERE t1.id = t2.id"},
+		{statement: "UPDATE t1 JOIN t2 ON t1.id = t2.id SET t1.a = t2.a"},
+		{statement: "DELETE FROM t1 USING t1, t2 WHERE t1.id = t2.id"},
+		// Aliases.
+		{statement: "DELETE FROM t AS x WHERE x.a = 1"},
+		// ORDER BY and LIMIT.
+		{statement: "DELETE FROM t WHERE a = 1 ORDER BY id LIMIT 10"},
+		// String literals containing WHERE.
+		{statement: "UPDATE t SET note = 'updated where needed' WHERE a = 1"},
+		// SET clauses we can't parse.
+		{statement: "UPDATE t SET a WHERE b = 1"},
+		// Other statements.
+		{statement: "INSERT INTO t VALUES (1)"},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, parseBatchStatement(test.statement), test.statement)
+	}
+}
+
+func TestGetAssignedColumnList(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   []string
+		ok     bool
+	}{
+		{prefix: "UPDATE t SET a = 1, `b`= 'x, y', t.\"c\" = f(1, 2)", want: []string{"a", "b", "c"}, ok: true},
+		{prefix: "UPDATE t SET (a, id) = (1, 2)", want: []string{"a", "id"}, ok: true},
+		{prefix: "UPDATE t SET a", ok: false},
+		{prefix: "DELETE FROM t", ok: true},
+	}
+	for _, test := range tests {
+		got, ok := getAssignedColumnList(test.prefix)
+		require.Equal(t, test.ok, ok, test.prefix)
+		require.Equal(t, test.want, got, test.prefix)
+	}
+
+	// The statement changing the primary key isn't executed in batches of the primary key ranges, since the rows
+	// moved into the ranges not updated yet would be updated again.
+	batch := parseBatchStatement("UPDATE t SET id = id + 1000 WHERE a = 1")
+	require.NotNil(t, batch)
+	require.True(t, isColumnAssigned(batch.prefix, "id"))
+	batch = parseBatchStatement("UPDATE t SET a = id + 1000")
+	require.NotNil(t, batch)
+	require.False(t, isColumnAssigned(batch.prefix, "id"))
+}
+
+func TestGetBatchQuery(t *testing.T) {
+	s := &batchStatement{table: "t", prefix: "UPDATE t SET a = 1", condition: "b = 1 OR c = 1"}
+	require.Equal(t, "UPDATE t SET a = 1 WHERE (b = 1 OR c = 1) AND `id` BETWEEN 1 AND 1000", s.getBatchQuery("`id`", 1, 1000))
+	s = &batchStatement{table: "t", prefix: "DELETE FROM t"}
+	require.Equal(t, `DELETE FROM t WHERE "id" BETWEEN -5 AND 4`, s.getBatchQuery(`"id"`, -5, 4))
+}
+
+func TestBatchCount(t *testing.T) {
+	batch := &batchStatement{table: "t", prefix: "DELETE FROM t"}
+	require.Equal(t, int64(1), (&batchPlan{}).batchCount(100))
+	require.Equal(t, int64(0), (&batchPlan{batch: batch, empty: true}).batchCount(100))
+	require.Equal(t, int64(1), (&batchPlan{batch: batch, minKey: 1, maxKey: 100}).batchCount(100))
+	require.Equal(t, int64(2), (&batchPlan{batch: batch, minKey: 1, maxKey: 101}).batchCount(100))
+	require.Equal(t, int64(1), (&batchPlan{batch: batch, minKey: 7, maxKey: 7}).batchCount(100))
+}
@@ -0,0 +1,286 @@
+package util
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+// dataBackupMaxTableNameLength is the max table name length of both MySQL (64) and Postgres (63).
+const dataBackupMaxTableNameLength = 63
+
+// dataBackupConn is the common interface of *sql.Conn and *sql.Tx for taking the pre-change backup.
+type dataBackupConn interface {
+	queryer
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// BackupData backs up the rows touched by the UPDATE / DELETE statements into the backup tables before executing them,
+// and records the backup tables to the recorder.
+// Only the single-table UPDATE / DELETE statements on the tables with a primary key are supported, since the rows are restored by the primary key.
+// The rows changed by others between the backup and the execution aren't captured unless the backup is taken in the same transaction.
+func BackupData(ctx context.Context, conn dataBackupConn, dbType db.Type, statementList []string, recorder *db.DataBackupRecorder) error {
+	var createDatabaseQuery string
+	switch dbType {
+	case db.MySQL, db.TiDB:
+		createDatabaseQuery = fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", quoteIdentifier(dbType, db.DataBackupDatabase))
+	case db.Postgres:
+		createDatabaseQuery = fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", quoteIdentifier(dbType, db.DataBackupDatabase))
+	default:
+		return fmt.Errorf("pre-change backup isn't supported for %s", dbType)
+	}
+	if _, err := conn.ExecContext(ctx, createDatabaseQuery); err != nil {
+		return FormatErrorWithQuery(err, createDatabaseQuery)
+	}
+	if err := purgeExpiredDataBackupTables(ctx, conn, dbType, time.Now()); err != nil {
+		return err
+	}
+
+	for i, statement := range statementList {
+		table, err := backupStatementData(ctx, conn, dbType, i, statement, recorder.TablePrefix)
+		if err != nil {
+			return err
+		}
+		table.CreatedTs = time.Now().Unix()
+		recorder.Record(table)
+	}
+	return nil
+}
+
+// purgeExpiredDataBackupTables drops the backup tables which have been kept longer than the retention period.
+func purgeExpiredDataBackupTables(ctx context.Context, conn dataBackupConn, dbType db.Type, now time.Time) error {
+	query := "SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = ?"
+	if dbType == db.Postgres {
+		query = "SELECT tablename FROM pg_tables WHERE schemaname = $1"
+	}
+	rows, err := conn.QueryContext(ctx, query, db.DataBackupDatabase)
+	if err != nil {
+		return FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+	var expiredList []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if isDataBackupTableExpired(name, now) {
+			expiredList = append(expiredList, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range expiredList {
+		dropQuery := fmt.Sprintf("DROP TABLE IF EXISTS %s.%s", quoteIdentifier(dbType, db.DataBackupDatabase), quoteIdentifier(dbType, name))
+		if _, err := conn.ExecContext(ctx, dropQuery); err != nil {
+			return FormatErrorWithQuery(err, dropQuery)
+		}
+	}
+	return nil
+}
+
+// isDataBackupTableExpired returns true if the backup table named _<unix timestamp>_... has expired.
+// The tables not named so are left alone.
+func isDataBackupTableExpired(name string, now time.Time) bool {
+	partList := strings.SplitN(name, "_", 3)
+	if len(partList) < 3 || partList[0] != "" {
+		return false
+	}
+	ts, err := strconv.ParseInt(partList[1], 10, 64)
+	if err != nil {
+		return false
+	}
+	return (&db.DataBackupTable{CreatedTs: ts}).IsExpired(now)
+}
+
+func backupStatementData(ctx context.Context, conn dataBackupConn, dbType db.Type, index int, statement string, tablePrefix string) (*db.DataBackupTable, error) {
+	batch := parseBatchStatement(statement)
+	if batch == nil {
+		return nil, unsupportedDataBackupStatementError(statement)
+	}
+	primaryKeyList, _, err := getPrimaryKey(ctx, conn, dbType, batch.table)
+	if err != nil {
+		return nil, err
+	}
+	if len(primaryKeyList) == 0 {
+		return nil, fmt.Errorf("pre-change backup requires table %s to have a primary key to restore the rows by", batch.table)
+	}
+	// The rows whose primary key is changed can't be found by the original primary key on rollback.
+	for _, primaryKey := range primaryKeyList {
+		if isColumnAssigned(batch.prefix, primaryKey) {
+			return nil, fmt.Errorf("pre-change backup doesn't support changing the primary key column %s of table %s", primaryKey, batch.table)
+		}
+	}
+	columnList, err := getNonGeneratedColumnList(ctx, conn, dbType, batch.table)
+	if err != nil {
+		return nil, err
+	}
+
+	backupTable := fmt.Sprintf("%s.%s", quoteIdentifier(dbType, db.DataBackupDatabase), quoteIdentifier(dbType, getDataBackupTableName(dbType, tablePrefix, index, batch.table)))
+	createQuery := fmt.Sprintf("CREATE TABLE %s LIKE %s", backupTable, batch.table)
+	if dbType == db.Postgres {
+		// The generated columns are copied as the regular columns without INCLUDING GENERATED.
+		createQuery = fmt.Sprintf("CREATE TABLE %s (LIKE %s)", backupTable, batch.table)
+	}
+	if _, err := conn.ExecContext(ctx, createQuery); err != nil {
+		return nil, FormatErrorWithQuery(err, createQuery)
+	}
+	columns := strings.Join(columnList, ", ")
+	insertQuery := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", backupTable, columns, columns, batch.table)
+	if batch.condition != "" {
+		insertQuery = fmt.Sprintf("%s WHERE %s", insertQuery, batch.condition)
+	}
+	sqlResult, err := conn.ExecContext(ctx, insertQuery)
+	if err != nil {
+		return nil, FormatErrorWithQuery(err, insertQuery)
+	}
+
+	var quotedPrimaryKeyList []string
+	for _, primaryKey := range primaryKeyList {
+		quotedPrimaryKeyList = append(quotedPrimaryKeyList, quoteIdentifier(dbType, primaryKey))
+	}
+	return &db.DataBackupTable{
+		Index:          index,
+		Table:          batch.table,
+		BackupTable:    backupTable,
+		PrimaryKeyList: quotedPrimaryKeyList,
+		ColumnList:     columnList,
+		RowCount:       getRowsAffected(sqlResult),
+	}, nil
+}
+
+// getDataBackupTableName returns the backup table name, e.g. _bb_123_0_t for the first statement updating table t.
+func getDataBackupTableName(dbType db.Type, tablePrefix string, index int, table string) string {
+	name := table
+	if dbType == db.Postgres {
+		if i := strings.LastIndex(table, "."); i >= 0 {
+			name = table[i+1:]
+		}
+		name = strings.Trim(name, `"`)
+	} else {
+		_, name = splitMySQLTable(table)
+	}
+	backupTableName := fmt.Sprintf("%s%d_%s", tablePrefix, index, name)
+	if len(backupTableName) > dataBackupMaxTableNameLength {
+		backupTableName = strings.ToValidUTF8(backupTableName[:dataBackupMaxTableNameLength], "")
+	}
+	return backupTableName
+}
+
+// getNonGeneratedColumnList returns the quoted columns of the table in order, excluding the generated columns.
+func getNonGeneratedColumnList(ctx context.Context, conn queryer, dbType db.Type, table string) ([]string, error) {
+	var query string
+	var args []interface{}
+	switch dbType {
+	case db.MySQL, db.TiDB:
+		schema, name := splitMySQLTable(table)
+		query = `
+			SELECT COLUMN_NAME FROM information_schema.COLUMNS
+			WHERE TABLE_NAME = ? AND TABLE_SCHEMA = IF(? = '', DATABASE(), ?) AND EXTRA NOT IN ('VIRTUAL GENERATED', 'STORED GENERATED')
+			ORDER BY ORDINAL_POSITION`
+		args = []interface{}{name, schema, schema}
+	case db.Postgres:
+		query = `
+			SELECT column_name FROM information_schema.columns
+			WHERE (quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass = $1::regclass AND is_generated = 'NEVER'
+			ORDER BY ordinal_position`
+		args = []interface{}{table}
+	default:
+		return nil, fmt.Errorf("pre-change backup isn't supported for %s", dbType)
+	}
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+	var columnList []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columnList = append(columnList, quoteIdentifier(dbType, column))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return columnList, nil
+}
+
+// GetDataRollbackStatement returns the statement restoring the rows from the backup tables.
+// The rows are upserted by the primary key, so that the deleted rows are inserted back and the updated rows are updated back,
+// without deleting the rows referenced by the foreign keys.
+// The backup tables are restored in the reverse order, so that the rows touched by multiple statements end up as they were before the first one.
+func GetDataRollbackStatement(dbType db.Type, tableList []*db.DataBackupTable) string {
+	var buf strings.Builder
+	for i := len(tableList) - 1; i >= 0; i-- {
+		table := tableList[i]
+		columns := strings.Join(table.ColumnList, ", ")
+		var updateColumnList []string
+		for _, column := range table.ColumnList {
+			if !containsString(table.PrimaryKeyList, column) {
+				updateColumnList = append(updateColumnList, column)
+			}
+		}
+		if dbType == db.Postgres {
+			conflictAction := "DO NOTHING"
+			if len(updateColumnList) > 0 {
+				var setList []string
+				for _, column := range updateColumnList {
+					setList = append(setList, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+				}
+				conflictAction = "DO UPDATE SET " + strings.Join(setList, ", ")
+			}
+			// Restore the identity columns generated always with the original values.
+			fmt.Fprintf(&buf, "INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE SELECT %s FROM %s ON CONFLICT (%s) %s;\n",
+				table.Table, columns, columns, table.BackupTable, strings.Join(table.PrimaryKeyList, ", "), conflictAction)
+			continue
+		}
+		// The table consisting of the primary key only has nothing to update, so the primary key is assigned to itself.
+		if len(updateColumnList) == 0 {
+			updateColumnList = table.PrimaryKeyList
+		}
+		var setList []string
+		for _, column := range updateColumnList {
+			setList = append(setList, fmt.Sprintf("%s = VALUES(%s)", column, column))
+		}
+		fmt.Fprintf(&buf, "INSERT INTO %s (%s) SELECT %s FROM %s ON DUPLICATE KEY UPDATE %s;\n",
+			table.Table, columns, columns, table.BackupTable, strings.Join(setList, ", "))
+	}
+	return buf.String()
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckDataBackupStatement checks whether the pre-change backup supports all the statements.
+func CheckDataBackupStatement(statement string) error {
+	statementList, err := SplitMultiStatements(statement)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range statementList {
+		if parseBatchStatement(stmt) == nil {
+			return unsupportedDataBackupStatementError(stmt)
+		}
+	}
+	return nil
+}
+
+func unsupportedDataBackupStatementError(statement string) error {
+	return fmt.Errorf("pre-change backup only supports the single-table UPDATE and DELETE statements without subqueries, joins, ORDER BY or LIMIT, got %q", TruncateStatement(statement, statementMaxLength))
+}
@@ -0,0 +1,110 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+func TestGetDataBackupTableName(t *testing.T) {
+	tests := []struct {
+		dbType db.Type
+		table  string
+		want   string
+	}{
+		{dbType: db.MySQL, table: "t", want: "_1_2_0_t"},
+		{dbType: db.MySQL, table: "`db`.`t`", want: "_1_2_0_t"},
+		{dbType: db.Postgres, table: `public."T"`, want: "_1_2_0_T"},
+		{dbType: db.Postgres, table: "a123456789a123456789a123456789a123456789a123456789a123456789", want: "_1_2_0_a123456789a123456789a123456789a123456789a123456789a12345"},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.want, getDataBackupTableName(test.dbType, "_1_2_", 0, test.table), test.table)
+	}
+}
+
+func TestIsColumnAssigned(t *testing.T) {
+	tests := []struct {
+		prefix string
+		column string
+		want   bool
+	}{
+		{prefix: "UPDATE t SET id = 1", column: "id", want: true},
+		{prefix: "UPDATE t SET a = 1, `id`=2", column: "id", want: true},
+		{prefix: `UPDATE t SET t."id" = 2`, column: "id", want: true},
+		{prefix: "UPDATE t SET paid = 1", column: "id", want: false},
+		{prefix: "UPDATE t SET a = id + 1", column: "id", want: false},
+		{prefix: "DELETE FROM t", column: "id", want: false},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.want, isColumnAssigned(test.prefix, test.column), test.prefix)
+	}
+}
+
+func TestCheckDataBackupStatement(t *testing.T) {
+	require.NoError(t, CheckDataBackupStatement("UPDATE t SET a = 1 WHERE id < 10;\nDELETE FROM t WHERE a = 2;"))
+	require.Error(t, CheckDataBackupStatement("UPDATE t SET a = 1;\nINSERT INTO t VALUES (1);"))
+	require.Error(t, CheckDataBackupStatement("DELETE FROM t WHERE id IN (SELECT id FROM s)"))
+}
+
+func TestGetDataRollbackStatement(t *testing.T) {
+	tableList := []*db.DataBackupTable{
+		{
+			Index:          0,
+			Table:          "t",
+			BackupTable:    `"bbdataarchive"."_1_2_0_t"`,
+			PrimaryKeyList: []string{`"id"`},
+			ColumnList:     []string{`"id"`, `"a"`},
+		},
+		{
+			Index:          1,
+			Table:          "s",
+			BackupTable:    `"bbdataarchive"."_1_2_1_s"`,
+			PrimaryKeyList: []string{`"x"`, `"y"`},
+			ColumnList:     []string{`"x"`, `"y"`, `"b"`},
+		},
+	}
+	want := `INSERT INTO s ("x", "y", "b") OVERRIDING SYSTEM VALUE SELECT "x", "y", "b" FROM "bbdataarchive"."_1_2_1_s" ON CONFLICT ("x", "y") DO UPDATE SET "b" = EXCLUDED."b";
+INSERT INTO t ("id", "a") OVERRIDING SYSTEM VALUE SELECT "id", "a" FROM "bbdataarchive"."_1_2_0_t" ON CONFLICT ("id") DO UPDATE SET "a" = EXCLUDED."a";
+`
+	require.Equal(t, want, GetDataRollbackStatement(db.Postgres, tableList))
+
+	tableList = []*db.DataBackupTable{
+		{
+			Index:          0,
+			Table:          "t",
+			BackupTable:    "`bbdataarchive`.`_1_2_0_t`",
+			PrimaryKeyList: []string{"`id`"},
+			ColumnList:     []string{"`id`", "`a`", "`b`"},
+		},
+		{
+			Index:          1,
+			Table:          "s",
+			BackupTable:    "`bbdataarchive`.`_1_2_1_s`",
+			PrimaryKeyList: []string{"`x`"},
+			ColumnList:     []string{"`x`"},
+		},
+	}
+	want = "INSERT INTO s (`x`) SELECT `x` FROM `bbdataarchive`.`_1_2_1_s` ON DUPLICATE KEY UPDATE `x` = VALUES(`x`);\n" +
+		"INSERT INTO t (`id`, `a`, `b`) SELECT `id`, `a`, `b` FROM `bbdataarchive`.`_1_2_0_t` ON DUPLICATE KEY UPDATE `a` = VALUES(`a`), `b` = VALUES(`b`);\n"
+	require.Equal(t, want, GetDataRollbackStatement(db.MySQL, tableList))
+}
+
+func TestIsDataBackupTableExpired(t *testing.T) {
+	now := time.Unix(1659312000, 0)
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: fmt.Sprintf("_%d_2_0_t", now.Add(-db.DataBackupRetentionPeriod).Unix()), want: true},
+		{name: fmt.Sprintf("_%d_2_0_t", now.Add(-db.DataBackupRetentionPeriod+time.Second).Unix()), want: false},
+		{name: "_abc_2_0_t", want: false},
+		{name: "t", want: false},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.want, isDataBackupTableExpired(test.name, now), test.name)
+	}
+}
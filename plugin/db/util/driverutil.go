@@ -5,12 +5,20 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/blang/semver/v4"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
 	"go.uber.org/zap"
 
 	"github.com/bytebase/bytebase/common"
@@ -94,6 +102,67 @@ func ApplyMultiStatements(sc *bufio.Scanner, f func(string) error) error {
 	return nil
 }
 
+// SplitMultiStatements splits the statement into the list of statements the same way as ApplyMultiStatements().
+func SplitMultiStatements(statement string) ([]string, error) {
+	var statementList []string
+	sc := bufio.NewScanner(strings.NewReader(statement))
+	if err := ApplyMultiStatements(sc, func(stmt string) error {
+		statementList = append(statementList, stmt)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return statementList, nil
+}
+
+// statementMaxLength is the max length of the statement text recorded in the statement result.
+const statementMaxLength = 1024
+
+// TruncateStatement truncates the statement to at most maxLength bytes without splitting a UTF-8 character.
+func TruncateStatement(statement string, maxLength int) string {
+	if len(statement) <= maxLength {
+		return statement
+	}
+	end := maxLength
+	for end > 0 && !utf8.RuneStart(statement[end]) {
+		end--
+	}
+	return statement[:end] + "..."
+}
+
+// NewStatementResult returns the statement result of the statement at index, which is not executed yet.
+func NewStatementResult(index int, statement string) *db.StatementResult {
+	return &db.StatementResult{
+		Index:        index,
+		Statement:    TruncateStatement(statement, statementMaxLength),
+		RowsAffected: -1,
+		Status:       db.StatementSkipped,
+	}
+}
+
+// ExecuteStatement executes the query and fills the execution detail into the statement result.
+// The query is usually the statement itself, but drivers may wrap the statement, e.g. to switch the privilege.
+func ExecuteStatement(ctx context.Context, execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}, result *db.StatementResult, query string) error {
+	recorder := db.GetStatementRecorder(ctx)
+	recorder.Start(result)
+	defer recorder.End(result)
+	startedNs := time.Now().UnixNano()
+	sqlResult, err := execer.ExecContext(ctx, query)
+	result.DurationNs = time.Now().UnixNano() - startedNs
+	if err != nil {
+		result.Status = db.StatementFailed
+		result.Error = err.Error()
+		return err
+	}
+	result.Status = db.StatementDone
+	if rowsAffected, err := sqlResult.RowsAffected(); err == nil {
+		result.RowsAffected = rowsAffected
+	}
+	return nil
+}
+
 // NeedsSetupMigrationSchema will return whether it's needed to setup migration schema.
 func NeedsSetupMigrationSchema(ctx context.Context, sqldb *sql.DB, query string) (bool, error) {
 	rows, err := sqldb.QueryContext(ctx, query)
@@ -128,6 +197,12 @@ type MigrationExecutor interface {
 	UpdateHistoryAsFailed(ctx context.Context, tx *sql.Tx, migrationDurationNs int64, insertedID int64) error
 }
 
+// MigrationPayloadUpdater is implemented by the migration executors supporting the batched data update, which records the rows affected in the migration history payload.
+type MigrationPayloadUpdater interface {
+	// UpdateHistoryPayload will update the payload of the migration record.
+	UpdateHistoryPayload(ctx context.Context, tx *sql.Tx, payload string, insertedID int64) error
+}
+
 // ExecuteMigration will execute the database migration.
 // Returns the created migration history id and the updated schema on success.
 func ExecuteMigration(ctx context.Context, executor MigrationExecutor, m *db.MigrationInfo, statement string, databaseName string) (migrationHistoryID int64, updatedSchema string, resErr error) {
@@ -154,12 +229,26 @@ func ExecuteMigration(ctx context.Context, executor MigrationExecutor, m *db.Mig
 	startedNs := time.Now().UnixNano()
 
 	defer func() {
-		if err := EndMigration(ctx, executor, startedNs, insertedID, updatedSchema, databaseName, resErr == nil /*isDone*/); err != nil {
+		// Still record the migration history as FAILED if the migration is canceled.
+		endCtx := ctx
+		if ctx.Err() != nil {
+			endCtx = context.Background()
+		}
+		if err := EndMigration(endCtx, executor, startedNs, insertedID, updatedSchema, databaseName, resErr == nil /*isDone*/); err != nil {
 			log.Error("Failed to update migration history record",
 				zap.Error(err),
 				zap.Int64("migration_id", migrationHistoryID),
 			)
 		}
+		// Record the rows affected even if the batched data update fails, since the previous batches have been committed.
+		if controller := db.GetBatchController(ctx); controller != nil {
+			if err := updateMigrationRowsAffected(endCtx, executor, m, insertedID, databaseName, controller.Progress().RowsAffected); err != nil {
+				log.Error("Failed to record the rows affected in migration history record",
+					zap.Error(err),
+					zap.Int64("migration_id", insertedID),
+				)
+			}
+		}
 	}()
 
 	// Phase 3 - Executing migration
@@ -260,6 +349,9 @@ func BeginMigration(ctx context.Context, executor MigrationExecutor, m *db.Migra
 	// MySQL runs DDL in its own transaction, so we can't commit migration history together with DDL in a single transaction.
 	// Thus we sort of doing a 2-phase commit, where we first write a PENDING migration record, and after migration completes, we then
 	// update the record to DONE together with the updated schema.
+	if err := setMigrationChecksum(m, statement); err != nil {
+		return -1, err
+	}
 	if insertedID, err = executor.InsertPendingHistory(ctx, tx, largestSequence+1, prevSchema, m, storedVersion, statement); err != nil {
 		return -1, err
 	}
@@ -304,6 +396,58 @@ func EndMigration(ctx context.Context, executor MigrationExecutor, startedNs int
 	return nil
 }
 
+// setMigrationChecksum records the checksum of the statement in the migration payload, which is
+// compared against the migration file in the repository to detect the file modified after being applied.
+func setMigrationChecksum(m *db.MigrationInfo, statement string) error {
+	payload := &db.MigrationInfoPayload{}
+	if m.Payload != "" {
+		if err := json.Unmarshal([]byte(m.Payload), payload); err != nil {
+			return fmt.Errorf("failed to unmarshal migration payload, error: %w", err)
+		}
+	}
+	payload.Checksum = db.GetMigrationChecksum(statement)
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal migration payload, error: %w", err)
+	}
+	m.Payload = string(bytes)
+	return nil
+}
+
+// updateMigrationRowsAffected records the rows affected in the payload of the migration history record.
+func updateMigrationRowsAffected(ctx context.Context, executor MigrationExecutor, m *db.MigrationInfo, migrationHistoryID int64, databaseName string, rowsAffected int64) error {
+	updater, ok := executor.(MigrationPayloadUpdater)
+	if !ok {
+		return nil
+	}
+	payload := &db.MigrationInfoPayload{}
+	if m.Payload != "" {
+		if err := json.Unmarshal([]byte(m.Payload), payload); err != nil {
+			return fmt.Errorf("failed to unmarshal migration payload, error: %w", err)
+		}
+	}
+	payload.RowsAffected = &rowsAffected
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal migration payload, error: %w", err)
+	}
+
+	sqldb, err := executor.GetDBConnection(ctx, databaseName)
+	if err != nil {
+		return err
+	}
+	tx, err := sqldb.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updater.UpdateHistoryPayload(ctx, tx, string(bytes), migrationHistoryID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // Query will execute a readonly / SELECT query.
 func Query(ctx context.Context, sqldb *sql.DB, statement string, limit int) ([]interface{}, error) {
 	// Not all sql engines support ReadOnly flag, so we will use tx rollback semantics to enforce readonly.
@@ -483,6 +627,79 @@ func FormatError(err error) error {
 	return err
 }
 
+// killQueryTimeout is the timeout for killing the server-side query on cancellation.
+const killQueryTimeout = 10 * time.Second
+
+// WatchCancel calls kill if ctx is canceled before the returned stop function is called.
+// Drivers use it to kill the server-side query, because closing the client connection doesn't stop the query running on the server.
+// The stop function must be called once the query completes, and it waits for the ongoing kill to finish.
+func WatchCancel(ctx context.Context, kill func(ctx context.Context) error) (stop func()) {
+	done := make(chan struct{})
+	killed := make(chan struct{})
+	go func() {
+		defer close(killed)
+		select {
+		case <-ctx.Done():
+			killCtx, cancel := context.WithTimeout(context.Background(), killQueryTimeout)
+			defer cancel()
+			if err := kill(killCtx); err != nil {
+				log.Warn("Failed to kill the query on cancellation", zap.Error(err))
+			}
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-killed
+	}
+}
+
+// ClassifyError returns the class of the error returned by the database of the given type.
+// Connection errors are recognized for all database types, while lock, deadlock and serialization errors are only recognized for MySQL, TiDB and Postgres.
+func ClassifyError(dbType db.Type, err error) db.ErrorClass {
+	if err == nil {
+		return db.ErrorClassUnknown
+	}
+
+	switch dbType {
+	case db.MySQL, db.TiDB:
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) {
+			switch mysqlErr.Number {
+			// ER_LOCK_WAIT_TIMEOUT
+			case 1205:
+				return db.ErrorClassLockTimeout
+			// ER_LOCK_DEADLOCK
+			case 1213:
+				return db.ErrorClassDeadlock
+			}
+		}
+		if errors.Is(err, mysql.ErrInvalidConn) {
+			return db.ErrorClassConnection
+		}
+	case db.Postgres:
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			// lock_not_available
+			case "55P03":
+				return db.ErrorClassLockTimeout
+			// deadlock_detected
+			case "40P01":
+				return db.ErrorClassDeadlock
+			// serialization_failure
+			case "40001":
+				return db.ErrorClassSerialization
+			}
+		}
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return db.ErrorClassConnection
+	}
+	return db.ErrorClassUnknown
+}
+
 // NonSemanticPrefix is the prefix for non-semantic version.
 const NonSemanticPrefix = "0000.0000.0000-"
 
@@ -1,9 +1,20 @@
 package util
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"syscall"
 	"testing"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
 	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
 )
 
 func TestToStoredVersion(t *testing.T) {
@@ -68,3 +79,141 @@ func TestFromStoredVersion(t *testing.T) {
 		require.Equal(t, tc.wantSemanticVersionSuffix, gotSemanticVersionSuffix)
 	}
 }
+
+func TestClassifyError(t *testing.T) {
+	type test struct {
+		dbType db.Type
+		err    error
+		want   db.ErrorClass
+	}
+	tests := []test{
+		{db.MySQL, nil, db.ErrorClassUnknown},
+		{db.MySQL, &mysql.MySQLError{Number: 1205}, db.ErrorClassLockTimeout},
+		{db.TiDB, &mysql.MySQLError{Number: 1213}, db.ErrorClassDeadlock},
+		{db.MySQL, &mysql.MySQLError{Number: 1064}, db.ErrorClassUnknown},
+		{db.MySQL, FormatErrorWithQuery(&mysql.MySQLError{Number: 1213}, "DELETE FROM t"), db.ErrorClassDeadlock},
+		{db.MySQL, mysql.ErrInvalidConn, db.ErrorClassConnection},
+		{db.Postgres, &pgconn.PgError{Code: "55P03"}, db.ErrorClassLockTimeout},
+		{db.Postgres, &pgconn.PgError{Code: "40P01"}, db.ErrorClassDeadlock},
+		{db.Postgres, common.Errorf(common.DbExecutionError, "failed, error: %w", &pgconn.PgError{Code: "40001"}), db.ErrorClassSerialization},
+		{db.Postgres, &pgconn.PgError{Code: "42601"}, db.ErrorClassUnknown},
+		// MySQL error numbers are not recognized for Postgres.
+		{db.Postgres, &mysql.MySQLError{Number: 1205}, db.ErrorClassUnknown},
+		{db.ClickHouse, driver.ErrBadConn, db.ErrorClassConnection},
+		{db.Snowflake, fmt.Errorf("failed to execute, error: %w", syscall.ECONNRESET), db.ErrorClassConnection},
+		{db.SQLite, fmt.Errorf("database is locked"), db.ErrorClassUnknown},
+	}
+	for _, tc := range tests {
+		got := ClassifyError(tc.dbType, tc.err)
+		require.Equal(t, tc.want, got, "%s: %v", tc.dbType, tc.err)
+	}
+
+	// The outcome is unknown for a broken connection, which may happen after the change is committed.
+	require.True(t, db.ErrorClassDeadlock.IsRolledBack())
+	require.False(t, db.ErrorClassConnection.IsRolledBack())
+}
+
+func TestWatchCancel(t *testing.T) {
+	// The query is killed if the context is canceled before stop.
+	ctx, cancel := context.WithCancel(context.Background())
+	killed := make(chan struct{}, 1)
+	stop := WatchCancel(ctx, func(context.Context) error {
+		killed <- struct{}{}
+		return nil
+	})
+	cancel()
+	<-killed
+	stop()
+
+	// The query isn't killed if the context is canceled after stop.
+	ctx, cancel = context.WithCancel(context.Background())
+	stop = WatchCancel(ctx, func(context.Context) error {
+		killed <- struct{}{}
+		return nil
+	})
+	stop()
+	cancel()
+	require.Len(t, killed, 0)
+}
+
+type fakeExecer struct {
+	err error
+}
+
+func (e *fakeExecer) ExecContext(_ context.Context, _ string, _ ...interface{}) (sql.Result, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return driver.RowsAffected(3), nil
+}
+
+func TestExecuteStatementNotifiesRecorder(t *testing.T) {
+	var eventList []string
+	recorder := &db.StatementRecorder{
+		OnStart: func(result *db.StatementResult) {
+			eventList = append(eventList, fmt.Sprintf("start %s", result.Statement))
+		},
+		OnEnd: func(result *db.StatementResult) {
+			eventList = append(eventList, fmt.Sprintf("end %s %s %d %s", result.Statement, result.Status, result.RowsAffected, result.Error))
+		},
+	}
+	ctx := db.WithStatementRecorder(context.Background(), recorder)
+
+	// The end event carries the execution detail of the statement.
+	err := ExecuteStatement(ctx, &fakeExecer{}, NewStatementResult(0, "DELETE FROM t"), "DELETE FROM t")
+	require.NoError(t, err)
+	err = ExecuteStatement(ctx, &fakeExecer{err: fmt.Errorf("deadlock")}, NewStatementResult(1, "DELETE FROM s"), "DELETE FROM s")
+	require.Error(t, err)
+	require.Equal(t, []string{
+		"start DELETE FROM t",
+		"end DELETE FROM t DONE 3 ",
+		"start DELETE FROM s",
+		"end DELETE FROM s FAILED -1 deadlock",
+	}, eventList)
+
+	// No recorder is fine.
+	err = ExecuteStatement(context.Background(), &fakeExecer{}, NewStatementResult(0, "DELETE FROM t"), "DELETE FROM t")
+	require.NoError(t, err)
+}
+
+func TestTruncateStatement(t *testing.T) {
+	require.Equal(t, "SELECT 1", TruncateStatement("SELECT 1", 10))
+	require.Equal(t, "SELECT...", TruncateStatement("SELECT 1", 6))
+	// Don't split the multi-byte character.
+	require.Equal(t, "SELECT '...", TruncateStatement("SELECT '你好'", 9))
+}
+
+func TestSplitMultiStatements(t *testing.T) {
+	statementList, err := SplitMultiStatements("-- comment\nCREATE TABLE t (id INT);\n\nINSERT INTO t\nVALUES (1);\nDELETE FROM t")
+	require.NoError(t, err)
+	require.Equal(t, []string{"CREATE TABLE t (id INT);", "INSERT INTO t\nVALUES (1);", "DELETE FROM t"}, statementList)
+}
+
+func TestSetMigrationChecksum(t *testing.T) {
+	statement := "CREATE TABLE t (id INT);"
+	m := &db.MigrationInfo{
+		Payload: `{"rollbackStatement":"DROP TABLE t;"}`,
+	}
+	err := setMigrationChecksum(m, statement)
+	require.NoError(t, err)
+
+	// The checksum is recorded along with the existing payload.
+	checksum, err := db.GetMigrationHistoryChecksum(&db.MigrationHistory{Payload: m.Payload})
+	require.NoError(t, err)
+	require.Equal(t, db.GetMigrationChecksum(statement), checksum)
+	require.Contains(t, m.Payload, `"rollbackStatement":"DROP TABLE t;"`)
+
+	// The migration file read from the repository has the surrounding whitespaces trimmed when being applied,
+	// its checksum still matches the recorded one.
+	content := "\n" + statement + "\n\n"
+	err = setMigrationChecksum(m, strings.TrimSpace(content))
+	require.NoError(t, err)
+	checksum, err = db.GetMigrationHistoryChecksum(&db.MigrationHistory{Payload: m.Payload})
+	require.NoError(t, err)
+	require.Equal(t, db.GetMigrationChecksum(content), checksum)
+
+	// The migration history recorded before the checksum has no checksum.
+	checksum, err = db.GetMigrationHistoryChecksum(&db.MigrationHistory{Payload: `{"rollbackStatement":"DROP TABLE t;"}`})
+	require.NoError(t, err)
+	require.Equal(t, "", checksum)
+}
@@ -0,0 +1,392 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+const (
+	// flywayHistoryTable is the default table Flyway records the applied migrations in.
+	flywayHistoryTable = "flyway_schema_history"
+	// liquibaseHistoryTable is the default table Liquibase records the executed changesets in.
+	liquibaseHistoryTable = "DATABASECHANGELOG"
+	// golangMigrateHistoryTable is the default table golang-migrate records the current version in.
+	golangMigrateHistoryTable = "schema_migrations"
+)
+
+// MigrationHistoryImportExecutor is an adapter for ImportMigrationHistory().
+type MigrationHistoryImportExecutor interface {
+	MigrationExecutor
+	// FormatImportTable returns the table reference of the migration tool table in the database used in the query.
+	FormatImportTable(database, table string) string
+	// FormatUnixTimestamp returns the expression converting the timestamp column to the unix timestamp in seconds.
+	FormatUnixTimestamp(column string) string
+	// InsertImportedHistory will insert the migration record imported from the migration tool with the status of m,
+	// the schema, the applied time and the execution duration, and return the inserted ID.
+	InsertImportedHistory(ctx context.Context, tx *sql.Tx, sequence int, m *db.MigrationInfo, storedVersion, schema string, createdTs int64, executionDurationNs int64) (insertedID int64, err error)
+}
+
+// importedMigration is a migration read from the table of the migration tool.
+type importedMigration struct {
+	version       string
+	description   string
+	migrationType db.MigrationType
+	status        db.MigrationStatus
+	// createdTs is the unix timestamp the migration was applied at, or 0 if the migration tool doesn't record it.
+	createdTs           int64
+	executionDurationNs int64
+	checksum            string
+}
+
+// flywayRecord is a row of the flyway_schema_history table.
+type flywayRecord struct {
+	version         sql.NullString
+	description     string
+	migrationType   string
+	checksum        sql.NullInt64
+	installedTs     int64
+	executionTimeMs int64
+	success         bool
+}
+
+// liquibaseRecord is a row of the DATABASECHANGELOG table.
+type liquibaseRecord struct {
+	id            string
+	author        string
+	filename      string
+	executedTs    int64
+	orderExecuted int
+	execType      string
+	md5sum        sql.NullString
+	description   sql.NullString
+}
+
+// ImportMigrationHistory imports the migration history of the database recorded by the migration tool of the source
+// into the migration history stored in the bytebaseDatabase. The migrations are appended in the order they were applied,
+// so that the version ordering continues from the imported ones. The versions already in the migration history are skipped.
+// The statements and schemas aren't recorded by the migration tools, so the imported migration history has no statement,
+// and only the last imported migration records the current schema, against which the schema drift is detected.
+func ImportMigrationHistory(ctx context.Context, executor MigrationHistoryImportExecutor, database string, source db.MigrationSource, creator string, bytebaseDatabase string) (int, error) {
+	var migrationList []*importedMigration
+	var err error
+	switch source {
+	case db.FLYWAY:
+		migrationList, err = readFlywayHistory(ctx, executor, database)
+	case db.LIQUIBASE:
+		migrationList, err = readLiquibaseHistory(ctx, executor, database)
+	case db.GolangMigrate:
+		migrationList, err = readGolangMigrateHistory(ctx, executor, database)
+	default:
+		return 0, fmt.Errorf("unsupported migration history source %q", source)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	historyList, err := executor.FindMigrationHistoryList(ctx, &db.MigrationHistoryFind{
+		Database: &database,
+	})
+	if err != nil {
+		return 0, err
+	}
+	migrationList = filterImportedMigrationList(migrationList, historyList)
+	if len(migrationList) == 0 {
+		return 0, nil
+	}
+
+	var schemaBuf bytes.Buffer
+	if _, err := executor.Dump(ctx, database, &schemaBuf, true /* schemaOnly */); err != nil {
+		return 0, FormatError(err)
+	}
+
+	sqldb, err := executor.GetDBConnection(ctx, bytebaseDatabase)
+	if err != nil {
+		return 0, err
+	}
+	tx, err := sqldb.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	largestSequence, err := executor.FindLargestSequence(ctx, tx, database, false /* baseline */)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().Unix()
+	for i, migration := range migrationList {
+		storedVersion, err := ToStoredVersion(false, migration.version, "")
+		if err != nil {
+			return 0, fmt.Errorf("failed to convert to stored version, error %w", err)
+		}
+		payload, err := json.Marshal(&db.MigrationInfoPayload{
+			SourceChecksum: migration.checksum,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal migration payload, error: %w", err)
+		}
+		createdTs := migration.createdTs
+		if createdTs == 0 {
+			createdTs = now
+		}
+		schema := ""
+		if i == len(migrationList)-1 {
+			schema = schemaBuf.String()
+		}
+		m := &db.MigrationInfo{
+			Version:     migration.version,
+			Namespace:   database,
+			Database:    database,
+			Source:      source,
+			Type:        migration.migrationType,
+			Status:      migration.status,
+			Description: migration.description,
+			Creator:     creator,
+			Payload:     string(payload),
+		}
+		if _, err := executor.InsertImportedHistory(ctx, tx, largestSequence+i+1, m, storedVersion, schema, createdTs, migration.executionDurationNs); err != nil {
+			return 0, FormatError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(migrationList), nil
+}
+
+// filterImportedMigrationList returns the imported migrations whose versions aren't in the migration history.
+// If the migration tool recorded a version more than once, e.g. a failed migration retried, the last one wins.
+func filterImportedMigrationList(migrationList []*importedMigration, historyList []*db.MigrationHistory) []*importedMigration {
+	appliedVersionMap := make(map[string]bool)
+	for _, history := range historyList {
+		if !history.UseSemanticVersion {
+			appliedVersionMap[history.Version] = true
+		}
+	}
+	var result []*importedMigration
+	indexMap := make(map[string]int)
+	for _, migration := range migrationList {
+		if appliedVersionMap[migration.version] {
+			continue
+		}
+		if i, ok := indexMap[migration.version]; ok {
+			result[i] = migration
+			continue
+		}
+		indexMap[migration.version] = len(result)
+		result = append(result, migration)
+	}
+	return result
+}
+
+func readFlywayHistory(ctx context.Context, executor MigrationHistoryImportExecutor, database string) ([]*importedMigration, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			version,
+			description,
+			type,
+			checksum,
+			%s,
+			execution_time,
+			success
+		FROM %s
+		ORDER BY installed_rank`,
+		executor.FormatUnixTimestamp("installed_on"),
+		executor.FormatImportTable(database, flywayHistoryTable),
+	)
+	var recordList []*flywayRecord
+	if err := queryImportTable(ctx, executor, database, query, func(rows *sql.Rows) error {
+		var record flywayRecord
+		if err := rows.Scan(
+			&record.version,
+			&record.description,
+			&record.migrationType,
+			&record.checksum,
+			&record.installedTs,
+			&record.executionTimeMs,
+			&record.success,
+		); err != nil {
+			return err
+		}
+		recordList = append(recordList, &record)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read the Flyway migration history from table %q of database %q, error: %w", flywayHistoryTable, database, err)
+	}
+
+	var migrationList []*importedMigration
+	for _, record := range recordList {
+		if migration := convertFlywayRecord(record); migration != nil {
+			migrationList = append(migrationList, migration)
+		}
+	}
+	return migrationList, nil
+}
+
+// convertFlywayRecord converts the Flyway record to the imported migration, or returns nil if the record
+// isn't a versioned migration, e.g. the repeatable migrations and the undo migrations.
+func convertFlywayRecord(record *flywayRecord) *importedMigration {
+	if !record.version.Valid || record.version.String == "" {
+		return nil
+	}
+	migrationType := db.Migrate
+	switch {
+	case strings.Contains(record.migrationType, "BASELINE"):
+		migrationType = db.Baseline
+	case record.migrationType == "SCHEMA", record.migrationType == "DELETE", strings.HasPrefix(record.migrationType, "UNDO"):
+		return nil
+	}
+	status := db.Done
+	if !record.success {
+		status = db.Failed
+	}
+	var checksum string
+	if record.checksum.Valid {
+		checksum = fmt.Sprintf("%d", record.checksum.Int64)
+	}
+	return &importedMigration{
+		version:             record.version.String,
+		description:         record.description,
+		migrationType:       migrationType,
+		status:              status,
+		createdTs:           record.installedTs,
+		executionDurationNs: record.executionTimeMs * int64(time.Millisecond),
+		checksum:            checksum,
+	}
+}
+
+func readLiquibaseHistory(ctx context.Context, executor MigrationHistoryImportExecutor, database string) ([]*importedMigration, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			ID,
+			AUTHOR,
+			FILENAME,
+			%s,
+			ORDEREXECUTED,
+			EXECTYPE,
+			MD5SUM,
+			DESCRIPTION
+		FROM %s
+		ORDER BY ORDEREXECUTED`,
+		executor.FormatUnixTimestamp("DATEEXECUTED"),
+		executor.FormatImportTable(database, liquibaseHistoryTable),
+	)
+	var recordList []*liquibaseRecord
+	if err := queryImportTable(ctx, executor, database, query, func(rows *sql.Rows) error {
+		var record liquibaseRecord
+		if err := rows.Scan(
+			&record.id,
+			&record.author,
+			&record.filename,
+			&record.executedTs,
+			&record.orderExecuted,
+			&record.execType,
+			&record.md5sum,
+			&record.description,
+		); err != nil {
+			return err
+		}
+		recordList = append(recordList, &record)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read the Liquibase migration history from table %q of database %q, error: %w", liquibaseHistoryTable, database, err)
+	}
+
+	var migrationList []*importedMigration
+	for _, record := range recordList {
+		if migration := convertLiquibaseRecord(record); migration != nil {
+			migrationList = append(migrationList, migration)
+		}
+	}
+	return migrationList, nil
+}
+
+// convertLiquibaseRecord converts the Liquibase changeset record to the imported migration, or returns nil if the
+// changeset wasn't executed. Liquibase changesets have no version, so the zero-padded execution order is used as the
+// version, which keeps the version ordering the same as the execution ordering.
+func convertLiquibaseRecord(record *liquibaseRecord) *importedMigration {
+	status := db.Done
+	switch record.execType {
+	case "EXECUTED", "RERAN", "MARK_RAN":
+	case "FAILED":
+		status = db.Failed
+	default:
+		// SKIPPED changesets are not executed.
+		return nil
+	}
+	description := fmt.Sprintf("%s by %s from %s", record.id, record.author, record.filename)
+	if record.description.Valid && record.description.String != "" {
+		description = fmt.Sprintf("%s: %s", description, record.description.String)
+	}
+	return &importedMigration{
+		version:       fmt.Sprintf("%05d", record.orderExecuted),
+		description:   description,
+		migrationType: db.Migrate,
+		status:        status,
+		createdTs:     record.executedTs,
+		checksum:      record.md5sum.String,
+	}
+}
+
+// readGolangMigrateHistory reads the current version recorded by golang-migrate. Unlike other migration tools,
+// golang-migrate only records the current version, so a single migration is imported. The dirty version
+// whose migration failed halfway is imported as failed.
+func readGolangMigrateHistory(ctx context.Context, executor MigrationHistoryImportExecutor, database string) ([]*importedMigration, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			version,
+			dirty
+		FROM %s`,
+		executor.FormatImportTable(database, golangMigrateHistoryTable),
+	)
+	var migrationList []*importedMigration
+	if err := queryImportTable(ctx, executor, database, query, func(rows *sql.Rows) error {
+		var version int64
+		var dirty bool
+		if err := rows.Scan(&version, &dirty); err != nil {
+			return err
+		}
+		status := db.Done
+		if dirty {
+			status = db.Failed
+		}
+		migrationList = append(migrationList, &importedMigration{
+			version:       fmt.Sprintf("%d", version),
+			description:   fmt.Sprintf("Migrated to version %d by golang-migrate", version),
+			migrationType: db.Migrate,
+			status:        status,
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read the golang-migrate migration history from table %q of database %q, error: %w", golangMigrateHistoryTable, database, err)
+	}
+	return migrationList, nil
+}
+
+// queryImportTable queries the migration tool table in the database and calls scan for each row.
+func queryImportTable(ctx context.Context, executor MigrationHistoryImportExecutor, database string, query string, scan func(rows *sql.Rows) error) error {
+	sqldb, err := executor.GetDBConnection(ctx, database)
+	if err != nil {
+		return err
+	}
+	rows, err := sqldb.QueryContext(ctx, query)
+	if err != nil {
+		return FormatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
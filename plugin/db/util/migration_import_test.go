@@ -0,0 +1,179 @@
+package util
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+func TestConvertFlywayRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		record *flywayRecord
+		want   *importedMigration
+	}{
+		{
+			name: "versioned migration",
+			record: &flywayRecord{
+				version:         sql.NullString{String: "1.1", Valid: true},
+				description:     "create table",
+				migrationType:   "SQL",
+				checksum:        sql.NullInt64{Int64: -1234, Valid: true},
+				installedTs:     1650000000,
+				executionTimeMs: 15,
+				success:         true,
+			},
+			want: &importedMigration{
+				version:             "1.1",
+				description:         "create table",
+				migrationType:       db.Migrate,
+				status:              db.Done,
+				createdTs:           1650000000,
+				executionDurationNs: 15000000,
+				checksum:            "-1234",
+			},
+		},
+		{
+			name: "failed migration",
+			record: &flywayRecord{
+				version:       sql.NullString{String: "2", Valid: true},
+				description:   "add column",
+				migrationType: "JDBC",
+				installedTs:   1650000001,
+			},
+			want: &importedMigration{
+				version:       "2",
+				description:   "add column",
+				migrationType: db.Migrate,
+				status:        db.Failed,
+				createdTs:     1650000001,
+			},
+		},
+		{
+			name: "baseline",
+			record: &flywayRecord{
+				version:       sql.NullString{String: "1", Valid: true},
+				description:   "<< Flyway Baseline >>",
+				migrationType: "BASELINE",
+				installedTs:   1650000002,
+				success:       true,
+			},
+			want: &importedMigration{
+				version:       "1",
+				description:   "<< Flyway Baseline >>",
+				migrationType: db.Baseline,
+				status:        db.Done,
+				createdTs:     1650000002,
+			},
+		},
+		{
+			name: "repeatable migration",
+			record: &flywayRecord{
+				description:   "create view",
+				migrationType: "SQL",
+				success:       true,
+			},
+			want: nil,
+		},
+		{
+			name: "undo migration",
+			record: &flywayRecord{
+				version:       sql.NullString{String: "2", Valid: true},
+				migrationType: "UNDO_SQL",
+				success:       true,
+			},
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, convertFlywayRecord(test.record))
+		})
+	}
+}
+
+func TestConvertLiquibaseRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		record *liquibaseRecord
+		want   *importedMigration
+	}{
+		{
+			name: "executed changeset",
+			record: &liquibaseRecord{
+				id:            "1",
+				author:        "alice",
+				filename:      "changelog.xml",
+				executedTs:    1650000000,
+				orderExecuted: 3,
+				execType:      "EXECUTED",
+				md5sum:        sql.NullString{String: "8:d41d8cd98f00b204e9800998ecf8427e", Valid: true},
+				description:   sql.NullString{String: "createTable tableName=t", Valid: true},
+			},
+			want: &importedMigration{
+				version:       "00003",
+				description:   "1 by alice from changelog.xml: createTable tableName=t",
+				migrationType: db.Migrate,
+				status:        db.Done,
+				createdTs:     1650000000,
+				checksum:      "8:d41d8cd98f00b204e9800998ecf8427e",
+			},
+		},
+		{
+			name: "failed changeset",
+			record: &liquibaseRecord{
+				id:            "2",
+				author:        "bob",
+				filename:      "changelog.xml",
+				executedTs:    1650000001,
+				orderExecuted: 12,
+				execType:      "FAILED",
+			},
+			want: &importedMigration{
+				version:       "00012",
+				description:   "2 by bob from changelog.xml",
+				migrationType: db.Migrate,
+				status:        db.Failed,
+				createdTs:     1650000001,
+			},
+		},
+		{
+			name: "skipped changeset",
+			record: &liquibaseRecord{
+				id:            "3",
+				author:        "bob",
+				filename:      "changelog.xml",
+				orderExecuted: 13,
+				execType:      "SKIPPED",
+			},
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, convertLiquibaseRecord(test.record))
+		})
+	}
+}
+
+func TestFilterImportedMigrationList(t *testing.T) {
+	migrationList := []*importedMigration{
+		{version: "1", status: db.Done},
+		{version: "2", status: db.Failed},
+		{version: "3", status: db.Done},
+		{version: "2", status: db.Done},
+	}
+	historyList := []*db.MigrationHistory{
+		{Version: "1"},
+		{Version: "3", UseSemanticVersion: true},
+	}
+	// The version in the migration history is skipped, and the last record of the version wins.
+	want := []*importedMigration{
+		{version: "2", status: db.Done},
+		{version: "3", status: db.Done},
+	}
+	require.Equal(t, want, filterImportedMigrationList(migrationList, historyList))
+}
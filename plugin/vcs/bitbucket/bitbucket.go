@@ -0,0 +1,467 @@
+// Package bitbucket is the VCS provider for Bitbucket Cloud and Bitbucket Data
+// Center (formerly Bitbucket Server). The two editions have different REST
+// APIs, and the edition is determined by the instance URL: the instance URL of
+// Bitbucket Cloud is always https://bitbucket.org, and any other instance URL is
+// treated as a Bitbucket Data Center instance.
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/vcs"
+	"github.com/bytebase/bytebase/plugin/vcs/internal/oauth"
+)
+
+const (
+	// CloudInstanceURL is the instance URL of Bitbucket Cloud.
+	CloudInstanceURL = "https://bitbucket.org"
+	// cloudAPIURL is the API URL of Bitbucket Cloud.
+	cloudAPIURL = "https://api.bitbucket.org/2.0"
+
+	// apiPageSize is the default page size when making API requests, which is
+	// the maximum page size of listing repositories of Bitbucket Cloud.
+	apiPageSize = 100
+
+	// webhookName is the name of the webhook created by Bytebase.
+	webhookName = "Bytebase GitOps"
+)
+
+// emptyCommitID is the commit ID of a ref that does not exist, which is used as
+// the "from" commit of a newly created ref.
+var emptyCommitID = strings.Repeat("0", 40)
+
+func init() {
+	vcs.Register(vcs.Bitbucket, newProvider)
+}
+
+var _ vcs.Provider = (*Provider)(nil)
+
+// Provider is a Bitbucket VCS provider, which delegates to the API client of
+// the edition determined by the instance URL.
+type Provider struct {
+	client *http.Client
+}
+
+func newProvider(config vcs.ProviderConfig) vcs.Provider {
+	if config.Client == nil {
+		config.Client = &http.Client{}
+	}
+	return &Provider{
+		client: config.Client,
+	}
+}
+
+// edition is the API client of a Bitbucket edition, which serves the methods
+// of vcs.Provider except the OAuth token exchange.
+type edition interface {
+	APIURL(instanceURL string) string
+	TryLogin(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) (*vcs.UserInfo, error)
+	FetchCommitByID(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string) (*vcs.Commit, error)
+	FetchUserInfo(ctx context.Context, oauthCtx common.OauthContext, instanceURL, user string) (*vcs.UserInfo, error)
+	FetchRepositoryActiveMemberList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string) ([]*vcs.RepositoryMember, error)
+	FetchAllRepositoryList(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) ([]*vcs.Repository, error)
+	FetchRepositoryFileList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, ref, filePath string) ([]*vcs.RepositoryTreeNode, error)
+	CreateFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommit vcs.FileCommitCreate) error
+	OverwriteFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommit vcs.FileCommitCreate) error
+	ReadFileMeta(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (*vcs.FileMeta, error)
+	ReadFileContent(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (string, error)
+	CreateWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, payload []byte) (string, error)
+	PatchWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string, payload []byte) error
+	DeleteWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string) error
+
+	// fetchPushEventCommitList fetches the commits pushed by the ref change,
+	// along with the files added by each commit.
+	fetchPushEventCommitList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, change WebhookPushChange) ([]WebhookCommit, error)
+}
+
+// IsCloud returns true if the instance URL is the Bitbucket Cloud.
+func IsCloud(instanceURL string) bool {
+	return instanceURL == CloudInstanceURL
+}
+
+func (p *Provider) edition(instanceURL string) edition {
+	if IsCloud(instanceURL) {
+		return &cloud{client: p.client}
+	}
+	return &dataCenter{client: p.client}
+}
+
+// APIURL returns the API URL path of Bitbucket.
+func (p *Provider) APIURL(instanceURL string) string {
+	return p.edition(instanceURL).APIURL(instanceURL)
+}
+
+// ExchangeOAuthToken exchanges OAuth content with the provided authorization code.
+func (p *Provider) ExchangeOAuthToken(ctx context.Context, instanceURL string, oauthExchange *common.OAuthExchange) (*vcs.OAuthToken, error) {
+	params := url.Values{}
+	params.Set("grant_type", "authorization_code")
+	params.Set("code", oauthExchange.Code)
+	if oauthExchange.RedirectURL != "" {
+		params.Set("redirect_uri", oauthExchange.RedirectURL)
+	}
+
+	resp, err := requestOAuthToken(ctx, p.client, instanceURL, oauthExchange.ClientID, oauthExchange.ClientSecret, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "exchange OAuth token")
+	}
+	return resp.toVCSOAuthToken(), nil
+}
+
+// TryLogin tries to fetch the user info from the current OAuth context.
+func (p *Provider) TryLogin(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) (*vcs.UserInfo, error) {
+	return p.edition(instanceURL).TryLogin(ctx, oauthCtx, instanceURL)
+}
+
+// FetchCommitByID fetches the commit data by its ID from the repository.
+func (p *Provider) FetchCommitByID(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string) (*vcs.Commit, error) {
+	return p.edition(instanceURL).FetchCommitByID(ctx, oauthCtx, instanceURL, repositoryID, commitID)
+}
+
+// FetchUserInfo fetches user info of given user.
+func (p *Provider) FetchUserInfo(ctx context.Context, oauthCtx common.OauthContext, instanceURL, user string) (*vcs.UserInfo, error) {
+	return p.edition(instanceURL).FetchUserInfo(ctx, oauthCtx, instanceURL, user)
+}
+
+// FetchRepositoryActiveMemberList fetches all active members of a repository.
+func (p *Provider) FetchRepositoryActiveMemberList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string) ([]*vcs.RepositoryMember, error) {
+	return p.edition(instanceURL).FetchRepositoryActiveMemberList(ctx, oauthCtx, instanceURL, repositoryID)
+}
+
+// FetchAllRepositoryList fetches all repositories where the authenticated user
+// has the admin permission, which is required to create webhook in the
+// repository.
+func (p *Provider) FetchAllRepositoryList(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) ([]*vcs.Repository, error) {
+	return p.edition(instanceURL).FetchAllRepositoryList(ctx, oauthCtx, instanceURL)
+}
+
+// FetchRepositoryFileList fetches the all files from the given repository tree
+// recursively.
+func (p *Provider) FetchRepositoryFileList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, ref, filePath string) ([]*vcs.RepositoryTreeNode, error) {
+	return p.edition(instanceURL).FetchRepositoryFileList(ctx, oauthCtx, instanceURL, repositoryID, ref, filePath)
+}
+
+// CreateFile creates a file at given path in the repository.
+func (p *Provider) CreateFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	return p.edition(instanceURL).CreateFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, fileCommitCreate)
+}
+
+// OverwriteFile overwrites an existing file at given path in the repository.
+func (p *Provider) OverwriteFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	return p.edition(instanceURL).OverwriteFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, fileCommitCreate)
+}
+
+// ReadFileMeta reads the metadata of the given file in the repository.
+func (p *Provider) ReadFileMeta(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (*vcs.FileMeta, error) {
+	return p.edition(instanceURL).ReadFileMeta(ctx, oauthCtx, instanceURL, repositoryID, filePath, ref)
+}
+
+// ReadFileContent reads the content of the given file in the repository.
+func (p *Provider) ReadFileContent(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (string, error) {
+	return p.edition(instanceURL).ReadFileContent(ctx, oauthCtx, instanceURL, repositoryID, filePath, ref)
+}
+
+// CreateWebhook creates a webhook in the repository with given payload, which
+// is the JSON of WebhookCreateOrUpdate.
+func (p *Provider) CreateWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, payload []byte) (string, error) {
+	return p.edition(instanceURL).CreateWebhook(ctx, oauthCtx, instanceURL, repositoryID, payload)
+}
+
+// PatchWebhook patches the webhook in the repository with given payload, which
+// is the JSON of WebhookCreateOrUpdate.
+func (p *Provider) PatchWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string, payload []byte) error {
+	return p.edition(instanceURL).PatchWebhook(ctx, oauthCtx, instanceURL, repositoryID, webhookID, payload)
+}
+
+// DeleteWebhook deletes the webhook from the repository.
+func (p *Provider) DeleteWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string) error {
+	return p.edition(instanceURL).DeleteWebhook(ctx, oauthCtx, instanceURL, repositoryID, webhookID)
+}
+
+// FetchPushEventCommitList fetches the commits pushed by the ref change in
+// chronological order, along with the files added by each commit. Merge commits
+// are excluded because the files they add have been added by the merged
+// commits. Neither edition of Bitbucket includes the changed files in the push
+// event payload, thus they have to be fetched by the API.
+func (p *Provider) FetchPushEventCommitList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, change WebhookPushChange) ([]WebhookCommit, error) {
+	return p.edition(instanceURL).fetchPushEventCommitList(ctx, oauthCtx, instanceURL, repositoryID, change)
+}
+
+// WebhookEventKey is the event key of a Bitbucket webhook, which is sent in the
+// "X-Event-Key" header.
+type WebhookEventKey string
+
+const (
+	// WebhookRepoPush is the event key of the push event of Bitbucket Cloud.
+	WebhookRepoPush WebhookEventKey = "repo:push"
+	// WebhookRepoRefsChanged is the event key of the push event of Bitbucket
+	// Data Center.
+	WebhookRepoRefsChanged WebhookEventKey = "repo:refs_changed"
+)
+
+// WebhookCreateOrUpdate is the API message for creating or updating a webhook,
+// which is translated to the request of the Bitbucket edition. Bitbucket does
+// not support filtering the push event by branch, it must be done by the
+// receiver.
+type WebhookCreateOrUpdate struct {
+	// URL is the URL to which the payloads will be delivered.
+	URL string `json:"url"`
+	// Secret is the key to generate the HMAC hex digest value for the delivery
+	// signature header.
+	Secret string `json:"secret"`
+	// Active determines whether the hook is triggered.
+	Active bool `json:"active"`
+}
+
+// WebhookRepository is the repository of the webhook push event.
+type WebhookRepository struct {
+	// FullName is the full path of the repository, which is the
+	// "{workspace}/{repository}" in Bitbucket Cloud and the
+	// "{project key}/{repository slug}" in Bitbucket Data Center.
+	FullName string
+	HTMLURL  string
+}
+
+// WebhookPushChange is a ref change of the webhook push event.
+type WebhookPushChange struct {
+	// Ref is the full name of the branch, e.g. "refs/heads/main".
+	Ref string
+	// Branch is the short name of the branch, e.g. "main".
+	Branch string
+	// FromHash is the commit of the branch before the push, which is empty or
+	// all zeros if the branch is newly created.
+	FromHash string
+	// ToHash is the commit of the branch after the push.
+	ToHash string
+	// Commits is the commits of the change in the payload, which is only
+	// available in Bitbucket Cloud. Bitbucket Cloud includes at most 5 commits,
+	// and Truncated is true if there are more.
+	Commits   []WebhookCommit
+	Truncated bool
+}
+
+// WebhookCommit is a commit pushed to the repository.
+type WebhookCommit struct {
+	ID          string
+	Message     string
+	Timestamp   time.Time
+	URL         string
+	AuthorName  string
+	AuthorEmail string
+	// ParentCount is the number of the parent commits, a merge commit has more
+	// than one parent.
+	ParentCount int
+	// Added is the list of the files added by the commit, which is only
+	// available from FetchPushEventCommitList.
+	Added []string
+	// Modified is the list of the files modified by the commit, which is only
+	// available from FetchPushEventCommitList.
+	Modified []string
+}
+
+// WebhookPushEvent is the push event of either Bitbucket edition.
+type WebhookPushEvent struct {
+	Repository WebhookRepository
+	ActorName  string
+	Changes    []WebhookPushChange
+}
+
+// ParseWebhookPushEvent parses the payload of the push event with the given
+// event key.
+//
+// Docs:
+//   - https://support.atlassian.com/bitbucket-cloud/docs/event-payloads/#Push
+//   - https://confluence.atlassian.com/bitbucketserver/event-payload-938025882.html#Eventpayload-Push
+func ParseWebhookPushEvent(eventKey WebhookEventKey, body []byte) (*WebhookPushEvent, error) {
+	switch eventKey {
+	case WebhookRepoPush:
+		return parseCloudWebhookPushEvent(body)
+	case WebhookRepoRefsChanged:
+		return parseDataCenterWebhookPushEvent(body)
+	}
+	return nil, errors.Errorf("unsupported webhook event key %q", eventKey)
+}
+
+// request makes the HTTP request with the token in the OAuth context. Bitbucket
+// responds the expired token with 401 instead of an OAuth error, so we refresh
+// the token and retry the request once on 401.
+func request(ctx context.Context, client *http.Client, oauthCtx common.OauthContext, instanceURL, method, url, contentType string, body []byte) (code int, respBody string, err error) {
+	do := func() (int, string, error) {
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return 0, "", errors.Wrapf(err, "construct %s %s", method, url)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", oauthCtx.AccessToken))
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, "", errors.Wrapf(err, "%s %s", method, url)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return 0, "", errors.Wrapf(err, "read response body of %s %s", method, url)
+		}
+		return resp.StatusCode, string(respBody), nil
+	}
+
+	code, respBody, err = do()
+	if err != nil {
+		return 0, "", err
+	}
+	if code != http.StatusUnauthorized || oauthCtx.RefreshToken == "" {
+		return code, respBody, nil
+	}
+
+	refresher := tokenRefresher(instanceURL, oauthCtx.ClientID, oauthCtx.ClientSecret, oauthCtx.RefreshToken, oauthCtx.Refresher)
+	if err := refresher(ctx, client, &oauthCtx.AccessToken); err != nil {
+		return 0, "", errors.Wrap(err, "refresh token")
+	}
+	return do()
+}
+
+// oauthResponse is a Bitbucket OAuth response.
+type oauthResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error,omitempty"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// toVCSOAuthToken converts the response to *vcs.OAuthToken.
+func (o oauthResponse) toVCSOAuthToken() *vcs.OAuthToken {
+	// Bitbucket doesn't return the creation time of the token.
+	oauthToken := &vcs.OAuthToken{
+		AccessToken:  o.AccessToken,
+		RefreshToken: o.RefreshToken,
+		ExpiresIn:    o.ExpiresIn,
+		CreatedAt:    time.Now().Unix(),
+	}
+	if oauthToken.ExpiresIn != 0 {
+		oauthToken.ExpiresTs = oauthToken.CreatedAt + oauthToken.ExpiresIn
+	}
+	return oauthToken
+}
+
+// requestOAuthToken requests the OAuth token from the token endpoint of the
+// Bitbucket edition with the given form parameters.
+//
+// Docs:
+//   - https://developer.atlassian.com/cloud/bitbucket/oauth-2/
+//   - https://confluence.atlassian.com/bitbucketserver/bitbucket-oauth-2-0-provider-api-1108483661.html
+func requestOAuthToken(ctx context.Context, client *http.Client, instanceURL, clientID, clientSecret string, params url.Values) (*oauthResponse, error) {
+	var tokenURL string
+	if IsCloud(instanceURL) {
+		tokenURL = fmt.Sprintf("%s/site/oauth2/access_token", instanceURL)
+	} else {
+		// Bitbucket Data Center takes the client credentials from the form
+		// parameters instead of the basic authentication.
+		tokenURL = fmt.Sprintf("%s/rest/oauth2/latest/token", instanceURL)
+		params.Set("client_id", clientID)
+		params.Set("client_secret", clientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "construct POST %s", tokenURL)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if IsCloud(instanceURL) {
+		req.SetBasicAuth(clientID, clientSecret)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "POST %s", tokenURL)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read body of POST %s", tokenURL)
+	}
+
+	oauthResp := new(oauthResponse)
+	if err := json.Unmarshal(body, oauthResp); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal body of POST %s with status code %d", tokenURL, resp.StatusCode)
+	}
+	if oauthResp.Error != "" {
+		return nil, errors.Errorf("OAuth error %q, description %q", oauthResp.Error, oauthResp.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("non-200 POST %s status code %d with body %q", tokenURL, resp.StatusCode, body)
+	}
+	return oauthResp, nil
+}
+
+func tokenRefresher(instanceURL, clientID, clientSecret, refreshToken string, refresher common.TokenRefresher) oauth.TokenRefresher {
+	return func(ctx context.Context, client *http.Client, oldToken *string) error {
+		params := url.Values{}
+		params.Set("grant_type", "refresh_token")
+		params.Set("refresh_token", refreshToken)
+		r, err := requestOAuthToken(ctx, client, instanceURL, clientID, clientSecret, params)
+		if err != nil {
+			return err
+		}
+
+		// Update the old token to new value for retries.
+		*oldToken = r.AccessToken
+
+		var expireAt int64
+		if r.ExpiresIn != 0 {
+			expireAt = time.Now().Unix() + r.ExpiresIn
+		}
+		return refresher(r.AccessToken, r.RefreshToken, expireAt)
+	}
+}
+
+// escapeFilePath escapes each segment of the file path, Bitbucket doesn't
+// accept the escaped path separator.
+func escapeFilePath(filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// parseWebhookCreateOrUpdate parses the webhook payload passed to the
+// provider.
+func parseWebhookCreateOrUpdate(payload []byte) (*WebhookCreateOrUpdate, error) {
+	var webhook WebhookCreateOrUpdate
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, errors.Wrap(err, "unmarshal webhook payload")
+	}
+	return &webhook, nil
+}
+
+// checkResponse returns the error for the non-2xx response of the operation
+// described by the action, e.g. "read file".
+func checkResponse(action, url string, code int, body string) error {
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to %s from URL %s", action, url)
+	} else if code >= 300 {
+		return fmt.Errorf("failed to %s from URL %s, status code: %d, body: %s", action, url, code, body)
+	}
+	return nil
+}
@@ -0,0 +1,424 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+const testDataCenterInstanceURL = "https://bitbucket.example.com"
+
+func newTestResponse(code int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: code,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestParseWebhookPushEvent(t *testing.T) {
+	t.Run("cloud", func(t *testing.T) {
+		body := `
+{
+  "actor": {"display_name": "Alice"},
+  "repository": {"full_name": "acme/shop", "links": {"html": {"href": "https://bitbucket.org/acme/shop"}}},
+  "push": {
+    "changes": [
+      {
+        "new": {"type": "branch", "name": "main", "target": {"hash": "c2"}},
+        "old": {"type": "branch", "name": "main", "target": {"hash": "c0"}},
+        "commits": [
+          {"hash": "c2", "message": "Add v2", "date": "2022-07-27T10:00:00+00:00", "author": {"raw": "Alice <alice@example.com>"}, "links": {"html": {"href": "https://bitbucket.org/acme/shop/commits/c2"}}, "parents": [{"hash": "c1"}]},
+          {"hash": "c1", "message": "Add v1", "date": "2022-07-27T09:00:00+00:00", "author": {"raw": "Alice <alice@example.com>"}, "links": {"html": {"href": "https://bitbucket.org/acme/shop/commits/c1"}}, "parents": [{"hash": "c0"}]}
+        ],
+        "truncated": false
+      },
+      {
+        "new": {"type": "tag", "name": "v1.0.0", "target": {"hash": "c2"}},
+        "old": null,
+        "commits": []
+      }
+    ]
+  }
+}`
+		got, err := ParseWebhookPushEvent(WebhookRepoPush, []byte(body))
+		require.NoError(t, err)
+
+		want := &WebhookPushEvent{
+			Repository: WebhookRepository{
+				FullName: "acme/shop",
+				HTMLURL:  "https://bitbucket.org/acme/shop",
+			},
+			ActorName: "Alice",
+			Changes: []WebhookPushChange{
+				{
+					Ref:      "refs/heads/main",
+					Branch:   "main",
+					FromHash: "c0",
+					ToHash:   "c2",
+					Commits: []WebhookCommit{
+						{
+							ID:          "c1",
+							Message:     "Add v1",
+							Timestamp:   time.Date(2022, 7, 27, 9, 0, 0, 0, time.UTC),
+							URL:         "https://bitbucket.org/acme/shop/commits/c1",
+							AuthorName:  "Alice",
+							AuthorEmail: "alice@example.com",
+							ParentCount: 1,
+						},
+						{
+							ID:          "c2",
+							Message:     "Add v2",
+							Timestamp:   time.Date(2022, 7, 27, 10, 0, 0, 0, time.UTC),
+							URL:         "https://bitbucket.org/acme/shop/commits/c2",
+							AuthorName:  "Alice",
+							AuthorEmail: "alice@example.com",
+							ParentCount: 1,
+						},
+					},
+				},
+			},
+		}
+		require.Len(t, got.Changes, 1)
+		for i := range got.Changes[0].Commits {
+			// Compare the time in UTC.
+			got.Changes[0].Commits[i].Timestamp = got.Changes[0].Commits[i].Timestamp.UTC()
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("data center", func(t *testing.T) {
+		body := `
+{
+  "eventKey": "repo:refs_changed",
+  "actor": {"name": "alice", "emailAddress": "alice@example.com", "displayName": "Alice"},
+  "repository": {"slug": "shop", "id": 1, "name": "Shop", "project": {"key": "ACME"}, "links": {"self": [{"href": "https://bitbucket.example.com/projects/ACME/repos/shop/browse"}]}},
+  "changes": [
+    {"ref": {"id": "refs/heads/main", "displayId": "main", "type": "BRANCH"}, "refId": "refs/heads/main", "fromHash": "c0", "toHash": "c2", "type": "UPDATE"},
+    {"ref": {"id": "refs/heads/old", "displayId": "old", "type": "BRANCH"}, "refId": "refs/heads/old", "fromHash": "c0", "toHash": "0000000000000000000000000000000000000000", "type": "DELETE"}
+  ]
+}`
+		got, err := ParseWebhookPushEvent(WebhookRepoRefsChanged, []byte(body))
+		require.NoError(t, err)
+
+		want := &WebhookPushEvent{
+			Repository: WebhookRepository{
+				FullName: "ACME/shop",
+				HTMLURL:  "https://bitbucket.example.com/projects/ACME/repos/shop",
+			},
+			ActorName: "Alice",
+			Changes: []WebhookPushChange{
+				{
+					Ref:      "refs/heads/main",
+					Branch:   "main",
+					FromHash: "c0",
+					ToHash:   "c2",
+				},
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestProvider_FetchPushEventCommitList(t *testing.T) {
+	t.Run("cloud", func(t *testing.T) {
+		p := newProvider(
+			vcs.ProviderConfig{
+				Client: &http.Client{
+					Transport: &common.MockRoundTripper{
+						MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+							switch r.URL.Path {
+							case "/2.0/repositories/acme/shop/diffstat/c1":
+								return newTestResponse(http.StatusOK, `
+{
+  "values": [
+    {"status": "added", "new": {"path": "bytebase/db__202207270900__migrate__v1.sql"}},
+    {"status": "modified", "new": {"path": "README.md"}},
+    {"status": "removed", "new": null}
+  ]
+}`), nil
+							}
+							return nil, errors.Errorf("unexpected request path %s", r.URL.Path)
+						},
+					},
+				},
+			},
+		).(*Provider)
+
+		ctx := context.Background()
+		got, err := p.FetchPushEventCommitList(ctx, common.OauthContext{}, CloudInstanceURL, "acme/shop",
+			WebhookPushChange{
+				Ref:      "refs/heads/main",
+				Branch:   "main",
+				FromHash: "c0",
+				ToHash:   "c2",
+				Commits: []WebhookCommit{
+					{ID: "c1", ParentCount: 1},
+					// The merge commit is skipped.
+					{ID: "c2", ParentCount: 2},
+				},
+			},
+		)
+		require.NoError(t, err)
+
+		want := []WebhookCommit{
+			{
+				ID:          "c1",
+				ParentCount: 1,
+				Added:       []string{"bytebase/db__202207270900__migrate__v1.sql"},
+				Modified:    []string{"README.md"},
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("data center", func(t *testing.T) {
+		p := newProvider(
+			vcs.ProviderConfig{
+				Client: &http.Client{
+					Transport: &common.MockRoundTripper{
+						MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+							switch r.URL.Path {
+							case "/rest/api/1.0/projects/ACME/repos/shop/commits":
+								assert.Equal(t, "c0", r.URL.Query().Get("since"))
+								assert.Equal(t, "c2", r.URL.Query().Get("until"))
+								return newTestResponse(http.StatusOK, `
+{
+  "values": [
+    {"id": "c2", "message": "Add v2", "author": {"name": "alice", "emailAddress": "alice@example.com"}, "authorTimestamp": 1658916000000, "parents": [{"id": "c1"}]},
+    {"id": "c1", "message": "Add v1", "author": {"name": "alice", "emailAddress": "alice@example.com"}, "authorTimestamp": 1658912400000, "parents": [{"id": "c0"}]}
+  ],
+  "isLastPage": true
+}`), nil
+							case "/rest/api/1.0/projects/ACME/repos/shop/commits/c1/changes":
+								return newTestResponse(http.StatusOK, `{"values": [{"type": "ADD", "path": {"toString": "bytebase/v1.sql"}}], "isLastPage": true}`), nil
+							case "/rest/api/1.0/projects/ACME/repos/shop/commits/c2/changes":
+								return newTestResponse(http.StatusOK, `{"values": [{"type": "MODIFY", "path": {"toString": "bytebase/v1.sql"}}, {"type": "ADD", "path": {"toString": "bytebase/v2.sql"}}], "isLastPage": true}`), nil
+							}
+							return nil, errors.Errorf("unexpected request path %s", r.URL.Path)
+						},
+					},
+				},
+			},
+		).(*Provider)
+
+		ctx := context.Background()
+		got, err := p.FetchPushEventCommitList(ctx, common.OauthContext{}, testDataCenterInstanceURL, "ACME/shop",
+			WebhookPushChange{
+				Ref:      "refs/heads/main",
+				Branch:   "main",
+				FromHash: "c0",
+				ToHash:   "c2",
+			},
+		)
+		require.NoError(t, err)
+
+		want := []WebhookCommit{
+			{
+				ID:          "c1",
+				Message:     "Add v1",
+				Timestamp:   time.Unix(1658912400, 0),
+				URL:         "https://bitbucket.example.com/projects/ACME/repos/shop/commits/c1",
+				AuthorName:  "alice",
+				AuthorEmail: "alice@example.com",
+				ParentCount: 1,
+				Added:       []string{"bytebase/v1.sql"},
+			},
+			{
+				ID:          "c2",
+				Message:     "Add v2",
+				Timestamp:   time.Unix(1658916000, 0),
+				URL:         "https://bitbucket.example.com/projects/ACME/repos/shop/commits/c2",
+				AuthorName:  "alice",
+				AuthorEmail: "alice@example.com",
+				ParentCount: 1,
+				Added:       []string{"bytebase/v2.sql"},
+				Modified:    []string{"bytebase/v1.sql"},
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestProvider_FetchAllRepositoryList(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/rest/api/1.0/repos", r.URL.Path)
+						assert.Equal(t, "REPO_ADMIN", r.URL.Query().Get("permission"))
+						if r.URL.Query().Get("start") == "0" {
+							return newTestResponse(http.StatusOK, `
+{
+  "values": [{"id": 1, "slug": "shop", "name": "Shop", "project": {"key": "ACME"}, "links": {"self": [{"href": "https://bitbucket.example.com/projects/ACME/repos/shop/browse"}]}}],
+  "isLastPage": false,
+  "nextPageStart": 1
+}`), nil
+						}
+						return newTestResponse(http.StatusOK, `
+{
+  "values": [{"id": 2, "slug": "blog", "name": "Blog", "project": {"key": "~ALICE"}, "links": {"self": [{"href": "https://bitbucket.example.com/users/alice/repos/blog/browse"}]}}],
+  "isLastPage": true
+}`), nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchAllRepositoryList(ctx, common.OauthContext{}, testDataCenterInstanceURL)
+	require.NoError(t, err)
+
+	want := []*vcs.Repository{
+		{
+			ID:       1,
+			Name:     "Shop",
+			FullPath: "ACME/shop",
+			WebURL:   "https://bitbucket.example.com/projects/ACME/repos/shop",
+		},
+		{
+			ID:       2,
+			Name:     "Blog",
+			FullPath: "~ALICE/blog",
+			WebURL:   "https://bitbucket.example.com/users/alice/repos/blog",
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_ReadFileContent(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/rest/api/1.0/projects/ACME/repos/shop/raw/bytebase/v1__init.sql", r.URL.Path)
+						assert.Equal(t, "main", r.URL.Query().Get("at"))
+						return newTestResponse(http.StatusOK, "CREATE TABLE t (id INT);"), nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.ReadFileContent(ctx, common.OauthContext{}, testDataCenterInstanceURL, "ACME/shop", "bytebase/v1__init.sql", "main")
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE t (id INT);", got)
+}
+
+func TestProvider_CreateWebhook(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/2.0/repositories/acme/shop/hooks", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						var webhook cloudWebhook
+						require.NoError(t, json.Unmarshal(body, &webhook))
+						assert.Equal(t,
+							cloudWebhook{
+								Description: webhookName,
+								URL:         "https://example.com/hook/bitbucket/1",
+								Active:      true,
+								Events:      []string{"repo:push"},
+								Secret:      "s3cr3t",
+							},
+							webhook,
+						)
+						return newTestResponse(http.StatusCreated, `{"uuid": "{7e4b4a5c-1ff0-4a2c-9b3b-6bbf0c1b2d52}", "events": ["repo:push"]}`), nil
+					},
+				},
+			},
+		},
+	)
+
+	payload, err := json.Marshal(
+		WebhookCreateOrUpdate{
+			URL:    "https://example.com/hook/bitbucket/1",
+			Secret: "s3cr3t",
+			Active: true,
+		},
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	got, err := p.CreateWebhook(ctx, common.OauthContext{}, CloudInstanceURL, "acme/shop", payload)
+	require.NoError(t, err)
+	assert.Equal(t, "{7e4b4a5c-1ff0-4a2c-9b3b-6bbf0c1b2d52}", got)
+}
+
+func TestProvider_RefreshTokenOnUnauthorized(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						if r.URL.Path == "/site/oauth2/access_token" {
+							clientID, clientSecret, ok := r.BasicAuth()
+							require.True(t, ok)
+							assert.Equal(t, "id", clientID)
+							assert.Equal(t, "secret", clientSecret)
+							require.NoError(t, r.ParseForm())
+							assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+							assert.Equal(t, "old-refresh", r.PostForm.Get("refresh_token"))
+							return newTestResponse(http.StatusOK, `{"access_token": "new", "token_type": "bearer", "expires_in": 7200, "refresh_token": "new-refresh"}`), nil
+						}
+
+						// Bitbucket responds the expired token with 401 instead of an OAuth error.
+						if r.Header.Get("Authorization") != "Bearer new" {
+							return newTestResponse(http.StatusUnauthorized, `{"type": "error", "error": {"message": "Access token expired."}}`), nil
+						}
+						switch r.URL.Path {
+						case "/2.0/user":
+							return newTestResponse(http.StatusOK, `{"uuid": "{1}", "nickname": "alice", "display_name": "Alice"}`), nil
+						case "/2.0/user/emails":
+							return newTestResponse(http.StatusOK, `{"values": [{"email": "old@example.com", "is_primary": false, "is_confirmed": true}, {"email": "alice@example.com", "is_primary": true, "is_confirmed": true}]}`), nil
+						}
+						return nil, errors.Errorf("unexpected request path %s", r.URL.Path)
+					},
+				},
+			},
+		},
+	)
+
+	var refreshedToken, refreshedRefreshToken string
+	oauthCtx := common.OauthContext{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		AccessToken:  "expired",
+		RefreshToken: "old-refresh",
+		Refresher: func(token, refreshToken string, _ int64) error {
+			refreshedToken, refreshedRefreshToken = token, refreshToken
+			return nil
+		},
+	}
+
+	ctx := context.Background()
+	got, err := p.TryLogin(ctx, oauthCtx, CloudInstanceURL)
+	require.NoError(t, err)
+
+	want := &vcs.UserInfo{
+		PublicEmail: "alice@example.com",
+		Name:        "Alice",
+		State:       vcs.StateActive,
+	}
+	assert.Equal(t, want, got)
+	assert.Equal(t, "new", refreshedToken)
+	assert.Equal(t, "new-refresh", refreshedRefreshToken)
+}
@@ -0,0 +1,610 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+var _ edition = (*cloud)(nil)
+
+// cloud is the API client of Bitbucket Cloud.
+//
+// Docs: https://developer.atlassian.com/cloud/bitbucket/rest/intro/
+type cloud struct {
+	client *http.Client
+}
+
+// cloudPage represents a Bitbucket Cloud API response for a page of a
+// paginated list.
+type cloudPage struct {
+	Values json.RawMessage `json:"values"`
+	// Next is the URL of the next page, which is empty on the last page.
+	Next string `json:"next"`
+}
+
+// cloudLink represents a Bitbucket Cloud API response for a link.
+type cloudLink struct {
+	Href string `json:"href"`
+}
+
+// cloudUser represents a Bitbucket Cloud API response for a user.
+type cloudUser struct {
+	UUID        string `json:"uuid"`
+	Nickname    string `json:"nickname"`
+	DisplayName string `json:"display_name"`
+}
+
+// cloudEmail represents a Bitbucket Cloud API response for an email address of
+// the authenticated user.
+type cloudEmail struct {
+	Email       string `json:"email"`
+	IsPrimary   bool   `json:"is_primary"`
+	IsConfirmed bool   `json:"is_confirmed"`
+}
+
+// cloudRepository represents a Bitbucket Cloud API response for a repository.
+type cloudRepository struct {
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	Links    struct {
+		HTML cloudLink `json:"html"`
+	} `json:"links"`
+}
+
+// cloudCommit represents a Bitbucket Cloud API response for a commit.
+type cloudCommit struct {
+	Hash    string `json:"hash"`
+	Message string `json:"message"`
+	// Date expects corresponding JSON value is a string in RFC 3339 format,
+	// see https://pkg.go.dev/time#Time.MarshalJSON.
+	Date   time.Time `json:"date"`
+	Author struct {
+		// Raw is the author in the format of "Name <email>".
+		Raw  string    `json:"raw"`
+		User cloudUser `json:"user"`
+	} `json:"author"`
+	Links struct {
+		HTML cloudLink `json:"html"`
+	} `json:"links"`
+	Parents []struct {
+		Hash string `json:"hash"`
+	} `json:"parents"`
+}
+
+// toWebhookCommit converts the commit to WebhookCommit.
+func (c cloudCommit) toWebhookCommit() WebhookCommit {
+	commit := WebhookCommit{
+		ID:          c.Hash,
+		Message:     c.Message,
+		Timestamp:   c.Date,
+		URL:         c.Links.HTML.Href,
+		AuthorName:  c.Author.User.DisplayName,
+		ParentCount: len(c.Parents),
+	}
+	if address, err := mail.ParseAddress(c.Author.Raw); err == nil {
+		if commit.AuthorName == "" {
+			commit.AuthorName = address.Name
+		}
+		commit.AuthorEmail = address.Address
+	} else if commit.AuthorName == "" {
+		commit.AuthorName = c.Author.Raw
+	}
+	return commit
+}
+
+// cloudTreeEntry represents a Bitbucket Cloud API response for an entry of a
+// directory listing.
+type cloudTreeEntry struct {
+	// Type is either "commit_file" or "commit_directory".
+	Type string `json:"type"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// Commit is the commit that the entry is read at.
+	Commit struct {
+		Hash string `json:"hash"`
+	} `json:"commit"`
+}
+
+// cloudDiffStat represents a Bitbucket Cloud API response for the diff stat of
+// a file.
+type cloudDiffStat struct {
+	// Status is one of "added", "removed", "modified" and "renamed".
+	Status string `json:"status"`
+	New    *struct {
+		Path string `json:"path"`
+	} `json:"new"`
+}
+
+// cloudWebhook represents a Bitbucket Cloud API message for a webhook.
+type cloudWebhook struct {
+	UUID        string   `json:"uuid,omitempty"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Active      bool     `json:"active"`
+	Events      []string `json:"events"`
+	Secret      string   `json:"secret,omitempty"`
+}
+
+// cloudWebhookPushEvent represents a Bitbucket Cloud webhook payload of the
+// push event.
+type cloudWebhookPushEvent struct {
+	Actor      cloudUser       `json:"actor"`
+	Repository cloudRepository `json:"repository"`
+	Push       struct {
+		Changes []struct {
+			// New and Old are nil if the ref is created and deleted respectively.
+			New *cloudWebhookRef `json:"new"`
+			Old *cloudWebhookRef `json:"old"`
+			// Commits are in reverse chronological order.
+			Commits   []cloudCommit `json:"commits"`
+			Truncated bool          `json:"truncated"`
+		} `json:"changes"`
+	} `json:"push"`
+}
+
+// cloudWebhookRef represents a ref in the Bitbucket Cloud webhook payload.
+type cloudWebhookRef struct {
+	// Type is one of "branch", "tag", "named_branch" and "bookmark".
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Target struct {
+		Hash string `json:"hash"`
+	} `json:"target"`
+}
+
+func parseCloudWebhookPushEvent(body []byte) (*WebhookPushEvent, error) {
+	var payload cloudWebhookPushEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.Wrap(err, "unmarshal push event")
+	}
+
+	event := &WebhookPushEvent{
+		Repository: WebhookRepository{
+			FullName: payload.Repository.FullName,
+			HTMLURL:  payload.Repository.Links.HTML.Href,
+		},
+		ActorName: payload.Actor.DisplayName,
+	}
+	for _, c := range payload.Push.Changes {
+		// Skip the deleted branches and the tags.
+		if c.New == nil || c.New.Type != "branch" {
+			continue
+		}
+		change := WebhookPushChange{
+			Ref:       "refs/heads/" + c.New.Name,
+			Branch:    c.New.Name,
+			ToHash:    c.New.Target.Hash,
+			Truncated: c.Truncated,
+		}
+		if c.Old != nil {
+			change.FromHash = c.Old.Target.Hash
+		}
+		// Reverse the commits to the chronological order.
+		for i := len(c.Commits) - 1; i >= 0; i-- {
+			change.Commits = append(change.Commits, c.Commits[i].toWebhookCommit())
+		}
+		event.Changes = append(event.Changes, change)
+	}
+	return event, nil
+}
+
+// repositoryURL returns the API URL of the repository, the repositoryID is the
+// full name of the repository.
+func (c *cloud) repositoryURL(repositoryID string) string {
+	return fmt.Sprintf("%s/repositories/%s", cloudAPIURL, repositoryID)
+}
+
+// get makes a GET request to the API URL and unmarshals the response body to
+// v. The action describes the request in the error message.
+func (c *cloud) get(ctx context.Context, oauthCtx common.OauthContext, instanceURL, url, action string, v interface{}) error {
+	code, body, err := request(ctx, c.client, oauthCtx, instanceURL, http.MethodGet, url, "", nil)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(action, url, code, body); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(body), v); err != nil {
+		return errors.Wrap(err, "unmarshal body")
+	}
+	return nil
+}
+
+// listAll fetches all pages of the paginated list starting from the API URL,
+// and calls the appendPage with the values of each page.
+func (c *cloud) listAll(ctx context.Context, oauthCtx common.OauthContext, instanceURL, url, action string, appendPage func(values json.RawMessage) error) error {
+	for url != "" {
+		var page cloudPage
+		if err := c.get(ctx, oauthCtx, instanceURL, url, action, &page); err != nil {
+			return err
+		}
+		if err := appendPage(page.Values); err != nil {
+			return errors.Wrap(err, "unmarshal values")
+		}
+		url = page.Next
+	}
+	return nil
+}
+
+// APIURL returns the API URL of Bitbucket Cloud.
+func (*cloud) APIURL(string) string {
+	return cloudAPIURL
+}
+
+// TryLogin tries to fetch the user info from the current OAuth context. The
+// email of the user is the primary email, which requires the "email" scope.
+//
+// Docs:
+//   - https://developer.atlassian.com/cloud/bitbucket/rest/api-group-users/#api-user-get
+//   - https://developer.atlassian.com/cloud/bitbucket/rest/api-group-users/#api-user-emails-get
+func (c *cloud) TryLogin(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) (*vcs.UserInfo, error) {
+	var user cloudUser
+	if err := c.get(ctx, oauthCtx, instanceURL, fmt.Sprintf("%s/user", cloudAPIURL), "read user info", &user); err != nil {
+		return nil, err
+	}
+
+	var emails []cloudEmail
+	err := c.listAll(ctx, oauthCtx, instanceURL, fmt.Sprintf("%s/user/emails", cloudAPIURL), "read user emails",
+		func(values json.RawMessage) error {
+			var page []cloudEmail
+			if err := json.Unmarshal(values, &page); err != nil {
+				return err
+			}
+			emails = append(emails, page...)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	userInfo := &vcs.UserInfo{
+		Name:  user.DisplayName,
+		State: vcs.StateActive,
+	}
+	for _, e := range emails {
+		if e.IsPrimary && e.IsConfirmed {
+			userInfo.PublicEmail = e.Email
+			break
+		}
+	}
+	return userInfo, nil
+}
+
+// FetchCommitByID fetches the commit data by its ID from the repository.
+//
+// Docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-commits/#api-repositories-workspace-repo-slug-commit-commit-get
+func (c *cloud) FetchCommitByID(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string) (*vcs.Commit, error) {
+	var commit cloudCommit
+	url := fmt.Sprintf("%s/commit/%s", c.repositoryURL(repositoryID), commitID)
+	if err := c.get(ctx, oauthCtx, instanceURL, url, "fetch commit data", &commit); err != nil {
+		return nil, err
+	}
+
+	webhookCommit := commit.toWebhookCommit()
+	return &vcs.Commit{
+		ID:         webhookCommit.ID,
+		AuthorName: webhookCommit.AuthorName,
+		CreatedTs:  webhookCommit.Timestamp.Unix(),
+	}, nil
+}
+
+// FetchUserInfo fetches user info of given user UUID or account ID. Bitbucket
+// Cloud never exposes the email of other users.
+//
+// Docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-users/#api-users-selected-user-get
+func (c *cloud) FetchUserInfo(ctx context.Context, oauthCtx common.OauthContext, instanceURL, user string) (*vcs.UserInfo, error) {
+	var u cloudUser
+	url := fmt.Sprintf("%s/users/%s", cloudAPIURL, url.PathEscape(user))
+	if err := c.get(ctx, oauthCtx, instanceURL, url, "read user info", &u); err != nil {
+		return nil, err
+	}
+	return &vcs.UserInfo{
+		Name:  u.DisplayName,
+		State: vcs.StateActive,
+	}, nil
+}
+
+// FetchRepositoryActiveMemberList is not supported by Bitbucket Cloud because
+// it never exposes the email of the repository members, which is required to
+// match the Bytebase users.
+func (*cloud) FetchRepositoryActiveMemberList(context.Context, common.OauthContext, string, string) ([]*vcs.RepositoryMember, error) {
+	return nil, errors.New("Bitbucket Cloud does not expose the email of the repository members, syncing members is not supported")
+}
+
+// FetchAllRepositoryList fetches all repositories where the authenticated user
+// has the admin permission.
+//
+// Docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-repositories/#api-repositories-get
+func (c *cloud) FetchAllRepositoryList(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) ([]*vcs.Repository, error) {
+	var allRepos []*vcs.Repository
+	url := fmt.Sprintf("%s/repositories?role=admin&pagelen=%d", cloudAPIURL, apiPageSize)
+	err := c.listAll(ctx, oauthCtx, instanceURL, url, "fetch repository list",
+		func(values json.RawMessage) error {
+			var repos []cloudRepository
+			if err := json.Unmarshal(values, &repos); err != nil {
+				return err
+			}
+			for _, r := range repos {
+				// Bitbucket Cloud identifies the repository by its full name
+				// instead of a numeric ID.
+				allRepos = append(allRepos,
+					&vcs.Repository{
+						Name:     r.Name,
+						FullPath: r.FullName,
+						WebURL:   r.Links.HTML.Href,
+					},
+				)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return allRepos, nil
+}
+
+// FetchRepositoryFileList fetches the all files from the given repository tree
+// recursively.
+//
+// Docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-source/#api-repositories-workspace-repo-slug-src-commit-path-get
+func (c *cloud) FetchRepositoryFileList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, ref, filePath string) ([]*vcs.RepositoryTreeNode, error) {
+	var allTreeNodes []*vcs.RepositoryTreeNode
+	dirs := []string{strings.Trim(filePath, "/")}
+	for len(dirs) > 0 {
+		dir := dirs[0]
+		dirs = dirs[1:]
+
+		url := fmt.Sprintf("%s/src/%s/%s/?pagelen=%d", c.repositoryURL(repositoryID), url.PathEscape(ref), escapeFilePath(dir), apiPageSize)
+		err := c.listAll(ctx, oauthCtx, instanceURL, url, "fetch repository file list",
+			func(values json.RawMessage) error {
+				var entries []cloudTreeEntry
+				if err := json.Unmarshal(values, &entries); err != nil {
+					return err
+				}
+				for _, e := range entries {
+					switch e.Type {
+					case "commit_directory":
+						dirs = append(dirs, e.Path)
+					case "commit_file":
+						allTreeNodes = append(allTreeNodes,
+							&vcs.RepositoryTreeNode{
+								Path: e.Path,
+								Type: "blob",
+							},
+						)
+					}
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return allTreeNodes, nil
+}
+
+// commitFile creates or updates a file at given path in the repository. Both
+// are the same in Bitbucket Cloud.
+//
+// Docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-source/#api-repositories-workspace-repo-slug-src-post
+func (c *cloud) commitFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	params := url.Values{}
+	params.Set(filePath, fileCommitCreate.Content)
+	params.Set("message", fileCommitCreate.CommitMessage)
+	params.Set("branch", fileCommitCreate.Branch)
+
+	url := fmt.Sprintf("%s/src", c.repositoryURL(repositoryID))
+	code, body, err := request(ctx, c.client, oauthCtx, instanceURL, http.MethodPost, url, "application/x-www-form-urlencoded", []byte(params.Encode()))
+	if err != nil {
+		return err
+	}
+	return checkResponse("create/update file", url, code, body)
+}
+
+// CreateFile creates a file at given path in the repository.
+func (c *cloud) CreateFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	return c.commitFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, fileCommitCreate)
+}
+
+// OverwriteFile overwrites an existing file at given path in the repository.
+// Bitbucket Cloud does not detect conflicting writes of a file, thus the
+// LastCommitID of the file commit is not used.
+func (c *cloud) OverwriteFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	return c.commitFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, fileCommitCreate)
+}
+
+// ReadFileMeta reads the metadata of the given file in the repository.
+//
+// Docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-source/#api-repositories-workspace-repo-slug-src-commit-path-get
+func (c *cloud) ReadFileMeta(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (*vcs.FileMeta, error) {
+	var entry cloudTreeEntry
+	url := fmt.Sprintf("%s/src/%s/%s?format=meta", c.repositoryURL(repositoryID), url.PathEscape(ref), escapeFilePath(filePath))
+	if err := c.get(ctx, oauthCtx, instanceURL, url, "read file meta", &entry); err != nil {
+		return nil, err
+	}
+	if entry.Type != "commit_file" {
+		return nil, errors.Errorf("%q is a directory not a file", filePath)
+	}
+
+	return &vcs.FileMeta{
+		Name:         path.Base(entry.Path),
+		Path:         entry.Path,
+		Size:         entry.Size,
+		LastCommitID: entry.Commit.Hash,
+	}, nil
+}
+
+// ReadFileContent reads the content of the given file in the repository.
+//
+// Docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-source/#api-repositories-workspace-repo-slug-src-commit-path-get
+func (c *cloud) ReadFileContent(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (string, error) {
+	url := fmt.Sprintf("%s/src/%s/%s", c.repositoryURL(repositoryID), url.PathEscape(ref), escapeFilePath(filePath))
+	code, body, err := request(ctx, c.client, oauthCtx, instanceURL, http.MethodGet, url, "", nil)
+	if err != nil {
+		return "", err
+	}
+	if err := checkResponse("read file", url, code, body); err != nil {
+		return "", err
+	}
+	return body, nil
+}
+
+// newCloudWebhook translates the webhook payload to the Bitbucket Cloud webhook.
+func newCloudWebhook(payload []byte) ([]byte, error) {
+	webhook, err := parseWebhookCreateOrUpdate(payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(
+		cloudWebhook{
+			Description: webhookName,
+			URL:         webhook.URL,
+			Active:      webhook.Active,
+			Events:      []string{string(WebhookRepoPush)},
+			Secret:      webhook.Secret,
+		},
+	)
+}
+
+// CreateWebhook creates a webhook in the repository, and returns the UUID of
+// the webhook.
+//
+// Docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-repositories/#api-repositories-workspace-repo-slug-hooks-post
+func (c *cloud) CreateWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, payload []byte) (string, error) {
+	body, err := newCloudWebhook(payload)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/hooks", c.repositoryURL(repositoryID))
+	code, respBody, err := request(ctx, c.client, oauthCtx, instanceURL, http.MethodPost, url, "application/json", body)
+	if err != nil {
+		return "", err
+	}
+	if err := checkResponse("create webhook", url, code, respBody); err != nil {
+		return "", err
+	}
+
+	var webhook cloudWebhook
+	if err := json.Unmarshal([]byte(respBody), &webhook); err != nil {
+		return "", errors.Wrap(err, "unmarshal body")
+	}
+	return webhook.UUID, nil
+}
+
+// PatchWebhook updates the webhook in the repository.
+//
+// Docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-repositories/#api-repositories-workspace-repo-slug-hooks-uid-put
+func (c *cloud) PatchWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string, payload []byte) error {
+	body, err := newCloudWebhook(payload)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/hooks/%s", c.repositoryURL(repositoryID), url.PathEscape(webhookID))
+	code, respBody, err := request(ctx, c.client, oauthCtx, instanceURL, http.MethodPut, url, "application/json", body)
+	if err != nil {
+		return err
+	}
+	return checkResponse("patch webhook", url, code, respBody)
+}
+
+// DeleteWebhook deletes the webhook from the repository.
+//
+// Docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-repositories/#api-repositories-workspace-repo-slug-hooks-uid-delete
+func (c *cloud) DeleteWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string) error {
+	url := fmt.Sprintf("%s/hooks/%s", c.repositoryURL(repositoryID), url.PathEscape(webhookID))
+	code, body, err := request(ctx, c.client, oauthCtx, instanceURL, http.MethodDelete, url, "", nil)
+	if err != nil {
+		return err
+	}
+	if code == http.StatusNotFound {
+		return nil // It is OK if the webhook has already gone
+	}
+	return checkResponse("delete webhook", url, code, body)
+}
+
+// fetchPushEventCommitList uses the commits in the payload unless they are
+// truncated, and fetches the files added by each commit.
+//
+// Docs:
+//   - https://developer.atlassian.com/cloud/bitbucket/rest/api-group-commits/#api-repositories-workspace-repo-slug-commits-revision-get
+//   - https://developer.atlassian.com/cloud/bitbucket/rest/api-group-commits/#api-repositories-workspace-repo-slug-diffstat-spec-get
+func (c *cloud) fetchPushEventCommitList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, change WebhookPushChange) ([]WebhookCommit, error) {
+	commits := change.Commits
+	// The commits of a newly created branch cannot be listed without the whole
+	// history, the commits in the payload are used even if they are truncated.
+	if change.Truncated && change.FromHash != "" && change.FromHash != emptyCommitID {
+		commits = nil
+		url := fmt.Sprintf("%s/commits/%s?exclude=%s&pagelen=%d", c.repositoryURL(repositoryID), change.ToHash, change.FromHash, apiPageSize)
+		err := c.listAll(ctx, oauthCtx, instanceURL, url, "fetch commit list",
+			func(values json.RawMessage) error {
+				var page []cloudCommit
+				if err := json.Unmarshal(values, &page); err != nil {
+					return err
+				}
+				for _, commit := range page {
+					commits = append(commits, commit.toWebhookCommit())
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		// The commits are listed in reverse chronological order.
+		for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+			commits[i], commits[j] = commits[j], commits[i]
+		}
+	}
+
+	var commitList []WebhookCommit
+	for _, commit := range commits {
+		if commit.ParentCount > 1 {
+			continue
+		}
+
+		commit.Added, commit.Modified = nil, nil
+		url := fmt.Sprintf("%s/diffstat/%s?pagelen=%d", c.repositoryURL(repositoryID), commit.ID, apiPageSize)
+		err := c.listAll(ctx, oauthCtx, instanceURL, url, "fetch commit diff stat",
+			func(values json.RawMessage) error {
+				var diffStats []cloudDiffStat
+				if err := json.Unmarshal(values, &diffStats); err != nil {
+					return err
+				}
+				for _, d := range diffStats {
+					if d.New == nil {
+						continue
+					}
+					switch d.Status {
+					case "added":
+						commit.Added = append(commit.Added, d.New.Path)
+					case "modified":
+						commit.Modified = append(commit.Modified, d.New.Path)
+					}
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		commitList = append(commitList, commit)
+	}
+	return commitList, nil
+}
@@ -0,0 +1,690 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+// RepositoryPermission is the permission of a user to the repository in
+// Bitbucket Data Center.
+type RepositoryPermission string
+
+// The list of Bitbucket Data Center repository permissions.
+const (
+	RepositoryPermissionAdmin RepositoryPermission = "REPO_ADMIN"
+	RepositoryPermissionWrite RepositoryPermission = "REPO_WRITE"
+	RepositoryPermissionRead  RepositoryPermission = "REPO_READ"
+)
+
+var _ edition = (*dataCenter)(nil)
+
+// dataCenter is the API client of Bitbucket Data Center.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/rest/
+type dataCenter struct {
+	client *http.Client
+}
+
+// dataCenterPage represents a Bitbucket Data Center API response for a page of
+// a paginated list.
+type dataCenterPage struct {
+	Values        json.RawMessage `json:"values"`
+	IsLastPage    bool            `json:"isLastPage"`
+	NextPageStart int             `json:"nextPageStart"`
+}
+
+// dataCenterUser represents a Bitbucket Data Center API response for a user.
+type dataCenterUser struct {
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	EmailAddress string `json:"emailAddress"`
+	DisplayName  string `json:"displayName"`
+	Active       bool   `json:"active"`
+}
+
+// dataCenterRepository represents a Bitbucket Data Center API response for a
+// repository.
+type dataCenterRepository struct {
+	ID      int64  `json:"id"`
+	Slug    string `json:"slug"`
+	Name    string `json:"name"`
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Links struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+// fullName returns the full path of the repository, which is the
+// "{project key}/{repository slug}".
+func (r dataCenterRepository) fullName() string {
+	return fmt.Sprintf("%s/%s", r.Project.Key, r.Slug)
+}
+
+// webURL returns the web URL of the repository, the self link points to the
+// "browse" page of the repository.
+func (r dataCenterRepository) webURL() string {
+	if len(r.Links.Self) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(r.Links.Self[0].Href, "/browse")
+}
+
+// dataCenterRepositoryPermission represents a Bitbucket Data Center API
+// response for the permission of a user to the repository.
+type dataCenterRepositoryPermission struct {
+	User       dataCenterUser `json:"user"`
+	Permission string         `json:"permission"`
+}
+
+// dataCenterCommit represents a Bitbucket Data Center API response for a
+// commit.
+type dataCenterCommit struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+	Author  struct {
+		Name         string `json:"name"`
+		EmailAddress string `json:"emailAddress"`
+	} `json:"author"`
+	// AuthorTimestamp is the Unix timestamp in milliseconds.
+	AuthorTimestamp int64 `json:"authorTimestamp"`
+	Parents         []struct {
+		ID string `json:"id"`
+	} `json:"parents"`
+}
+
+// dataCenterChange represents a Bitbucket Data Center API response for a file
+// change of a commit.
+type dataCenterChange struct {
+	// Type is one of "ADD", "MODIFY", "DELETE", "MOVE" and "COPY".
+	Type string `json:"type"`
+	Path struct {
+		ToString string `json:"toString"`
+	} `json:"path"`
+}
+
+// dataCenterWebhook represents a Bitbucket Data Center API message for a
+// webhook.
+type dataCenterWebhook struct {
+	ID            int      `json:"id,omitempty"`
+	Name          string   `json:"name"`
+	URL           string   `json:"url"`
+	Active        bool     `json:"active"`
+	Events        []string `json:"events"`
+	Configuration struct {
+		Secret string `json:"secret,omitempty"`
+	} `json:"configuration"`
+}
+
+// dataCenterWebhookPushEvent represents a Bitbucket Data Center webhook payload
+// of the push event.
+type dataCenterWebhookPushEvent struct {
+	Actor      dataCenterUser       `json:"actor"`
+	Repository dataCenterRepository `json:"repository"`
+	Changes    []struct {
+		Ref struct {
+			ID        string `json:"id"`
+			DisplayID string `json:"displayId"`
+			// Type is either "BRANCH" or "TAG".
+			Type string `json:"type"`
+		} `json:"ref"`
+		FromHash string `json:"fromHash"`
+		ToHash   string `json:"toHash"`
+		// Type is one of "ADD", "UPDATE" and "DELETE".
+		Type string `json:"type"`
+	} `json:"changes"`
+}
+
+func parseDataCenterWebhookPushEvent(body []byte) (*WebhookPushEvent, error) {
+	var payload dataCenterWebhookPushEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.Wrap(err, "unmarshal push event")
+	}
+
+	event := &WebhookPushEvent{
+		Repository: WebhookRepository{
+			FullName: payload.Repository.fullName(),
+			HTMLURL:  payload.Repository.webURL(),
+		},
+		ActorName: payload.Actor.DisplayName,
+	}
+	for _, c := range payload.Changes {
+		// Skip the deleted branches and the tags.
+		if c.Type == "DELETE" || c.Ref.Type != "BRANCH" {
+			continue
+		}
+		event.Changes = append(event.Changes,
+			WebhookPushChange{
+				Ref:      c.Ref.ID,
+				Branch:   c.Ref.DisplayID,
+				FromHash: c.FromHash,
+				ToHash:   c.ToHash,
+			},
+		)
+	}
+	return event, nil
+}
+
+// splitRepositoryID splits the repositoryID, which is the
+// "{project key}/{repository slug}", into the project key and the repository
+// slug.
+func splitRepositoryID(repositoryID string) (projectKey, slug string) {
+	if i := strings.Index(repositoryID, "/"); i >= 0 {
+		return repositoryID[:i], repositoryID[i+1:]
+	}
+	return repositoryID, ""
+}
+
+// repositoryURL returns the API URL of the repository.
+func (d *dataCenter) repositoryURL(instanceURL, repositoryID string) string {
+	projectKey, slug := splitRepositoryID(repositoryID)
+	return fmt.Sprintf("%s/projects/%s/repos/%s", d.APIURL(instanceURL), url.PathEscape(projectKey), url.PathEscape(slug))
+}
+
+// get makes a GET request to the API URL and unmarshals the response body to
+// v. The action describes the request in the error message.
+func (d *dataCenter) get(ctx context.Context, oauthCtx common.OauthContext, instanceURL, url, action string, v interface{}) error {
+	code, body, err := request(ctx, d.client, oauthCtx, instanceURL, http.MethodGet, url, "", nil)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(action, url, code, body); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(body), v); err != nil {
+		return errors.Wrap(err, "unmarshal body")
+	}
+	return nil
+}
+
+// listAll fetches all pages of the paginated list of the API URL, and calls
+// the appendPage with the values of each page.
+func (d *dataCenter) listAll(ctx context.Context, oauthCtx common.OauthContext, instanceURL, url, action string, appendPage func(values json.RawMessage) error) error {
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+	start := 0
+	for {
+		var page dataCenterPage
+		pageURL := fmt.Sprintf("%s%sstart=%d&limit=%d", url, separator, start, apiPageSize)
+		if err := d.get(ctx, oauthCtx, instanceURL, pageURL, action, &page); err != nil {
+			return err
+		}
+		if err := appendPage(page.Values); err != nil {
+			return errors.Wrap(err, "unmarshal values")
+		}
+		if page.IsLastPage {
+			return nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+// APIURL returns the API URL of the Bitbucket Data Center instance.
+func (*dataCenter) APIURL(instanceURL string) string {
+	return fmt.Sprintf("%s/rest/api/1.0", instanceURL)
+}
+
+// TryLogin tries to fetch the user info from the current OAuth context.
+// Bitbucket Data Center does not have an API for the authenticated user, thus
+// we get the username from the "whoami" endpoint of the application links.
+func (d *dataCenter) TryLogin(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) (*vcs.UserInfo, error) {
+	url := fmt.Sprintf("%s/plugins/servlet/applinks/whoami", instanceURL)
+	code, body, err := request(ctx, d.client, oauthCtx, instanceURL, http.MethodGet, url, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse("read authenticated user", url, code, body); err != nil {
+		return nil, err
+	}
+
+	username := strings.TrimSpace(body)
+	if username == "" {
+		return nil, errors.Errorf("failed to read authenticated user from URL %s, the user is anonymous", url)
+	}
+	return d.FetchUserInfo(ctx, oauthCtx, instanceURL, username)
+}
+
+// FetchCommitByID fetches the commit data by its ID from the repository.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-commits-commitid-get
+func (d *dataCenter) FetchCommitByID(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string) (*vcs.Commit, error) {
+	var commit dataCenterCommit
+	url := fmt.Sprintf("%s/commits/%s", d.repositoryURL(instanceURL, repositoryID), commitID)
+	if err := d.get(ctx, oauthCtx, instanceURL, url, "fetch commit data", &commit); err != nil {
+		return nil, err
+	}
+	return &vcs.Commit{
+		ID:         commit.ID,
+		AuthorName: commit.Author.Name,
+		CreatedTs:  commit.AuthorTimestamp / 1000,
+	}, nil
+}
+
+// FetchUserInfo fetches user info of given user slug.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-system-maintenance/#api-api-latest-users-userslug-get
+func (d *dataCenter) FetchUserInfo(ctx context.Context, oauthCtx common.OauthContext, instanceURL, user string) (*vcs.UserInfo, error) {
+	var u dataCenterUser
+	url := fmt.Sprintf("%s/users/%s", d.APIURL(instanceURL), url.PathEscape(user))
+	if err := d.get(ctx, oauthCtx, instanceURL, url, "read user info", &u); err != nil {
+		return nil, err
+	}
+
+	state := vcs.StateActive
+	if !u.Active {
+		state = vcs.StateArchived
+	}
+	return &vcs.UserInfo{
+		PublicEmail: u.EmailAddress,
+		Name:        u.DisplayName,
+		State:       state,
+	}, nil
+}
+
+func getRoleAndMappedRole(permission string) (bitbucketPermission RepositoryPermission, bytebaseRole common.ProjectRole) {
+	switch RepositoryPermission(permission) {
+	case RepositoryPermissionAdmin:
+		return RepositoryPermissionAdmin, common.ProjectOwner
+	case RepositoryPermissionWrite:
+		return RepositoryPermissionWrite, common.ProjectOwner
+	case RepositoryPermissionRead:
+		return RepositoryPermissionRead, common.ProjectDeveloper
+	}
+	return "", ""
+}
+
+// FetchRepositoryActiveMemberList fetches all active members of a repository.
+// Only the users granted the permission to the repository directly are
+// returned, the permissions inherited from the project and the groups are not
+// included.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-permission-management/#api-api-latest-projects-projectkey-repos-repositoryslug-permissions-users-get
+func (d *dataCenter) FetchRepositoryActiveMemberList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string) ([]*vcs.RepositoryMember, error) {
+	var allPermissions []dataCenterRepositoryPermission
+	url := fmt.Sprintf("%s/permissions/users", d.repositoryURL(instanceURL, repositoryID))
+	err := d.listAll(ctx, oauthCtx, instanceURL, url, "fetch repository members",
+		func(values json.RawMessage) error {
+			var page []dataCenterRepositoryPermission
+			if err := json.Unmarshal(values, &page); err != nil {
+				return err
+			}
+			allPermissions = append(allPermissions, page...)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var emptyEmailUserList []string
+	var allMembers []*vcs.RepositoryMember
+	for _, p := range allPermissions {
+		if !p.User.Active {
+			continue
+		}
+		if p.User.EmailAddress == "" {
+			emptyEmailUserList = append(emptyEmailUserList, p.User.DisplayName)
+			continue
+		}
+
+		bitbucketPermission, bytebaseRole := getRoleAndMappedRole(p.Permission)
+		if bytebaseRole == "" {
+			continue
+		}
+		allMembers = append(allMembers,
+			&vcs.RepositoryMember{
+				Name:         p.User.DisplayName,
+				Email:        p.User.EmailAddress,
+				Role:         bytebaseRole,
+				VCSRole:      string(bitbucketPermission),
+				State:        vcs.StateActive,
+				RoleProvider: vcs.Bitbucket,
+			},
+		)
+	}
+
+	if len(emptyEmailUserList) != 0 {
+		return nil, fmt.Errorf("[ %v ] did not have their email set in Bitbucket, please make sure every members' email is set before syncing", strings.Join(emptyEmailUserList, ", "))
+	}
+	return allMembers, nil
+}
+
+// FetchAllRepositoryList fetches all repositories where the authenticated user
+// has the admin permission.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-repos-get
+func (d *dataCenter) FetchAllRepositoryList(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) ([]*vcs.Repository, error) {
+	var allRepos []*vcs.Repository
+	url := fmt.Sprintf("%s/repos?permission=%s", d.APIURL(instanceURL), RepositoryPermissionAdmin)
+	err := d.listAll(ctx, oauthCtx, instanceURL, url, "fetch repository list",
+		func(values json.RawMessage) error {
+			var repos []dataCenterRepository
+			if err := json.Unmarshal(values, &repos); err != nil {
+				return err
+			}
+			for _, r := range repos {
+				allRepos = append(allRepos,
+					&vcs.Repository{
+						ID:       r.ID,
+						Name:     r.Name,
+						FullPath: r.fullName(),
+						WebURL:   r.webURL(),
+					},
+				)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return allRepos, nil
+}
+
+// FetchRepositoryFileList fetches the all files from the given repository tree
+// recursively.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-files-path-get
+func (d *dataCenter) FetchRepositoryFileList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, ref, filePath string) ([]*vcs.RepositoryTreeNode, error) {
+	filePath = strings.Trim(filePath, "/")
+
+	var allTreeNodes []*vcs.RepositoryTreeNode
+	url := fmt.Sprintf("%s/files/%s?at=%s", d.repositoryURL(instanceURL, repositoryID), escapeFilePath(filePath), url.QueryEscape(ref))
+	err := d.listAll(ctx, oauthCtx, instanceURL, url, "fetch repository file list",
+		func(values json.RawMessage) error {
+			// The values are the paths relative to the given path.
+			var paths []string
+			if err := json.Unmarshal(values, &paths); err != nil {
+				return err
+			}
+			for _, p := range paths {
+				allTreeNodes = append(allTreeNodes,
+					&vcs.RepositoryTreeNode{
+						Path: path.Join(filePath, p),
+						Type: "blob",
+					},
+				)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return allTreeNodes, nil
+}
+
+// commitFile creates or updates a file at given path in the repository. The
+// sourceCommitID is the commit that last modified the file, which is used to
+// detect conflicting writes, and must be empty for creating a file.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-browse-path-put
+func (d *dataCenter) commitFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, sourceCommitID string, fileCommitCreate vcs.FileCommitCreate) error {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"branch", fileCommitCreate.Branch},
+		{"content", fileCommitCreate.Content},
+		{"message", fileCommitCreate.CommitMessage},
+		{"sourceCommitId", sourceCommitID},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if err := w.WriteField(f.name, f.value); err != nil {
+			return errors.Wrapf(err, "write field %q", f.name)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "close multipart writer")
+	}
+
+	url := fmt.Sprintf("%s/browse/%s", d.repositoryURL(instanceURL, repositoryID), escapeFilePath(filePath))
+	code, respBody, err := request(ctx, d.client, oauthCtx, instanceURL, http.MethodPut, url, w.FormDataContentType(), body.Bytes())
+	if err != nil {
+		return err
+	}
+	return checkResponse("create/update file", url, code, respBody)
+}
+
+// CreateFile creates a file at given path in the repository.
+func (d *dataCenter) CreateFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	return d.commitFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, "", fileCommitCreate)
+}
+
+// OverwriteFile overwrites an existing file at given path in the repository.
+// The LastCommitID of the file commit is the commit that last modified the
+// file.
+func (d *dataCenter) OverwriteFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	return d.commitFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, fileCommitCreate.LastCommitID, fileCommitCreate)
+}
+
+// ReadFileMeta reads the metadata of the given file in the repository. The
+// LastCommitID is the commit that last modified the file at the ref.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-commits-get
+func (d *dataCenter) ReadFileMeta(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (*vcs.FileMeta, error) {
+	content, err := d.ReadFileContent(ctx, oauthCtx, instanceURL, repositoryID, filePath, ref)
+	if err != nil {
+		return nil, errors.Wrap(err, "read file")
+	}
+
+	var page dataCenterPage
+	url := fmt.Sprintf("%s/commits?path=%s&until=%s&limit=1", d.repositoryURL(instanceURL, repositoryID), url.QueryEscape(filePath), url.QueryEscape(ref))
+	if err := d.get(ctx, oauthCtx, instanceURL, url, "fetch file commit", &page); err != nil {
+		return nil, err
+	}
+	var commits []dataCenterCommit
+	if err := json.Unmarshal(page.Values, &commits); err != nil {
+		return nil, errors.Wrap(err, "unmarshal values")
+	}
+	if len(commits) == 0 {
+		return nil, common.Errorf(common.NotFound, "failed to fetch file commit from URL %s", url)
+	}
+
+	return &vcs.FileMeta{
+		Name:         path.Base(filePath),
+		Path:         filePath,
+		Size:         int64(len(content)),
+		LastCommitID: commits[0].ID,
+	}, nil
+}
+
+// ReadFileContent reads the content of the given file in the repository.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-raw-path-get
+func (d *dataCenter) ReadFileContent(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (string, error) {
+	url := fmt.Sprintf("%s/raw/%s?at=%s", d.repositoryURL(instanceURL, repositoryID), escapeFilePath(filePath), url.QueryEscape(ref))
+	code, body, err := request(ctx, d.client, oauthCtx, instanceURL, http.MethodGet, url, "", nil)
+	if err != nil {
+		return "", err
+	}
+	if err := checkResponse("read file", url, code, body); err != nil {
+		return "", err
+	}
+	return body, nil
+}
+
+// newDataCenterWebhook translates the webhook payload to the Bitbucket Data
+// Center webhook.
+func newDataCenterWebhook(payload []byte) ([]byte, error) {
+	webhook, err := parseWebhookCreateOrUpdate(payload)
+	if err != nil {
+		return nil, err
+	}
+	dataCenterWebhook := dataCenterWebhook{
+		Name:   webhookName,
+		URL:    webhook.URL,
+		Active: webhook.Active,
+		Events: []string{string(WebhookRepoRefsChanged)},
+	}
+	dataCenterWebhook.Configuration.Secret = webhook.Secret
+	return json.Marshal(dataCenterWebhook)
+}
+
+// CreateWebhook creates a webhook in the repository, and returns the ID of the
+// webhook.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-webhooks-post
+func (d *dataCenter) CreateWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, payload []byte) (string, error) {
+	body, err := newDataCenterWebhook(payload)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/webhooks", d.repositoryURL(instanceURL, repositoryID))
+	code, respBody, err := request(ctx, d.client, oauthCtx, instanceURL, http.MethodPost, url, "application/json", body)
+	if err != nil {
+		return "", err
+	}
+	if err := checkResponse("create webhook", url, code, respBody); err != nil {
+		return "", err
+	}
+
+	var webhook dataCenterWebhook
+	if err := json.Unmarshal([]byte(respBody), &webhook); err != nil {
+		return "", errors.Wrap(err, "unmarshal body")
+	}
+	return strconv.Itoa(webhook.ID), nil
+}
+
+// PatchWebhook updates the webhook in the repository.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-webhooks-webhookid-put
+func (d *dataCenter) PatchWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string, payload []byte) error {
+	body, err := newDataCenterWebhook(payload)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/webhooks/%s", d.repositoryURL(instanceURL, repositoryID), webhookID)
+	code, respBody, err := request(ctx, d.client, oauthCtx, instanceURL, http.MethodPut, url, "application/json", body)
+	if err != nil {
+		return err
+	}
+	return checkResponse("patch webhook", url, code, respBody)
+}
+
+// DeleteWebhook deletes the webhook from the repository.
+//
+// Docs: https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-webhooks-webhookid-delete
+func (d *dataCenter) DeleteWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string) error {
+	url := fmt.Sprintf("%s/webhooks/%s", d.repositoryURL(instanceURL, repositoryID), webhookID)
+	code, body, err := request(ctx, d.client, oauthCtx, instanceURL, http.MethodDelete, url, "", nil)
+	if err != nil {
+		return err
+	}
+	if code == http.StatusNotFound {
+		return nil // It is OK if the webhook has already gone
+	}
+	return checkResponse("delete webhook", url, code, body)
+}
+
+// toWebhookCommit converts the commit to WebhookCommit.
+func (d *dataCenter) toWebhookCommit(instanceURL, repositoryID string, c dataCenterCommit) WebhookCommit {
+	projectKey, slug := splitRepositoryID(repositoryID)
+	return WebhookCommit{
+		ID:          c.ID,
+		Message:     c.Message,
+		Timestamp:   time.Unix(0, c.AuthorTimestamp*int64(time.Millisecond)),
+		URL:         fmt.Sprintf("%s/projects/%s/repos/%s/commits/%s", instanceURL, projectKey, slug, c.ID),
+		AuthorName:  c.Author.Name,
+		AuthorEmail: c.Author.EmailAddress,
+		ParentCount: len(c.Parents),
+	}
+}
+
+// fetchPushEventCommitList fetches the commits between the from and to commits
+// of the ref change, and the files added by each commit. Only the head commit
+// is taken for a newly created branch, because its commits cannot be listed
+// without the whole history.
+//
+// Docs:
+//   - https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-commits-get
+//   - https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-commits-commitid-changes-get
+func (d *dataCenter) fetchPushEventCommitList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, change WebhookPushChange) ([]WebhookCommit, error) {
+	var commits []WebhookCommit
+	if change.FromHash == "" || change.FromHash == emptyCommitID {
+		var commit dataCenterCommit
+		url := fmt.Sprintf("%s/commits/%s", d.repositoryURL(instanceURL, repositoryID), change.ToHash)
+		if err := d.get(ctx, oauthCtx, instanceURL, url, "fetch commit data", &commit); err != nil {
+			return nil, err
+		}
+		commits = append(commits, d.toWebhookCommit(instanceURL, repositoryID, commit))
+	} else {
+		url := fmt.Sprintf("%s/commits?since=%s&until=%s", d.repositoryURL(instanceURL, repositoryID), change.FromHash, change.ToHash)
+		err := d.listAll(ctx, oauthCtx, instanceURL, url, "fetch commit list",
+			func(values json.RawMessage) error {
+				var page []dataCenterCommit
+				if err := json.Unmarshal(values, &page); err != nil {
+					return err
+				}
+				for _, commit := range page {
+					commits = append(commits, d.toWebhookCommit(instanceURL, repositoryID, commit))
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		// The commits are listed in reverse chronological order.
+		for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+			commits[i], commits[j] = commits[j], commits[i]
+		}
+	}
+
+	var commitList []WebhookCommit
+	for _, commit := range commits {
+		if commit.ParentCount > 1 {
+			continue
+		}
+
+		url := fmt.Sprintf("%s/commits/%s/changes", d.repositoryURL(instanceURL, repositoryID), commit.ID)
+		err := d.listAll(ctx, oauthCtx, instanceURL, url, "fetch commit changes",
+			func(values json.RawMessage) error {
+				var changes []dataCenterChange
+				if err := json.Unmarshal(values, &changes); err != nil {
+					return err
+				}
+				for _, c := range changes {
+					switch c.Type {
+					case "ADD":
+						commit.Added = append(commit.Added, c.Path.ToString)
+					case "MODIFY":
+						commit.Modified = append(commit.Modified, c.Path.ToString)
+					}
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		commitList = append(commitList, commit)
+	}
+	return commitList, nil
+}
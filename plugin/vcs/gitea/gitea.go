@@ -0,0 +1,921 @@
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/vcs"
+	"github.com/bytebase/bytebase/plugin/vcs/internal/oauth"
+)
+
+const (
+	// apiPageSize is the default page size when making API requests, which is
+	// the default maximum number of items per page of Gitea.
+	apiPageSize = 50
+)
+
+func init() {
+	vcs.Register(vcs.Gitea, newProvider)
+}
+
+var _ vcs.Provider = (*Provider)(nil)
+
+// Provider is a Gitea VCS provider. Forgejo shares the same API as Gitea.
+type Provider struct {
+	client *http.Client
+}
+
+func newProvider(config vcs.ProviderConfig) vcs.Provider {
+	if config.Client == nil {
+		config.Client = &http.Client{}
+	}
+	return &Provider{
+		client: config.Client,
+	}
+}
+
+// APIURL returns the API URL path of Gitea.
+func (*Provider) APIURL(instanceURL string) string {
+	return fmt.Sprintf("%s/api/v1", instanceURL)
+}
+
+// RepositoryPermission is the permission of the repository collaborator.
+type RepositoryPermission string
+
+// The list of Gitea repository permissions.
+const (
+	RepositoryPermissionOwner RepositoryPermission = "owner"
+	RepositoryPermissionAdmin RepositoryPermission = "admin"
+	RepositoryPermissionWrite RepositoryPermission = "write"
+	RepositoryPermissionRead  RepositoryPermission = "read"
+)
+
+// User represents a Gitea API response for a user.
+type User struct {
+	ID       int64  `json:"id"`
+	Login    string `json:"login"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+// CollaboratorPermission represents a Gitea API response for the permission of
+// a repository collaborator.
+type CollaboratorPermission struct {
+	Permission string `json:"permission"`
+	RoleName   string `json:"role_name"`
+}
+
+// RepositoryPermissions represents the permissions of the authenticated user
+// to a repository.
+type RepositoryPermissions struct {
+	Admin bool `json:"admin"`
+	Push  bool `json:"push"`
+	Pull  bool `json:"pull"`
+}
+
+// Repository represents a Gitea API response for a repository.
+type Repository struct {
+	ID          int64                 `json:"id"`
+	Name        string                `json:"name"`
+	FullName    string                `json:"full_name"`
+	HTMLURL     string                `json:"html_url"`
+	Permissions RepositoryPermissions `json:"permissions"`
+}
+
+// RepositoryTree represents a Gitea API response for a page of a repository
+// tree.
+type RepositoryTree struct {
+	Tree      []RepositoryTreeNode `json:"tree"`
+	Truncated bool                 `json:"truncated"`
+}
+
+// RepositoryTreeNode represents a Gitea API response for a repository tree
+// node.
+type RepositoryTreeNode struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+// File represents a Gitea API response for a repository file.
+type File struct {
+	Type     string `json:"type"`
+	Encoding string `json:"encoding"`
+	Size     int64  `json:"size"`
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Content  string `json:"content"`
+	SHA      string `json:"sha"`
+}
+
+// FileCommit represents a Gitea API request for committing a file.
+type FileCommit struct {
+	Message string `json:"message"`
+	Content string `json:"content"`
+	// SHA is the blob SHA of the file being replaced, which is required for
+	// updating a file.
+	SHA    string `json:"sha,omitempty"`
+	Branch string `json:"branch,omitempty"`
+}
+
+// Commit represents a Gitea API response for a commit.
+type Commit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Author struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+			// Date expects corresponding JSON value is a string in RFC 3339 format,
+			// see https://pkg.go.dev/time#Time.MarshalJSON.
+			Date time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+// WebhookType is the Gitea webhook type.
+type WebhookType string
+
+const (
+	// WebhookPush is the webhook type for push.
+	WebhookPush WebhookType = "push"
+)
+
+// WebhookInfo represents a Gitea API response for the webhook information.
+type WebhookInfo struct {
+	ID int `json:"id"`
+}
+
+// WebhookConfig represents the Gitea API message for webhook configuration.
+type WebhookConfig struct {
+	// URL is the URL to which the payloads will be delivered.
+	URL string `json:"url"`
+	// ContentType is the media type used to serialize the payloads. Supported
+	// values include "json" and "form".
+	ContentType string `json:"content_type"`
+	// Secret is the secret will be used as the key to generate the HMAC hex digest
+	// value for delivery signature headers.
+	Secret string `json:"secret"`
+}
+
+// WebhookCreateOrUpdate represents a Gitea API request for creating or
+// updating a webhook.
+type WebhookCreateOrUpdate struct {
+	// Type is the type of the webhook, which is always "gitea" for creating.
+	Type string `json:"type,omitempty"`
+	// Config contains settings for the webhook.
+	Config WebhookConfig `json:"config"`
+	// Events determines what events the hook is triggered for.
+	Events []string `json:"events"`
+	// BranchFilter is the glob pattern of the branches triggering the hook.
+	BranchFilter string `json:"branch_filter"`
+	// Active determines whether the hook is triggered.
+	Active bool `json:"active"`
+}
+
+// WebhookRepository is the API message for webhook repository.
+type WebhookRepository struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+	HTMLURL  string `json:"html_url"`
+}
+
+// WebhookCommitAuthor is the API message for webhook commit author.
+type WebhookCommitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// WebhookSender is the API message for webhook sender.
+type WebhookSender struct {
+	Login string `json:"login"`
+}
+
+// WebhookCommit is the API message for webhook commit.
+type WebhookCommit struct {
+	ID        string              `json:"id"`
+	Message   string              `json:"message"`
+	Timestamp time.Time           `json:"timestamp"`
+	URL       string              `json:"url"`
+	Author    WebhookCommitAuthor `json:"author"`
+	Added     []string            `json:"added"`
+	Modified  []string            `json:"modified"`
+}
+
+// WebhookPushEvent is the API message for webhook push event.
+type WebhookPushEvent struct {
+	Ref        string            `json:"ref"`
+	Repository WebhookRepository `json:"repository"`
+	Sender     WebhookSender     `json:"sender"`
+	Commits    []WebhookCommit   `json:"commits"`
+}
+
+// request makes the HTTP request with the token in the OAuth context. Gitea
+// responds the expired token with 401 instead of an OAuth error, so we refresh
+// the token and retry the request once on 401.
+func (p *Provider) request(ctx context.Context, oauthCtx common.OauthContext, instanceURL, method, url string, body []byte) (code int, respBody string, err error) {
+	refresher := tokenRefresher(
+		instanceURL,
+		oauthContext{
+			ClientID:     oauthCtx.ClientID,
+			ClientSecret: oauthCtx.ClientSecret,
+			RefreshToken: oauthCtx.RefreshToken,
+		},
+		oauthCtx.Refresher,
+	)
+	do := func() (int, string, error) {
+		switch method {
+		case http.MethodGet:
+			return oauth.Get(ctx, p.client, url, &oauthCtx.AccessToken, refresher)
+		case http.MethodPost:
+			return oauth.Post(ctx, p.client, url, &oauthCtx.AccessToken, bytes.NewReader(body), refresher)
+		case http.MethodPut:
+			return oauth.Put(ctx, p.client, url, &oauthCtx.AccessToken, bytes.NewReader(body), refresher)
+		case http.MethodPatch:
+			return oauth.Patch(ctx, p.client, url, &oauthCtx.AccessToken, bytes.NewReader(body), refresher)
+		case http.MethodDelete:
+			return oauth.Delete(ctx, p.client, url, &oauthCtx.AccessToken, refresher)
+		}
+		return 0, "", errors.Errorf("unsupported method %s", method)
+	}
+
+	code, respBody, err = do()
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "%s %s", method, url)
+	}
+	if code != http.StatusUnauthorized || oauthCtx.RefreshToken == "" {
+		return code, respBody, nil
+	}
+
+	if err := refresher(ctx, p.client, &oauthCtx.AccessToken); err != nil {
+		return 0, "", errors.Wrap(err, "refresh token")
+	}
+	code, respBody, err = do()
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "%s %s", method, url)
+	}
+	return code, respBody, nil
+}
+
+// fetchUserInfo fetches user information from the given resourceURI, which
+// should be either "user" or "users/{username}".
+func (p *Provider) fetchUserInfo(ctx context.Context, oauthCtx common.OauthContext, instanceURL, resourceURI string) (*vcs.UserInfo, error) {
+	url := fmt.Sprintf("%s/%s", p.APIURL(instanceURL), resourceURI)
+	code, body, err := p.request(ctx, oauthCtx, instanceURL, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to read user info from URL %s", url)
+	} else if code >= 300 {
+		return nil, fmt.Errorf("failed to read user info from URL %s, status code: %d, body: %s", url, code, body)
+	}
+
+	var user User
+	if err = json.Unmarshal([]byte(body), &user); err != nil {
+		return nil, errors.Wrap(err, "unmarshal")
+	}
+	return &vcs.UserInfo{
+		PublicEmail: user.Email,
+		Name:        user.displayName(),
+		State:       vcs.StateActive,
+	}, nil
+}
+
+// displayName returns the full name of the user, or the login name if the full
+// name is not set.
+func (u User) displayName() string {
+	if u.FullName != "" {
+		return u.FullName
+	}
+	return u.Login
+}
+
+// TryLogin tries to fetch the user info from the current OAuth context.
+func (p *Provider) TryLogin(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) (*vcs.UserInfo, error) {
+	return p.fetchUserInfo(ctx, oauthCtx, instanceURL, "user")
+}
+
+// FetchCommitByID fetches the commit data by its ID from the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoGetSingleCommit
+func (p *Provider) FetchCommitByID(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string) (*vcs.Commit, error) {
+	url := fmt.Sprintf("%s/repos/%s/git/commits/%s", p.APIURL(instanceURL), repositoryID, commitID)
+	code, body, err := p.request(ctx, oauthCtx, instanceURL, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to fetch commit data from URL %s", url)
+	} else if code >= 300 {
+		return nil, fmt.Errorf("failed to fetch commit data from URL %s, status code: %d, body: %s", url, code, body)
+	}
+
+	commit := &Commit{}
+	if err := json.Unmarshal([]byte(body), commit); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+
+	return &vcs.Commit{
+		ID:         commit.SHA,
+		AuthorName: commit.Commit.Author.Name,
+		CreatedTs:  commit.Commit.Author.Date.Unix(),
+	}, nil
+}
+
+// FetchUserInfo fetches user info of given username.
+func (p *Provider) FetchUserInfo(ctx context.Context, oauthCtx common.OauthContext, instanceURL, username string) (*vcs.UserInfo, error) {
+	return p.fetchUserInfo(ctx, oauthCtx, instanceURL, fmt.Sprintf("users/%s", username))
+}
+
+func getRoleAndMappedRole(permission string) (giteaPermission RepositoryPermission, bytebaseRole common.ProjectRole) {
+	// Please refer to https://docs.gitea.io/en-us/permissions/ for the detailed
+	// permission descriptions of Gitea.
+	switch permission {
+	case "owner":
+		return RepositoryPermissionOwner, common.ProjectOwner
+	case "admin":
+		return RepositoryPermissionAdmin, common.ProjectOwner
+	case "write":
+		return RepositoryPermissionWrite, common.ProjectOwner
+	case "read":
+		return RepositoryPermissionRead, common.ProjectDeveloper
+	}
+	return "", ""
+}
+
+// FetchRepositoryActiveMemberList fetch all active members of a repository.
+// Only the collaborators of the repository are returned, the members of the
+// organization teams have to be added as collaborators explicitly.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoListCollaborators
+func (p *Provider) FetchRepositoryActiveMemberList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string) ([]*vcs.RepositoryMember, error) {
+	var allCollaborators []User
+	page := 1
+	for {
+		collaborators, hasNextPage, err := p.fetchPaginatedRepositoryCollaborators(ctx, oauthCtx, instanceURL, repositoryID, page)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch paginated list")
+		}
+		allCollaborators = append(allCollaborators, collaborators...)
+
+		if !hasNextPage {
+			break
+		}
+		page++
+	}
+
+	var emptyEmailUserList []string
+	var allMembers []*vcs.RepositoryMember
+	for _, c := range allCollaborators {
+		if c.Email == "" {
+			emptyEmailUserList = append(emptyEmailUserList, c.displayName())
+			continue
+		}
+
+		permission, err := p.fetchCollaboratorPermission(ctx, oauthCtx, instanceURL, repositoryID, c.Login)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetch collaborator permission, login: %s", c.Login)
+		}
+		giteaPermission, bytebaseRole := getRoleAndMappedRole(permission)
+		if bytebaseRole == "" {
+			continue
+		}
+		allMembers = append(allMembers,
+			&vcs.RepositoryMember{
+				Name:         c.displayName(),
+				Email:        c.Email,
+				Role:         bytebaseRole,
+				VCSRole:      string(giteaPermission),
+				State:        vcs.StateActive,
+				RoleProvider: vcs.Gitea,
+			},
+		)
+	}
+
+	if len(emptyEmailUserList) != 0 {
+		return nil, fmt.Errorf("[ %v ] did not have their email visible in Gitea, please make sure every members' email is visible before syncing", strings.Join(emptyEmailUserList, ", "))
+	}
+
+	return allMembers, nil
+}
+
+// fetchPaginatedRepositoryCollaborators fetches collaborators of a repository
+// in given page. It return the paginated results along with a boolean
+// indicating whether the next page exists.
+func (p *Provider) fetchPaginatedRepositoryCollaborators(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, page int) (collaborators []User, hasNextPage bool, err error) {
+	url := fmt.Sprintf("%s/repos/%s/collaborators?page=%d&limit=%d", p.APIURL(instanceURL), repositoryID, page, apiPageSize)
+	code, body, err := p.request(ctx, oauthCtx, instanceURL, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if code == http.StatusNotFound {
+		return nil, false, common.Errorf(common.NotFound, "failed to fetch repository collaborators from URL %s", url)
+	} else if code >= 300 {
+		return nil, false,
+			fmt.Errorf("failed to read repository collaborators from URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+	}
+
+	if err := json.Unmarshal([]byte(body), &collaborators); err != nil {
+		return nil, false, errors.Wrap(err, "unmarshal body")
+	}
+	return collaborators, len(collaborators) >= apiPageSize, nil
+}
+
+// fetchCollaboratorPermission fetches the permission of the collaborator to the
+// repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoGetRepoPermissions
+func (p *Provider) fetchCollaboratorPermission(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, login string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/collaborators/%s/permission", p.APIURL(instanceURL), repositoryID, login)
+	code, body, err := p.request(ctx, oauthCtx, instanceURL, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if code == http.StatusNotFound {
+		return "", common.Errorf(common.NotFound, "failed to fetch collaborator permission from URL %s", url)
+	} else if code >= 300 {
+		return "", fmt.Errorf("failed to fetch collaborator permission from URL %s, status code: %d, body: %s", url, code, body)
+	}
+
+	var permission CollaboratorPermission
+	if err := json.Unmarshal([]byte(body), &permission); err != nil {
+		return "", errors.Wrap(err, "unmarshal body")
+	}
+	return permission.Permission, nil
+}
+
+// oauthResponse is a Gitea OAuth response.
+type oauthResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error,omitempty"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// toVCSOAuthToken converts the response to *vcs.OAuthToken.
+func (o oauthResponse) toVCSOAuthToken() *vcs.OAuthToken {
+	// Gitea doesn't return the creation time of the token.
+	oauthToken := &vcs.OAuthToken{
+		AccessToken:  o.AccessToken,
+		RefreshToken: o.RefreshToken,
+		ExpiresIn:    o.ExpiresIn,
+		CreatedAt:    time.Now().Unix(),
+	}
+	if oauthToken.ExpiresIn != 0 {
+		oauthToken.ExpiresTs = oauthToken.CreatedAt + oauthToken.ExpiresIn
+	}
+	return oauthToken
+}
+
+// ExchangeOAuthToken exchanges OAuth content with the provided authorization code.
+//
+// Docs: https://docs.gitea.io/en-us/oauth2-provider/
+func (p *Provider) ExchangeOAuthToken(ctx context.Context, instanceURL string, oauthExchange *common.OAuthExchange) (*vcs.OAuthToken, error) {
+	body, err := json.Marshal(
+		struct {
+			ClientID     string `json:"client_id"`
+			ClientSecret string `json:"client_secret"`
+			Code         string `json:"code"`
+			RedirectURI  string `json:"redirect_uri"`
+			GrantType    string `json:"grant_type"`
+		}{
+			ClientID:     oauthExchange.ClientID,
+			ClientSecret: oauthExchange.ClientSecret,
+			Code:         oauthExchange.Code,
+			RedirectURI:  oauthExchange.RedirectURL,
+			GrantType:    "authorization_code",
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal OAuth exchange")
+	}
+
+	url := fmt.Sprintf("%s/login/oauth/access_token", instanceURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrapf(err, "construct POST %s", url)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange OAuth token, error: %v", err)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OAuth response body, code %v, error: %v", resp.StatusCode, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	oauthResp := new(oauthResponse)
+	if err := json.Unmarshal(respBody, oauthResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal OAuth response body, code %v, error: %v", resp.StatusCode, err)
+	}
+	if oauthResp.Error != "" {
+		return nil, fmt.Errorf("failed to exchange OAuth token, error: %v, error_description: %v", oauthResp.Error, oauthResp.ErrorDescription)
+	}
+	return oauthResp.toVCSOAuthToken(), nil
+}
+
+// FetchAllRepositoryList fetches all repositories where the authenticated user
+// has the admin permission, which is required to create webhook in the
+// repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/user/userCurrentListRepos
+func (p *Provider) FetchAllRepositoryList(ctx context.Context, oauthCtx common.OauthContext, instanceURL string) ([]*vcs.Repository, error) {
+	var giteaRepos []Repository
+	page := 1
+	for {
+		repos, hasNextPage, err := p.fetchPaginatedRepositoryList(ctx, oauthCtx, instanceURL, page)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch paginated list")
+		}
+		giteaRepos = append(giteaRepos, repos...)
+
+		if !hasNextPage {
+			break
+		}
+		page++
+	}
+
+	var allRepos []*vcs.Repository
+	for _, r := range giteaRepos {
+		if !r.Permissions.Admin {
+			continue
+		}
+		allRepos = append(allRepos,
+			&vcs.Repository{
+				ID:       r.ID,
+				Name:     r.Name,
+				FullPath: r.FullName,
+				WebURL:   r.HTMLURL,
+			},
+		)
+	}
+	return allRepos, nil
+}
+
+// fetchPaginatedRepositoryList fetches repositories of the authenticated user
+// in given page. It return the paginated results along with a boolean
+// indicating whether the next page exists.
+func (p *Provider) fetchPaginatedRepositoryList(ctx context.Context, oauthCtx common.OauthContext, instanceURL string, page int) (repos []Repository, hasNextPage bool, err error) {
+	url := fmt.Sprintf("%s/user/repos?page=%d&limit=%d", p.APIURL(instanceURL), page, apiPageSize)
+	code, body, err := p.request(ctx, oauthCtx, instanceURL, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if code == http.StatusNotFound {
+		return nil, false, common.Errorf(common.NotFound, "failed to fetch repository list from URL %s", url)
+	} else if code >= 300 {
+		return nil, false,
+			fmt.Errorf("failed to fetch repository list from URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+	}
+
+	if err := json.Unmarshal([]byte(body), &repos); err != nil {
+		return nil, false, errors.Wrap(err, "unmarshal")
+	}
+	return repos, len(repos) >= apiPageSize, nil
+}
+
+// FetchRepositoryFileList fetches the all files from the given repository tree
+// recursively.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/GetTree
+func (p *Provider) FetchRepositoryFileList(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, ref, filePath string) ([]*vcs.RepositoryTreeNode, error) {
+	if filePath != "" && !strings.HasSuffix(filePath, "/") {
+		filePath += "/"
+	}
+
+	var allTreeNodes []*vcs.RepositoryTreeNode
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/repos/%s/git/trees/%s?recursive=true&page=%d", p.APIURL(instanceURL), repositoryID, url.PathEscape(ref), page)
+		code, body, err := p.request(ctx, oauthCtx, instanceURL, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if code == http.StatusNotFound {
+			return nil, common.Errorf(common.NotFound, "failed to fetch repository file list from URL %s", url)
+		} else if code >= 300 {
+			return nil,
+				fmt.Errorf("failed to fetch repository file list from URL %s, status code: %d, body: %s",
+					url,
+					code,
+					body,
+				)
+		}
+
+		var repoTree RepositoryTree
+		if err := json.Unmarshal([]byte(body), &repoTree); err != nil {
+			return nil, errors.Wrap(err, "unmarshal body")
+		}
+
+		for _, n := range repoTree.Tree {
+			// Gitea does not support filtering by path prefix, thus simulating the
+			// behavior here.
+			if n.Type == "blob" && strings.HasPrefix(n.Path, filePath) {
+				allTreeNodes = append(allTreeNodes,
+					&vcs.RepositoryTreeNode{
+						Path: n.Path,
+						Type: n.Type,
+					},
+				)
+			}
+		}
+
+		// The tree is truncated if there are more pages.
+		if !repoTree.Truncated || len(repoTree.Tree) == 0 {
+			break
+		}
+	}
+	return allTreeNodes, nil
+}
+
+// escapeFilePath escapes each segment of the file path, Gitea doesn't accept
+// the escaped path separator.
+func escapeFilePath(filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// commitFile creates or updates a file at given path in the repository.
+func (p *Provider) commitFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, method string, fileCommitCreate vcs.FileCommitCreate) error {
+	body, err := json.Marshal(
+		FileCommit{
+			Message: fileCommitCreate.CommitMessage,
+			Content: base64.StdEncoding.EncodeToString([]byte(fileCommitCreate.Content)),
+			Branch:  fileCommitCreate.Branch,
+			SHA:     fileCommitCreate.LastCommitID,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal file commit")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/contents/%s", p.APIURL(instanceURL), repositoryID, escapeFilePath(filePath))
+	code, respBody, err := p.request(ctx, oauthCtx, instanceURL, method, url, body)
+	if err != nil {
+		return err
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create/update file through URL %s", url)
+	} else if code >= 300 {
+		return fmt.Errorf("failed to create/update file through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			respBody,
+		)
+	}
+	return nil
+}
+
+// CreateFile creates a file at given path in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoCreateFile
+func (p *Provider) CreateFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	return p.commitFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, http.MethodPost, fileCommitCreate)
+}
+
+// OverwriteFile overwrites an existing file at given path in the repository.
+// The LastCommitID of the file commit is the blob SHA of the existing file.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoUpdateFile
+func (p *Provider) OverwriteFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath string, fileCommitCreate vcs.FileCommitCreate) error {
+	return p.commitFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, http.MethodPut, fileCommitCreate)
+}
+
+// ReadFileMeta reads the metadata of the given file in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoGetContents
+func (p *Provider) ReadFileMeta(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (*vcs.FileMeta, error) {
+	file, err := p.readFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, ref)
+	if err != nil {
+		return nil, errors.Wrap(err, "read file")
+	}
+
+	return &vcs.FileMeta{
+		Name:         file.Name,
+		Path:         file.Path,
+		Size:         file.Size,
+		LastCommitID: file.SHA,
+	}, nil
+}
+
+// ReadFileContent reads the content of the given file in the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoGetContents
+func (p *Provider) ReadFileContent(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (string, error) {
+	file, err := p.readFile(ctx, oauthCtx, instanceURL, repositoryID, filePath, ref)
+	if err != nil {
+		return "", errors.Wrap(err, "read file")
+	}
+	return file.Content, nil
+}
+
+// readFile reads the given file in the repository.
+func (p *Provider) readFile(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, filePath, ref string) (*File, error) {
+	url := fmt.Sprintf("%s/repos/%s/contents/%s?ref=%s", p.APIURL(instanceURL), repositoryID, escapeFilePath(filePath), url.QueryEscape(ref))
+	code, body, err := p.request(ctx, oauthCtx, instanceURL, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to read file from URL %s", url)
+	} else if code >= 300 {
+		return nil,
+			fmt.Errorf("failed to read file from URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+	}
+
+	// This API endpoint returns a JSON array if the path is a directory, and we do
+	// not want that.
+	if body != "" && body[0] == '[' {
+		return nil, errors.Errorf("%q is a directory not a file", filePath)
+	}
+
+	var file File
+	if err = json.Unmarshal([]byte(body), &file); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+
+	if file.Encoding == "base64" {
+		decodedContent, err := base64.StdEncoding.DecodeString(file.Content)
+		if err != nil {
+			return nil, errors.Wrap(err, "decode file content")
+		}
+		file.Content = string(decodedContent)
+	}
+	return &file, nil
+}
+
+// CreateWebhook creates a webhook in the repository with given payload.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoCreateHook
+func (p *Provider) CreateWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, payload []byte) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/hooks", p.APIURL(instanceURL), repositoryID)
+	code, body, err := p.request(ctx, oauthCtx, instanceURL, http.MethodPost, url, payload)
+	if err != nil {
+		return "", err
+	}
+
+	if code == http.StatusNotFound {
+		return "", common.Errorf(common.NotFound, "failed to create webhook through URL %s", url)
+	} else if code >= 300 {
+		return "",
+			fmt.Errorf("failed to create webhook through URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+	}
+
+	var webhookInfo WebhookInfo
+	if err = json.Unmarshal([]byte(body), &webhookInfo); err != nil {
+		return "", errors.Wrap(err, "unmarshal body")
+	}
+	return strconv.Itoa(webhookInfo.ID), nil
+}
+
+// PatchWebhook patches the webhook in the repository with given payload.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoEditHook
+func (p *Provider) PatchWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string, payload []byte) error {
+	url := fmt.Sprintf("%s/repos/%s/hooks/%s", p.APIURL(instanceURL), repositoryID, webhookID)
+	code, body, err := p.request(ctx, oauthCtx, instanceURL, http.MethodPatch, url, payload)
+	if err != nil {
+		return err
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to patch webhook through URL %s", url)
+	} else if code >= 300 {
+		return fmt.Errorf("failed to patch webhook through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+	return nil
+}
+
+// DeleteWebhook deletes the webhook from the repository.
+//
+// Docs: https://try.gitea.io/api/swagger#/repository/repoDeleteHook
+func (p *Provider) DeleteWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string) error {
+	url := fmt.Sprintf("%s/repos/%s/hooks/%s", p.APIURL(instanceURL), repositoryID, webhookID)
+	code, body, err := p.request(ctx, oauthCtx, instanceURL, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	if code == http.StatusNotFound {
+		return nil // It is OK if the webhook has already gone
+	} else if code >= 300 {
+		return fmt.Errorf("failed to delete webhook through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+	return nil
+}
+
+// oauthContext is the request context for refreshing oauth token.
+type oauthContext struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+	GrantType    string `json:"grant_type"`
+}
+
+type refreshOAuthResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	// token_type is not used.
+}
+
+func tokenRefresher(instanceURL string, oauthCtx oauthContext, refresher common.TokenRefresher) oauth.TokenRefresher {
+	return func(ctx context.Context, client *http.Client, oldToken *string) error {
+		url := fmt.Sprintf("%s/login/oauth/access_token", instanceURL)
+		oauthCtx.GrantType = "refresh_token"
+		body, err := json.Marshal(oauthCtx)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return errors.Wrapf(err, "construct POST %s", url)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return errors.Wrapf(err, "POST %s", url)
+		}
+
+		body, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return errors.Wrapf(err, "read body of POST %s", url)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return errors.Errorf("non-200 POST %s status code %d with body %q", url, resp.StatusCode, body)
+		}
+
+		var r refreshOAuthResponse
+		if err = json.Unmarshal(body, &r); err != nil {
+			return errors.Wrapf(err, "unmarshal body from POST %s", url)
+		}
+
+		// Update the old token to new value for retries.
+		*oldToken = r.AccessToken
+
+		// Gitea doesn't return the creation time of the token.
+		var expireAt int64
+		if r.ExpiresIn != 0 {
+			expireAt = time.Now().Unix() + r.ExpiresIn
+		}
+		if err = refresher(r.AccessToken, r.RefreshToken, expireAt); err != nil {
+			return err
+		}
+		return nil
+	}
+}
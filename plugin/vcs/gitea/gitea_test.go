@@ -0,0 +1,347 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+const testInstanceURL = "https://gitea.example.com"
+
+func TestProvider_FetchUserInfo(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/users/alice", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "id": 1,
+  "login": "alice",
+  "full_name": "",
+  "email": "alice@example.com",
+  "avatar_url": "https://gitea.example.com/avatars/1",
+  "language": "en-US",
+  "is_admin": false,
+  "restricted": false,
+  "active": true,
+  "prohibit_login": false
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchUserInfo(ctx, common.OauthContext{}, testInstanceURL, "alice")
+	require.NoError(t, err)
+
+	want := &vcs.UserInfo{
+		PublicEmail: "alice@example.com",
+		Name:        "alice",
+		State:       vcs.StateActive,
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_FetchRepositoryActiveMemberList(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						switch r.URL.Path {
+						case "/api/v1/repos/alice/shop/collaborators":
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body: io.NopCloser(strings.NewReader(`
+[
+  {"id": 2, "login": "bob", "full_name": "Bob", "email": "bob@example.com"},
+  {"id": 3, "login": "carol", "full_name": "Carol", "email": "carol@example.com"}
+]
+`)),
+							}, nil
+						case "/api/v1/repos/alice/shop/collaborators/bob/permission":
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body:       io.NopCloser(strings.NewReader(`{"permission": "write", "role_name": "write"}`)),
+							}, nil
+						case "/api/v1/repos/alice/shop/collaborators/carol/permission":
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body:       io.NopCloser(strings.NewReader(`{"permission": "read", "role_name": "read"}`)),
+							}, nil
+						}
+						return nil, errors.Errorf("unexpected request path %s", r.URL.Path)
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchRepositoryActiveMemberList(ctx, common.OauthContext{}, testInstanceURL, "alice/shop")
+	require.NoError(t, err)
+
+	want := []*vcs.RepositoryMember{
+		{
+			Name:         "Bob",
+			Email:        "bob@example.com",
+			Role:         common.ProjectOwner,
+			VCSRole:      string(RepositoryPermissionWrite),
+			State:        vcs.StateActive,
+			RoleProvider: vcs.Gitea,
+		},
+		{
+			Name:         "Carol",
+			Email:        "carol@example.com",
+			Role:         common.ProjectDeveloper,
+			VCSRole:      string(RepositoryPermissionRead),
+			State:        vcs.StateActive,
+			RoleProvider: vcs.Gitea,
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_FetchAllRepositoryList(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/user/repos", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+[
+  {"id": 1, "name": "shop", "full_name": "alice/shop", "html_url": "https://gitea.example.com/alice/shop", "permissions": {"admin": true, "push": true, "pull": true}},
+  {"id": 2, "name": "blog", "full_name": "bob/blog", "html_url": "https://gitea.example.com/bob/blog", "permissions": {"admin": false, "push": true, "pull": true}}
+]
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchAllRepositoryList(ctx, common.OauthContext{}, testInstanceURL)
+	require.NoError(t, err)
+
+	// The repository without the admin permission is excluded.
+	want := []*vcs.Repository{
+		{
+			ID:       1,
+			Name:     "shop",
+			FullPath: "alice/shop",
+			WebURL:   "https://gitea.example.com/alice/shop",
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_FetchRepositoryFileList(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/repos/alice/shop/git/trees/main", r.URL.Path)
+						body := `{"tree": [{"path": "bytebase/v1__init.sql", "type": "blob"}, {"path": "bytebase", "type": "tree"}], "truncated": true}`
+						if r.URL.Query().Get("page") == "2" {
+							body = `{"tree": [{"path": "README.md", "type": "blob"}, {"path": "bytebase/v2__add.sql", "type": "blob"}], "truncated": false}`
+						}
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(body)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.FetchRepositoryFileList(ctx, common.OauthContext{}, testInstanceURL, "alice/shop", "main", "bytebase")
+	require.NoError(t, err)
+
+	want := []*vcs.RepositoryTreeNode{
+		{Path: "bytebase/v1__init.sql", Type: "blob"},
+		{Path: "bytebase/v2__add.sql", Type: "blob"},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_OverwriteFile(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPut, r.Method)
+						assert.Equal(t, "/api/v1/repos/alice/shop/contents/bytebase/LATEST schema.sql", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						var fileCommit FileCommit
+						require.NoError(t, json.Unmarshal(body, &fileCommit))
+						assert.Equal(t, "0e7d2f2d", fileCommit.SHA)
+						assert.Equal(t, "Q1JFQVRFIFRBQkxFIHQgKGlkIElOVCk7", fileCommit.Content)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader("{}")),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	err := p.OverwriteFile(ctx, common.OauthContext{}, testInstanceURL, "alice/shop", "bytebase/LATEST schema.sql",
+		vcs.FileCommitCreate{
+			Branch:        "main",
+			Content:       "CREATE TABLE t (id INT);",
+			CommitMessage: "Update schema",
+			LastCommitID:  "0e7d2f2d",
+		},
+	)
+	require.NoError(t, err)
+}
+
+func TestProvider_ReadFileContent(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/repos/alice/shop/contents/bytebase/v1__init.sql", r.URL.Path)
+						assert.Equal(t, "main", r.URL.Query().Get("ref"))
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "name": "v1__init.sql",
+  "path": "bytebase/v1__init.sql",
+  "sha": "0e7d2f2d",
+  "type": "file",
+  "size": 24,
+  "encoding": "base64",
+  "content": "Q1JFQVRFIFRBQkxFIHQgKGlkIElOVCk7"
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.ReadFileContent(ctx, common.OauthContext{}, testInstanceURL, "alice/shop", "bytebase/v1__init.sql", "main")
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE t (id INT);", got)
+}
+
+func TestProvider_CreateWebhook(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v1/repos/alice/shop/hooks", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "id": 7,
+  "type": "gitea",
+  "config": {"content_type": "json", "url": "https://example.com/hook/gitea/1"},
+  "events": ["push"],
+  "active": true
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.CreateWebhook(ctx, common.OauthContext{}, testInstanceURL, "alice/shop", []byte(""))
+	require.NoError(t, err)
+	assert.Equal(t, "7", got)
+}
+
+func TestProvider_RefreshTokenOnUnauthorized(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						if r.URL.Path == "/login/oauth/access_token" {
+							body, err := io.ReadAll(r.Body)
+							require.NoError(t, err)
+							var oauthCtx oauthContext
+							require.NoError(t, json.Unmarshal(body, &oauthCtx))
+							assert.Equal(t, "refresh_token", oauthCtx.GrantType)
+							assert.Equal(t, "old-refresh", oauthCtx.RefreshToken)
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body:       io.NopCloser(strings.NewReader(`{"access_token": "new", "token_type": "bearer", "expires_in": 3600, "refresh_token": "new-refresh"}`)),
+							}, nil
+						}
+
+						// Gitea responds the expired token with 401 instead of an OAuth error.
+						if r.Header.Get("Authorization") != "Bearer new" {
+							return &http.Response{
+								StatusCode: http.StatusUnauthorized,
+								Body:       io.NopCloser(strings.NewReader(`{"message": "token is required"}`)),
+							}, nil
+						}
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader(`{"id": 1, "login": "alice", "full_name": "Alice", "email": "alice@example.com"}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	var refreshedToken, refreshedRefreshToken string
+	oauthCtx := common.OauthContext{
+		AccessToken:  "expired",
+		RefreshToken: "old-refresh",
+		Refresher: func(token, refreshToken string, _ int64) error {
+			refreshedToken, refreshedRefreshToken = token, refreshToken
+			return nil
+		},
+	}
+
+	ctx := context.Background()
+	got, err := p.TryLogin(ctx, oauthCtx, testInstanceURL)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", got.Name)
+	assert.Equal(t, "new", refreshedToken)
+	assert.Equal(t, "new-refresh", refreshedRefreshToken)
+}
@@ -30,13 +30,18 @@ const (
 
 func init() {
 	vcs.Register(vcs.GitHubCom, newProvider)
+	vcs.Register(vcs.GitHubEnterprise, newEnterpriseProvider)
 }
 
 var _ vcs.Provider = (*Provider)(nil)
+var _ vcs.PullRequestReviewer = (*Provider)(nil)
+var _ vcs.PullRequestCreator = (*Provider)(nil)
 
-// Provider is a GitHub VCS provider.
+// Provider is a GitHub VCS provider, which serves both GitHub.com and the GitHub
+// Enterprise Server.
 type Provider struct {
-	client *http.Client
+	client  *http.Client
+	vcsType vcs.Type
 }
 
 func newProvider(config vcs.ProviderConfig) vcs.Provider {
@@ -44,12 +49,22 @@ func newProvider(config vcs.ProviderConfig) vcs.Provider {
 		config.Client = &http.Client{}
 	}
 	return &Provider{
-		client: config.Client,
+		client:  config.Client,
+		vcsType: vcs.GitHubCom,
 	}
 }
 
+// newEnterpriseProvider returns the provider for the GitHub Enterprise Server,
+// whose APIs are served under the "/api/v3" path of the configured instance URL.
+func newEnterpriseProvider(config vcs.ProviderConfig) vcs.Provider {
+	p := newProvider(config).(*Provider)
+	p.vcsType = vcs.GitHubEnterprise
+	return p
+}
+
 // APIURL returns the API URL path of GitHub.
 func (*Provider) APIURL(instanceURL string) string {
+	instanceURL = strings.TrimSuffix(instanceURL, "/")
 	if instanceURL == githubComURL {
 		return "https://api.github.com"
 	}
@@ -119,6 +134,20 @@ type WebhookType string
 const (
 	// WebhookPush is the webhook type for push.
 	WebhookPush WebhookType = "push"
+	// WebhookPullRequest is the webhook type for pull request.
+	WebhookPullRequest WebhookType = "pull_request"
+)
+
+// WebhookPullRequestAction is the action of the GitHub webhook pull request event.
+type WebhookPullRequestAction string
+
+const (
+	// WebhookPullRequestOpened is the action when a pull request is opened.
+	WebhookPullRequestOpened WebhookPullRequestAction = "opened"
+	// WebhookPullRequestReopened is the action when a pull request is reopened.
+	WebhookPullRequestReopened WebhookPullRequestAction = "reopened"
+	// WebhookPullRequestSynchronize is the action when new commits are pushed to the head branch of a pull request.
+	WebhookPullRequestSynchronize WebhookPullRequestAction = "synchronize"
 )
 
 // WebhookInfo represents a GitHub API response for the webhook information.
@@ -185,6 +214,7 @@ type WebhookCommit struct {
 	URL       string              `json:"url"`
 	Author    WebhookCommitAuthor `json:"author"`
 	Added     []string            `json:"added"`
+	Modified  []string            `json:"modified"`
 }
 
 // WebhookPushEvent is the API message for webhook push event.
@@ -195,6 +225,100 @@ type WebhookPushEvent struct {
 	Commits    []WebhookCommit   `json:"commits"`
 }
 
+// PullRequestBranch is the API message for the head or base branch of pull
+// request.
+type PullRequestBranch struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// PullRequest is the API message for pull request.
+type PullRequest struct {
+	Number  int               `json:"number"`
+	Title   string            `json:"title"`
+	HTMLURL string            `json:"html_url"`
+	Head    PullRequestBranch `json:"head"`
+	Base    PullRequestBranch `json:"base"`
+}
+
+// WebhookPullRequestEvent is the API message for webhook pull request event.
+type WebhookPullRequestEvent struct {
+	Action      WebhookPullRequestAction `json:"action"`
+	PullRequest PullRequest              `json:"pull_request"`
+	Repository  WebhookRepository        `json:"repository"`
+	Sender      WebhookSender            `json:"sender"`
+}
+
+// PullRequestFile represents a GitHub API response for a file changed by a
+// pull request.
+type PullRequestFile struct {
+	Filename string `json:"filename"`
+	// Status is one of "added", "removed", "modified", "renamed", "copied",
+	// "changed" and "unchanged".
+	Status string `json:"status"`
+}
+
+// PullRequestReviewComment represents a GitHub API request for an inline
+// comment of a pull request review.
+type PullRequestReviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	// Side is the side of the diff that the comment applies to, "RIGHT" is for
+	// the new version of the file.
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+// PullRequestReviewCreate represents a GitHub API request for creating a pull
+// request review.
+type PullRequestReviewCreate struct {
+	CommitID string `json:"commit_id"`
+	Body     string `json:"body"`
+	// Event is the review action, we only use "COMMENT" as the merge is blocked
+	// by the commit status instead.
+	Event    string                     `json:"event"`
+	Comments []PullRequestReviewComment `json:"comments,omitempty"`
+}
+
+// CommitStatusCreate represents a GitHub API request for creating a commit
+// status.
+type CommitStatusCreate struct {
+	// State is one of "error", "failure", "pending" and "success".
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description"`
+	Context     string `json:"context"`
+}
+
+// Branch represents a GitHub API response for a branch.
+type Branch struct {
+	Name   string       `json:"name"`
+	Commit BranchCommit `json:"commit"`
+}
+
+// BranchCommit represents a GitHub API response for the head commit of a
+// branch.
+type BranchCommit struct {
+	SHA string `json:"sha"`
+}
+
+// ReferenceCreate represents a GitHub API request for creating a git
+// reference.
+type ReferenceCreate struct {
+	// Ref is the fully qualified reference name, e.g. "refs/heads/main".
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// PullRequestCreate represents a GitHub API request for creating a pull
+// request.
+type PullRequestCreate struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+}
+
 // fetchUserInfo fetches user information from the given resourceURI, which
 // should be either "user" or "users/{username}".
 func (p *Provider) fetchUserInfo(ctx context.Context, oauthCtx common.OauthContext, instanceURL, resourceURI string) (*vcs.UserInfo, error) {
@@ -344,7 +468,7 @@ func (p *Provider) FetchRepositoryActiveMemberList(ctx context.Context, oauthCtx
 	var emptyEmailUserList []string
 	var allMembers []*vcs.RepositoryMember
 	for _, c := range allCollaborators {
-		userInfo, err := p.FetchUserInfo(ctx, oauthCtx, githubComURL, c.Login)
+		userInfo, err := p.FetchUserInfo(ctx, oauthCtx, instanceURL, c.Login)
 		if err != nil {
 			return nil, errors.Wrapf(err, "fetch user info, login: %s", c.Login)
 		}
@@ -362,7 +486,7 @@ func (p *Provider) FetchRepositoryActiveMemberList(ctx context.Context, oauthCtx
 				Role:         bytebaseRole,
 				VCSRole:      string(githubRole),
 				State:        vcs.StateActive,
-				RoleProvider: vcs.GitHubCom,
+				RoleProvider: p.vcsType,
 			},
 		)
 	}
@@ -878,6 +1002,384 @@ func (p *Provider) DeleteWebhook(ctx context.Context, oauthCtx common.OauthConte
 	return nil
 }
 
+// ListPullRequestFiles lists the files changed by the pull request.
+//
+// Docs: https://docs.github.com/en/rest/pulls/pulls#list-pull-requests-files
+func (p *Provider) ListPullRequestFiles(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string) ([]*vcs.PullRequestFile, error) {
+	var allFiles []PullRequestFile
+	page := 1
+	for {
+		files, hasNextPage, err := p.fetchPaginatedPullRequestFiles(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID, page)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch paginated list")
+		}
+		allFiles = append(allFiles, files...)
+
+		if !hasNextPage {
+			break
+		}
+		page++
+	}
+
+	var fileList []*vcs.PullRequestFile
+	for _, file := range allFiles {
+		fileList = append(fileList,
+			&vcs.PullRequestFile{
+				Path:  file.Filename,
+				IsNew: file.Status == "added",
+			},
+		)
+	}
+	return fileList, nil
+}
+
+// fetchPaginatedPullRequestFiles fetches files changed by the pull request in
+// given page. It return the paginated results along with a boolean indicating
+// whether the next page exists.
+func (p *Provider) fetchPaginatedPullRequestFiles(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string, page int) (files []PullRequestFile, hasNextPage bool, err error) {
+	url := fmt.Sprintf("%s/repos/%s/pulls/%s/files?page=%d&per_page=%d", p.APIURL(instanceURL), repositoryID, pullRequestID, page, apiPageSize)
+	code, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, false, common.Errorf(common.NotFound, "failed to fetch pull request files from URL %s", url)
+	} else if code >= 300 {
+		return nil, false,
+			fmt.Errorf("failed to read pull request files from URL %s, status code: %d, body: %s",
+				url,
+				code,
+				body,
+			)
+	}
+
+	if err := json.Unmarshal([]byte(body), &files); err != nil {
+		return nil, false, errors.Wrap(err, "unmarshal body")
+	}
+	return files, len(files) >= apiPageSize, nil
+}
+
+// CreatePullRequestReview creates a review with the summary body and the inline
+// comments on the pull request.
+//
+// Docs: https://docs.github.com/en/rest/pulls/reviews#create-a-review-for-a-pull-request
+func (p *Provider) CreatePullRequestReview(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID, commitID, body string, commentList []*vcs.ReviewComment) error {
+	reviewCreate := PullRequestReviewCreate{
+		CommitID: commitID,
+		Body:     body,
+		Event:    "COMMENT",
+	}
+	for _, comment := range commentList {
+		reviewCreate.Comments = append(reviewCreate.Comments,
+			PullRequestReviewComment{
+				Path: comment.Path,
+				Line: comment.Line,
+				Side: "RIGHT",
+				Body: comment.Body,
+			},
+		)
+	}
+	payload, err := json.Marshal(reviewCreate)
+	if err != nil {
+		return errors.Wrap(err, "marshal review create")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/pulls/%s/reviews", p.APIURL(instanceURL), repositoryID, pullRequestID)
+	code, respBody, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(payload),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create pull request review through URL %s", url)
+	} else if code >= 300 {
+		return fmt.Errorf("failed to create pull request review through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			respBody,
+		)
+	}
+	return nil
+}
+
+// SetCommitStatus sets the status of the commit.
+//
+// Docs: https://docs.github.com/en/rest/commits/statuses#create-a-commit-status
+func (p *Provider) SetCommitStatus(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string, status *vcs.CommitStatus) error {
+	payload, err := json.Marshal(
+		CommitStatusCreate{
+			State:       string(status.State),
+			TargetURL:   status.TargetURL,
+			Description: status.Description,
+			Context:     status.Context,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal commit status create")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/statuses/%s", p.APIURL(instanceURL), repositoryID, commitID)
+	code, body, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(payload),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to set commit status through URL %s", url)
+	} else if code >= 300 {
+		return fmt.Errorf("failed to set commit status through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+	return nil
+}
+
+// GetBranch gets the branch along with its head commit.
+//
+// Docs: https://docs.github.com/en/rest/branches/branches#get-a-branch
+func (p *Provider) GetBranch(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, branchName string) (*vcs.BranchInfo, error) {
+	url := fmt.Sprintf("%s/repos/%s/branches/%s", p.APIURL(instanceURL), repositoryID, url.PathEscape(branchName))
+	code, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to get branch from URL %s", url)
+	} else if code >= 300 {
+		return nil, fmt.Errorf("failed to get branch from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var branch Branch
+	if err := json.Unmarshal([]byte(body), &branch); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+	return &vcs.BranchInfo{
+		Name:         branch.Name,
+		LastCommitID: branch.Commit.SHA,
+	}, nil
+}
+
+// CreateBranch creates the branch pointing to its last commit.
+//
+// Docs: https://docs.github.com/en/rest/git/refs#create-a-reference
+func (p *Provider) CreateBranch(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, branch *vcs.BranchInfo) error {
+	payload, err := json.Marshal(
+		ReferenceCreate{
+			Ref: fmt.Sprintf("refs/heads/%s", branch.Name),
+			SHA: branch.LastCommitID,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal reference create")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/git/refs", p.APIURL(instanceURL), repositoryID)
+	code, body, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(payload),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create branch through URL %s", url)
+	} else if code == http.StatusUnprocessableEntity && strings.Contains(body, "already exists") {
+		return common.Errorf(common.Conflict, "branch %q already exists", branch.Name)
+	} else if code >= 300 {
+		return fmt.Errorf("failed to create branch through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+	return nil
+}
+
+// CreatePullRequest creates the pull request.
+//
+// Docs: https://docs.github.com/en/rest/pulls/pulls#create-a-pull-request
+func (p *Provider) CreatePullRequest(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, pullRequestCreate *vcs.PullRequestCreate) (*vcs.PullRequest, error) {
+	payload, err := json.Marshal(
+		PullRequestCreate{
+			Title: pullRequestCreate.Title,
+			Body:  pullRequestCreate.Body,
+			Head:  pullRequestCreate.Head,
+			Base:  pullRequestCreate.Base,
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal pull request create")
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/pulls", p.APIURL(instanceURL), repositoryID)
+	code, body, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(payload),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to create pull request through URL %s", url)
+	} else if code == http.StatusUnprocessableEntity && strings.Contains(body, "already exists") {
+		return nil, common.Errorf(common.Conflict, "pull request from branch %q to %q already exists", pullRequestCreate.Head, pullRequestCreate.Base)
+	} else if code >= 300 {
+		return nil, fmt.Errorf("failed to create pull request through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var pullRequest PullRequest
+	if err := json.Unmarshal([]byte(body), &pullRequest); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+	return &vcs.PullRequest{
+		URL: pullRequest.HTMLURL,
+	}, nil
+}
+
+// FindOpenPullRequest finds the open pull request from the head branch to the base branch.
+//
+// Docs: https://docs.github.com/en/rest/pulls/pulls#list-pull-requests
+func (p *Provider) FindOpenPullRequest(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, head, base string) (*vcs.PullRequest, error) {
+	// The head branch is qualified by the owner of the repository, i.e. the first part of the repository ID "owner/repo".
+	owner := strings.SplitN(repositoryID, "/", 2)[0]
+	url := fmt.Sprintf("%s/repos/%s/pulls?state=open&head=%s&base=%s", p.APIURL(instanceURL), repositoryID, url.QueryEscape(owner+":"+head), url.QueryEscape(base))
+	code, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to list pull requests from URL %s", url)
+	} else if code >= 300 {
+		return nil, fmt.Errorf("failed to list pull requests from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var pullRequestList []PullRequest
+	if err := json.Unmarshal([]byte(body), &pullRequestList); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+	if len(pullRequestList) == 0 {
+		return nil, nil
+	}
+	return &vcs.PullRequest{
+		URL: pullRequestList[0].HTMLURL,
+	}, nil
+}
+
 // oauthContext is the request context for refreshing oauth token.
 type oauthContext struct {
 	ClientID     string `json:"client_id"`
@@ -31,6 +31,8 @@ const (
 )
 
 var _ vcs.Provider = (*Provider)(nil)
+var _ vcs.PullRequestReviewer = (*Provider)(nil)
+var _ vcs.PullRequestCreator = (*Provider)(nil)
 
 // WebhookType is the GitLab webhook type.
 type WebhookType string
@@ -38,6 +40,21 @@ type WebhookType string
 const (
 	// WebhookPush is the webhook type for push.
 	WebhookPush WebhookType = "push"
+	// WebhookMergeRequest is the webhook type for merge request.
+	WebhookMergeRequest WebhookType = "merge_request"
+)
+
+// WebhookMergeRequestAction is the action of the GitLab webhook merge request event.
+type WebhookMergeRequestAction string
+
+const (
+	// WebhookMergeRequestOpen is the action when a merge request is opened.
+	WebhookMergeRequestOpen WebhookMergeRequestAction = "open"
+	// WebhookMergeRequestReopen is the action when a merge request is reopened.
+	WebhookMergeRequestReopen WebhookMergeRequestAction = "reopen"
+	// WebhookMergeRequestUpdate is the action when a merge request is updated,
+	// which includes pushing new commits to the source branch.
+	WebhookMergeRequestUpdate WebhookMergeRequestAction = "update"
 )
 
 // WebhookInfo represents a GitLab API response for the webhook information.
@@ -51,12 +68,9 @@ type WebhookCreate struct {
 	SecretToken string `json:"token"`
 	// This is set to true
 	PushEvents bool `json:"push_events"`
-	// For now, there is no native dry run DDL support in mysql/postgres. One may wonder if we could wrap the DDL
-	// in a transaction and just not commit at the end, unfortunately there are side effects which are hard to control.
-	// See https://www.postgresql.org/message-id/CAMsr%2BYGiYQ7PYvYR2Voio37YdCpp79j5S%2BcmgVJMOLM2LnRQcA%40mail.gmail.com
-	// So we can't possibly display useful info when reviewing a MR, thus we don't enable this event.
-	// Saying that, delivering a souding dry run solution would be great and hopefully we can achieve that one day.
-	// MergeRequestsEvents  bool   `json:"merge_requests_events"`
+	// There is no native dry run DDL support in mysql/postgres, so we can't tell whether the migration in a MR would
+	// succeed. But we can still run the SQL review against the migration files and report the advices on the MR.
+	MergeRequestsEvents    bool   `json:"merge_requests_events"`
 	PushEventsBranchFilter string `json:"push_events_branch_filter"`
 	EnableSSLVerification  bool   `json:"enable_ssl_verification"`
 }
@@ -64,6 +78,7 @@ type WebhookCreate struct {
 // WebhookUpdate represents a GitLab API request for updating a new webhook.
 type WebhookUpdate struct {
 	URL                    string `json:"url"`
+	MergeRequestsEvents    bool   `json:"merge_requests_events"`
 	PushEventsBranchFilter string `json:"push_events_branch_filter"`
 }
 
@@ -82,13 +97,14 @@ type WebhookCommitAuthor struct {
 
 // WebhookCommit is the API message for webhook commit.
 type WebhookCommit struct {
-	ID        string              `json:"id"`
-	Title     string              `json:"title"`
-	Message   string              `json:"message"`
-	Timestamp string              `json:"timestamp"`
-	URL       string              `json:"url"`
-	Author    WebhookCommitAuthor `json:"author"`
-	AddedList []string            `json:"added"`
+	ID           string              `json:"id"`
+	Title        string              `json:"title"`
+	Message      string              `json:"message"`
+	Timestamp    string              `json:"timestamp"`
+	URL          string              `json:"url"`
+	Author       WebhookCommitAuthor `json:"author"`
+	AddedList    []string            `json:"added"`
+	ModifiedList []string            `json:"modified"`
 }
 
 // WebhookPushEvent is the API message for webhook push event.
@@ -100,6 +116,111 @@ type WebhookPushEvent struct {
 	CommitList []WebhookCommit `json:"commits"`
 }
 
+// WebhookMergeRequestCommit is the API message for the commit of webhook merge request.
+type WebhookMergeRequestCommit struct {
+	ID string `json:"id"`
+}
+
+// WebhookMergeRequestAttributes is the API message for the attributes of webhook merge request.
+type WebhookMergeRequestAttributes struct {
+	IID          int                       `json:"iid"`
+	Title        string                    `json:"title"`
+	URL          string                    `json:"url"`
+	SourceBranch string                    `json:"source_branch"`
+	TargetBranch string                    `json:"target_branch"`
+	Action       WebhookMergeRequestAction `json:"action"`
+	// OldRev is only present on the "update" action when new commits are pushed.
+	OldRev     string                    `json:"oldrev"`
+	LastCommit WebhookMergeRequestCommit `json:"last_commit"`
+}
+
+// WebhookMergeRequestEvent is the API message for webhook merge request event.
+type WebhookMergeRequestEvent struct {
+	ObjectKind       WebhookType                   `json:"object_kind"`
+	Project          WebhookProject                `json:"project"`
+	ObjectAttributes WebhookMergeRequestAttributes `json:"object_attributes"`
+}
+
+// MergeRequestDiffRefs represents the GitLab API message for the SHAs of the merge request diff.
+type MergeRequestDiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+// MergeRequestChange represents a GitLab API response for a file changed by a merge request.
+type MergeRequestChange struct {
+	NewPath     string `json:"new_path"`
+	NewFile     bool   `json:"new_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// MergeRequestChanges represents a GitLab API response for a merge request with its changes.
+type MergeRequestChanges struct {
+	IID      int                  `json:"iid"`
+	DiffRefs MergeRequestDiffRefs `json:"diff_refs"`
+	Changes  []MergeRequestChange `json:"changes"`
+}
+
+// MergeRequestDiscussionPosition represents a GitLab API request for the position of a merge request discussion.
+type MergeRequestDiscussionPosition struct {
+	PositionType string `json:"position_type"`
+	BaseSHA      string `json:"base_sha"`
+	StartSHA     string `json:"start_sha"`
+	HeadSHA      string `json:"head_sha"`
+	NewPath      string `json:"new_path"`
+	NewLine      int    `json:"new_line"`
+}
+
+// MergeRequestDiscussionCreate represents a GitLab API request for creating a merge request discussion.
+// The discussion is a general comment on the merge request if the position is nil.
+type MergeRequestDiscussionCreate struct {
+	Body     string                          `json:"body"`
+	Position *MergeRequestDiscussionPosition `json:"position,omitempty"`
+}
+
+// CommitStatusCreate represents a GitLab API request for creating a commit status.
+type CommitStatusCreate struct {
+	// State is one of "pending", "running", "success", "failed" and "canceled".
+	State       string `json:"state"`
+	Name        string `json:"name"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description"`
+}
+
+// Branch represents a GitLab API response for a branch.
+type Branch struct {
+	Name   string       `json:"name"`
+	Commit BranchCommit `json:"commit"`
+}
+
+// BranchCommit represents a GitLab API response for the head commit of a branch.
+type BranchCommit struct {
+	ID string `json:"id"`
+}
+
+// BranchCreate represents a GitLab API request for creating a branch.
+type BranchCreate struct {
+	Branch string `json:"branch"`
+	// Ref is the branch name or commit SHA to create the branch from.
+	Ref string `json:"ref"`
+}
+
+// MergeRequestCreate represents a GitLab API request for creating a merge request.
+type MergeRequestCreate struct {
+	Title              string `json:"title"`
+	Description        string `json:"description"`
+	SourceBranch       string `json:"source_branch"`
+	TargetBranch       string `json:"target_branch"`
+	RemoveSourceBranch bool   `json:"remove_source_branch"`
+}
+
+// MergeRequest represents a GitLab API response for a merge request.
+type MergeRequest struct {
+	IID    int    `json:"iid"`
+	WebURL string `json:"web_url"`
+}
+
 // Commit is the API message for commit.
 type Commit struct {
 	ID         string `json:"id"`
@@ -892,6 +1013,397 @@ func (p *Provider) DeleteWebhook(ctx context.Context, oauthCtx common.OauthConte
 	return nil
 }
 
+// ListPullRequestFiles lists the files changed by the merge request.
+//
+// Docs: https://docs.gitlab.com/ee/api/merge_requests.html#get-single-merge-request-changes
+func (p *Provider) ListPullRequestFiles(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string) ([]*vcs.PullRequestFile, error) {
+	mergeRequest, err := p.fetchMergeRequestChanges(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileList []*vcs.PullRequestFile
+	for _, change := range mergeRequest.Changes {
+		if change.DeletedFile {
+			continue
+		}
+		fileList = append(fileList,
+			&vcs.PullRequestFile{
+				Path:  change.NewPath,
+				IsNew: change.NewFile,
+			},
+		)
+	}
+	return fileList, nil
+}
+
+// CreatePullRequestReview creates a discussion for each inline comment on the
+// merge request, along with a general discussion for the summary body.
+//
+// Docs: https://docs.gitlab.com/ee/api/discussions.html#create-new-merge-request-thread
+func (p *Provider) CreatePullRequestReview(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID, commitID, body string, commentList []*vcs.ReviewComment) error {
+	// The inline comment must be positioned with the SHAs of the merge request diff.
+	mergeRequest, err := p.fetchMergeRequestChanges(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID)
+	if err != nil {
+		return err
+	}
+	headSHA := mergeRequest.DiffRefs.HeadSHA
+	if headSHA == "" {
+		headSHA = commitID
+	}
+
+	for _, comment := range commentList {
+		if err := p.createMergeRequestDiscussion(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID,
+			&MergeRequestDiscussionCreate{
+				Body: comment.Body,
+				Position: &MergeRequestDiscussionPosition{
+					PositionType: "text",
+					BaseSHA:      mergeRequest.DiffRefs.BaseSHA,
+					StartSHA:     mergeRequest.DiffRefs.StartSHA,
+					HeadSHA:      headSHA,
+					NewPath:      comment.Path,
+					NewLine:      comment.Line,
+				},
+			},
+		); err != nil {
+			return err
+		}
+	}
+	return p.createMergeRequestDiscussion(ctx, oauthCtx, instanceURL, repositoryID, pullRequestID, &MergeRequestDiscussionCreate{Body: body})
+}
+
+// SetCommitStatus sets the status of the commit.
+//
+// Docs: https://docs.gitlab.com/ee/api/commits.html#set-the-pipeline-status-of-a-commit
+func (p *Provider) SetCommitStatus(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string, status *vcs.CommitStatus) error {
+	state := string(status.State)
+	// GitLab has no separate state for errors.
+	if status.State == vcs.CommitStateFailure || status.State == vcs.CommitStateError {
+		state = "failed"
+	}
+	payload, err := json.Marshal(
+		CommitStatusCreate{
+			State:       state,
+			Name:        status.Context,
+			TargetURL:   status.TargetURL,
+			Description: status.Description,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal commit status create")
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/statuses/%s", p.APIURL(instanceURL), repositoryID, commitID)
+	code, body, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(payload),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to set commit status through URL %s", url)
+	} else if code >= 300 {
+		return fmt.Errorf("failed to set commit status through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+	return nil
+}
+
+// GetBranch gets the branch along with its head commit.
+//
+// Docs: https://docs.gitlab.com/ee/api/branches.html#get-single-repository-branch
+func (p *Provider) GetBranch(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, branchName string) (*vcs.BranchInfo, error) {
+	url := fmt.Sprintf("%s/projects/%s/repository/branches/%s", p.APIURL(instanceURL), repositoryID, url.QueryEscape(branchName))
+	code, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to get branch from URL %s", url)
+	} else if code >= 300 {
+		return nil, fmt.Errorf("failed to get branch from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var branch Branch
+	if err := json.Unmarshal([]byte(body), &branch); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+	return &vcs.BranchInfo{
+		Name:         branch.Name,
+		LastCommitID: branch.Commit.ID,
+	}, nil
+}
+
+// CreateBranch creates the branch pointing to its last commit.
+//
+// Docs: https://docs.gitlab.com/ee/api/branches.html#create-repository-branch
+func (p *Provider) CreateBranch(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, branch *vcs.BranchInfo) error {
+	payload, err := json.Marshal(
+		BranchCreate{
+			Branch: branch.Name,
+			Ref:    branch.LastCommitID,
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "marshal branch create")
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/repository/branches", p.APIURL(instanceURL), repositoryID)
+	code, body, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(payload),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create branch through URL %s", url)
+	} else if code == http.StatusBadRequest && strings.Contains(body, "already exists") {
+		return common.Errorf(common.Conflict, "branch %q already exists", branch.Name)
+	} else if code >= 300 {
+		return fmt.Errorf("failed to create branch through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+	return nil
+}
+
+// CreatePullRequest creates the merge request, whose source branch is removed
+// once merged.
+//
+// Docs: https://docs.gitlab.com/ee/api/merge_requests.html#create-mr
+func (p *Provider) CreatePullRequest(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, pullRequestCreate *vcs.PullRequestCreate) (*vcs.PullRequest, error) {
+	payload, err := json.Marshal(
+		MergeRequestCreate{
+			Title:              pullRequestCreate.Title,
+			Description:        pullRequestCreate.Body,
+			SourceBranch:       pullRequestCreate.Head,
+			TargetBranch:       pullRequestCreate.Base,
+			RemoveSourceBranch: true,
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal merge request create")
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/merge_requests", p.APIURL(instanceURL), repositoryID)
+	code, body, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(payload),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to create merge request through URL %s", url)
+	} else if code == http.StatusConflict {
+		return nil, common.Errorf(common.Conflict, "merge request from branch %q to %q already exists", pullRequestCreate.Head, pullRequestCreate.Base)
+	} else if code >= 300 {
+		return nil, fmt.Errorf("failed to create merge request through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var mergeRequest MergeRequest
+	if err := json.Unmarshal([]byte(body), &mergeRequest); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+	return &vcs.PullRequest{
+		URL: mergeRequest.WebURL,
+	}, nil
+}
+
+// FindOpenPullRequest finds the open merge request from the source branch to the target branch.
+//
+// Docs: https://docs.gitlab.com/ee/api/merge_requests.html#list-project-merge-requests
+func (p *Provider) FindOpenPullRequest(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, head, base string) (*vcs.PullRequest, error) {
+	url := fmt.Sprintf("%s/projects/%s/merge_requests?state=opened&source_branch=%s&target_branch=%s", p.APIURL(instanceURL), repositoryID, url.QueryEscape(head), url.QueryEscape(base))
+	code, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to list merge requests from URL %s", url)
+	} else if code >= 300 {
+		return nil, fmt.Errorf("failed to list merge requests from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	var mergeRequestList []MergeRequest
+	if err := json.Unmarshal([]byte(body), &mergeRequestList); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+	if len(mergeRequestList) == 0 {
+		return nil, nil
+	}
+	return &vcs.PullRequest{
+		URL: mergeRequestList[0].WebURL,
+	}, nil
+}
+
+// fetchMergeRequestChanges fetches the merge request along with its changes.
+func (p *Provider) fetchMergeRequestChanges(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, mergeRequestIID string) (*MergeRequestChanges, error) {
+	url := fmt.Sprintf("%s/projects/%s/merge_requests/%s/changes", p.APIURL(instanceURL), repositoryID, mergeRequestIID)
+	code, body, err := oauth.Get(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return nil, common.Errorf(common.NotFound, "failed to fetch merge request changes from URL %s", url)
+	} else if code >= 300 {
+		return nil, fmt.Errorf("failed to read merge request changes from URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+
+	mergeRequest := &MergeRequestChanges{}
+	if err := json.Unmarshal([]byte(body), mergeRequest); err != nil {
+		return nil, errors.Wrap(err, "unmarshal body")
+	}
+	return mergeRequest, nil
+}
+
+// createMergeRequestDiscussion creates a discussion on the merge request.
+func (p *Provider) createMergeRequestDiscussion(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, mergeRequestIID string, discussionCreate *MergeRequestDiscussionCreate) error {
+	payload, err := json.Marshal(discussionCreate)
+	if err != nil {
+		return errors.Wrap(err, "marshal discussion create")
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/merge_requests/%s/discussions", p.APIURL(instanceURL), repositoryID, mergeRequestIID)
+	code, body, err := oauth.Post(
+		ctx,
+		p.client,
+		url,
+		&oauthCtx.AccessToken,
+		bytes.NewReader(payload),
+		tokenRefresher(
+			instanceURL,
+			oauthContext{
+				ClientID:     oauthCtx.ClientID,
+				ClientSecret: oauthCtx.ClientSecret,
+				RefreshToken: oauthCtx.RefreshToken,
+			},
+			oauthCtx.Refresher,
+		),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "POST %s", url)
+	}
+
+	if code == http.StatusNotFound {
+		return common.Errorf(common.NotFound, "failed to create merge request discussion through URL %s", url)
+	} else if code >= 300 {
+		return fmt.Errorf("failed to create merge request discussion through URL %s, status code: %d, body: %s",
+			url,
+			code,
+			body,
+		)
+	}
+	return nil
+}
+
 // readFile reads the given file in the repository.
 //
 // TODO: The same GitLab API endpoint supports using the HEAD request to only
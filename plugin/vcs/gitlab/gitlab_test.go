@@ -2,6 +2,7 @@ package gitlab
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"strings"
@@ -808,3 +809,378 @@ func TestOAuth_RefreshToken(t *testing.T) {
 	assert.Equal(t, "de6780bc506a0446309bd9362820ba8aed28aa506c71eedbe1c5c4f9dd350e54", token)
 	assert.True(t, calledRefresher)
 }
+
+func TestProvider_ListPullRequestFiles(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v4/projects/1/merge_requests/7/changes", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							// Example response derived from https://docs.gitlab.com/ee/api/merge_requests.html#get-single-merge-request-changes
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "id": 21,
+  "iid": 7,
+  "diff_refs": {
+    "base_sha": "1111111111111111111111111111111111111111",
+    "head_sha": "3333333333333333333333333333333333333333",
+    "start_sha": "2222222222222222222222222222222222222222"
+  },
+  "changes": [
+    {
+      "old_path": "bytebase/prod/db1__202207280000__migrate__add_index.sql",
+      "new_path": "bytebase/prod/db1__202207280000__migrate__add_index.sql",
+      "new_file": true,
+      "renamed_file": false,
+      "deleted_file": false
+    },
+    {
+      "old_path": "README.md",
+      "new_path": "README.md",
+      "new_file": false,
+      "renamed_file": false,
+      "deleted_file": false
+    },
+    {
+      "old_path": "bytebase/prod/db1__202207270000__migrate__init.sql",
+      "new_path": "bytebase/prod/db1__202207270000__migrate__init.sql",
+      "new_file": false,
+      "renamed_file": false,
+      "deleted_file": true
+    }
+  ]
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	reviewer, ok := p.(vcs.PullRequestReviewer)
+	require.True(t, ok)
+
+	ctx := context.Background()
+	got, err := reviewer.ListPullRequestFiles(ctx, common.OauthContext{}, "", "1", "7")
+	require.NoError(t, err)
+
+	// The deleted file is excluded.
+	want := []*vcs.PullRequestFile{
+		{
+			Path:  "bytebase/prod/db1__202207280000__migrate__add_index.sql",
+			IsNew: true,
+		},
+		{
+			Path:  "README.md",
+			IsNew: false,
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_CreatePullRequestReview(t *testing.T) {
+	var discussionList []MergeRequestDiscussionCreate
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						switch r.URL.Path {
+						case "/api/v4/projects/1/merge_requests/7/changes":
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body: io.NopCloser(strings.NewReader(`
+{
+  "iid": 7,
+  "diff_refs": {
+    "base_sha": "1111111111111111111111111111111111111111",
+    "head_sha": "3333333333333333333333333333333333333333",
+    "start_sha": "2222222222222222222222222222222222222222"
+  },
+  "changes": []
+}
+`)),
+							}, nil
+						case "/api/v4/projects/1/merge_requests/7/discussions":
+							body, err := io.ReadAll(r.Body)
+							require.NoError(t, err)
+							var discussionCreate MergeRequestDiscussionCreate
+							require.NoError(t, json.Unmarshal(body, &discussionCreate))
+							discussionList = append(discussionList, discussionCreate)
+							return &http.Response{
+								StatusCode: http.StatusCreated,
+								Body:       io.NopCloser(strings.NewReader(`{"id": "6a9c1750b37d513a43987b574953fceb50b03ce7"}`)),
+							}, nil
+						}
+						return nil, errors.Errorf("unexpected request path: %s", r.URL.Path)
+					},
+				},
+			},
+		},
+	)
+
+	reviewer, ok := p.(vcs.PullRequestReviewer)
+	require.True(t, ok)
+
+	ctx := context.Background()
+	err := reviewer.CreatePullRequestReview(ctx, common.OauthContext{}, "", "1", "7", "3333333333333333333333333333333333333333", "summary",
+		[]*vcs.ReviewComment{
+			{
+				Path: "bytebase/prod/db1__202207280000__migrate__add_index.sql",
+				Line: 3,
+				Body: "advice",
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	want := []MergeRequestDiscussionCreate{
+		{
+			Body: "advice",
+			Position: &MergeRequestDiscussionPosition{
+				PositionType: "text",
+				BaseSHA:      "1111111111111111111111111111111111111111",
+				StartSHA:     "2222222222222222222222222222222222222222",
+				HeadSHA:      "3333333333333333333333333333333333333333",
+				NewPath:      "bytebase/prod/db1__202207280000__migrate__add_index.sql",
+				NewLine:      3,
+			},
+		},
+		{
+			Body: "summary",
+		},
+	}
+	assert.Equal(t, want, discussionList)
+}
+
+func TestProvider_SetCommitStatus(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v4/projects/1/statuses/3333333333333333333333333333333333333333", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						var statusCreate CommitStatusCreate
+						require.NoError(t, json.Unmarshal(body, &statusCreate))
+						// GitLab has no separate state for errors.
+						assert.Equal(t, "failed", statusCreate.State)
+						assert.Equal(t, "bytebase/sql-review", statusCreate.Name)
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body:       io.NopCloser(strings.NewReader(`{"id": 93}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	reviewer, ok := p.(vcs.PullRequestReviewer)
+	require.True(t, ok)
+
+	ctx := context.Background()
+	err := reviewer.SetCommitStatus(ctx, common.OauthContext{}, "", "1", "3333333333333333333333333333333333333333",
+		&vcs.CommitStatus{
+			State:       vcs.CommitStateError,
+			Context:     "bytebase/sql-review",
+			Description: "Failed to review the migration files",
+		},
+	)
+	require.NoError(t, err)
+}
+
+func TestProvider_GetBranch(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v4/projects/1/repository/branches/main", r.URL.Path)
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "name": "main",
+  "merged": false,
+  "protected": true,
+  "commit": {
+    "id": "7b5c3cc8be40ee161ae89a06bba6229da1032a0c",
+    "short_id": "7b5c3cc"
+  }
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	creator, ok := p.(vcs.PullRequestCreator)
+	require.True(t, ok)
+
+	ctx := context.Background()
+	got, err := creator.GetBranch(ctx, common.OauthContext{}, "", "1", "main")
+	require.NoError(t, err)
+	want := &vcs.BranchInfo{
+		Name:         "main",
+		LastCommitID: "7b5c3cc8be40ee161ae89a06bba6229da1032a0c",
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestProvider_CreateBranch(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v4/projects/1/repository/branches", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						var branchCreate BranchCreate
+						require.NoError(t, json.Unmarshal(body, &branchCreate))
+						want := BranchCreate{
+							Branch: "bytebase/schema-drift/db1",
+							Ref:    "7b5c3cc8be40ee161ae89a06bba6229da1032a0c",
+						}
+						assert.Equal(t, want, branchCreate)
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body:       io.NopCloser(strings.NewReader(`{"name": "bytebase/schema-drift/db1"}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	creator, ok := p.(vcs.PullRequestCreator)
+	require.True(t, ok)
+
+	ctx := context.Background()
+	err := creator.CreateBranch(ctx, common.OauthContext{}, "", "1",
+		&vcs.BranchInfo{
+			Name:         "bytebase/schema-drift/db1",
+			LastCommitID: "7b5c3cc8be40ee161ae89a06bba6229da1032a0c",
+		},
+	)
+	require.NoError(t, err)
+}
+
+func TestProvider_CreatePullRequest(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v4/projects/1/merge_requests", r.URL.Path)
+
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						var mergeRequestCreate MergeRequestCreate
+						require.NoError(t, json.Unmarshal(body, &mergeRequestCreate))
+						want := MergeRequestCreate{
+							Title:              "Reconcile schema drift",
+							Description:        "The schema drifted.",
+							SourceBranch:       "bytebase/schema-drift/db1",
+							TargetBranch:       "main",
+							RemoveSourceBranch: true,
+						}
+						assert.Equal(t, want, mergeRequestCreate)
+						return &http.Response{
+							StatusCode: http.StatusCreated,
+							Body: io.NopCloser(strings.NewReader(`
+{
+  "id": 1,
+  "iid": 12,
+  "web_url": "https://gitlab.example.com/my-group/my-project/merge_requests/12"
+}
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	creator, ok := p.(vcs.PullRequestCreator)
+	require.True(t, ok)
+
+	ctx := context.Background()
+	got, err := creator.CreatePullRequest(ctx, common.OauthContext{}, "", "1",
+		&vcs.PullRequestCreate{
+			Title: "Reconcile schema drift",
+			Body:  "The schema drifted.",
+			Head:  "bytebase/schema-drift/db1",
+			Base:  "main",
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "https://gitlab.example.com/my-group/my-project/merge_requests/12", got.URL)
+}
+
+func TestProvider_FindOpenPullRequest(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						assert.Equal(t, "/api/v4/projects/1/merge_requests", r.URL.Path)
+						assert.Equal(t, "opened", r.URL.Query().Get("state"))
+						assert.Equal(t, "bytebase/schema-drift/prod/db/v1", r.URL.Query().Get("source_branch"))
+						assert.Equal(t, "main", r.URL.Query().Get("target_branch"))
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							// Example response derived from https://docs.gitlab.com/ee/api/merge_requests.html#list-project-merge-requests
+							Body: io.NopCloser(strings.NewReader(`
+[
+  {
+    "id": 1,
+    "iid": 1,
+    "web_url": "http://gitlab.example.com/my-group/my-project/merge_requests/1"
+  }
+]
+`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	got, err := p.(vcs.PullRequestCreator).FindOpenPullRequest(ctx, common.OauthContext{}, "", "1", "bytebase/schema-drift/prod/db/v1", "main")
+	require.NoError(t, err)
+	assert.Equal(t, &vcs.PullRequest{URL: "http://gitlab.example.com/my-group/my-project/merge_requests/1"}, got)
+}
+
+func TestProvider_CreatePullRequestAlreadyExists(t *testing.T) {
+	p := newProvider(
+		vcs.ProviderConfig{
+			Client: &http.Client{
+				Transport: &common.MockRoundTripper{
+					MockRoundTrip: func(r *http.Request) (*http.Response, error) {
+						return &http.Response{
+							StatusCode: http.StatusConflict,
+							Body:       io.NopCloser(strings.NewReader(`{"message":["Another open merge request already exists for this source branch: !1"]}`)),
+						}, nil
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	_, err := p.(vcs.PullRequestCreator).CreatePullRequest(ctx, common.OauthContext{}, "", "1", &vcs.PullRequestCreate{Head: "feature", Base: "main"})
+	require.Error(t, err)
+	assert.Equal(t, common.Conflict, common.ErrorCode(err))
+}
@@ -17,6 +17,12 @@ const (
 	GitLabSelfHost Type = "GITLAB_SELF_HOST"
 	// GitHubCom is the VCS type for GitHub.com.
 	GitHubCom Type = "GITHUB_COM"
+	// GitHubEnterprise is the VCS type for GitHub Enterprise Server.
+	GitHubEnterprise Type = "GITHUB_ENTERPRISE"
+	// Gitea is the VCS type for self-hosted Gitea, which also covers its fork Forgejo.
+	Gitea Type = "GITEA"
+	// Bitbucket is the VCS type for Bitbucket, which covers both Bitbucket Cloud and Bitbucket Data Center.
+	Bitbucket Type = "BITBUCKET"
 )
 
 // OAuthToken is the API message for OAuthToken.
@@ -87,6 +93,66 @@ type PushEvent struct {
 	FileCommit         FileCommit `json:"fileCommit"`
 }
 
+// PullRequestFile is a file changed by a pull request, which is called merge request in GitLab.
+type PullRequestFile struct {
+	Path string
+	// IsNew is true if the file is added by the pull request.
+	IsNew bool
+}
+
+// ReviewComment is an inline review comment on a line of the file changed by a pull request.
+type ReviewComment struct {
+	Path string
+	// Line is the 1-based line number in the new version of the file.
+	Line int
+	Body string
+}
+
+// BranchInfo is the API message for branch info.
+type BranchInfo struct {
+	Name         string
+	LastCommitID string
+}
+
+// PullRequestCreate is the API message for creating a pull request.
+type PullRequestCreate struct {
+	Title string
+	Body  string
+	// Head is the branch where the changes are made.
+	Head string
+	// Base is the branch which the changes are merged into.
+	Base string
+}
+
+// PullRequest is the API message for a pull request.
+type PullRequest struct {
+	// URL is the web URL of the pull request.
+	URL string
+}
+
+// CommitState is the state of a commit status.
+type CommitState string
+
+const (
+	// CommitStatePending is the pending state of a commit status.
+	CommitStatePending CommitState = "pending"
+	// CommitStateSuccess is the success state of a commit status.
+	CommitStateSuccess CommitState = "success"
+	// CommitStateFailure is the failure state of a commit status.
+	CommitStateFailure CommitState = "failure"
+	// CommitStateError is the error state of a commit status.
+	CommitStateError CommitState = "error"
+)
+
+// CommitStatus is the status of a commit reported by an external service, which could be required to pass before merging.
+type CommitStatus struct {
+	State CommitState
+	// Context distinguishes the status from the ones reported by other services.
+	Context     string
+	Description string
+	TargetURL   string
+}
+
 // State is the state of a VCS user account.
 type State string
 
@@ -217,6 +283,41 @@ type Provider interface {
 	DeleteWebhook(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, webhookID string) error
 }
 
+// PullRequestReviewer is the interface for the VCS provider which supports reviewing pull requests.
+type PullRequestReviewer interface {
+	// Lists the files changed by the pull request
+	//
+	// oauthCtx: OAuth context to list the files
+	// instanceURL: VCS instance URL
+	// repositoryID: the repository ID from the external VCS system (note this is NOT the ID of Bytebase's own repository resource)
+	// pullRequestID: the number of the pull request, or the IID of the merge request in GitLab
+	ListPullRequestFiles(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID string) ([]*PullRequestFile, error)
+	// Creates a review with the summary body and the inline comments on the pull request
+	//
+	// commitID: the head commit ID of the pull request which the comments are made against
+	CreatePullRequestReview(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, pullRequestID, commitID, body string, commentList []*ReviewComment) error
+	// Sets the status of the commit
+	SetCommitStatus(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, commitID string, status *CommitStatus) error
+}
+
+// PullRequestCreator is the interface for the VCS provider which supports creating pull requests.
+type PullRequestCreator interface {
+	// Gets the branch along with its last commit
+	//
+	// oauthCtx: OAuth context to get the branch
+	// instanceURL: VCS instance URL
+	// repositoryID: the repository ID from the external VCS system (note this is NOT the ID of Bytebase's own repository resource)
+	// branchName: the name of the branch
+	GetBranch(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, branchName string) (*BranchInfo, error)
+	// Creates a branch pointing to its last commit. A Conflict error is returned if the branch already exists.
+	CreateBranch(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, branch *BranchInfo) error
+	// Creates a pull request, or a merge request in GitLab. A Conflict error is returned if an open pull request from
+	// the head branch to the base branch already exists.
+	CreatePullRequest(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID string, pullRequestCreate *PullRequestCreate) (*PullRequest, error)
+	// Finds the open pull request from the head branch to the base branch, or returns nil if there is none
+	FindOpenPullRequest(ctx context.Context, oauthCtx common.OauthContext, instanceURL, repositoryID, head, base string) (*PullRequest, error)
+}
+
 var (
 	providerMu sync.RWMutex
 	providers  = make(map[Type]providerFunc)
@@ -140,6 +140,10 @@ func (s *AnomalyScanner) Run(ctx context.Context, wg *sync.WaitGroup) {
 					// Sleep 1 second after finishing scanning each instance to avoid database lock error in SQLITE
 					time.Sleep(1 * time.Second)
 				}
+
+				if s.server.feature(api.FeatureMultiTenancy) {
+					s.checkTenantSchemaAnomaly(ctx)
+				}
 			}()
 		case <-ctx.Done(): // if cancel() execute
 			return
@@ -147,6 +151,31 @@ func (s *AnomalyScanner) Run(ctx context.Context, wg *sync.WaitGroup) {
 	}
 }
 
+// checkTenantSchemaAnomaly checks the schema consistency of tenant databases in every tenant mode project.
+func (s *AnomalyScanner) checkTenantSchemaAnomaly(ctx context.Context) {
+	rowStatus := api.Normal
+	projectList, err := s.server.store.FindProject(ctx, &api.ProjectFind{
+		RowStatus: &rowStatus,
+	})
+	if err != nil {
+		log.Error("Failed to retrieve project list", zap.Error(err))
+		return
+	}
+
+	for _, project := range projectList {
+		if project.TenantMode != api.TenantModeTenant {
+			continue
+		}
+		log.Debug("Scan tenant schema anomaly", zap.String("project", project.Name))
+		if _, err := s.server.checkTenantSchemaConsistency(ctx, project); err != nil {
+			log.Error("Failed to check tenant schema consistency",
+				zap.String("project", project.Name),
+				zap.String("type", string(api.AnomalyDatabaseSchemaInconsistency)),
+				zap.Error(err))
+		}
+	}
+}
+
 func (s *AnomalyScanner) checkInstanceAnomaly(ctx context.Context, instance *api.Instance) {
 	driver, err := getAdminDatabaseDriver(ctx, instance, "", s.server.pgInstanceDir)
 
@@ -322,6 +351,15 @@ func (s *AnomalyScanner) checkDatabaseAnomaly(ctx context.Context, instance *api
 					Expect:  list[0].Schema,
 					Actual:  schemaBuf.String(),
 				}
+				// Failing to open the pull request shouldn't stop recording the drift, and it's retried in the next scan.
+				pullRequestURL, err := s.server.createSchemaDriftPullRequest(ctx, instance, database, &anomalyPayload)
+				if err != nil {
+					log.Error("Failed to open pull request for schema drift",
+						zap.String("instance", instance.Name),
+						zap.String("database", database.Name),
+						zap.Error(err))
+				}
+				anomalyPayload.PullRequestURL = pullRequestURL
 				payload, err := json.Marshal(anomalyPayload)
 				if err != nil {
 					log.Error("Failed to marshal anomaly payload",
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+// validateBranchEnvironmentList validates the branch environment list of the repository linked to the project,
+// and returns the normalized JSON encoded list to be stored. An *echo.HTTPError is returned if the list is invalid.
+func (s *Server) validateBranchEnvironmentList(ctx context.Context, project *api.Project, branchEnvironmentList string) (string, error) {
+	list, err := api.ParseBranchEnvironmentList(branchEnvironmentList)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid branch environment list: %s", err.Error()))
+	}
+	// We don't take environment for tenant mode project because the databases needing schema update are determined by database name and deployment configuration.
+	if len(list) > 0 && project.TenantMode == api.TenantModeTenant {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "Branch environment list isn't supported for tenant mode project")
+	}
+	for _, branchEnvironment := range list {
+		environment, err := s.store.GetEnvironmentByID(ctx, branchEnvironment.EnvironmentID)
+		if err != nil {
+			return "", echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch environment ID: %d", branchEnvironment.EnvironmentID)).SetInternal(err)
+		}
+		if environment == nil || environment.RowStatus == api.Archived {
+			return "", echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Environment ID %d for branch filter %q not found", branchEnvironment.EnvironmentID, branchEnvironment.BranchFilter))
+		}
+	}
+	if list == nil {
+		list = []*api.BranchEnvironment{}
+	}
+	bytes, err := json.Marshal(list)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusInternalServerError, "Failed to marshal branch environment list").SetInternal(err)
+	}
+	return string(bytes), nil
+}
+
+// webhookBranchFilter returns the branch filter of the webhook created in the VCS. If the repository maps the
+// branches to the environments, the webhook receives the push events of all branches, and we match the branch
+// against the branch environment list ourselves since the VCS only supports a single branch filter.
+func webhookBranchFilter(branchFilter, branchEnvironmentList string) string {
+	if isBranchEnvironmentListSet(branchEnvironmentList) {
+		return ""
+	}
+	return branchFilter
+}
+
+// hasBranchEnvironment returns true if the repository maps the branches to the environments.
+func hasBranchEnvironment(repo *api.Repository) bool {
+	return isBranchEnvironmentListSet(repo.BranchEnvironmentList)
+}
+
+func isBranchEnvironmentListSet(branchEnvironmentList string) bool {
+	list, err := api.ParseBranchEnvironmentList(branchEnvironmentList)
+	return err == nil && len(list) > 0
+}
+
+// matchBranchEnvironment returns the first branch environment whose branch filter matches the branch, or nil if none matches.
+func matchBranchEnvironment(branchEnvironmentList []*api.BranchEnvironment, branch string) *api.BranchEnvironment {
+	for _, branchEnvironment := range branchEnvironmentList {
+		if matchBranchFilter(branchEnvironment.BranchFilter, branch) {
+			return branchEnvironment
+		}
+	}
+	return nil
+}
+
+// getBranchEnvironment returns the environment the branch is mapped to by the repository, or nil if the repository
+// doesn't map the branches to the environments. A NotFound error is returned if the branch isn't mapped.
+func (s *Server) getBranchEnvironment(ctx context.Context, repo *api.Repository, branch string) (*api.Environment, error) {
+	list, err := api.ParseBranchEnvironmentList(repo.BranchEnvironmentList)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	branchEnvironment := matchBranchEnvironment(list, branch)
+	if branchEnvironment == nil {
+		return nil, common.Errorf(common.NotFound, "branch %q isn't mapped to any environment", branch)
+	}
+	environment, err := s.store.GetEnvironmentByID(ctx, branchEnvironment.EnvironmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment ID %d mapped by branch %q, error: %w", branchEnvironment.EnvironmentID, branch, err)
+	}
+	if environment == nil {
+		return nil, common.Errorf(common.NotFound, "environment ID %d mapped by branch %q not found", branchEnvironment.EnvironmentID, branch)
+	}
+	return environment, nil
+}
+
+// getEnvironmentBranch returns the branch holding the migration files applied to the databases in the environment.
+// It's the branch mapped to the environment if the repository maps the branches to the environments, or the branch
+// filter otherwise. An empty string is returned if the branch filter contains wildcards, which isn't a branch name.
+func getEnvironmentBranch(repo *api.Repository, environmentID int) (string, error) {
+	list, err := api.ParseBranchEnvironmentList(repo.BranchEnvironmentList)
+	if err != nil {
+		return "", err
+	}
+	if len(list) == 0 {
+		if isBranchName(repo.BranchFilter) {
+			return repo.BranchFilter, nil
+		}
+		return "", nil
+	}
+	for _, branchEnvironment := range list {
+		if branchEnvironment.EnvironmentID == environmentID && isBranchName(branchEnvironment.BranchFilter) {
+			return branchEnvironment.BranchFilter, nil
+		}
+	}
+	return "", nil
+}
+
+// isBranchName returns true if the branch filter is a branch name without wildcards.
+func isBranchName(branchFilter string) bool {
+	return branchFilter != "" && !strings.ContainsAny(branchFilter, `*?[\`)
+}
+
+// filterAppliedMigrationDatabaseList returns the databases which haven't applied the migration version yet. It's used
+// when promoting the migration files to the next environment by merging the branch, where the migration files
+// applied before are pushed again along with the new ones. The databases having an open issue to apply the version
+// are excluded as well, so that pushing the same file again doesn't create a duplicate issue.
+func (s *Server) filterAppliedMigrationDatabaseList(ctx context.Context, repo *api.Repository, databaseList []*api.Database, version string) ([]*api.Database, error) {
+	var filteredDatabaseList []*api.Database
+	for _, database := range databaseList {
+		applied, err := s.isMigrationVersionApplied(ctx, database, version)
+		if err != nil {
+			return nil, err
+		}
+		if applied {
+			continue
+		}
+		pending, err := s.isMigrationVersionPending(ctx, repo, database, version)
+		if err != nil {
+			return nil, err
+		}
+		if !pending {
+			filteredDatabaseList = append(filteredDatabaseList, database)
+		}
+	}
+	return filteredDatabaseList, nil
+}
+
+func (s *Server) isMigrationVersionApplied(ctx context.Context, database *api.Database, version string) (bool, error) {
+	history, err := s.getAppliedMigrationHistory(ctx, database, version)
+	if err != nil {
+		return false, err
+	}
+	return history != nil, nil
+}
+
+// isMigrationVersionPending returns true if the database has an unfinished migration task of the version in an open pipeline.
+func (s *Server) isMigrationVersionPending(ctx context.Context, repo *api.Repository, database *api.Database, version string) (bool, error) {
+	taskStatusList := []api.TaskStatus{api.TaskPendingApproval, api.TaskPending, api.TaskRunning, api.TaskFailed}
+	taskList, err := s.store.FindTask(ctx, &api.TaskFind{DatabaseID: &database.ID, StatusList: &taskStatusList}, true /* returnOnErr */)
+	if err != nil {
+		return false, fmt.Errorf("failed to find the unfinished tasks of database %q, error: %w", database.Name, err)
+	}
+	for _, task := range taskList {
+		if getTaskMigrationVersion(repo, task) != version {
+			continue
+		}
+		pipeline, err := s.store.GetPipelineByID(ctx, task.PipelineID)
+		if err != nil {
+			return false, fmt.Errorf("failed to find the pipeline of task %d, error: %w", task.ID, err)
+		}
+		if pipeline != nil && pipeline.Status == api.PipelineOpen {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getTaskMigrationVersion returns the migration version the task records in the migration history, which is parsed
+// from the migration file for the task created by the VCS push event, or empty if the task isn't a migration task.
+func getTaskMigrationVersion(repo *api.Repository, task *api.Task) string {
+	switch task.Type {
+	case api.TaskDatabaseSchemaUpdate, api.TaskDatabaseSchemaUpdateGhostSync, api.TaskDatabaseDataUpdate:
+	default:
+		return ""
+	}
+	payload := &struct {
+		MigrationType db.MigrationType `json:"migrationType,omitempty"`
+		SchemaVersion string           `json:"schemaVersion,omitempty"`
+		VCSPushEvent  *vcs.PushEvent   `json:"pushEvent,omitempty"`
+	}{}
+	if err := json.Unmarshal([]byte(task.Payload), payload); err != nil {
+		return ""
+	}
+	if payload.VCSPushEvent == nil || payload.MigrationType == db.MigrateSDL {
+		return payload.SchemaVersion
+	}
+	mi, err := db.ParseMigrationInfo(
+		payload.VCSPushEvent.FileCommit.Added,
+		filepath.Join(payload.VCSPushEvent.BaseDirectory, repo.FilePathTemplate),
+	)
+	if err != nil {
+		return ""
+	}
+	return mi.Version
+}
+
+// getBranchFromRef returns the branch name from the full ref name, e.g. "refs/heads/main".
+func getBranchFromRef(ref string) string {
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
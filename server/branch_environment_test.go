@@ -0,0 +1,210 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+func TestMatchBranchEnvironment(t *testing.T) {
+	list := []*api.BranchEnvironment{
+		{BranchFilter: "develop", EnvironmentID: 101},
+		{BranchFilter: "release/*", EnvironmentID: 102},
+		{BranchFilter: "main", EnvironmentID: 103},
+		{BranchFilter: "*", EnvironmentID: 104},
+	}
+	tests := []struct {
+		branch string
+		want   int
+	}{
+		{"develop", 101},
+		{"release/1.2", 102},
+		{"main", 103},
+		// The first matching branch filter wins.
+		{"feature", 104},
+		// The glob doesn't match the path separator.
+		{"feature/foo", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.branch, func(t *testing.T) {
+			got := matchBranchEnvironment(list, test.branch)
+			if test.want == 0 {
+				assert.Nil(t, got)
+				return
+			}
+			if assert.NotNil(t, got) {
+				assert.Equal(t, test.want, got.EnvironmentID)
+			}
+		})
+	}
+}
+
+func TestWebhookBranchFilter(t *testing.T) {
+	assert.Equal(t, "main", webhookBranchFilter("main", ""))
+	assert.Equal(t, "main", webhookBranchFilter("main", "[]"))
+	assert.Equal(t, "", webhookBranchFilter("main", `[{"branchFilter":"develop","environmentId":101}]`))
+}
+
+func TestDedupPushEventFileList(t *testing.T) {
+	newFile := func(file, commitID string, createdTs int64) *api.PushEventFile {
+		return &api.PushEventFile{
+			PushEvent: vcs.PushEvent{
+				FileCommit: vcs.FileCommit{
+					ID:        commitID,
+					CreatedTs: createdTs,
+				},
+			},
+			File: file,
+		}
+	}
+	fileList := []*api.PushEventFile{
+		newFile("v1__db.sql", "c1", 100),
+		newFile("v2__db.sql", "c2", 200),
+		// The merge commit adds the same file again.
+		newFile("v1__db.sql", "c3", 300),
+	}
+
+	got := dedupPushEventFileList(fileList)
+	var gotCommitIDList []string
+	for _, file := range got {
+		gotCommitIDList = append(gotCommitIDList, file.File+"@"+file.PushEvent.FileCommit.ID)
+	}
+	assert.Equal(t, []string{"v1__db.sql@c3", "v2__db.sql@c2"}, gotCommitIDList)
+}
+
+func TestGetEnvironmentBranch(t *testing.T) {
+	tests := []struct {
+		name                  string
+		branchFilter          string
+		branchEnvironmentList string
+		environmentID         int
+		want                  string
+	}{
+		{
+			name:         "branch filter",
+			branchFilter: "main",
+			want:         "main",
+		},
+		{
+			name:         "branch filter with wildcards",
+			branchFilter: "release/*",
+			want:         "",
+		},
+		{
+			name:                  "branch mapped to the environment",
+			branchFilter:          "main",
+			branchEnvironmentList: `[{"branchFilter":"develop","environmentId":101},{"branchFilter":"main","environmentId":102}]`,
+			environmentID:         102,
+			want:                  "main",
+		},
+		{
+			name:                  "environment not mapped",
+			branchFilter:          "main",
+			branchEnvironmentList: `[{"branchFilter":"develop","environmentId":101}]`,
+			environmentID:         102,
+			want:                  "",
+		},
+		{
+			name:                  "branch with wildcards mapped to the environment",
+			branchEnvironmentList: `[{"branchFilter":"release/*","environmentId":102},{"branchFilter":"main","environmentId":102}]`,
+			environmentID:         102,
+			want:                  "main",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := getEnvironmentBranch(&api.Repository{
+				BranchFilter:          test.branchFilter,
+				BranchEnvironmentList: test.branchEnvironmentList,
+			}, test.environmentID)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestGetTaskMigrationVersion(t *testing.T) {
+	repo := &api.Repository{FilePathTemplate: "{{ENV_NAME}}/{{DB_NAME}}__{{VERSION}}__{{TYPE}}__{{DESCRIPTION}}.sql"}
+	newPayload := func(payload interface{}) string {
+		bytes, err := json.Marshal(payload)
+		assert.NoError(t, err)
+		return string(bytes)
+	}
+	pushEvent := &vcs.PushEvent{
+		BaseDirectory: "bytebase",
+		FileCommit: vcs.FileCommit{
+			Added: "bytebase/prod/db__v1.2__migrate__add_column.sql",
+		},
+	}
+	tests := []struct {
+		name string
+		task *api.Task
+		want string
+	}{
+		{
+			name: "schema update from the VCS push event",
+			task: &api.Task{
+				Type: api.TaskDatabaseSchemaUpdate,
+				Payload: newPayload(api.TaskDatabaseSchemaUpdatePayload{
+					MigrationType: db.Migrate,
+					SchemaVersion: "20220802000000",
+					VCSPushEvent:  pushEvent,
+				}),
+			},
+			want: "v1.2",
+		},
+		{
+			name: "data update from the VCS push event",
+			task: &api.Task{
+				Type: api.TaskDatabaseDataUpdate,
+				Payload: newPayload(api.TaskDatabaseDataUpdatePayload{
+					SchemaVersion: "20220802000000",
+					VCSPushEvent:  pushEvent,
+				}),
+			},
+			want: "v1.2",
+		},
+		{
+			name: "declarative schema migration",
+			task: &api.Task{
+				Type: api.TaskDatabaseSchemaUpdate,
+				Payload: newPayload(api.TaskDatabaseSchemaUpdatePayload{
+					MigrationType: db.MigrateSDL,
+					SchemaVersion: "20220802000000",
+					VCSPushEvent:  pushEvent,
+				}),
+			},
+			want: "20220802000000",
+		},
+		{
+			name: "schema update from the UI",
+			task: &api.Task{
+				Type: api.TaskDatabaseSchemaUpdate,
+				Payload: newPayload(api.TaskDatabaseSchemaUpdatePayload{
+					MigrationType: db.Migrate,
+					SchemaVersion: "20220802000000",
+				}),
+			},
+			want: "20220802000000",
+		},
+		{
+			name: "not a migration task",
+			task: &api.Task{
+				Type:    api.TaskDatabaseBackup,
+				Payload: "{}",
+			},
+			want: "",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, getTaskMigrationVersion(repo, test.task))
+		})
+	}
+}
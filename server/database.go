@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/google/jsonapi"
 	"github.com/labstack/echo/v4"
@@ -775,6 +777,169 @@ func (s *Server) registerDatabaseRoutes(g *echo.Group) {
 		}
 		return nil
 	})
+
+	g.POST("/database/:id/migration/rollback", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		rollback := &api.MigrationRollback{}
+		if err := jsonapi.UnmarshalPayload(c.Request().Body, rollback); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Malformed rollback migration request").SetInternal(err)
+		}
+		if rollback.Version == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Rollback migration request missing version")
+		}
+
+		database, err := s.store.GetDatabase(ctx, &api.DatabaseFind{ID: &id})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
+		}
+		if database == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database not found with ID %d", id))
+		}
+
+		driver, err := getAdminDatabaseDriver(ctx, database.Instance, "", s.pgInstanceDir)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch migration history for database %q", database.Name)).SetInternal(err)
+		}
+		defer driver.Close(ctx)
+		historyList, err := driver.FindMigrationHistoryList(ctx, &db.MigrationHistoryFind{Database: &database.Name})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch migration history list").SetInternal(err)
+		}
+
+		statement, skippedVersionList, err := getMigrationRollbackStatement(historyList, rollback.Version)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+
+		description := fmt.Sprintf("Roll back database %q to version %s by applying the down statements of the later migrations in the reverse order.", database.Name, rollback.Version)
+		if len(skippedVersionList) > 0 {
+			description += fmt.Sprintf(" The data changes of version %s are not rolled back.", strings.Join(skippedVersionList, ", "))
+		}
+		createContext, err := json.Marshal(&api.UpdateSchemaContext{
+			MigrationType: db.Migrate,
+			DetailList: []*api.UpdateSchemaDetail{
+				{
+					DatabaseID: database.ID,
+					Statement:  statement,
+				},
+			},
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to marshal rollback issue create context").SetInternal(err)
+		}
+		issue, err := s.createIssue(ctx, &api.IssueCreate{
+			ProjectID:     database.ProjectID,
+			Name:          fmt.Sprintf("Roll back %q to version %s", database.Name, rollback.Version),
+			Type:          api.IssueDatabaseSchemaUpdate,
+			Description:   description,
+			AssigneeID:    rollback.AssigneeID,
+			CreateContext: string(createContext),
+		}, c.Get(getPrincipalIDContextKey()).(int))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create rollback issue").SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, issue); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to marshal create rollback issue response").SetInternal(err)
+		}
+		return nil
+	})
+
+	g.POST("/database/:id/migration/import", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		historyImport := &api.MigrationHistoryImport{}
+		if err := jsonapi.UnmarshalPayload(c.Request().Body, historyImport); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Malformed import migration history request").SetInternal(err)
+		}
+		switch historyImport.Source {
+		case db.FLYWAY, db.LIQUIBASE, db.GolangMigrate:
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid migration history source %q, should be one of %s, %s, %s", historyImport.Source, db.FLYWAY, db.LIQUIBASE, db.GolangMigrate))
+		}
+
+		database, err := s.store.GetDatabase(ctx, &api.DatabaseFind{ID: &id})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
+		}
+		if database == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database not found with ID %d", id))
+		}
+		principalID := c.Get(getPrincipalIDContextKey()).(int)
+		principal, err := s.store.GetPrincipalByID(ctx, principalID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch principal ID: %d", principalID)).SetInternal(err)
+		}
+		if principal == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Principal ID not found: %d", principalID))
+		}
+
+		driver, err := getAdminDatabaseDriver(ctx, database.Instance, "", s.pgInstanceDir)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to connect to instance %q", database.Instance.Name)).SetInternal(err)
+		}
+		defer driver.Close(ctx)
+		importer, ok := driver.(db.MigrationHistoryImporter)
+		if !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Importing migration history isn't supported for %s", database.Instance.Engine))
+		}
+		if err := driver.SetupMigrationIfNeeded(ctx); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to set up migration schema for instance %q", database.Instance.Name)).SetInternal(err)
+		}
+		// Reading the table of the migration tool fails mostly because the database isn't managed by the tool.
+		if _, err := importer.ImportMigrationHistory(ctx, database.Name, historyImport.Source, principal.Name); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Failed to import migration history from %s: %v", historyImport.Source, err)).SetInternal(err)
+		}
+
+		list, err := driver.FindMigrationHistoryList(ctx, &db.MigrationHistoryFind{
+			Database: &database.Name,
+			Source:   &historyImport.Source,
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch migration history list").SetInternal(err)
+		}
+		historyList := []*api.MigrationHistory{}
+		for _, entry := range list {
+			historyList = append(historyList, &api.MigrationHistory{
+				ID:                    entry.ID,
+				Creator:               entry.Creator,
+				CreatedTs:             entry.CreatedTs,
+				Updater:               entry.Updater,
+				UpdatedTs:             entry.UpdatedTs,
+				ReleaseVersion:        entry.ReleaseVersion,
+				Database:              entry.Namespace,
+				Source:                entry.Source,
+				Type:                  entry.Type,
+				Status:                entry.Status,
+				Version:               entry.Version,
+				UseSemanticVersion:    entry.UseSemanticVersion,
+				SemanticVersionSuffix: entry.SemanticVersionSuffix,
+				Description:           entry.Description,
+				Statement:             entry.Statement,
+				Schema:                entry.Schema,
+				SchemaPrev:            entry.SchemaPrev,
+				ExecutionDurationNs:   entry.ExecutionDurationNs,
+				IssueID:               entry.IssueID,
+				Payload:               entry.Payload,
+			})
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, historyList); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal imported migration history response for database: %v", database.Name)).SetInternal(err)
+		}
+		return nil
+	})
 }
 
 func (s *Server) setDatabaseLabels(ctx context.Context, labelsJSON string, database *api.Database, project *api.Project, updaterID int, validateOnly bool) error {
@@ -784,7 +949,7 @@ func (s *Server) setDatabaseLabels(ctx context.Context, labelsJSON string, datab
 		return err
 	}
 
-	// For scalability, each database can have up to four labels for now.
+	// For scalability, each database can have up to eight labels for now.
 	if len(labels) > api.DatabaseLabelSizeMax {
 		err := fmt.Errorf("database labels are up to a maximum of %d", api.DatabaseLabelSizeMax)
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
@@ -824,6 +989,66 @@ func (s *Server) setDatabaseLabels(ctx context.Context, labelsJSON string, datab
 	return nil
 }
 
+// getMigrationRollbackStatement returns the statement rolling back the database to the migration version,
+// which applies the rollback statements of the later schema migrations in the reverse order.
+// The data changes are skipped and returned as they can't be rolled back by the down statements.
+func getMigrationRollbackStatement(historyList []*db.MigrationHistory, version string) (string, []string, error) {
+	sorted := make([]*db.MigrationHistory, len(historyList))
+	copy(sorted, historyList)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Sequence > sorted[j].Sequence
+	})
+
+	var target *db.MigrationHistory
+	for _, history := range sorted {
+		if history.Version == version && history.Status == db.Done {
+			target = history
+			break
+		}
+	}
+	if target == nil {
+		return "", nil, fmt.Errorf("migration version %s not found or not done", version)
+	}
+
+	var statementList []string
+	var skippedVersionList []string
+	var missingVersionList []string
+	for _, history := range sorted {
+		if history.Sequence <= target.Sequence {
+			break
+		}
+		switch {
+		case history.Status == db.Failed:
+			continue
+		case history.Status == db.Pending:
+			return "", nil, fmt.Errorf("migration version %s is still pending", history.Version)
+		case history.Type == db.Baseline || history.Type == db.Branch:
+			return "", nil, fmt.Errorf("cannot roll back past the %s migration version %s", strings.ToLower(string(history.Type)), history.Version)
+		case history.Type == db.Data:
+			skippedVersionList = append(skippedVersionList, history.Version)
+			continue
+		}
+		payload := &db.MigrationInfoPayload{}
+		if history.Payload != "" {
+			if err := json.Unmarshal([]byte(history.Payload), payload); err != nil {
+				return "", nil, fmt.Errorf("failed to unmarshal the payload of migration version %s, error: %w", history.Version, err)
+			}
+		}
+		if payload.RollbackStatement == "" {
+			missingVersionList = append(missingVersionList, history.Version)
+			continue
+		}
+		statementList = append(statementList, payload.RollbackStatement)
+	}
+	if len(missingVersionList) > 0 {
+		return "", nil, fmt.Errorf("migration version %s has no rollback statement", strings.Join(missingVersionList, ", "))
+	}
+	if len(statementList) == 0 {
+		return "", nil, fmt.Errorf("no schema migration to roll back after version %s", version)
+	}
+	return strings.Join(statementList, "\n"), skippedVersionList, nil
+}
+
 // Try to get database driver using the instance's admin data source.
 // Upon successful return, caller MUST call driver.Close, otherwise, it will leak the database connection.
 func getAdminDatabaseDriver(ctx context.Context, instance *api.Instance, databaseName, pgInstanceDir string) (db.Driver, error) {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -151,3 +152,40 @@ func TestValidateDatabaseLabelList(t *testing.T) {
 		}
 	}
 }
+
+func TestGetMigrationRollbackStatement(t *testing.T) {
+	historyList := []*db.MigrationHistory{
+		{Sequence: 5, Version: "0005", Type: db.Migrate, Status: db.Done, Payload: `{"rollbackStatement":"DROP TABLE t3;"}`},
+		{Sequence: 4, Version: "0004", Type: db.Migrate, Status: db.Failed},
+		{Sequence: 3, Version: "0003", Type: db.Data, Status: db.Done},
+		{Sequence: 2, Version: "0002", Type: db.Migrate, Status: db.Done, Payload: `{"rollbackStatement":"DROP TABLE t2;"}`},
+		{Sequence: 1, Version: "0001", Type: db.Baseline, Status: db.Done},
+	}
+
+	statement, skippedVersionList, err := getMigrationRollbackStatement(historyList, "0001")
+	assert.NoError(t, err)
+	assert.Equal(t, "DROP TABLE t3;\nDROP TABLE t2;", statement)
+	assert.Equal(t, []string{"0003"}, skippedVersionList)
+
+	statement, _, err = getMigrationRollbackStatement(historyList, "0003")
+	assert.NoError(t, err)
+	assert.Equal(t, "DROP TABLE t3;", statement)
+
+	// The latest version has nothing to roll back.
+	_, _, err = getMigrationRollbackStatement(historyList, "0005")
+	assert.Error(t, err)
+
+	// The failed version can't be the target.
+	_, _, err = getMigrationRollbackStatement(historyList, "0004")
+	assert.Error(t, err)
+
+	// The migration without the rollback statement can't be rolled back.
+	historyList[0].Payload = ""
+	_, _, err = getMigrationRollbackStatement(historyList, "0002")
+	assert.Error(t, err)
+
+	// The baseline can't be rolled back past.
+	historyList = append([]*db.MigrationHistory{{Sequence: 6, Version: "0006", Type: db.Baseline, Status: db.Done}}, historyList...)
+	_, _, err = getMigrationRollbackStatement(historyList, "0005")
+	assert.Error(t, err)
+}
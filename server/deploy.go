@@ -5,6 +5,7 @@ import (
 	"sort"
 
 	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
 )
 
 // isMatchExpression checks whether a databases matches the query.
@@ -22,9 +23,24 @@ func isMatchExpression(labels map[string]string, expression *api.LabelSelectorRe
 			}
 		}
 		return false
+	case api.NotInOperatorType:
+		// A database without the label is not in any of the values.
+		value, ok := labels[expression.Key]
+		if !ok {
+			return true
+		}
+		for _, exprValue := range expression.Values {
+			if exprValue == value {
+				return false
+			}
+		}
+		return true
 	case api.ExistsOperatorType:
 		_, ok := labels[expression.Key]
 		return ok
+	case api.DoesNotExistOperatorType:
+		_, ok := labels[expression.Key]
+		return !ok
 	default:
 		return false
 	}
@@ -46,9 +62,60 @@ func isMatchExpressions(labels map[string]string, expressionList []*api.LabelSel
 
 // getDatabaseMatrixFromDeploymentSchedule gets a pipeline based on deployment schedule.
 // The returned matrix doesn't include deployment with no matched database.
+// Unlike the deployment preview, the base database name is required so that the pipeline never applies to every database.
 func getDatabaseMatrixFromDeploymentSchedule(schedule *api.DeploymentSchedule, baseDatabaseName, dbNameTemplate string, databaseList []*api.Database) ([]*api.Deployment, [][]*api.Database, error) {
+	if baseDatabaseName == "" {
+		return nil, nil, common.Errorf(common.Invalid, "base database name is required for the deployment pipeline")
+	}
+	fullMatrix, _, err := matchDeploymentSchedule(schedule, baseDatabaseName, dbNameTemplate, databaseList)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var matrix [][]*api.Database
 	var deployments []*api.Deployment
+	for i, databaseList := range fullMatrix {
+		if len(databaseList) > 0 {
+			matrix = append(matrix, databaseList)
+			deployments = append(deployments, schedule.Deployments[i])
+		}
+	}
+	return deployments, matrix, nil
+}
+
+// getDeploymentPreview resolves the deployment schedule into stages of databases without creating any pipeline.
+// If baseDatabaseName is empty, databases are not filtered by the database name template.
+func getDeploymentPreview(schedule *api.DeploymentSchedule, baseDatabaseName, dbNameTemplate string, databaseList []*api.Database) (*api.DeploymentPreview, error) {
+	matrix, unmatchedList, err := matchDeploymentSchedule(schedule, baseDatabaseName, dbNameTemplate, databaseList)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &api.DeploymentPreview{
+		StageList:             []*api.DeploymentPreviewStage{},
+		UnmatchedDatabaseList: []*api.DeploymentPreviewDatabase{},
+	}
+	for i, databaseList := range matrix {
+		stage := &api.DeploymentPreviewStage{
+			Name:         schedule.Deployments[i].Name,
+			DatabaseList: []*api.DeploymentPreviewDatabase{},
+		}
+		for _, database := range databaseList {
+			stage.DatabaseList = append(stage.DatabaseList, &api.DeploymentPreviewDatabase{ID: database.ID, Name: database.Name})
+		}
+		preview.StageList = append(preview.StageList, stage)
+	}
+	for _, database := range unmatchedList {
+		preview.UnmatchedDatabaseList = append(preview.UnmatchedDatabaseList, &api.DeploymentPreviewDatabase{ID: database.ID, Name: database.Name})
+	}
+	return preview, nil
+}
+
+// matchDeploymentSchedule matches the databases against every deployment in the schedule.
+// The returned matrix has exactly one database list for each deployment, and the returned unmatched list has the databases matching no deployment.
+// If baseDatabaseName is empty, databases are not filtered by the database name template.
+func matchDeploymentSchedule(schedule *api.DeploymentSchedule, baseDatabaseName, dbNameTemplate string, databaseList []*api.Database) ([][]*api.Database, []*api.Database, error) {
+	var matrix [][]*api.Database
 
 	// idToLabels maps databaseID -> label.Key -> label.Value
 	idToLabels := make(map[int]map[string]string)
@@ -67,30 +134,37 @@ func getDatabaseMatrixFromDeploymentSchedule(schedule *api.DeploymentSchedule, b
 		}
 	}
 
-	// idsSeen records database id which is already in a stage.
-	idsSeen := make(map[int]bool)
-
-	// For each stage, we loop over all databases to see if it is a match.
-	for _, deployment := range schedule.Deployments {
-		// For each stage, we will get a list of matched databases.
-		var matchedDatabaseList []int
-		// Loop over databaseList instead of idToLabels to get determinant results.
-		for _, database := range databaseList {
-			labels := idToLabels[database.ID]
+	// candidateList is the list of databases matching the database name.
+	var candidateList []*api.Database
+	for _, database := range databaseList {
+		if baseDatabaseName != "" {
 			// The tenant database should match the database name.
-			name, err := formatDatabaseName(baseDatabaseName, dbNameTemplate, labels)
+			name, err := formatDatabaseName(baseDatabaseName, dbNameTemplate, idToLabels[database.ID])
 			if err != nil {
 				continue
 			}
 			if database.Name != name {
 				continue
 			}
+		}
+		candidateList = append(candidateList, database)
+	}
+
+	// idsSeen records database id which is already in a stage.
+	idsSeen := make(map[int]bool)
+
+	// For each stage, we loop over all databases to see if it is a match.
+	for _, deployment := range schedule.Deployments {
+		// For each stage, we will get a list of matched databases.
+		var matchedDatabaseList []int
+		// Loop over candidateList instead of idToLabels to get determinant results.
+		for _, database := range candidateList {
 			// Skip if the database is already in a stage.
 			if _, ok := idsSeen[database.ID]; ok {
 				continue
 			}
 
-			if isMatchExpressions(labels, deployment.Spec.Selector.MatchExpressions) {
+			if isMatchExpressions(idToLabels[database.ID], deployment.Spec.Selector.MatchExpressions) {
 				matchedDatabaseList = append(matchedDatabaseList, database.ID)
 				idsSeen[database.ID] = true
 			}
@@ -105,13 +179,17 @@ func getDatabaseMatrixFromDeploymentSchedule(schedule *api.DeploymentSchedule, b
 			return databaseList[i].Name > databaseList[j].Name
 		})
 
-		if len(databaseList) > 0 {
-			matrix = append(matrix, databaseList)
-			deployments = append(deployments, deployment)
+		matrix = append(matrix, databaseList)
+	}
+
+	var unmatchedList []*api.Database
+	for _, database := range candidateList {
+		if !idsSeen[database.ID] {
+			unmatchedList = append(unmatchedList, database)
 		}
 	}
 
-	return deployments, matrix, nil
+	return matrix, unmatchedList, nil
 }
 
 // formatDatabaseName will return the full database name given the dbNameTemplate, base database name, and labels.
@@ -122,12 +200,7 @@ func formatDatabaseName(baseDatabaseName, dbNameTemplate string, labels map[stri
 	tokens := make(map[string]string)
 	tokens[api.DBNameToken] = baseDatabaseName
 	for k, v := range labels {
-		switch k {
-		case api.LocationLabelKey:
-			tokens[api.LocationToken] = v
-		case api.TenantLabelKey:
-			tokens[api.TenantToken] = v
-		}
+		tokens[api.GetLabelToken(k)] = v
 	}
 	return api.FormatTemplate(dbNameTemplate, tokens)
 }
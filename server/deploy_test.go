@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -240,6 +241,48 @@ func TestGetDatabaseMatrixFromDeploymentSchedule(t *testing.T) {
 				{dbs[5], dbs[6]},
 			},
 		},
+		{
+			"notInAndDoesNotExist",
+			&api.DeploymentSchedule{
+				Deployments: []*api.Deployment{
+					{
+						Spec: &api.DeploymentSpec{
+							Selector: &api.LabelSelector{
+								MatchExpressions: []*api.LabelSelectorRequirement{
+									{
+										Key:      "bb.tenant",
+										Operator: "DoesNotExist",
+										Values:   nil,
+									},
+								},
+							},
+						},
+					},
+					{
+						Spec: &api.DeploymentSpec{
+							Selector: &api.LabelSelector{
+								MatchExpressions: []*api.LabelSelectorRequirement{
+									{
+										Key:      "bb.location",
+										Operator: "NotIn",
+										Values:   []string{"earth"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"hello",
+			"",
+			[]*api.Database{
+				dbs[0], dbs[1], dbs[2], dbs[3],
+			},
+			[][]*api.Database{
+				{dbs[3]},
+				{dbs[0], dbs[2]},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -247,3 +290,139 @@ func TestGetDatabaseMatrixFromDeploymentSchedule(t *testing.T) {
 		assert.Equal(t, matrix, test.want)
 	}
 }
+
+func TestGetDatabaseMatrixFromDeploymentScheduleRequiresDatabaseName(t *testing.T) {
+	schedule := &api.DeploymentSchedule{
+		Deployments: []*api.Deployment{
+			{
+				Spec: &api.DeploymentSpec{
+					Selector: &api.LabelSelector{
+						MatchExpressions: []*api.LabelSelectorRequirement{
+							{
+								Key:      "bb.location",
+								Operator: "Exists",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	databaseList := []*api.Database{
+		{ID: 0, Name: "db1", Labels: "[{\"key\":\"bb.location\",\"value\":\"us\"}]"},
+	}
+	// The empty database name matching every database is only allowed in the deployment preview.
+	_, _, err := getDatabaseMatrixFromDeploymentSchedule(schedule, "", "", databaseList)
+	assert.Equal(t, common.Invalid, common.ErrorCode(err))
+	preview, err := getDeploymentPreview(schedule, "", "", databaseList)
+	assert.NoError(t, err)
+	assert.Len(t, preview.StageList[0].DatabaseList, 1)
+}
+
+func TestGetDeploymentPreview(t *testing.T) {
+	dbs := []*api.Database{
+		{
+			ID:     0,
+			Name:   "db1_us",
+			Labels: "[{\"key\":\"bb.location\",\"value\":\"us\"},{\"key\":\"bb.environment\",\"value\":\"Prod\"}]",
+		},
+		{
+			ID:     1,
+			Name:   "db1_eu",
+			Labels: "[{\"key\":\"bb.location\",\"value\":\"eu\"},{\"key\":\"bb.environment\",\"value\":\"Prod\"}]",
+		},
+		{
+			ID:     2,
+			Name:   "db1_asia",
+			Labels: "[{\"key\":\"bb.location\",\"value\":\"asia\"},{\"key\":\"bb.environment\",\"value\":\"Prod\"}]",
+		},
+		{
+			ID:     3,
+			Name:   "db2_us",
+			Labels: "[{\"key\":\"bb.location\",\"value\":\"us\"},{\"key\":\"bb.environment\",\"value\":\"Prod\"}]",
+		},
+	}
+	schedule := &api.DeploymentSchedule{
+		Deployments: []*api.Deployment{
+			{
+				Name: "us",
+				Spec: &api.DeploymentSpec{
+					Selector: &api.LabelSelector{
+						MatchExpressions: []*api.LabelSelectorRequirement{
+							{
+								Key:      "bb.location",
+								Operator: "In",
+								Values:   []string{"us"},
+							},
+						},
+					},
+				},
+			},
+			{
+				Name: "moon",
+				Spec: &api.DeploymentSpec{
+					Selector: &api.LabelSelector{
+						MatchExpressions: []*api.LabelSelectorRequirement{
+							{
+								Key:      "bb.location",
+								Operator: "In",
+								Values:   []string{"moon"},
+							},
+						},
+					},
+				},
+			},
+			{
+				Name: "eu",
+				Spec: &api.DeploymentSpec{
+					Selector: &api.LabelSelector{
+						MatchExpressions: []*api.LabelSelectorRequirement{
+							{
+								Key:      "bb.location",
+								Operator: "In",
+								Values:   []string{"eu"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		baseDatabaseName string
+		want             *api.DeploymentPreview
+	}{
+		{
+			"withDatabaseName",
+			"db1",
+			&api.DeploymentPreview{
+				StageList: []*api.DeploymentPreviewStage{
+					{Name: "us", DatabaseList: []*api.DeploymentPreviewDatabase{{ID: 0, Name: "db1_us"}}},
+					{Name: "moon", DatabaseList: []*api.DeploymentPreviewDatabase{}},
+					{Name: "eu", DatabaseList: []*api.DeploymentPreviewDatabase{{ID: 1, Name: "db1_eu"}}},
+				},
+				UnmatchedDatabaseList: []*api.DeploymentPreviewDatabase{{ID: 2, Name: "db1_asia"}},
+			},
+		},
+		{
+			"withoutDatabaseName",
+			"",
+			&api.DeploymentPreview{
+				StageList: []*api.DeploymentPreviewStage{
+					{Name: "us", DatabaseList: []*api.DeploymentPreviewDatabase{{ID: 3, Name: "db2_us"}, {ID: 0, Name: "db1_us"}}},
+					{Name: "moon", DatabaseList: []*api.DeploymentPreviewDatabase{}},
+					{Name: "eu", DatabaseList: []*api.DeploymentPreviewDatabase{{ID: 1, Name: "db1_eu"}}},
+				},
+				UnmatchedDatabaseList: []*api.DeploymentPreviewDatabase{{ID: 2, Name: "db1_asia"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		preview, err := getDeploymentPreview(schedule, test.baseDatabaseName, "{{DB_NAME}}_{{LOCATION}}", dbs)
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.want, preview, test.name)
+	}
+}
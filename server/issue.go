@@ -16,6 +16,8 @@ import (
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/pg"
+	"github.com/bytebase/bytebase/plugin/db/util"
 	"github.com/bytebase/bytebase/plugin/vcs"
 )
 
@@ -641,6 +643,8 @@ func (s *Server) getPipelineCreateForDatabaseSchemaAndDataUpdate(ctx context.Con
 		create.Name = "Establish database baseline pipeline"
 	case db.Migrate:
 		create.Name = "Update database schema pipeline"
+	case db.MigrateSDL:
+		create.Name = "Update database schema (declarative) pipeline"
 	case db.Data:
 		create.Name = "Update database data pipeline"
 	default:
@@ -657,14 +661,18 @@ func (s *Server) getPipelineCreateForDatabaseSchemaAndDataUpdate(ctx context.Con
 		if !s.feature(api.FeatureMultiTenancy) {
 			return nil, echo.NewHTTPError(http.StatusForbidden, api.FeatureMultiTenancy.AccessErrorMessage())
 		}
-		if c.MigrationType != db.Migrate && c.MigrationType != db.Data {
-			return nil, echo.NewHTTPError(http.StatusBadRequest, "Only Migrate and Data type migration can be performed on tenant mode project")
+		if c.MigrationType != db.Migrate && c.MigrationType != db.MigrateSDL && c.MigrationType != db.Data {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Only Migrate, MigrateSDL and Data type migration can be performed on tenant mode project")
 		}
 		if len(c.DetailList) != 1 {
 			return nil, echo.NewHTTPError(http.StatusBadRequest, "Tenant mode project should have exactly one update schema detail")
 		}
 		d := c.DetailList[0]
-		if d.Statement == "" {
+		if c.MigrationType == db.MigrateSDL {
+			if d.DesiredSchema == "" {
+				return nil, echo.NewHTTPError(http.StatusBadRequest, "Failed to create issue, desired schema missing")
+			}
+		} else if d.Statement == "" {
 			return nil, echo.NewHTTPError(http.StatusBadRequest, "Failed to create issue, sql statement missing")
 		}
 
@@ -686,7 +694,11 @@ func (s *Server) getPipelineCreateForDatabaseSchemaAndDataUpdate(ctx context.Con
 				return nil, err
 			}
 
-			taskCreate, err := getUpdateTask(database, c.MigrationType, c.VCSPushEvent, d, schemaVersion, taskStatus)
+			detail, err := s.prepareUpdateSchemaDetail(ctx, c.MigrationType, database, d)
+			if err != nil {
+				return nil, err
+			}
+			taskCreate, err := getUpdateTask(database, c.MigrationType, c.VCSPushEvent, detail, schemaVersion, taskStatus)
 			if err != nil {
 				return nil, err
 			}
@@ -724,7 +736,11 @@ func (s *Server) getPipelineCreateForDatabaseSchemaAndDataUpdate(ctx context.Con
 						return nil, err
 					}
 
-					taskCreate, err := getUpdateTask(database, c.MigrationType, c.VCSPushEvent, d, schemaVersion, taskStatus)
+					detail, err := s.prepareUpdateSchemaDetail(ctx, c.MigrationType, database, d)
+					if err != nil {
+						return nil, err
+					}
+					taskCreate, err := getUpdateTask(database, c.MigrationType, c.VCSPushEvent, detail, schemaVersion, taskStatus)
 					if err != nil {
 						return nil, err
 					}
@@ -757,6 +773,9 @@ func (s *Server) getPipelineCreateForDatabaseSchemaAndDataUpdate(ctx context.Con
 			if c.MigrationType == db.Migrate && d.Statement == "" {
 				return nil, echo.NewHTTPError(http.StatusBadRequest, "Failed to create issue, sql statement missing")
 			}
+			if c.MigrationType == db.MigrateSDL && d.DesiredSchema == "" {
+				return nil, echo.NewHTTPError(http.StatusBadRequest, "Failed to create issue, desired schema missing")
+			}
 			database, err := s.store.GetDatabase(ctx, &api.DatabaseFind{ID: &d.DatabaseID})
 			if err != nil {
 				return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", d.DatabaseID)).SetInternal(err)
@@ -770,7 +789,11 @@ func (s *Server) getPipelineCreateForDatabaseSchemaAndDataUpdate(ctx context.Con
 				return nil, err
 			}
 
-			taskCreate, err := getUpdateTask(database, c.MigrationType, c.VCSPushEvent, d, schemaVersion, taskStatus)
+			detail, err := s.prepareUpdateSchemaDetail(ctx, c.MigrationType, database, d)
+			if err != nil {
+				return nil, err
+			}
+			taskCreate, err := getUpdateTask(database, c.MigrationType, c.VCSPushEvent, detail, schemaVersion, taskStatus)
 			if err != nil {
 				return nil, err
 			}
@@ -857,20 +880,94 @@ func (s *Server) getPipelineCreateForDatabaseSchemaUpdateGhost(ctx context.Conte
 	return create, nil
 }
 
+// prepareUpdateSchemaDetail generates the statement of the declarative (MIGRATE_SDL) migration by diffing the desired schema
+// against the live schema of the database. The detail of other migration types is returned as is.
+func (s *Server) prepareUpdateSchemaDetail(ctx context.Context, migrationType db.MigrationType, database *api.Database, d *api.UpdateSchemaDetail) (*api.UpdateSchemaDetail, error) {
+	if migrationType != db.MigrateSDL {
+		return d, nil
+	}
+	switch database.Instance.Engine {
+	case db.MySQL, db.TiDB, db.Postgres:
+	default:
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Declarative schema migration is not supported for %s", database.Instance.Engine))
+	}
+
+	driver, err := getAdminDatabaseDriver(ctx, database.Instance, database.Name, s.pgInstanceDir)
+	if err != nil {
+		return nil, err
+	}
+	defer driver.Close(ctx)
+	var schemaBuf bytes.Buffer
+	if _, err := driver.Dump(ctx, database.Name, &schemaBuf, true /* schemaOnly */); err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to dump the schema of database %q", database.Name)).SetInternal(err)
+	}
+
+	statement, err := generateSchemaDiff(database.Instance.Engine, schemaBuf.String(), d.DesiredSchema)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Failed to generate the schema migration for database %q: %v", database.Name, err)).SetInternal(err)
+	}
+	if statement == "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("The desired schema has no difference from the schema of database %q", database.Name))
+	}
+	detail := *d
+	detail.Statement = statement
+	return &detail, nil
+}
+
 func getUpdateTask(database *api.Database, migrationType db.MigrationType, vcsPushEvent *vcs.PushEvent, d *api.UpdateSchemaDetail, schemaVersion string, taskStatus api.TaskStatus) (*api.TaskCreate, error) {
 	taskName := fmt.Sprintf("Establish %q baseline", database.Name)
 	switch migrationType {
 	case db.Migrate:
 		taskName = fmt.Sprintf("Update %q schema", database.Name)
+	case db.MigrateSDL:
+		taskName = fmt.Sprintf("Update %q schema to the desired state", database.Name)
 	case db.Data:
 		taskName = fmt.Sprintf("Update %q data", database.Name)
 	}
-	payload := api.TaskDatabaseSchemaUpdatePayload{}
-	payload.MigrationType = migrationType
-	payload.Statement = d.Statement
-	payload.SchemaVersion = schemaVersion
-	if vcsPushEvent != nil {
-		payload.VCSPushEvent = vcsPushEvent
+	if d.RollbackStatement != "" && migrationType != db.Migrate && migrationType != db.MigrateSDL {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Rollback statement is only supported for schema migration")
+	}
+	if d.DesiredSchema != "" && migrationType != db.MigrateSDL {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Desired schema is only supported for declarative schema migration")
+	}
+	var payload interface{}
+	if d.BatchConfig != nil || d.RollbackEnabled {
+		if migrationType != db.Data {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Batched execution and pre-change backup are only supported for data update")
+		}
+		switch database.Instance.Engine {
+		case db.MySQL, db.TiDB, db.Postgres:
+		default:
+			return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Batched data update and pre-change backup are not supported for %s", database.Instance.Engine))
+		}
+		if d.BatchConfig != nil {
+			if err := d.BatchConfig.Validate(); err != nil {
+				return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+		}
+		if d.RollbackEnabled {
+			if err := util.CheckDataBackupStatement(d.Statement); err != nil {
+				return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+		}
+		payload = api.TaskDatabaseDataUpdatePayload{
+			Statement:       d.Statement,
+			SchemaVersion:   schemaVersion,
+			VCSPushEvent:    vcsPushEvent,
+			BatchConfig:     d.BatchConfig,
+			RollbackEnabled: d.RollbackEnabled,
+		}
+	} else {
+		schemaUpdatePayload := api.TaskDatabaseSchemaUpdatePayload{}
+		schemaUpdatePayload.MigrationType = migrationType
+		schemaUpdatePayload.Statement = d.Statement
+		schemaUpdatePayload.SchemaVersion = schemaVersion
+		schemaUpdatePayload.RollbackStatement = d.RollbackStatement
+		schemaUpdatePayload.DesiredSchema = d.DesiredSchema
+		if vcsPushEvent != nil {
+			schemaUpdatePayload.VCSPushEvent = vcsPushEvent
+		}
+		payload = schemaUpdatePayload
 	}
 	bytes, err := json.Marshal(payload)
 	if err != nil {
@@ -946,6 +1043,19 @@ func createPITRTaskList(database *api.Database, projectID int, taskStatus api.Ta
 
 // creates gh-ost TaskCreate list and dependency.
 func createGhostTaskList(database *api.Database, vcsPushEvent *vcs.PushEvent, detail *api.UpdateSchemaGhostDetail, schemaVersion string, taskStatus api.TaskStatus) ([]api.TaskCreate, []api.TaskIndexDAG, error) {
+	// Postgres uses the trigger-based online schema change in place of gh-ost.
+	tool := "gh-ost"
+	switch database.Instance.Engine {
+	case db.MySQL:
+	case db.Postgres:
+		tool = "online schema change"
+		if _, err := pg.NewOnlineSchemaChange(detail.Statement); err != nil {
+			return nil, nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	default:
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("online schema change isn't supported for %s", database.Instance.Engine))
+	}
+
 	var taskCreateList []api.TaskCreate
 	// task "sync"
 	payloadSync := api.TaskDatabaseSchemaUpdateGhostSyncPayload{
@@ -958,7 +1068,7 @@ func createGhostTaskList(database *api.Database, vcsPushEvent *vcs.PushEvent, de
 		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to marshal database schema update gh-ost sync payload, error: %v", err))
 	}
 	taskCreateList = append(taskCreateList, api.TaskCreate{
-		Name:              fmt.Sprintf("Update %q schema gh-ost sync", database.Name),
+		Name:              fmt.Sprintf("Update %q schema %s sync", database.Name, tool),
 		InstanceID:        database.InstanceID,
 		DatabaseID:        &database.ID,
 		Status:            taskStatus,
@@ -976,7 +1086,7 @@ func createGhostTaskList(database *api.Database, vcsPushEvent *vcs.PushEvent, de
 		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to marshal database schema update ghost cutover payload, error: %v", err))
 	}
 	taskCreateList = append(taskCreateList, api.TaskCreate{
-		Name:              fmt.Sprintf("Update %q schema gh-ost cutover", database.Name),
+		Name:              fmt.Sprintf("Update %q schema %s cutover", database.Name, tool),
 		InstanceID:        database.InstanceID,
 		DatabaseID:        &database.ID,
 		Status:            taskStatus,
@@ -1221,6 +1331,9 @@ func (s *Server) getTenantDatabaseMatrix(ctx context.Context, projectID int, dbN
 
 	d, matrix, err := getDatabaseMatrixFromDeploymentSchedule(deploySchedule, baseDatabaseName, dbNameTemplate, dbList)
 	if err != nil {
+		if common.ErrorCode(err) == common.Invalid {
+			return nil, nil, echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		}
 		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to create deployment pipeline").SetInternal(err)
 	}
 	return d, matrix, nil
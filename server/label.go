@@ -13,6 +13,34 @@ import (
 )
 
 func (s *Server) registerLabelRoutes(g *echo.Group) {
+	g.POST("/label", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		create := &api.LabelKeyCreate{
+			CreatorID: c.Get(getPrincipalIDContextKey()).(int),
+		}
+		if err := jsonapi.UnmarshalPayload(c.Request().Body, create); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Malformed create label key request").SetInternal(err)
+		}
+
+		if err := create.Validate(); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid create label key request: %v", err)).SetInternal(err)
+		}
+
+		labelKey, err := s.store.CreateLabelKey(ctx, create)
+		if err != nil {
+			if common.ErrorCode(err) == common.Conflict {
+				return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("Label key already exists: %s", create.Key))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create label key").SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, labelKey); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to marshal create label key response").SetInternal(err)
+		}
+		return nil
+	})
+
 	g.GET("/label", func(c echo.Context) error {
 		ctx := c.Request().Context()
 		rowStatus := api.Normal
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+// checkModifiedMigrationFileList checks the migration files modified by the push event. If a migration file whose
+// version has been applied is modified, the checksum of the file no longer matches the one of the applied statement
+// recorded in the migration history. We create a WARNING project activity and a checksum mismatch anomaly for each
+// database having applied the version. The anomaly is resolved once the file is modified back to the applied statement.
+// It returns the messages of the modified migration files found, and never fails the push event.
+func (s *Server) checkModifiedMigrationFileList(ctx context.Context, repo *api.Repository, fileList []*api.PushEventFile) []string {
+	// The schema file of the declarative schema migration is expected to be modified.
+	if repo.Project.SchemaChangeType == api.ProjectSchemaChangeTypeSDL {
+		return nil
+	}
+
+	var messageList []string
+	for _, file := range dedupPushEventFileList(fileList) {
+		message, err := s.checkModifiedMigrationFile(ctx, repo, file.PushEvent, file.File)
+		if err != nil {
+			log.Error("Failed to check the modified migration file",
+				zap.String("project", repo.Project.Name),
+				zap.String("file", common.EscapeForLogging(file.File)),
+				zap.Error(err),
+			)
+			continue
+		}
+		if message != "" {
+			messageList = append(messageList, message)
+		}
+	}
+	return messageList
+}
+
+func (s *Server) checkModifiedMigrationFile(ctx context.Context, repo *api.Repository, pushEvent vcs.PushEvent, file string) (string, error) {
+	fileEscaped := common.EscapeForLogging(file)
+	if !strings.HasPrefix(fileEscaped, repo.BaseDirectory) || isSkipGeneratedSchemaFile(repo, fileEscaped) {
+		return "", nil
+	}
+	isDown, err := s.isDownMigrationFile(ctx, repo, fileEscaped, pushEvent.FileCommit.ID)
+	if err != nil {
+		return "", err
+	}
+	if isDown {
+		return "", nil
+	}
+	mi, err := db.ParseMigrationInfo(fileEscaped, filepath.Join(repo.BaseDirectory, repo.FilePathTemplate))
+	if err != nil {
+		// Not a migration file.
+		return "", nil
+	}
+
+	// Only the branch applying the migration files is checked, the files modified in other branches are not applied.
+	branch := getBranchFromRef(pushEvent.Ref)
+	environment, err := s.getBranchEnvironment(ctx, repo, branch)
+	if err != nil {
+		if common.ErrorCode(err) == common.NotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	if environment == nil && !matchBranchFilter(repo.BranchFilter, branch) {
+		return "", nil
+	}
+
+	databaseList, err := s.findModifiedMigrationDatabaseList(ctx, repo, mi, environment)
+	if err != nil {
+		return "", err
+	}
+	if len(databaseList) == 0 {
+		return "", nil
+	}
+
+	content, err := vcs.Get(repo.VCS.Type, vcs.ProviderConfig{}).ReadFileContent(
+		ctx,
+		common.OauthContext{
+			ClientID:     repo.VCS.ApplicationID,
+			ClientSecret: repo.VCS.Secret,
+			AccessToken:  repo.AccessToken,
+			RefreshToken: repo.RefreshToken,
+			Refresher:    s.refreshToken(ctx, repo.ID),
+		},
+		repo.VCS.InstanceURL,
+		repo.ExternalID,
+		fileEscaped,
+		pushEvent.FileCommit.ID,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %q, error: %w", fileEscaped, err)
+	}
+	checksum := db.GetMigrationChecksum(content)
+
+	var mismatchDatabaseList []string
+	for _, database := range databaseList {
+		history, err := s.getAppliedMigrationHistory(ctx, database, mi.Version)
+		if err != nil {
+			return "", err
+		}
+		if history == nil {
+			continue
+		}
+		appliedChecksum, err := db.GetMigrationHistoryChecksum(history)
+		if err != nil {
+			return "", err
+		}
+		// The migration history recorded before the checksum was introduced can't be checked.
+		if appliedChecksum == "" {
+			continue
+		}
+
+		var mismatch *api.MigrationChecksumMismatch
+		if appliedChecksum != checksum {
+			mismatch = &api.MigrationChecksumMismatch{
+				Version:   mi.Version,
+				File:      fileEscaped,
+				CommitID:  pushEvent.FileCommit.ID,
+				CommitURL: pushEvent.FileCommit.URL,
+				Expect:    appliedChecksum,
+				Actual:    checksum,
+			}
+			mismatchDatabaseList = append(mismatchDatabaseList, database.Name)
+		}
+		if err := s.updateMigrationChecksumMismatchAnomaly(ctx, database, mi.Version, mismatch); err != nil {
+			return "", err
+		}
+	}
+	if len(mismatchDatabaseList) == 0 {
+		return "", nil
+	}
+
+	message := fmt.Sprintf("Modified migration file %q of version %s, which has been applied to database %s.", fileEscaped, mi.Version, strings.Join(mismatchDatabaseList, ", "))
+	log.Warn("Modified applied migration file",
+		zap.String("project", repo.Project.Name),
+		zap.String("file", fileEscaped),
+		zap.String("version", mi.Version),
+	)
+	bytes, err := json.Marshal(api.ActivityProjectRepositoryPushPayload{
+		VCSPushEvent: pushEvent,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to construct activity payload, error: %w", err)
+	}
+	activityCreate := &api.ActivityCreate{
+		CreatorID:   api.SystemBotID,
+		ContainerID: repo.ProjectID,
+		Type:        api.ActivityProjectRepositoryPush,
+		Level:       api.ActivityWarn,
+		Comment:     fmt.Sprintf("%s The change won't be applied, please add a new migration file instead.", message),
+		Payload:     string(bytes),
+	}
+	if _, err := s.ActivityManager.CreateActivity(ctx, activityCreate, &ActivityMeta{}); err != nil {
+		return "", fmt.Errorf("failed to create project activity, error: %w", err)
+	}
+	return message, nil
+}
+
+// findModifiedMigrationDatabaseList finds the databases in the project which the modified migration file applies to.
+// If the environment is not nil, only the databases in the environment are returned.
+func (s *Server) findModifiedMigrationDatabaseList(ctx context.Context, repo *api.Repository, mi *db.MigrationInfo, environment *api.Environment) ([]*api.Database, error) {
+	if repo.Project.TenantMode != api.TenantModeTenant {
+		if environment != nil {
+			// Environment name comparison is case insensitive
+			if mi.Environment != "" && !strings.EqualFold(mi.Environment, environment.Name) {
+				return nil, nil
+			}
+			mi.Environment = environment.Name
+		}
+		return s.findMigrationDatabaseList(ctx, repo.ProjectID, mi)
+	}
+
+	// The tenant databases are named by the database name template of the project.
+	databaseList, err := s.store.FindDatabase(ctx, &api.DatabaseFind{
+		ProjectID: &repo.ProjectID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find databases of project %q, error: %w", repo.Project.Name, err)
+	}
+	var filteredDatabaseList []*api.Database
+	for _, database := range databaseList {
+		baseDatabaseName, err := api.GetBaseDatabaseName(database.Name, repo.Project.DBNameTemplate, database.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("api.GetBaseDatabaseName(%q, %q, %q) failed, error: %w", database.Name, repo.Project.DBNameTemplate, database.Labels, err)
+		}
+		if baseDatabaseName == mi.Database {
+			filteredDatabaseList = append(filteredDatabaseList, database)
+		}
+	}
+	return filteredDatabaseList, nil
+}
+
+// getAppliedMigrationHistory returns the migration history of the migration version applied to the database, or nil if not applied.
+func (s *Server) getAppliedMigrationHistory(ctx context.Context, database *api.Database, version string) (*db.MigrationHistory, error) {
+	driver, err := getAdminDatabaseDriver(ctx, database.Instance, "", s.pgInstanceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to instance %q for the migration history of database %q, error: %w", database.Instance.Name, database.Name, err)
+	}
+	defer driver.Close(ctx)
+
+	historyList, err := driver.FindMigrationHistoryList(ctx, &db.MigrationHistoryFind{
+		Database: &database.Name,
+		Version:  &version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find migration history of database %q, error: %w", database.Name, err)
+	}
+	for _, history := range historyList {
+		if history.Status == db.Done {
+			return history, nil
+		}
+	}
+	return nil, nil
+}
+
+// updateMigrationChecksumMismatchAnomaly updates the checksum mismatch of the migration version in the anomaly of the
+// database. The mismatch of the version is removed if mismatch is nil, and the anomaly is archived if no mismatch is left.
+func (s *Server) updateMigrationChecksumMismatchAnomaly(ctx context.Context, database *api.Database, version string, mismatch *api.MigrationChecksumMismatch) error {
+	anomalyType := api.AnomalyDatabaseMigrationChecksumMismatch
+	rowStatus := api.Normal
+	anomalyList, err := s.store.FindAnomaly(ctx, &api.AnomalyFind{
+		RowStatus:  &rowStatus,
+		DatabaseID: &database.ID,
+		Type:       &anomalyType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find anomaly of database %q, error: %w", database.Name, err)
+	}
+
+	payload := &api.AnomalyDatabaseMigrationChecksumMismatchPayload{}
+	if len(anomalyList) > 0 {
+		if err := json.Unmarshal([]byte(anomalyList[0].Payload), payload); err != nil {
+			return fmt.Errorf("failed to unmarshal anomaly payload of database %q, error: %w", database.Name, err)
+		}
+	}
+	mismatchList := mergeMigrationChecksumMismatchList(payload.MismatchList, version, mismatch)
+
+	if len(mismatchList) == 0 {
+		if len(anomalyList) == 0 {
+			return nil
+		}
+		if err := s.store.ArchiveAnomaly(ctx, &api.AnomalyArchive{
+			DatabaseID: &database.ID,
+			Type:       anomalyType,
+		}); err != nil && common.ErrorCode(err) != common.NotFound {
+			return fmt.Errorf("failed to close anomaly of database %q, error: %w", database.Name, err)
+		}
+		return nil
+	}
+
+	payload.MismatchList = mismatchList
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal anomaly payload, error: %w", err)
+	}
+	if _, err := s.store.UpsertActiveAnomaly(ctx, &api.AnomalyUpsert{
+		CreatorID:  api.SystemBotID,
+		InstanceID: database.InstanceID,
+		DatabaseID: &database.ID,
+		Type:       anomalyType,
+		Payload:    string(bytes),
+	}); err != nil {
+		return fmt.Errorf("failed to create anomaly of database %q, error: %w", database.Name, err)
+	}
+	return nil
+}
+
+// mergeMigrationChecksumMismatchList replaces the checksum mismatch of the migration version in the list, or
+// removes it if mismatch is nil.
+func mergeMigrationChecksumMismatchList(mismatchList []*api.MigrationChecksumMismatch, version string, mismatch *api.MigrationChecksumMismatch) []*api.MigrationChecksumMismatch {
+	var result []*api.MigrationChecksumMismatch
+	for _, m := range mismatchList {
+		if m.Version != version {
+			result = append(result, m)
+		}
+	}
+	if mismatch != nil {
+		result = append(result, mismatch)
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bytebase/bytebase/api"
+)
+
+func TestMergeMigrationChecksumMismatchList(t *testing.T) {
+	v1 := &api.MigrationChecksumMismatch{Version: "v1", Expect: "a", Actual: "b"}
+	v2 := &api.MigrationChecksumMismatch{Version: "v2", Expect: "c", Actual: "d"}
+	v2Again := &api.MigrationChecksumMismatch{Version: "v2", Expect: "c", Actual: "e"}
+
+	tests := []struct {
+		name     string
+		list     []*api.MigrationChecksumMismatch
+		version  string
+		mismatch *api.MigrationChecksumMismatch
+		want     []*api.MigrationChecksumMismatch
+	}{
+		{
+			name:     "add the first mismatch",
+			list:     nil,
+			version:  "v1",
+			mismatch: v1,
+			want:     []*api.MigrationChecksumMismatch{v1},
+		},
+		{
+			name:     "add another version",
+			list:     []*api.MigrationChecksumMismatch{v1},
+			version:  "v2",
+			mismatch: v2,
+			want:     []*api.MigrationChecksumMismatch{v1, v2},
+		},
+		{
+			name:     "replace the mismatch of the same version",
+			list:     []*api.MigrationChecksumMismatch{v1, v2},
+			version:  "v2",
+			mismatch: v2Again,
+			want:     []*api.MigrationChecksumMismatch{v1, v2Again},
+		},
+		{
+			name:     "resolve the mismatch",
+			list:     []*api.MigrationChecksumMismatch{v1, v2},
+			version:  "v1",
+			mismatch: nil,
+			want:     []*api.MigrationChecksumMismatch{v2},
+		},
+		{
+			name:     "resolve the last mismatch",
+			list:     []*api.MigrationChecksumMismatch{v2},
+			version:  "v2",
+			mismatch: nil,
+			want:     nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, mergeMigrationChecksumMismatchList(test.list, test.version, test.mismatch))
+		})
+	}
+}
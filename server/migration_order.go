@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+func isMigrationOrder(order api.ProjectMigrationOrder) bool {
+	switch order {
+	case api.MigrationOrderStrict, api.MigrationOrderAllowOutOfOrder, api.MigrationOrderIgnore:
+		return true
+	}
+	return false
+}
+
+// appliedMigrationVersion is the migration versions applied to a database.
+type appliedMigrationVersion struct {
+	// latestVersion is the latest version applied since the last baseline or branch, and empty if none is applied.
+	latestVersion string
+	versionSet    map[string]bool
+}
+
+// newAppliedMigrationVersion returns the applied migration versions from the migration history list, which is
+// ordered by the creation time in descending order. Only the versions applied successfully are taken.
+func newAppliedMigrationVersion(historyList []*db.MigrationHistory) *appliedMigrationVersion {
+	applied := &appliedMigrationVersion{
+		versionSet: make(map[string]bool),
+	}
+	sinceBaseline := true
+	for _, history := range historyList {
+		if history.Status != db.Done {
+			continue
+		}
+		applied.versionSet[history.Version] = true
+		if sinceBaseline && (applied.latestVersion == "" || db.CompareMigrationVersion(history.Version, applied.latestVersion) > 0) {
+			applied.latestVersion = history.Version
+		}
+		if history.Type == db.Baseline || history.Type == db.Branch {
+			sinceBaseline = false
+		}
+	}
+	return applied
+}
+
+// isOutOfOrder returns true if the version isn't applied and is older than the latest applied version.
+func (applied *appliedMigrationVersion) isOutOfOrder(version string) bool {
+	return !applied.versionSet[version] && applied.latestVersion != "" && db.CompareMigrationVersion(applied.latestVersion, version) > 0
+}
+
+// classifyVersionList classifies the distinct versions of the migration files into the applied ones, the pending ones
+// and the out-of-order ones, each sorted in ascending order.
+func (applied *appliedMigrationVersion) classifyVersionList(versionList []string) (appliedList, pendingList, outOfOrderList []string) {
+	sorted := append([]string{}, versionList...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return db.CompareMigrationVersion(sorted[i], sorted[j]) < 0
+	})
+	appliedList, pendingList, outOfOrderList = []string{}, []string{}, []string{}
+	seen := make(map[string]bool)
+	for _, version := range sorted {
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		switch {
+		case applied.versionSet[version]:
+			appliedList = append(appliedList, version)
+		case applied.isOutOfOrder(version):
+			outOfOrderList = append(outOfOrderList, version)
+		default:
+			pendingList = append(pendingList, version)
+		}
+	}
+	return appliedList, pendingList, outOfOrderList
+}
+
+func (s *Server) getAppliedMigrationVersion(ctx context.Context, database *api.Database) (*appliedMigrationVersion, error) {
+	driver, err := getAdminDatabaseDriver(ctx, database.Instance, "", s.pgInstanceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to instance %q for the migration history of database %q, error: %w", database.Instance.Name, database.Name, err)
+	}
+	defer driver.Close(ctx)
+
+	historyList, err := driver.FindMigrationHistoryList(ctx, &db.MigrationHistoryFind{
+		Database: &database.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find migration history of database %q, error: %w", database.Name, err)
+	}
+	return newAppliedMigrationVersion(historyList), nil
+}
+
+// checkMigrationVersionOrder compares the version of the migration file with the versions applied to the databases
+// which the file applies to. It returns the message describing the databases having applied a newer version, or an
+// empty string if the version is in order for all databases.
+func (s *Server) checkMigrationVersionOrder(ctx context.Context, repo *api.Repository, mi *db.MigrationInfo) (string, error) {
+	databaseList, err := s.findModifiedMigrationDatabaseList(ctx, repo, mi, nil /* environment */)
+	if err != nil {
+		return "", err
+	}
+
+	var messageList []string
+	for _, database := range databaseList {
+		applied, err := s.getAppliedMigrationVersion(ctx, database)
+		if err != nil {
+			// The database not reachable doesn't block the migration file, the error is reported when applying it.
+			log.Warn("Failed to check the migration version order",
+				zap.String("database", database.Name),
+				zap.String("version", mi.Version),
+				zap.Error(err),
+			)
+			continue
+		}
+		if applied.isOutOfOrder(mi.Version) {
+			messageList = append(messageList, fmt.Sprintf("database %q has applied newer version %s", database.Name, applied.latestVersion))
+		}
+	}
+	if len(messageList) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("version %s is out of order, %s", mi.Version, strings.Join(messageList, ", ")), nil
+}
+
+// getProjectMigrationStatus returns the status of the migration files in the repository linked to the project
+// against the migration versions applied to each database in the project.
+func (s *Server) getProjectMigrationStatus(ctx context.Context, repo *api.Repository) (*api.ProjectMigrationStatus, error) {
+	databaseList, err := s.store.FindDatabase(ctx, &api.DatabaseFind{
+		ProjectID: &repo.ProjectID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find databases in project %q, error: %w", repo.Project.Name, err)
+	}
+	sort.Slice(databaseList, func(i, j int) bool {
+		return databaseList[i].ID < databaseList[j].ID
+	})
+
+	status := &api.ProjectMigrationStatus{
+		ProjectID:    repo.ProjectID,
+		DatabaseList: []*api.DatabaseMigrationStatus{},
+	}
+	// The migration files are listed once for each branch.
+	branchFileList := make(map[string][]*db.MigrationInfo)
+	for _, database := range databaseList {
+		databaseStatus := &api.DatabaseMigrationStatus{
+			ID:              database.ID,
+			Name:            database.Name,
+			EnvironmentName: database.Instance.Environment.Name,
+		}
+		status.DatabaseList = append(status.DatabaseList, databaseStatus)
+
+		branch, err := getEnvironmentBranch(repo, database.Instance.EnvironmentID)
+		if err != nil {
+			return nil, err
+		}
+		if branch == "" {
+			databaseStatus.Error = "the branch of the migration files is unknown, the branch filter or the branch mapped to the environment should be a branch name"
+			continue
+		}
+		databaseStatus.Branch = branch
+		fileList, ok := branchFileList[branch]
+		if !ok {
+			fileList, err = s.listMigrationFile(ctx, repo, branch)
+			if err != nil {
+				return nil, err
+			}
+			branchFileList[branch] = fileList
+		}
+
+		applied, err := s.getAppliedMigrationVersion(ctx, database)
+		if err != nil {
+			databaseStatus.Error = err.Error()
+			continue
+		}
+		var versionList []string
+		for _, mi := range fileList {
+			if matchMigrationFileDatabase(repo.Project, mi, database) {
+				versionList = append(versionList, mi.Version)
+			}
+		}
+		databaseStatus.LatestVersion = applied.latestVersion
+		databaseStatus.AppliedVersionList, databaseStatus.PendingVersionList, databaseStatus.OutOfOrderVersionList = applied.classifyVersionList(versionList)
+	}
+	return status, nil
+}
+
+// listMigrationFile returns the migration info of the migration files in the branch. The baseline files are excluded
+// since they establish a new version rather than following the order of the applied versions.
+func (s *Server) listMigrationFile(ctx context.Context, repo *api.Repository, branch string) ([]*db.MigrationInfo, error) {
+	nodeList, err := vcs.Get(repo.VCS.Type, vcs.ProviderConfig{}).FetchRepositoryFileList(
+		ctx,
+		common.OauthContext{
+			ClientID:     repo.VCS.ApplicationID,
+			ClientSecret: repo.VCS.Secret,
+			AccessToken:  repo.AccessToken,
+			RefreshToken: repo.RefreshToken,
+			Refresher:    s.refreshToken(ctx, repo.ID),
+		},
+		repo.VCS.InstanceURL,
+		repo.ExternalID,
+		branch,
+		repo.BaseDirectory,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the file list of branch %q, error: %w", branch, err)
+	}
+
+	fileSet := make(map[string]bool)
+	for _, node := range nodeList {
+		fileSet[node.Path] = true
+	}
+	var miList []*db.MigrationInfo
+	for _, node := range nodeList {
+		if node.Type != "blob" || isSkipGeneratedSchemaFile(repo, node.Path) {
+			continue
+		}
+		// The down migration file is the one alongside its up migration file.
+		if upFile, ok := getUpMigrationFile(node.Path); ok && fileSet[upFile] {
+			continue
+		}
+		mi, err := db.ParseMigrationInfo(node.Path, filepath.Join(repo.BaseDirectory, repo.FilePathTemplate))
+		if err != nil {
+			// Not a migration file.
+			continue
+		}
+		if mi.Type == db.Baseline {
+			continue
+		}
+		miList = append(miList, mi)
+	}
+	return miList, nil
+}
+
+// matchMigrationFileDatabase returns true if the migration file applies to the database.
+func matchMigrationFileDatabase(project *api.Project, mi *db.MigrationInfo, database *api.Database) bool {
+	if project.TenantMode == api.TenantModeTenant {
+		// The tenant databases are named by the database name template of the project.
+		baseDatabaseName, err := api.GetBaseDatabaseName(database.Name, project.DBNameTemplate, database.Labels)
+		return err == nil && baseDatabaseName == mi.Database
+	}
+	// Environment name comparison is case insensitive
+	return mi.Database == database.Name && (mi.Environment == "" || strings.EqualFold(mi.Environment, database.Instance.Environment.Name))
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+func TestNewAppliedMigrationVersion(t *testing.T) {
+	// The migration history list is ordered by the creation time in descending order.
+	historyList := []*db.MigrationHistory{
+		{Version: "1.10", Type: db.Migrate, Status: db.Done},
+		{Version: "1.11", Type: db.Migrate, Status: db.Failed},
+		{Version: "1.9", Type: db.Migrate, Status: db.Done},
+		{Version: "1.2", Type: db.Baseline, Status: db.Done},
+		{Version: "2.0", Type: db.Migrate, Status: db.Done},
+	}
+	applied := newAppliedMigrationVersion(historyList)
+	// The version applied before the last baseline isn't taken as the latest version.
+	require.Equal(t, "1.10", applied.latestVersion)
+	require.Equal(t, map[string]bool{"1.10": true, "1.9": true, "1.2": true, "2.0": true}, applied.versionSet)
+
+	require.False(t, applied.isOutOfOrder("1.11"))
+	require.False(t, applied.isOutOfOrder("1.9"))
+	require.True(t, applied.isOutOfOrder("1.5"))
+	require.False(t, newAppliedMigrationVersion(nil).isOutOfOrder("1.0"))
+}
+
+func TestClassifyVersionList(t *testing.T) {
+	applied := newAppliedMigrationVersion([]*db.MigrationHistory{
+		{Version: "1.5", Type: db.Migrate, Status: db.Done},
+		{Version: "1.1", Type: db.Migrate, Status: db.Done},
+	})
+	appliedList, pendingList, outOfOrderList := applied.classifyVersionList([]string{"1.10", "1.2", "1.5", "1.1", "1.6", "1.2"})
+	require.Equal(t, []string{"1.1", "1.5"}, appliedList)
+	require.Equal(t, []string{"1.6", "1.10"}, pendingList)
+	require.Equal(t, []string{"1.2"}, outOfOrderList)
+}
+
+func TestMatchMigrationFileDatabase(t *testing.T) {
+	database := &api.Database{
+		Name: "db1_us",
+		Instance: &api.Instance{
+			Environment: &api.Environment{Name: "Prod"},
+		},
+		Labels: `[{"key":"bb.location","value":"us"}]`,
+	}
+	project := &api.Project{TenantMode: api.TenantModeDisabled}
+	require.True(t, matchMigrationFileDatabase(project, &db.MigrationInfo{Database: "db1_us"}, database))
+	require.True(t, matchMigrationFileDatabase(project, &db.MigrationInfo{Database: "db1_us", Environment: "prod"}, database))
+	require.False(t, matchMigrationFileDatabase(project, &db.MigrationInfo{Database: "db1_us", Environment: "Dev"}, database))
+	require.False(t, matchMigrationFileDatabase(project, &db.MigrationInfo{Database: "db1"}, database))
+
+	tenantProject := &api.Project{TenantMode: api.TenantModeTenant, DBNameTemplate: "{{DB_NAME}}_{{LOCATION}}"}
+	require.True(t, matchMigrationFileDatabase(tenantProject, &db.MigrationInfo{Database: "db1"}, database))
+	require.False(t, matchMigrationFileDatabase(tenantProject, &db.MigrationInfo{Database: "db1_us"}, database))
+}
@@ -17,6 +17,8 @@ import (
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
 	vcsPlugin "github.com/bytebase/bytebase/plugin/vcs"
+	"github.com/bytebase/bytebase/plugin/vcs/bitbucket"
+	"github.com/bytebase/bytebase/plugin/vcs/gitea"
 	"github.com/bytebase/bytebase/plugin/vcs/github"
 	"github.com/bytebase/bytebase/plugin/vcs/gitlab"
 )
@@ -35,12 +37,23 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 		if projectCreate.TenantMode == "" {
 			projectCreate.TenantMode = api.TenantModeDisabled
 		}
-		if err := api.ValidateProjectDBNameTemplate(projectCreate.DBNameTemplate); err != nil {
+		rowStatus := api.Normal
+		labelKeyList, err := s.store.FindLabelKey(ctx, &api.LabelKeyFind{RowStatus: &rowStatus})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to find label key list").SetInternal(err)
+		}
+		if err := api.ValidateProjectDBNameTemplate(projectCreate.DBNameTemplate, labelKeyList); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Malformed create project request: %s", err.Error()))
 		}
 		if projectCreate.TenantMode != api.TenantModeTenant && projectCreate.DBNameTemplate != "" {
 			return echo.NewHTTPError(http.StatusBadRequest, "database name template can only be set for tenant mode project")
 		}
+		if v := projectCreate.SchemaChangeType; v != "" && v != api.ProjectSchemaChangeTypeDDL && v != api.ProjectSchemaChangeTypeSDL {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid schema change type: %s", v))
+		}
+		if v := projectCreate.MigrationOrder; v != "" && !isMigrationOrder(v) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid migration order: %s", v))
+		}
 		project, err := s.store.CreateProject(ctx, projectCreate)
 		if err != nil {
 			if common.ErrorCode(err) == common.Conflict {
@@ -156,6 +169,17 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 		if err := jsonapi.UnmarshalPayload(c.Request().Body, projectPatch); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "Malformed patch project request").SetInternal(err)
 		}
+		if v := projectPatch.SchemaChangeType; v != nil && *v != api.ProjectSchemaChangeTypeDDL && *v != api.ProjectSchemaChangeTypeSDL {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid schema change type: %s", *v))
+		}
+		if v := projectPatch.MigrationOrder; v != nil && !isMigrationOrder(*v) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid migration order: %s", *v))
+		}
+		if v := projectPatch.TaskRetryPolicy; v != nil && *v != "" {
+			if err := api.ValidatePolicy(api.PolicyTypeTaskRetry, *v); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid task retry policy: %v", err))
+			}
+		}
 
 		// Ensure the project has no database before it's archived.
 		if v := projectPatch.RowStatus; v != nil && *v == string(api.Archived) {
@@ -214,6 +238,12 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Malformed create linked repository request: %s", err.Error()))
 		}
 
+		branchEnvironmentList, err := s.validateBranchEnvironmentList(ctx, project, repositoryCreate.BranchEnvironmentList)
+		if err != nil {
+			return err
+		}
+		repositoryCreate.BranchEnvironmentList = branchEnvironmentList
+
 		vcs, err := s.store.GetVCSByID(ctx, repositoryCreate.VCSID)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to find VCS for creating repository: %d", repositoryCreate.VCSID)).SetInternal(err)
@@ -234,14 +264,15 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 				URL:                    fmt.Sprintf("%s:%d/%s/%s", s.profile.BackendHost, s.profile.BackendPort, gitlabWebhookPath, repositoryCreate.WebhookEndpointID),
 				SecretToken:            repositoryCreate.WebhookSecretToken,
 				PushEvents:             true,
-				PushEventsBranchFilter: repositoryCreate.BranchFilter,
+				MergeRequestsEvents:    true,
+				PushEventsBranchFilter: webhookBranchFilter(repositoryCreate.BranchFilter, repositoryCreate.BranchEnvironmentList),
 				EnableSSLVerification:  false, // TODO(tianzhou): This is set to false, be lax to not enable_ssl_verification
 			}
 			webhookCreatePayload, err = json.Marshal(webhookCreate)
 			if err != nil {
 				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal request body for creating webhook for project ID: %d", repositoryCreate.ProjectID)).SetInternal(err)
 			}
-		case vcsPlugin.GitHubCom:
+		case vcsPlugin.GitHubCom, vcsPlugin.GitHubEnterprise:
 			webhookPost := github.WebhookCreateOrUpdate{
 				Config: github.WebhookConfig{
 					URL:         fmt.Sprintf("%s/%s/%s", s.profile.BackendHost, githubWebhookPath, repositoryCreate.WebhookEndpointID),
@@ -249,12 +280,39 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 					Secret:      repositoryCreate.WebhookSecretToken,
 					InsecureSSL: 1, // TODO: Allow user to specify this value through api.RepositoryCreate
 				},
-				Events: []string{"push"},
+				Events: []string{"push", "pull_request"},
 			}
 			webhookCreatePayload, err = json.Marshal(webhookPost)
 			if err != nil {
 				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal request body for creating webhook for project ID: %d", repositoryCreate.ProjectID)).SetInternal(err)
 			}
+		case vcsPlugin.Gitea:
+			webhookCreate := gitea.WebhookCreateOrUpdate{
+				Type: "gitea",
+				Config: gitea.WebhookConfig{
+					URL:         fmt.Sprintf("%s:%d/%s/%s", s.profile.BackendHost, s.profile.BackendPort, giteaWebhookPath, repositoryCreate.WebhookEndpointID),
+					ContentType: "json",
+					Secret:      repositoryCreate.WebhookSecretToken,
+				},
+				Events:       []string{string(gitea.WebhookPush)},
+				BranchFilter: webhookBranchFilter(repositoryCreate.BranchFilter, repositoryCreate.BranchEnvironmentList),
+				Active:       true,
+			}
+			webhookCreatePayload, err = json.Marshal(webhookCreate)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal request body for creating webhook for project ID: %d", repositoryCreate.ProjectID)).SetInternal(err)
+			}
+		case vcsPlugin.Bitbucket:
+			// Bitbucket does not filter the push event by branch, the webhook receiver does it.
+			webhookCreate := bitbucket.WebhookCreateOrUpdate{
+				URL:    fmt.Sprintf("%s:%d/%s/%s", s.profile.BackendHost, s.profile.BackendPort, bitbucketWebhookPath, repositoryCreate.WebhookEndpointID),
+				Secret: repositoryCreate.WebhookSecretToken,
+				Active: true,
+			}
+			webhookCreatePayload, err = json.Marshal(webhookCreate)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal request body for creating webhook for project ID: %d", repositoryCreate.ProjectID)).SetInternal(err)
+			}
 		}
 
 		webhookID, err := vcsPlugin.Get(vcs.Type, vcsPlugin.ProviderConfig{}).CreateWebhook(
@@ -354,6 +412,14 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 			}
 		}
 
+		if repoPatch.BranchEnvironmentList != nil {
+			branchEnvironmentList, err := s.validateBranchEnvironmentList(ctx, project, *repoPatch.BranchEnvironmentList)
+			if err != nil {
+				return err
+			}
+			repoPatch.BranchEnvironmentList = &branchEnvironmentList
+		}
+
 		// Remove enclosing /
 		if repoPatch.BaseDirectory != nil {
 			baseDir := strings.Trim(*repoPatch.BaseDirectory, "/")
@@ -382,7 +448,8 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to update repository for project ID: %d", projectID)).SetInternal(err)
 		}
 
-		if repoPatch.BranchFilter != nil {
+		// The branch environment list supersedes the branch filter of the webhook.
+		if repoPatch.BranchFilter != nil || repoPatch.BranchEnvironmentList != nil {
 			vcs, err := s.store.GetVCSByID(ctx, repo.VCSID)
 			if err != nil {
 				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to update repository for project ID: %d", projectID)).SetInternal(err)
@@ -398,13 +465,14 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 			case vcsPlugin.GitLabSelfHost:
 				webhookUpdate := gitlab.WebhookUpdate{
 					URL:                    fmt.Sprintf("%s:%d/%s/%s", s.profile.BackendHost, s.profile.BackendPort, gitlabWebhookPath, updatedRepo.WebhookEndpointID),
-					PushEventsBranchFilter: *repoPatch.BranchFilter,
+					MergeRequestsEvents:    true,
+					PushEventsBranchFilter: webhookBranchFilter(updatedRepo.BranchFilter, updatedRepo.BranchEnvironmentList),
 				}
 				webhookUpdatePayload, err = json.Marshal(webhookUpdate)
 				if err != nil {
 					return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal request body for updating webhook %s for project ID: %v", repo.ExternalWebhookID, projectID)).SetInternal(err)
 				}
-			case vcsPlugin.GitHubCom:
+			case vcsPlugin.GitHubCom, vcsPlugin.GitHubEnterprise:
 				webhookUpdate := github.WebhookCreateOrUpdate{
 					Config: github.WebhookConfig{
 						URL:         fmt.Sprintf("%s:%d/%s/%s", s.profile.BackendHost, s.profile.BackendPort, githubWebhookPath, updatedRepo.WebhookEndpointID),
@@ -412,7 +480,32 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 						Secret:      updatedRepo.WebhookSecretToken,
 						InsecureSSL: 1, // TODO: Allow user to specify this value through api.RepositoryPatch
 					},
-					Events: []string{"push"},
+					Events: []string{"push", "pull_request"},
+				}
+				webhookUpdatePayload, err = json.Marshal(webhookUpdate)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal request body for updating webhook %s for project ID: %v", repo.ExternalWebhookID, projectID)).SetInternal(err)
+				}
+			case vcsPlugin.Gitea:
+				webhookUpdate := gitea.WebhookCreateOrUpdate{
+					Config: gitea.WebhookConfig{
+						URL:         fmt.Sprintf("%s:%d/%s/%s", s.profile.BackendHost, s.profile.BackendPort, giteaWebhookPath, updatedRepo.WebhookEndpointID),
+						ContentType: "json",
+						Secret:      updatedRepo.WebhookSecretToken,
+					},
+					Events:       []string{string(gitea.WebhookPush)},
+					BranchFilter: webhookBranchFilter(updatedRepo.BranchFilter, updatedRepo.BranchEnvironmentList),
+					Active:       true,
+				}
+				webhookUpdatePayload, err = json.Marshal(webhookUpdate)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal request body for updating webhook %s for project ID: %v", repo.ExternalWebhookID, projectID)).SetInternal(err)
+				}
+			case vcsPlugin.Bitbucket:
+				webhookUpdate := bitbucket.WebhookCreateOrUpdate{
+					URL:    fmt.Sprintf("%s:%d/%s/%s", s.profile.BackendHost, s.profile.BackendPort, bitbucketWebhookPath, updatedRepo.WebhookEndpointID),
+					Secret: updatedRepo.WebhookSecretToken,
+					Active: true,
 				}
 				webhookUpdatePayload, err = json.Marshal(webhookUpdate)
 				if err != nil {
@@ -516,6 +609,70 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 		return nil
 	})
 
+	g.POST("/project/:projectID/schema-consistency-check", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := strconv.Atoi(c.Param("projectID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("projectID"))).SetInternal(err)
+		}
+		if !s.feature(api.FeatureMultiTenancy) {
+			return echo.NewHTTPError(http.StatusForbidden, api.FeatureMultiTenancy.AccessErrorMessage())
+		}
+
+		project, err := s.store.GetProjectByID(ctx, id)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch project ID: %v", id)).SetInternal(err)
+		}
+		if project == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Project not found with ID %d", id))
+		}
+		if project.TenantMode != api.TenantModeTenant {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Project ID %d is not in tenant mode", id))
+		}
+
+		report, err := s.checkTenantSchemaConsistency(ctx, project)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to check schema consistency for project ID: %d", id)).SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, report); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal schema consistency report response: %v", id)).SetInternal(err)
+		}
+		return nil
+	})
+
+	g.GET("/project/:projectID/migration-status", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := strconv.Atoi(c.Param("projectID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("projectID"))).SetInternal(err)
+		}
+
+		repo, err := s.store.GetRepository(ctx, &api.RepositoryFind{ProjectID: &id})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch repository for project ID: %d", id)).SetInternal(err)
+		}
+		if repo == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Project ID %d is not linked to a VCS repository", id))
+		}
+		// The schema files of the declarative schema migration have no version.
+		if repo.Project.SchemaChangeType == api.ProjectSchemaChangeTypeSDL {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Project ID %d uses the declarative schema migration without migration versions", id))
+		}
+
+		status, err := s.getProjectMigrationStatus(ctx, repo)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch migration status for project ID: %d", id)).SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, status); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal migration status response: %v", id)).SetInternal(err)
+		}
+		return nil
+	})
+
 	g.PATCH("/project/:id/deployment", func(c echo.Context) error {
 		ctx := c.Request().Context()
 		id, err := strconv.Atoi(c.Param("id"))
@@ -581,6 +738,62 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 		}
 		return nil
 	})
+
+	g.POST("/project/:id/deployment/preview", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		schedulePreview := &api.DeploymentSchedulePreview{}
+		if err := jsonapi.UnmarshalPayload(c.Request().Body, schedulePreview); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Malformed preview deployment schedule request").SetInternal(err)
+		}
+
+		project, err := s.store.GetProjectByID(ctx, id)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch project ID: %v", id)).SetInternal(err)
+		}
+		if project == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Project not found with ID %d", id))
+		}
+
+		// Use the saved deployment configuration if the payload isn't provided.
+		payload := schedulePreview.Payload
+		if payload == "" {
+			deploymentConfig, err := s.store.GetDeploymentConfigByProjectID(ctx, id)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to get deployment configuration for project id: %d", id)).SetInternal(err)
+			}
+			if deploymentConfig == nil {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Deployment config missing for project ID: %v", id))
+			}
+			payload = deploymentConfig.Payload
+		}
+		schedule, err := api.ValidateAndGetDeploymentSchedule(payload)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid deployment schedule: %v", err)).SetInternal(err)
+		}
+
+		dbList, err := s.store.FindDatabase(ctx, &api.DatabaseFind{
+			ProjectID: &id,
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch databases in project ID: %v", id)).SetInternal(err)
+		}
+
+		preview, err := getDeploymentPreview(schedule, schedulePreview.DatabaseName, project.DBNameTemplate, dbList)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to preview deployment schedule").SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, preview); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal deployment preview response: %v", id)).SetInternal(err)
+		}
+		return nil
+	})
 }
 
 // refreshToken is a token refresher that stores the latest access token configuration to repository.
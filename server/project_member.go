@@ -120,6 +120,12 @@ func (s *Server) registerProjectMemberRoutes(g *echo.Group) {
 			roleProvider = api.ProjectRoleProviderGitLabSelfHost
 		case vcsPlugin.GitHubCom:
 			roleProvider = api.ProjectRoleProviderGitHubCom
+		case vcsPlugin.GitHubEnterprise:
+			roleProvider = api.ProjectRoleProviderGitHubEnterprise
+		case vcsPlugin.Gitea:
+			roleProvider = api.ProjectRoleProviderGitea
+		case vcsPlugin.Bitbucket:
+			roleProvider = api.ProjectRoleProviderBitbucket
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Unrecognized VCS type %q", vcs.Type))
 		}
@@ -0,0 +1,355 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/advisor"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+	"github.com/bytebase/bytebase/plugin/parser"
+	"github.com/bytebase/bytebase/plugin/vcs"
+	"github.com/bytebase/bytebase/store"
+)
+
+// sqlReviewCommitStatusContext distinguishes the commit status of the SQL review from the ones reported by other services.
+// It's the name to pick when requiring the status check to pass before merging in the branch protection rules.
+const sqlReviewCommitStatusContext = "bytebase/sql-review"
+
+// pullRequestEvent is the VCS agnostic pull request event to be reviewed.
+type pullRequestEvent struct {
+	// ID is the number of the GitHub pull request, or the IID of the GitLab merge request.
+	ID           string
+	HeadCommitID string
+	TargetBranch string
+}
+
+// lineAdvice is the SQL review advice located at the line of the statement which triggers it.
+type lineAdvice struct {
+	// Line is 0 if the advice can't be located to a single statement.
+	Line   int
+	Advice advisor.Advice
+}
+
+// statementWithLine is the statement along with the line where it starts in the file.
+type statementWithLine struct {
+	Statement string
+	Line      int
+}
+
+// reviewPullRequest runs the SQL review against the migration files added by the pull request, using the SQL
+// review policy of the environment each migration file targets. The advices are posted as inline review comments,
+// and the result is reported as the commit status, which blocks merging if it's required by the branch protection.
+func (s *Server) reviewPullRequest(ctx context.Context, repo *api.Repository, event pullRequestEvent) (string, error) {
+	// If the repository maps the branches to the environments, the migration files are only reviewed against the
+	// environment mapped by the target branch, which they are applied to after merging.
+	environment, err := s.getBranchEnvironment(ctx, repo, event.TargetBranch)
+	if err != nil {
+		if common.ErrorCode(err) == common.NotFound {
+			return fmt.Sprintf("Ignored pull request targeting branch %q, %s.", event.TargetBranch, err.Error()), nil
+		}
+		return "", err
+	}
+	if environment == nil && !matchBranchFilter(repo.BranchFilter, event.TargetBranch) {
+		return fmt.Sprintf("Ignored pull request targeting branch %q, not matching the branch filter %q.", event.TargetBranch, repo.BranchFilter), nil
+	}
+	if !s.feature(api.FeatureSQLReviewPolicy) {
+		return fmt.Sprintf("Ignored pull request, %s", api.FeatureSQLReviewPolicy.AccessErrorMessage()), nil
+	}
+	// The project with declarative schema migration takes the desired schema instead of the migration statement.
+	if repo.Project.SchemaChangeType == api.ProjectSchemaChangeTypeSDL {
+		return "Ignored pull request, the SQL review is not applicable to the declarative schema migration.", nil
+	}
+
+	provider := vcs.Get(repo.VCS.Type, vcs.ProviderConfig{})
+	reviewer, ok := provider.(vcs.PullRequestReviewer)
+	if !ok {
+		return "", fmt.Errorf("VCS type %q does not support reviewing pull requests", repo.VCS.Type)
+	}
+	oauthContext := common.OauthContext{
+		ClientID:     repo.VCS.ApplicationID,
+		ClientSecret: repo.VCS.Secret,
+		AccessToken:  repo.AccessToken,
+		RefreshToken: repo.RefreshToken,
+		Refresher:    s.refreshToken(ctx, repo.ID),
+	}
+	setCommitStatus := func(state vcs.CommitState, description string) error {
+		return reviewer.SetCommitStatus(ctx, oauthContext, repo.VCS.InstanceURL, repo.ExternalID, event.HeadCommitID,
+			&vcs.CommitStatus{
+				State:       state,
+				Context:     sqlReviewCommitStatusContext,
+				Description: description,
+				TargetURL:   fmt.Sprintf("%s:%d/project/%s", s.profile.FrontendHost, s.profile.FrontendPort, api.ProjectSlug(repo.Project)),
+			},
+		)
+	}
+
+	if err := setCommitStatus(vcs.CommitStatePending, "Reviewing the migration files"); err != nil {
+		return "", fmt.Errorf("failed to set the pending commit status, error: %w", err)
+	}
+	commentList, summary, errorCount, warningCount, err := s.sqlReviewPullRequestFiles(ctx, repo, provider, reviewer, oauthContext, event, environment)
+	if err != nil {
+		if statusErr := setCommitStatus(vcs.CommitStateError, "Failed to review the migration files"); statusErr != nil {
+			log.Warn("Failed to set the error commit status", zap.Error(statusErr))
+		}
+		return "", err
+	}
+
+	if len(commentList) > 0 || len(summary) > 0 {
+		body := fmt.Sprintf("Bytebase SQL review found %d error(s) and %d warning(s) in the migration files.", errorCount, warningCount)
+		if len(summary) > 0 {
+			body += "\n\n" + strings.Join(summary, "\n")
+		}
+		if err := reviewer.CreatePullRequestReview(ctx, oauthContext, repo.VCS.InstanceURL, repo.ExternalID, event.ID, event.HeadCommitID, body, commentList); err != nil {
+			if statusErr := setCommitStatus(vcs.CommitStateError, "Failed to post the review comments"); statusErr != nil {
+				log.Warn("Failed to set the error commit status", zap.Error(statusErr))
+			}
+			return "", fmt.Errorf("failed to create the pull request review, error: %w", err)
+		}
+	}
+
+	state, description := vcs.CommitStateSuccess, "No SQL review error found"
+	if errorCount > 0 {
+		state, description = vcs.CommitStateFailure, fmt.Sprintf("Found %d SQL review error(s)", errorCount)
+	} else if warningCount > 0 {
+		description = fmt.Sprintf("Found %d SQL review warning(s)", warningCount)
+	}
+	if err := setCommitStatus(state, description); err != nil {
+		return "", fmt.Errorf("failed to set the commit status, error: %w", err)
+	}
+	return fmt.Sprintf("Reviewed pull request %s, %s.", event.ID, strings.ToLower(description)), nil
+}
+
+// sqlReviewPullRequestFiles reviews the migration files added by the pull request. It returns the inline review comments
+// and the summary lines for the advices which can't be located or the files which can't be reviewed. If the environment
+// is not nil, only the databases in the environment are reviewed.
+func (s *Server) sqlReviewPullRequestFiles(ctx context.Context, repo *api.Repository, provider vcs.Provider, reviewer vcs.PullRequestReviewer, oauthContext common.OauthContext, event pullRequestEvent, environment *api.Environment) (commentList []*vcs.ReviewComment, summary []string, errorCount, warningCount int, _ error) {
+	fileList, err := reviewer.ListPullRequestFiles(ctx, oauthContext, repo.VCS.InstanceURL, repo.ExternalID, event.ID)
+	if err != nil {
+		return nil, nil, 0, 0, fmt.Errorf("failed to list the pull request files, error: %w", err)
+	}
+
+	// The same advice may be reported by multiple environments sharing the same migration file.
+	commented := make(map[string]bool)
+	for _, file := range fileList {
+		// Only the newly added migration files are applied by the push event.
+		if !file.IsNew || !strings.HasPrefix(file.Path, repo.BaseDirectory) || isSkipGeneratedSchemaFile(repo, file.Path) {
+			continue
+		}
+		isDown, err := s.isDownMigrationFile(ctx, repo, file.Path, event.HeadCommitID)
+		if err != nil {
+			return nil, nil, 0, 0, err
+		}
+		if isDown {
+			continue
+		}
+		mi, err := db.ParseMigrationInfo(file.Path, filepath.Join(repo.BaseDirectory, repo.FilePathTemplate))
+		if err != nil {
+			// Not a migration file.
+			continue
+		}
+		if environment != nil {
+			// Environment name comparison is case insensitive
+			if mi.Environment != "" && !strings.EqualFold(mi.Environment, environment.Name) {
+				summary = append(summary, fmt.Sprintf("- `%s`: skipped, environment %q does not match environment %q mapped by branch %q.", file.Path, mi.Environment, environment.Name, event.TargetBranch))
+				continue
+			}
+			mi.Environment = environment.Name
+		}
+
+		databaseList, err := s.findMigrationDatabaseList(ctx, repo.ProjectID, mi)
+		if err != nil {
+			return nil, nil, 0, 0, err
+		}
+		if len(databaseList) == 0 {
+			summary = append(summary, fmt.Sprintf("- `%s`: skipped, the project does not contain database %q.", file.Path, mi.Database))
+			continue
+		}
+
+		content, err := provider.ReadFileContent(ctx, oauthContext, repo.VCS.InstanceURL, repo.ExternalID, file.Path, event.HeadCommitID)
+		if err != nil {
+			return nil, nil, 0, 0, fmt.Errorf("failed to read file %q, error: %w", file.Path, err)
+		}
+
+		for _, database := range databaseList {
+			environment := database.Instance.Environment.Name
+			if !api.IsSQLReviewSupported(database.Instance.Engine, s.profile.Mode) {
+				summary = append(summary, fmt.Sprintf("- `%s`: skipped for environment %q, the SQL review does not support %s.", file.Path, environment, database.Instance.Engine))
+				continue
+			}
+			policyID, err := s.store.GetSchemaReviewPolicyIDByEnvID(ctx, database.Instance.EnvironmentID)
+			if err != nil {
+				return nil, nil, 0, 0, fmt.Errorf("failed to get the SQL review policy ID for environment %q, error: %w", environment, err)
+			}
+			policy, err := s.store.GetNormalSchemaReviewPolicy(ctx, &api.PolicyFind{ID: &policyID})
+			if err != nil {
+				if common.ErrorCode(err) == common.NotFound {
+					continue
+				}
+				return nil, nil, 0, 0, fmt.Errorf("failed to get the SQL review policy for environment %q, error: %w", environment, err)
+			}
+			advisorDBType, err := api.ConvertToAdvisorDBType(database.Instance.Engine)
+			if err != nil {
+				return nil, nil, 0, 0, err
+			}
+
+			adviceList, err := sqlReviewWithLine(database.Instance.Engine, content, policy.RuleList, advisor.SQLReviewCheckContext{
+				Charset:   database.CharacterSet,
+				Collation: database.Collation,
+				DbType:    advisorDBType,
+				Catalog:   store.NewCatalog(&database.ID, s.store, database.Instance.Engine),
+			})
+			if err != nil {
+				return nil, nil, 0, 0, fmt.Errorf("failed to review file %q for environment %q, error: %w", file.Path, environment, err)
+			}
+			for _, item := range adviceList {
+				key := fmt.Sprintf("%s:%d:%d:%s", file.Path, item.Line, item.Advice.Code, item.Advice.Content)
+				if commented[key] {
+					continue
+				}
+				commented[key] = true
+
+				switch item.Advice.Status {
+				case advisor.Error:
+					errorCount++
+				case advisor.Warn:
+					warningCount++
+				}
+				body := fmt.Sprintf("**[%s] %s** (environment %q)\n\n%s", item.Advice.Status, item.Advice.Title, environment, item.Advice.Content)
+				if item.Line == 0 {
+					summary = append(summary, fmt.Sprintf("- `%s`: %s", file.Path, strings.ReplaceAll(body, "\n\n", " ")))
+					continue
+				}
+				commentList = append(commentList, &vcs.ReviewComment{
+					Path: file.Path,
+					Line: item.Line,
+					Body: body,
+				})
+			}
+		}
+	}
+	return commentList, summary, errorCount, warningCount, nil
+}
+
+// findMigrationDatabaseList finds the databases in the project which the migration file applies to.
+// The environment in the migration info is optional, the database in each environment is returned if absent.
+func (s *Server) findMigrationDatabaseList(ctx context.Context, projectID int, mi *db.MigrationInfo) ([]*api.Database, error) {
+	databaseList, err := s.store.FindDatabase(ctx, &api.DatabaseFind{
+		ProjectID: &projectID,
+		Name:      &mi.Database,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find database matching database %q referenced by the migration file, error: %w", mi.Database, err)
+	}
+	if mi.Environment == "" {
+		return databaseList, nil
+	}
+
+	var filteredDatabaseList []*api.Database
+	for _, database := range databaseList {
+		// Environment name comparison is case insensitive
+		if strings.EqualFold(database.Instance.Environment.Name, mi.Environment) {
+			filteredDatabaseList = append(filteredDatabaseList, database)
+		}
+	}
+	return filteredDatabaseList, nil
+}
+
+// sqlReviewWithLine runs the SQL review against the whole content, which is the same as the task check does.
+// The advisors don't report the position, so each advice is located by reviewing the statements one by one,
+// and attributed to the first statement reporting the same advice.
+func sqlReviewWithLine(dbType db.Type, content string, ruleList []*advisor.SQLReviewRule, checkContext advisor.SQLReviewCheckContext) ([]lineAdvice, error) {
+	adviceList, err := advisor.SchemaReviewCheck(content, ruleList, checkContext)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []lineAdvice
+	for _, advice := range adviceList {
+		if advice.Status == advisor.Success {
+			continue
+		}
+		result = append(result, lineAdvice{Advice: advice})
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	statementList, err := splitStatementWithLine(dbType, content)
+	if err != nil {
+		// The statements can't be located, report the advices at the file level.
+		return result, nil
+	}
+	for _, statement := range statementList {
+		statementAdviceList, err := advisor.SchemaReviewCheck(statement.Statement, ruleList, checkContext)
+		if err != nil {
+			return nil, err
+		}
+		for _, statementAdvice := range statementAdviceList {
+			for i := range result {
+				if result[i].Line == 0 && sameAdvice(result[i].Advice, statementAdvice) {
+					result[i].Line = statement.Line
+					break
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func sameAdvice(a, b advisor.Advice) bool {
+	return a.Status == b.Status && a.Code == b.Code && a.Title == b.Title && a.Content == b.Content
+}
+
+// splitStatementWithLine splits the content into statements, along with the line where each statement starts.
+func splitStatementWithLine(dbType db.Type, content string) ([]statementWithLine, error) {
+	var statementList []string
+	var err error
+	switch dbType {
+	case db.MySQL, db.TiDB:
+		statementList, err = util.SplitMultiStatements(content)
+	case db.Postgres:
+		statementList, err = parser.SplitMultiSQL(parser.Postgres, content)
+	default:
+		return nil, fmt.Errorf("splitting statements is not supported for %s", dbType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result []statementWithLine
+	// The statements are located in order, so we search from the end of the previous statement.
+	cursor := 0
+	for _, statement := range statementList {
+		// The leading comments are kept in the statement by some splitters, which is not where the statement starts.
+		anchor := ""
+		for _, line := range strings.Split(statement, "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "--") {
+				anchor = line
+				break
+			}
+		}
+		if anchor == "" {
+			continue
+		}
+		index := strings.Index(content[cursor:], anchor)
+		if index < 0 {
+			return nil, fmt.Errorf("failed to locate statement %q", anchor)
+		}
+		start := cursor + index
+		result = append(result, statementWithLine{
+			Statement: statement,
+			Line:      strings.Count(content[:start], "\n") + 1,
+		})
+		cursor = start + len(anchor)
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatementWithLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbType  db.Type
+		content string
+		want    []int
+	}{
+		{
+			name:   "MySQL",
+			dbType: db.MySQL,
+			content: `-- Create the tables.
+CREATE TABLE t1 (
+  id INT PRIMARY KEY
+);
+
+CREATE TABLE t2 (id INT);
+-- Add the index.
+CREATE INDEX idx_t1_id
+  ON t1 (id);
+`,
+			want: []int{2, 6, 8},
+		},
+		{
+			name:   "Postgres",
+			dbType: db.Postgres,
+			content: `-- Create the table.
+CREATE TABLE t1 (
+  id INT PRIMARY KEY
+);
+
+-- Add the column.
+ALTER TABLE t1 ADD COLUMN name TEXT;`,
+			want: []int{2, 7},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statementList, err := splitStatementWithLine(test.dbType, test.content)
+			require.NoError(t, err)
+			var lineList []int
+			for _, statement := range statementList {
+				lineList = append(lineList, statement.Line)
+			}
+			assert.Equal(t, test.want, lineList)
+		})
+	}
+
+	_, err := splitStatementWithLine(db.Snowflake, "SELECT 1;")
+	assert.Error(t, err)
+}
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/jsonapi"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+)
+
+func (s *Server) registerPushEventDeliveryRoutes(g *echo.Group) {
+	g.GET("/project/:projectID/push-event-delivery", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		projectID, err := strconv.Atoi(c.Param("projectID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Project ID is not a number: %s", c.Param("projectID"))).SetInternal(err)
+		}
+
+		find := &api.PushEventDeliveryFind{
+			ProjectID: &projectID,
+		}
+		if limitStr := c.QueryParam("limit"); limitStr != "" {
+			limit, err := strconv.Atoi(limitStr)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Query parameter limit is not a number: %s", limitStr)).SetInternal(err)
+			}
+			if limit <= 0 {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Query parameter limit must be positive: %d", limit))
+			}
+			find.Limit = &limit
+		}
+		deliveryList, err := s.store.FindPushEventDelivery(ctx, find)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch push event delivery list for project ID: %d", projectID)).SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, deliveryList); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal push event delivery list response for project ID: %d", projectID)).SetInternal(err)
+		}
+		return nil
+	})
+
+	g.GET("/project/:projectID/push-event-delivery/:deliveryID", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		delivery, err := s.getProjectPushEventDelivery(ctx, c.Param("projectID"), c.Param("deliveryID"))
+		if err != nil {
+			return err
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, delivery); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal push event delivery ID response: %d", delivery.ID)).SetInternal(err)
+		}
+		return nil
+	})
+
+	// Replay re-runs the files of the push event which haven't had an issue created, e.g. after fixing the
+	// file path template or recovering from a transient error. The delivery is updated with the new outcome.
+	// A delivery being processed, either by the webhook or by another replay, cannot be replayed.
+	g.POST("/project/:projectID/push-event-delivery/:deliveryID/replay", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		delivery, err := s.getProjectPushEventDelivery(ctx, c.Param("projectID"), c.Param("deliveryID"))
+		if err != nil {
+			return err
+		}
+		if delivery.Status == api.PushEventDeliveryRunning {
+			return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("Push event delivery ID %d is being processed", delivery.ID))
+		}
+
+		repo, err := s.store.GetRepository(ctx, &api.RepositoryFind{ProjectID: &delivery.ProjectID})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch repository for project ID: %d", delivery.ProjectID)).SetInternal(err)
+		}
+		if repo == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Project ID %d is not linked to a VCS repository", delivery.ProjectID))
+		}
+		if repo.ID != delivery.RepositoryID {
+			return echo.NewHTTPError(http.StatusBadRequest, "Cannot replay the push event received by a repository no longer linked to the project")
+		}
+		if repo.VCS == nil {
+			err := fmt.Errorf("VCS not found for ID: %v", repo.VCSID)
+			return echo.NewHTTPError(http.StatusInternalServerError, err).SetInternal(err)
+		}
+
+		updaterID := c.Get(getPrincipalIDContextKey()).(int)
+		// Claim the delivery by moving it from the status we read to running, so that only one replay
+		// processes the files and the others get a conflict.
+		// The payload is read from the claimed delivery, since the one we read may have been updated by a replay finished
+		// in between.
+		runningStatus := api.PushEventDeliveryRunning
+		claimedDelivery, err := s.store.PatchPushEventDelivery(ctx, &api.PushEventDeliveryPatch{
+			ID:             delivery.ID,
+			UpdaterID:      updaterID,
+			Status:         &runningStatus,
+			ExpectedStatus: &delivery.Status,
+		})
+		if err != nil {
+			if common.ErrorCode(err) == common.Conflict {
+				return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("Push event delivery ID %d is being processed", delivery.ID)).SetInternal(err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to update push event delivery ID: %d", delivery.ID)).SetInternal(err)
+		}
+
+		var payload api.PushEventDeliveryPayload
+		if err := json.Unmarshal([]byte(claimedDelivery.Payload), &payload); err != nil {
+			if _, patchErr := s.store.PatchPushEventDelivery(ctx, &api.PushEventDeliveryPatch{
+				ID:        delivery.ID,
+				UpdaterID: updaterID,
+				Status:    &delivery.Status,
+			}); patchErr != nil {
+				log.Error("Failed to restore the status of the push event delivery",
+					zap.Int("delivery", delivery.ID),
+					zap.Error(patchErr),
+				)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to unmarshal push event delivery payload: %d", delivery.ID)).SetInternal(err)
+		}
+
+		log.Debug("Replaying push event delivery...",
+			zap.String("project", repo.Project.Name),
+			zap.Int("delivery", delivery.ID),
+		)
+		status, _ := s.createIssueFromPushEventFileList(ctx, repo, payload.FileList)
+		updatedDelivery, err := s.finishPushEventDelivery(ctx, delivery.ID, updaterID, status, payload.FileList)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to update push event delivery ID: %d", delivery.ID)).SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, updatedDelivery); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal push event delivery replay response: %d", delivery.ID)).SetInternal(err)
+		}
+		return nil
+	})
+}
+
+// getProjectPushEventDelivery gets the push event delivery of the project from the path parameters.
+// An *echo.HTTPError is returned if the delivery doesn't exist or doesn't belong to the project.
+func (s *Server) getProjectPushEventDelivery(ctx context.Context, projectIDStr, deliveryIDStr string) (*api.PushEventDelivery, error) {
+	projectID, err := strconv.Atoi(projectIDStr)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Project ID is not a number: %s", projectIDStr)).SetInternal(err)
+	}
+	id, err := strconv.Atoi(deliveryIDStr)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Push event delivery ID is not a number: %s", deliveryIDStr)).SetInternal(err)
+	}
+
+	delivery, err := s.store.GetPushEventDeliveryByID(ctx, id)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch push event delivery ID: %d", id)).SetInternal(err)
+	}
+	if delivery == nil || delivery.ProjectID != projectID {
+		return nil, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Push event delivery ID not found: %d", id))
+	}
+	return delivery, nil
+}
+
+// processPushEvent creates the issues from the added files of the push event, and records the
+// delivery along with the outcome of each file so that it can be inspected and replayed later.
+// The delivery is recorded as running before processing the files, so that it can't be replayed
+// until the webhook finishes. It returns the creation messages of the created issues. A failed
+// file doesn't stop processing the rest files, so that the outcome of every file is recorded, and
+// the first error is returned after all files have been processed.
+func (s *Server) processPushEvent(ctx context.Context, repo *api.Repository, rawPayload []byte, fileList []*api.PushEventFile) ([]string, error) {
+	// Failing to record the delivery shouldn't fail the webhook, since the issues can still be created.
+	var delivery *api.PushEventDelivery
+	bytes, err := json.Marshal(api.PushEventDeliveryPayload{FileList: fileList})
+	if err != nil {
+		log.Error("Failed to construct push event delivery payload",
+			zap.String("project", repo.Project.Name),
+			zap.Error(err),
+		)
+	} else if delivery, err = s.store.CreatePushEventDelivery(ctx, &api.PushEventDeliveryCreate{
+		CreatorID:    api.SystemBotID,
+		ProjectID:    repo.ProjectID,
+		RepositoryID: repo.ID,
+		Status:       api.PushEventDeliveryRunning,
+		RawPayload:   string(rawPayload),
+		Payload:      string(bytes),
+	}); err != nil {
+		log.Error("Failed to create push event delivery",
+			zap.String("project", repo.Project.Name),
+			zap.Error(err),
+		)
+	}
+
+	status, firstErr := s.createIssueFromPushEventFileList(ctx, repo, fileList)
+
+	if delivery != nil {
+		if _, err := s.finishPushEventDelivery(ctx, delivery.ID, api.SystemBotID, status, fileList); err != nil {
+			log.Error("Failed to update push event delivery",
+				zap.String("project", repo.Project.Name),
+				zap.Int("delivery", delivery.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	var createdMessageList []string
+	for _, file := range fileList {
+		if file.Status == api.PushEventFileIssueCreated {
+			createdMessageList = append(createdMessageList, file.Message)
+		}
+	}
+	return createdMessageList, firstErr
+}
+
+// finishPushEventDelivery records the outcome of processing the files of the push event delivery,
+// which releases the delivery for replaying. The status is still recorded if the files fail to be
+// encoded, so that the delivery doesn't stay running.
+func (s *Server) finishPushEventDelivery(ctx context.Context, id int, updaterID int, status api.PushEventDeliveryStatus, fileList []*api.PushEventFile) (*api.PushEventDelivery, error) {
+	patch := &api.PushEventDeliveryPatch{
+		ID:        id,
+		UpdaterID: updaterID,
+		Status:    &status,
+	}
+	bytes, marshalErr := json.Marshal(api.PushEventDeliveryPayload{FileList: fileList})
+	if marshalErr == nil {
+		payload := string(bytes)
+		patch.Payload = &payload
+	}
+	delivery, err := s.store.PatchPushEventDelivery(ctx, patch)
+	if err != nil {
+		return nil, err
+	}
+	if marshalErr != nil {
+		return nil, fmt.Errorf("failed to construct push event delivery payload, error: %w", marshalErr)
+	}
+	return delivery, nil
+}
+
+// createIssueFromPushEventFileList creates the issues from the files of the push event in place,
+// skipping the files which already have an issue created. It returns the delivery status along
+// with the first error during the process.
+func (s *Server) createIssueFromPushEventFileList(ctx context.Context, repo *api.Repository, fileList []*api.PushEventFile) (api.PushEventDeliveryStatus, error) {
+	status := api.PushEventDeliveryDone
+	var firstErr error
+	for i, file := range fileList {
+		if file.Status == api.PushEventFileIssueCreated {
+			continue
+		}
+
+		result, err := s.createIssueFromPushEvent(ctx, repo, file.PushEvent, file.File)
+		if err != nil {
+			log.Error("Failed to create issue from the committed file",
+				zap.String("project", repo.Project.Name),
+				zap.String("file", file.PushEvent.FileCommit.Added),
+				zap.Error(err),
+			)
+			result = &api.PushEventFile{
+				PushEvent: file.PushEvent,
+				File:      file.File,
+				Status:    api.PushEventFileError,
+				Message:   pushEventFileErrorMessage(err),
+			}
+			status = api.PushEventDeliveryFailed
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		fileList[i] = result
+	}
+	return status, firstErr
+}
+
+// pushEventFileErrorMessage returns the message of the error creating the issue from the file,
+// which includes the internal error of the *echo.HTTPError for troubleshooting.
+func pushEventFileErrorMessage(err error) string {
+	httpErr, ok := err.(*echo.HTTPError)
+	if !ok {
+		return err.Error()
+	}
+	if httpErr.Internal != nil {
+		return fmt.Sprintf("%v: %v", httpErr.Message, httpErr.Internal)
+	}
+	return fmt.Sprintf("%v", httpErr.Message)
+}
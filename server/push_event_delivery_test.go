@@ -0,0 +1,40 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushEventFileErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "HTTP error with internal error",
+			err:  echo.NewHTTPError(http.StatusInternalServerError, "Failed to create schema update issue").SetInternal(fmt.Errorf("connection refused")),
+			want: "Failed to create schema update issue: connection refused",
+		},
+		{
+			name: "HTTP error without internal error",
+			err:  echo.NewHTTPError(http.StatusForbidden, "Multi-tenancy is a TEAM feature"),
+			want: "Multi-tenancy is a TEAM feature",
+		},
+		{
+			name: "plain error",
+			err:  fmt.Errorf("unexpected error"),
+			want: "unexpected error",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, pushEventFileErrorMessage(test.err))
+		})
+	}
+}
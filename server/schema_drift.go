@@ -0,0 +1,217 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/vcs"
+)
+
+const (
+	// schemaDriftBranchPrefix is the prefix of the branch committing the actual schema of the drifted database.
+	schemaDriftBranchPrefix = "bytebase/schema-drift"
+	// maxSchemaDriftDiffLength is the maximum length of the schema diff in the pull request description,
+	// which keeps the description within the size limit of the VCS providers.
+	maxSchemaDriftDiffLength = 60000
+)
+
+var invalidBranchCharacterRegexp = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// createSchemaDriftPullRequest opens a pull request committing the actual schema of the drifted database to the
+// schema file in the repository, so that the drift is reconciled through code review. A single pull request is
+// opened for the drift from the same recorded version, and its URL is kept in the schema drift anomaly.
+// It returns the URL of the pull request, or an empty string if the project of the database isn't in the VCS
+// workflow, doesn't configure the schema path template, or its VCS doesn't support creating pull requests.
+func (s *Server) createSchemaDriftPullRequest(ctx context.Context, instance *api.Instance, database *api.Database, payload *api.AnomalyDatabaseSchemaDriftPayload) (string, error) {
+	if pullRequestURL, err := s.getSchemaDriftPullRequestURL(ctx, database, payload.Version); err != nil || pullRequestURL != "" {
+		return pullRequestURL, err
+	}
+
+	repo, err := s.store.GetRepository(ctx, &api.RepositoryFind{ProjectID: &database.ProjectID})
+	if err != nil {
+		return "", fmt.Errorf("failed to find repository of project ID %d, error: %w", database.ProjectID, err)
+	}
+	// The schema file is shared by the tenant databases, whose drift is covered by the schema inconsistency anomaly.
+	if repo == nil || repo.SchemaPathTemplate == "" || repo.Project.TenantMode == api.TenantModeTenant || instance.Environment == nil {
+		return "", nil
+	}
+	if repo.VCS == nil {
+		return "", fmt.Errorf("VCS not found for ID: %d", repo.VCSID)
+	}
+	provider := vcs.Get(repo.VCS.Type, vcs.ProviderConfig{})
+	creator, ok := provider.(vcs.PullRequestCreator)
+	if !ok {
+		return "", nil
+	}
+	baseBranch, err := getEnvironmentBranch(repo, instance.EnvironmentID)
+	if err != nil {
+		return "", err
+	}
+	if baseBranch == "" {
+		log.Debug("Skip opening pull request for schema drift since the branch to merge into is unknown",
+			zap.String("project", repo.Project.Name),
+			zap.String("database", database.Name),
+		)
+		return "", nil
+	}
+
+	schemaFile := filepath.Join(repo.BaseDirectory, repo.SchemaPathTemplate)
+	schemaFile = strings.ReplaceAll(schemaFile, "{{ENV_NAME}}", instance.Environment.Name)
+	schemaFile = strings.ReplaceAll(schemaFile, "{{DB_NAME}}", database.Name)
+	oauthCtx := common.OauthContext{
+		ClientID:     repo.VCS.ApplicationID,
+		ClientSecret: repo.VCS.Secret,
+		AccessToken:  repo.AccessToken,
+		RefreshToken: repo.RefreshToken,
+		Refresher:    s.refreshToken(ctx, repo.ID),
+	}
+
+	base, err := creator.GetBranch(ctx, oauthCtx, repo.VCS.InstanceURL, repo.ExternalID, baseBranch)
+	if err != nil {
+		return "", fmt.Errorf("failed to get branch %q, error: %w", baseBranch, err)
+	}
+	branch := getSchemaDriftBranch(instance.Environment.Name, database.Name, payload.Version)
+	if err := creator.CreateBranch(ctx, oauthCtx, repo.VCS.InstanceURL, repo.ExternalID, &vcs.BranchInfo{
+		Name:         branch,
+		LastCommitID: base.LastCommitID,
+	}); err != nil && common.ErrorCode(err) != common.Conflict {
+		// The branch is left by the previous attempt if it exists, and we continue to commit and open the pull request.
+		return "", fmt.Errorf("failed to create branch %q, error: %w", branch, err)
+	}
+
+	title := fmt.Sprintf("[Bytebase] Reconcile schema drift of %q", database.Name)
+	fileCommit := vcs.FileCommitCreate{
+		Branch:        branch,
+		CommitMessage: fmt.Sprintf("%s\n\nTHIS COMMIT IS AUTO-GENERATED BY BYTEBASE", title),
+		Content:       payload.Actual,
+	}
+	meta, err := provider.ReadFileMeta(ctx, oauthCtx, repo.VCS.InstanceURL, repo.ExternalID, schemaFile, branch)
+	if err != nil {
+		if common.ErrorCode(err) != common.NotFound {
+			return "", fmt.Errorf("failed to read file %q, error: %w", schemaFile, err)
+		}
+		if err := provider.CreateFile(ctx, oauthCtx, repo.VCS.InstanceURL, repo.ExternalID, schemaFile, fileCommit); err != nil {
+			return "", fmt.Errorf("failed to create file %q, error: %w", schemaFile, err)
+		}
+	} else {
+		// The branch left by the previous attempt may have committed the actual schema already.
+		content, err := provider.ReadFileContent(ctx, oauthCtx, repo.VCS.InstanceURL, repo.ExternalID, schemaFile, branch)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file %q, error: %w", schemaFile, err)
+		}
+		if content != payload.Actual {
+			fileCommit.LastCommitID = meta.LastCommitID
+			if err := provider.OverwriteFile(ctx, oauthCtx, repo.VCS.InstanceURL, repo.ExternalID, schemaFile, fileCommit); err != nil {
+				return "", fmt.Errorf("failed to overwrite file %q, error: %w", schemaFile, err)
+			}
+		}
+	}
+
+	pullRequest, err := creator.CreatePullRequest(ctx, oauthCtx, repo.VCS.InstanceURL, repo.ExternalID, &vcs.PullRequestCreate{
+		Title: title,
+		Body:  getSchemaDriftPullRequestBody(instance.Environment.Name, database.Name, payload),
+		Head:  branch,
+		Base:  baseBranch,
+	})
+	if err != nil {
+		if common.ErrorCode(err) != common.Conflict {
+			return "", fmt.Errorf("failed to create pull request from branch %q to %q, error: %w", branch, baseBranch, err)
+		}
+		// The pull request has been opened before, e.g. the anomaly was archived and detected again from the same version.
+		pullRequest, err = creator.FindOpenPullRequest(ctx, oauthCtx, repo.VCS.InstanceURL, repo.ExternalID, branch, baseBranch)
+		if err != nil {
+			return "", fmt.Errorf("failed to find pull request from branch %q to %q, error: %w", branch, baseBranch, err)
+		}
+		if pullRequest == nil {
+			return "", fmt.Errorf("pull request from branch %q to %q already exists but isn't found", branch, baseBranch)
+		}
+	}
+	log.Info("Opened pull request for schema drift",
+		zap.String("project", repo.Project.Name),
+		zap.String("database", database.Name),
+		zap.String("url", pullRequest.URL),
+	)
+	return pullRequest.URL, nil
+}
+
+// getSchemaDriftPullRequestURL returns the URL of the pull request opened for the active schema drift anomaly of the
+// database if the drift is from the same recorded version, or an empty string otherwise.
+func (s *Server) getSchemaDriftPullRequestURL(ctx context.Context, database *api.Database, version string) (string, error) {
+	anomalyType := api.AnomalyDatabaseSchemaDrift
+	rowStatus := api.Normal
+	anomalyList, err := s.store.FindAnomaly(ctx, &api.AnomalyFind{
+		RowStatus:  &rowStatus,
+		DatabaseID: &database.ID,
+		Type:       &anomalyType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to find anomaly of database %q, error: %w", database.Name, err)
+	}
+	if len(anomalyList) == 0 {
+		return "", nil
+	}
+	payload := &api.AnomalyDatabaseSchemaDriftPayload{}
+	if err := json.Unmarshal([]byte(anomalyList[0].Payload), payload); err != nil {
+		return "", fmt.Errorf("failed to unmarshal anomaly payload of database %q, error: %w", database.Name, err)
+	}
+	if payload.Version != version {
+		return "", nil
+	}
+	return payload.PullRequestURL, nil
+}
+
+// getSchemaDriftBranch returns the branch committing the actual schema of the drifted database, which is unique
+// for the drift from the recorded version of the database in the environment.
+func getSchemaDriftBranch(environmentName, databaseName, version string) string {
+	var partList []string
+	for _, part := range []string{environmentName, databaseName, version} {
+		part = strings.Trim(invalidBranchCharacterRegexp.ReplaceAllString(part, "-"), "-.")
+		if part == "" {
+			part = "-"
+		}
+		partList = append(partList, part)
+	}
+	return fmt.Sprintf("%s/%s", schemaDriftBranchPrefix, strings.Join(partList, "/"))
+}
+
+// getSchemaDriftPullRequestBody returns the description of the schema drift pull request, which includes the diff
+// of the actual schema against the schema recorded by the latest migration.
+func getSchemaDriftPullRequestBody(environmentName, databaseName string, payload *api.AnomalyDatabaseSchemaDriftPayload) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(payload.Expect),
+		B:        difflib.SplitLines(payload.Actual),
+		FromFile: fmt.Sprintf("%s (version %s)", databaseName, payload.Version),
+		ToFile:   fmt.Sprintf("%s (actual)", databaseName),
+		Context:  3,
+	})
+	if err != nil {
+		diff = fmt.Sprintf("failed to compute schema diff, error: %v", err)
+	}
+	if len(diff) > maxSchemaDriftDiffLength {
+		diff = diff[:maxSchemaDriftDiffLength] + "\n... (truncated)"
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Bytebase detected that the schema of database %q in environment %q has drifted from the schema recorded after applying version %s, ", databaseName, environmentName, payload.Version)
+	body.WriteString("i.e. the database has been changed outside of the migration workflow.\n\n")
+	body.WriteString("This pull request commits the actual schema to the schema file. Please review the drift below. To accept the change, ")
+	body.WriteString("merge it and establish a new baseline for the database. Otherwise, close it and revert the change in the database.\n\n")
+	body.WriteString("```diff\n")
+	body.WriteString(diff)
+	if !strings.HasSuffix(diff, "\n") {
+		body.WriteString("\n")
+	}
+	body.WriteString("```\n\n")
+	body.WriteString("THIS PULL REQUEST IS AUTO-GENERATED BY BYTEBASE")
+	return body.String()
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/api"
+)
+
+func TestGetSchemaDriftBranch(t *testing.T) {
+	tests := []struct {
+		environmentName string
+		databaseName    string
+		version         string
+		want            string
+	}{
+		{"Prod", "db1", "20220801120000", "bytebase/schema-drift/Prod/db1/20220801120000"},
+		{"Staging Area", "my db", "1.2.0", "bytebase/schema-drift/Staging-Area/my-db/1.2.0"},
+		{"..", "db1", "v1~2^3", "bytebase/schema-drift/-/db1/v1-2-3"},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.want, getSchemaDriftBranch(test.environmentName, test.databaseName, test.version))
+	}
+}
+
+func TestGetSchemaDriftPullRequestBody(t *testing.T) {
+	body := getSchemaDriftPullRequestBody("Prod", "db1", &api.AnomalyDatabaseSchemaDriftPayload{
+		Version: "20220801120000",
+		Expect:  "CREATE TABLE t (\n  id INT\n);\n",
+		Actual:  "CREATE TABLE t (\n  id INT,\n  name TEXT\n);\n",
+	})
+	require.Contains(t, body, `database "db1" in environment "Prod"`)
+	require.Contains(t, body, "--- db1 (version 20220801120000)\n+++ db1 (actual)\n")
+	require.Contains(t, body, "-  id INT\n+  id INT,\n+  name TEXT\n")
+	require.True(t, strings.HasSuffix(body, "THIS PULL REQUEST IS AUTO-GENERATED BY BYTEBASE"))
+
+	// The diff is truncated to keep the description within the size limit.
+	body = getSchemaDriftPullRequestBody("Prod", "db1", &api.AnomalyDatabaseSchemaDriftPayload{
+		Version: "20220801120000",
+		Actual:  strings.Repeat("CREATE TABLE t (id INT);\n", maxSchemaDriftDiffLength/10),
+	})
+	require.Contains(t, body, "\n... (truncated)\n```")
+	require.Less(t, len(body), maxSchemaDriftDiffLength+1000)
+}
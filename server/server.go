@@ -49,7 +49,8 @@ type Server struct {
 	AnomalyScanner     *AnomalyScanner
 	runnerWG           sync.WaitGroup
 
-	ActivityManager *ActivityManager
+	ActivityManager   *ActivityManager
+	TaskRunLogManager *TaskRunLogManager
 
 	LicenseService enterpriseAPI.LicenseService
 	subscription   enterpriseAPI.Subscription
@@ -308,6 +309,7 @@ func NewServer(ctx context.Context, prof Profile) (*Server, error) {
 	s.registerPolicyRoutes(apiGroup)
 	s.registerProjectRoutes(apiGroup)
 	s.registerProjectWebhookRoutes(apiGroup)
+	s.registerPushEventDeliveryRoutes(apiGroup)
 	s.registerProjectMemberRoutes(apiGroup)
 	s.registerEnvironmentRoutes(apiGroup)
 	s.registerInstanceRoutes(apiGroup)
@@ -338,6 +340,7 @@ func NewServer(ctx context.Context, prof Profile) (*Server, error) {
 	p.Use(e)
 
 	s.ActivityManager = NewActivityManager(s, storeInstance)
+	s.TaskRunLogManager = NewTaskRunLogManager(storeInstance)
 	s.LicenseService, err = enterpriseService.NewLicenseService(prof.Mode, s.store)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create license service, error: %w", err)
@@ -224,6 +224,12 @@ func (s *Server) registerSheetRoutes(g *echo.Group) {
 				sheetSource = api.SheetFromGitLabSelfHost
 			case vcsPlugin.GitHubCom:
 				sheetSource = api.SheetFromGitHubCom
+			case vcsPlugin.GitHubEnterprise:
+				sheetSource = api.SheetFromGitHubEnterprise
+			case vcsPlugin.Gitea:
+				sheetSource = api.SheetFromGitea
+			case vcsPlugin.Bitbucket:
+				sheetSource = api.SheetFromBitbucket
 			}
 			vscSheetType := api.SheetForSQL
 			sheetFind := &api.SheetFind{
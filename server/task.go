@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/google/jsonapi"
 	"github.com/labstack/echo/v4"
@@ -14,6 +15,9 @@ import (
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/mysql"
+	"github.com/bytebase/bytebase/plugin/db/util"
 )
 
 var (
@@ -36,6 +40,19 @@ func isTaskStatusTransitionAllowed(fromStatus, toStatus api.TaskStatus) bool {
 	return false
 }
 
+// getTaskRunStatus returns the status of the task run ended with the task status.
+func getTaskRunStatus(taskStatus api.TaskStatus) api.TaskRunStatus {
+	switch taskStatus {
+	case api.TaskDone:
+		return api.TaskRunDone
+	case api.TaskFailed:
+		return api.TaskRunFailed
+	case api.TaskCanceled:
+		return api.TaskRunCanceled
+	}
+	return api.TaskRunUnknown
+}
+
 func (s *Server) canUpdateTaskStatement(ctx context.Context, task *api.Task) *echo.HTTPError {
 	// Allow frontend to change the SQL statement of
 	// 1. a PendingApproval task which hasn't started yet
@@ -241,6 +258,450 @@ func (s *Server) registerTaskRoutes(g *echo.Group) {
 		}
 		return nil
 	})
+
+	// Pauses the running task, only supported for the batched data update between batches and the gh-ost migration by throttling it.
+	g.POST("/pipeline/:pipelineID/task/:taskID/pause", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		taskID, err := strconv.Atoi(c.Param("taskID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task ID is not a number: %s", c.Param("taskID"))).SetInternal(err)
+		}
+
+		task, err := s.store.GetTaskByID(ctx, taskID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to pause task").SetInternal(err)
+		}
+		if task == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Task not found with ID %d", taskID))
+		}
+
+		currentPrincipalID := c.Get(getPrincipalIDContextKey()).(int)
+		if err := s.validateIssueAssignee(ctx, currentPrincipalID, task.PipelineID); err != nil {
+			return err
+		}
+
+		if isGhostMigration(task) {
+			// Throttling gh-ost pauses both the row copy and the binlog apply.
+			_, err = s.controlGhostMigration(ctx, task, currentPrincipalID, "throttle")
+		} else {
+			err = s.TaskScheduler.PauseTask(task.ID)
+		}
+		if err != nil {
+			if common.ErrorCode(err) == common.Invalid {
+				return echo.NewHTTPError(http.StatusBadRequest, common.ErrorMessage(err))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to pause task \"%v\"", task.Name)).SetInternal(err)
+		}
+		if progress, ok := s.TaskScheduler.taskProgress.Load(task.ID); ok {
+			task.Progress = progress.(api.Progress)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, task); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal pause task \"%v\" response", task.Name)).SetInternal(err)
+		}
+		return nil
+	})
+
+	// Resumes the paused task.
+	g.POST("/pipeline/:pipelineID/task/:taskID/resume", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		taskID, err := strconv.Atoi(c.Param("taskID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task ID is not a number: %s", c.Param("taskID"))).SetInternal(err)
+		}
+
+		task, err := s.store.GetTaskByID(ctx, taskID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to resume task").SetInternal(err)
+		}
+		if task == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Task not found with ID %d", taskID))
+		}
+
+		currentPrincipalID := c.Get(getPrincipalIDContextKey()).(int)
+		if err := s.validateIssueAssignee(ctx, currentPrincipalID, task.PipelineID); err != nil {
+			return err
+		}
+
+		if isGhostMigration(task) {
+			_, err = s.controlGhostMigration(ctx, task, currentPrincipalID, "no-throttle")
+		} else {
+			err = s.TaskScheduler.ResumeTask(task.ID)
+		}
+		if err != nil {
+			if common.ErrorCode(err) == common.Invalid {
+				return echo.NewHTTPError(http.StatusBadRequest, common.ErrorMessage(err))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to resume task \"%v\"", task.Name)).SetInternal(err)
+		}
+		if progress, ok := s.TaskScheduler.taskProgress.Load(task.ID); ok {
+			task.Progress = progress.(api.Progress)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, task); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal resume task \"%v\" response", task.Name)).SetInternal(err)
+		}
+		return nil
+	})
+
+	// Reads the live status of the running gh-ost migration.
+	g.GET("/pipeline/:pipelineID/task/:taskID/ghost/status", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		taskID, err := strconv.Atoi(c.Param("taskID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task ID is not a number: %s", c.Param("taskID"))).SetInternal(err)
+		}
+
+		task, err := s.store.GetTaskByID(ctx, taskID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch gh-ost status").SetInternal(err)
+		}
+		if task == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Task not found with ID %d", taskID))
+		}
+
+		status, err := sendGhostCommand(ctx, task, "status")
+		if err != nil {
+			if common.ErrorCode(err) == common.Invalid {
+				return echo.NewHTTPError(http.StatusBadRequest, common.ErrorMessage(err))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch gh-ost status of task \"%v\"", task.Name)).SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, &api.TaskGhostStatus{TaskID: task.ID, Status: status}); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal gh-ost status of task \"%v\" response", task.Name)).SetInternal(err)
+		}
+		return nil
+	})
+
+	// Changes the throttling config of the running gh-ost migration.
+	g.PATCH("/pipeline/:pipelineID/task/:taskID/ghost/config", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		taskID, err := strconv.Atoi(c.Param("taskID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task ID is not a number: %s", c.Param("taskID"))).SetInternal(err)
+		}
+
+		currentPrincipalID := c.Get(getPrincipalIDContextKey()).(int)
+		configPatch := &api.TaskGhostConfigPatch{
+			ID:        taskID,
+			UpdaterID: currentPrincipalID,
+		}
+		if err := jsonapi.UnmarshalPayload(c.Request().Body, configPatch); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Malformed update gh-ost config request").SetInternal(err)
+		}
+		if err := configPatch.Validate(); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		task, err := s.store.GetTaskByID(ctx, taskID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update gh-ost config").SetInternal(err)
+		}
+		if task == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Task not found with ID %d", taskID))
+		}
+
+		if err := s.validateIssueAssignee(ctx, currentPrincipalID, task.PipelineID); err != nil {
+			return err
+		}
+
+		// gh-ost prints the status after applying each command, so the last response is the latest status.
+		var status string
+		for _, command := range getGhostConfigCommandList(configPatch) {
+			status, err = s.controlGhostMigration(ctx, task, currentPrincipalID, command)
+			if err != nil {
+				if common.ErrorCode(err) == common.Invalid {
+					return echo.NewHTTPError(http.StatusBadRequest, common.ErrorMessage(err))
+				}
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to update gh-ost config of task \"%v\"", task.Name)).SetInternal(err)
+			}
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, &api.TaskGhostStatus{TaskID: task.ID, Status: status}); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal update gh-ost config of task \"%v\" response", task.Name)).SetInternal(err)
+		}
+		return nil
+	})
+
+	// Creates the issue rolling back the data update by restoring the rows from its pre-change backup.
+	g.POST("/pipeline/:pipelineID/task/:taskID/rollback", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		taskID, err := strconv.Atoi(c.Param("taskID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task ID is not a number: %s", c.Param("taskID"))).SetInternal(err)
+		}
+
+		task, err := s.store.GetTaskByID(ctx, taskID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to roll back task").SetInternal(err)
+		}
+		if task == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Task not found with ID %d", taskID))
+		}
+
+		currentPrincipalID := c.Get(getPrincipalIDContextKey()).(int)
+		if err := s.validateIssueAssignee(ctx, currentPrincipalID, task.PipelineID); err != nil {
+			return err
+		}
+		if task.Type != api.TaskDatabaseDataUpdate || task.Database == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Only the data update task can be rolled back")
+		}
+		if task.Status != api.TaskDone && task.Status != api.TaskFailed && task.Status != api.TaskCanceled {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Cannot roll back task %q in status %s", task.Name, task.Status))
+		}
+
+		result, err := getLatestTaskRunResult(task)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to find the pre-change backup of task %q", task.Name)).SetInternal(err)
+		}
+		if result == nil || len(result.DataBackupList) == 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task %q has no pre-change backup to roll back with", task.Name))
+		}
+		for _, table := range result.DataBackupList {
+			if table.IsExpired(time.Now()) {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("The pre-change backup of task %q has expired after %v", task.Name, db.DataBackupRetentionPeriod))
+			}
+		}
+
+		issue, err := s.store.GetIssueByPipelineID(ctx, task.PipelineID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch issue with pipeline ID %d", task.PipelineID)).SetInternal(err)
+		}
+		if issue == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Issue not found with pipeline ID %d", task.PipelineID))
+		}
+
+		createContext, err := json.Marshal(&api.UpdateSchemaContext{
+			MigrationType: db.Data,
+			DetailList: []*api.UpdateSchemaDetail{
+				{
+					DatabaseID: task.Database.ID,
+					Statement:  util.GetDataRollbackStatement(task.Instance.Engine, result.DataBackupList),
+				},
+			},
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to marshal rollback issue create context").SetInternal(err)
+		}
+		rollbackIssue, err := s.createIssue(ctx, &api.IssueCreate{
+			ProjectID:     issue.ProjectID,
+			Name:          fmt.Sprintf("Roll back %q", task.Name),
+			Type:          api.IssueDatabaseDataUpdate,
+			Description:   fmt.Sprintf("Roll back task %q of issue %q by restoring the rows from its pre-change backup.", task.Name, issue.Name),
+			AssigneeID:    issue.AssigneeID,
+			CreateContext: string(createContext),
+		}, currentPrincipalID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create rollback issue").SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, rollbackIssue); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to marshal create rollback issue response").SetInternal(err)
+		}
+		return nil
+	})
+
+	// Creates the issue reversing the row changes of the data update on MySQL, with the reverse statements generated from the binlog.
+	g.POST("/pipeline/:pipelineID/task/:taskID/reverse", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		taskID, err := strconv.Atoi(c.Param("taskID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task ID is not a number: %s", c.Param("taskID"))).SetInternal(err)
+		}
+
+		task, err := s.store.GetTaskByID(ctx, taskID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to reverse task").SetInternal(err)
+		}
+		if task == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Task not found with ID %d", taskID))
+		}
+
+		currentPrincipalID := c.Get(getPrincipalIDContextKey()).(int)
+		if err := s.validateIssueAssignee(ctx, currentPrincipalID, task.PipelineID); err != nil {
+			return err
+		}
+		if task.Type != api.TaskDatabaseDataUpdate || task.Database == nil || task.Instance.Engine != db.MySQL {
+			return echo.NewHTTPError(http.StatusBadRequest, "Only the data update task on MySQL can be reversed")
+		}
+		if task.Status != api.TaskDone && task.Status != api.TaskFailed && task.Status != api.TaskCanceled {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Cannot reverse task %q in status %s", task.Name, task.Status))
+		}
+
+		result, err := getLatestTaskRunResult(task)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to find the binlog range of task %q", task.Name)).SetInternal(err)
+		}
+		if result == nil || result.BinlogRange == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task %q has no binlog range to reverse with, which requires the row-based binlog with the full row images", task.Name))
+		}
+
+		issue, err := s.store.GetIssueByPipelineID(ctx, task.PipelineID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch issue with pipeline ID %d", task.PipelineID)).SetInternal(err)
+		}
+		if issue == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Issue not found with pipeline ID %d", task.PipelineID))
+		}
+
+		statement, err := s.generateReverseStatement(ctx, task, result.BinlogRange)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to generate the reverse statements of task %q", task.Name)).SetInternal(err)
+		}
+		if statement == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task %q has no row changes to reverse", task.Name))
+		}
+
+		createContext, err := json.Marshal(&api.UpdateSchemaContext{
+			MigrationType: db.Data,
+			DetailList: []*api.UpdateSchemaDetail{
+				{
+					DatabaseID: task.Database.ID,
+					Statement:  statement,
+				},
+			},
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to marshal reverse issue create context").SetInternal(err)
+		}
+		reverseIssue, err := s.createIssue(ctx, &api.IssueCreate{
+			ProjectID:     issue.ProjectID,
+			Name:          fmt.Sprintf("Reverse %q", task.Name),
+			Type:          api.IssueDatabaseDataUpdate,
+			Description:   fmt.Sprintf("Reverse the row changes of task %q of issue %q, generated from the binlog from %s:%d to %s:%d.", task.Name, issue.Name, result.BinlogRange.StartFileName, result.BinlogRange.StartPosition, result.BinlogRange.EndFileName, result.BinlogRange.EndPosition),
+			AssigneeID:    issue.AssigneeID,
+			CreateContext: string(createContext),
+		}, currentPrincipalID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create reverse issue").SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, reverseIssue); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to marshal create reverse issue response").SetInternal(err)
+		}
+		return nil
+	})
+
+	// Streams the log events of the task run as Server-Sent Events.
+	// The persisted events are replayed first, then the live events follow until the task run ends.
+	// Clients reconnecting with the Last-Event-ID header only receive the events after it.
+	g.GET("/pipeline/:pipelineID/task/:taskID/run/:taskRunID/log", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		taskID, err := strconv.Atoi(c.Param("taskID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task ID is not a number: %s", c.Param("taskID"))).SetInternal(err)
+		}
+		taskRunID, err := strconv.Atoi(c.Param("taskRunID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task run ID is not a number: %s", c.Param("taskRunID"))).SetInternal(err)
+		}
+		taskRunLogFind := &api.TaskRunLogFind{
+			TaskRunID: taskRunID,
+		}
+		if lastEventIDStr := c.Request().Header.Get("Last-Event-ID"); lastEventIDStr != "" {
+			lastEventID, err := strconv.Atoi(lastEventIDStr)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Last-Event-ID is not a number: %s", lastEventIDStr)).SetInternal(err)
+			}
+			taskRunLogFind.AfterID = &lastEventID
+		}
+
+		// Subscribe before checking the task run status, so that we won't miss the end event.
+		liveLogCh, unsubscribe := s.TaskRunLogManager.Subscribe(taskRunID)
+		defer unsubscribe()
+
+		task, err := s.store.GetTaskByID(ctx, taskID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch task ID: %v", taskID)).SetInternal(err)
+		}
+		if task == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Task not found with ID %d", taskID))
+		}
+		var taskRun *api.TaskRun
+		for _, run := range task.TaskRunList {
+			if run.ID == taskRunID {
+				taskRun = run
+				break
+			}
+		}
+		if taskRun == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Task run not found with ID %d", taskRunID))
+		}
+
+		taskRunLogList, err := s.store.FindTaskRunLog(ctx, taskRunLogFind)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch log of task run ID: %v", taskRunID)).SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+		c.Response().Header().Set(echo.HeaderConnection, "keep-alive")
+		c.Response().WriteHeader(http.StatusOK)
+
+		lastID := 0
+		for _, taskRunLog := range taskRunLogList {
+			if err := writeTaskRunLogEvent(c.Response(), taskRunLog); err != nil {
+				return nil
+			}
+			lastID = taskRunLog.ID
+		}
+		if taskRun.Status != api.TaskRunRunning {
+			_ = writeTaskRunLogEvent(c.Response(), &api.TaskRunLog{
+				TaskRunID: taskRunID,
+				Type:      api.TaskRunLogEnd,
+				Payload:   api.TaskRunLogPayload{Status: taskRun.Status},
+			})
+			return nil
+		}
+
+		for {
+			select {
+			case taskRunLog, ok := <-liveLogCh:
+				// The channel is closed if the client falls behind, and the client is expected to reconnect.
+				if !ok {
+					return nil
+				}
+				// Skip the events which have been replayed.
+				if taskRunLog.Type != api.TaskRunLogEnd && taskRunLog.ID <= lastID {
+					continue
+				}
+				if err := writeTaskRunLogEvent(c.Response(), taskRunLog); err != nil {
+					return nil
+				}
+				if taskRunLog.Type == api.TaskRunLogEnd {
+					return nil
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+}
+
+// writeTaskRunLogEvent writes the task run log as a Server-Sent Event and flushes it to the client.
+func writeTaskRunLogEvent(w *echo.Response, taskRunLog *api.TaskRunLog) error {
+	data, err := json.Marshal(taskRunLog)
+	if err != nil {
+		return err
+	}
+	// The end event is never persisted, so it has no ID.
+	if taskRunLog.Type != api.TaskRunLogEnd {
+		if _, err := fmt.Fprintf(w, "id: %d\n", taskRunLog.ID); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", taskRunLog.Type, data); err != nil {
+		return err
+	}
+	w.Flush()
+	return nil
 }
 
 func (s *Server) patchTask(ctx context.Context, task *api.Task, taskPatch *api.TaskPatch, issue *api.Issue) (*api.Task, *echo.HTTPError) {
@@ -535,6 +996,18 @@ func (s *Server) changeTaskStatusWithPatch(ctx context.Context, task *api.Task,
 		return nil, fmt.Errorf("failed to change task %v(%v) status: %w", task.ID, task.Name, err)
 	}
 
+	// Close the log streams of the task run which has just ended.
+	if task.Status == api.TaskRunning && taskPatched.Status != api.TaskRunning {
+		if taskRun := getRunningTaskRun(task); taskRun != nil {
+			s.TaskRunLogManager.EndLog(taskRun.ID, getTaskRunStatus(taskPatched.Status))
+		}
+	}
+
+	// Cancel the running executor so that the statement stops executing on the database.
+	if task.Status == api.TaskRunning && taskPatched.Status == api.TaskCanceled && s.TaskScheduler != nil {
+		s.TaskScheduler.CancelTask(task.ID)
+	}
+
 	// Most tasks belong to a pipeline which in turns belongs to an issue. The followup code
 	// behaves differently depending on whether the task is wrapped in an issue.
 	// TODO(tianzhou): Refactor the followup code into chained onTaskStatusChange hook.
@@ -685,3 +1158,43 @@ func (s *Server) triggerDatabaseStatementAdviseTask(ctx context.Context, stateme
 
 	return nil
 }
+
+// generateReverseStatement generates the statements reversing the row changes of the data update on MySQL from the binlog in the binlog range.
+func (s *Server) generateReverseStatement(ctx context.Context, task *api.Task, binlogRange *db.BinlogRange) (string, error) {
+	driver, err := getAdminDatabaseDriver(ctx, task.Instance, task.Database.Name, "" /* pgInstanceDir */)
+	if err != nil {
+		return "", err
+	}
+	defer driver.Close(ctx)
+
+	mysqlDriver, ok := driver.(*mysql.Driver)
+	if !ok {
+		return "", fmt.Errorf("[internal] cast driver to mysql.Driver failed")
+	}
+	if err := createBinlogDir(s.profile.DataDir, task.Instance.ID); err != nil {
+		return "", err
+	}
+	mysqlDriver.SetUpForPITR(s.mysqlutil, getBinlogAbsDir(s.profile.DataDir, task.Instance.ID))
+	return mysqlDriver.GenerateReverseStatement(ctx, task.Database.Name, binlogRange)
+}
+
+// getLatestTaskRunResult returns the result of the latest finished run of the task, or nil if there is none.
+func getLatestTaskRunResult(task *api.Task) (*api.TaskRunResultPayload, error) {
+	var latest *api.TaskRun
+	for _, taskRun := range task.TaskRunList {
+		if taskRun.Status == api.TaskRunRunning {
+			continue
+		}
+		if latest == nil || taskRun.ID > latest.ID {
+			latest = taskRun
+		}
+	}
+	if latest == nil || latest.Result == "" {
+		return nil, nil
+	}
+	result := &api.TaskRunResultPayload{}
+	if err := json.Unmarshal([]byte(latest.Result), result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result of task run %d, error: %w", latest.ID, err)
+	}
+	return result, nil
+}
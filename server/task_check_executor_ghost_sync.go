@@ -9,6 +9,7 @@ import (
 
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
 )
 
 // NewTaskCheckGhostSyncExecutor creates a task check gh-ost sync executor.
@@ -79,6 +80,29 @@ func (*TaskCheckGhostSyncExecutor) Run(ctx context.Context, server *Server, task
 		return []api.TaskCheckResult{}, common.Errorf(common.Internal, "invalid database schema update gh-ost sync payload: %w", err)
 	}
 
+	if instance.Engine == db.Postgres {
+		if err := checkPgOnlineSchemaChange(ctx, task, payload.Statement); err != nil {
+			return []api.TaskCheckResult{
+				{
+					Status:    api.TaskCheckStatusError,
+					Namespace: api.BBNamespace,
+					Code:      common.ErrorCode(err).Int(),
+					Title:     "Online schema change check failed",
+					Content:   err.Error(),
+				},
+			}, nil
+		}
+		return []api.TaskCheckResult{
+			{
+				Status:    api.TaskCheckStatusSuccess,
+				Namespace: api.BBNamespace,
+				Code:      common.Ok.Int(),
+				Title:     "OK",
+				Content:   "online schema change check succeeded",
+			},
+		}, nil
+	}
+
 	databaseName := database.Name
 	tableName, err := getTableNameFromStatement(payload.Statement)
 	if err != nil {
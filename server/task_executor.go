@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -14,6 +15,8 @@ import (
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
 	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/mysql"
+	"github.com/bytebase/bytebase/plugin/db/pg"
 	vcsPlugin "github.com/bytebase/bytebase/plugin/vcs"
 )
 
@@ -33,6 +36,15 @@ type TaskExecutor interface {
 	GetProgress() api.Progress
 }
 
+// PausableTaskExecutor is the task executor which can be paused and resumed while running.
+type PausableTaskExecutor interface {
+	TaskExecutor
+	// Pause pauses the running task, returning an error if the task can't be paused.
+	Pause() error
+	// Resume resumes the paused task.
+	Resume() error
+}
+
 // RunTaskExecutorOnce wraps a TaskExecutor.RunOnce call with panic recovery.
 func RunTaskExecutorOnce(ctx context.Context, exec TaskExecutor, server *Server, task *api.Task) (terminated bool, result *api.TaskRunResultPayload, err error) {
 	defer func() {
@@ -51,7 +63,7 @@ func RunTaskExecutorOnce(ctx context.Context, exec TaskExecutor, server *Server,
 	return exec.RunOnce(ctx, server, task)
 }
 
-func preMigration(ctx context.Context, server *Server, task *api.Task, migrationType db.MigrationType, statement, schemaVersion string, vcsPushEvent *vcsPlugin.PushEvent) (*db.MigrationInfo, error) {
+func preMigration(ctx context.Context, server *Server, task *api.Task, migrationType db.MigrationType, statement, schemaVersion, rollbackStatement, desiredSchema string, vcsPushEvent *vcsPlugin.PushEvent) (*db.MigrationInfo, error) {
 	if task.Database == nil {
 		msg := "missing database when updating schema"
 		if migrationType == db.Data {
@@ -86,24 +98,26 @@ func preMigration(ctx context.Context, server *Server, task *api.Task, migration
 		if err != nil {
 			return nil, err
 		}
-		mi, err = db.ParseMigrationInfo(
-			vcsPushEvent.FileCommit.Added,
-			filepath.Join(vcsPushEvent.BaseDirectory, repo.FilePathTemplate),
-		)
+		if migrationType == db.MigrateSDL {
+			// The schema file of the declarative migration has no version, so we use the version assigned to the issue.
+			mi, err = db.ParseSchemaFileInfo(
+				vcsPushEvent.FileCommit.Added,
+				filepath.Join(vcsPushEvent.BaseDirectory, repo.SchemaPathTemplate),
+			)
+			if err == nil {
+				mi.Version = schemaVersion
+			}
+		} else {
+			mi, err = db.ParseMigrationInfo(
+				vcsPushEvent.FileCommit.Added,
+				filepath.Join(vcsPushEvent.BaseDirectory, repo.FilePathTemplate),
+			)
+		}
 		// This should not happen normally as we already check this when creating the issue. Just in case.
 		if err != nil {
 			return nil, fmt.Errorf("failed to prepare for database migration, error: %w", err)
 		}
 		mi.Creator = vcsPushEvent.FileCommit.AuthorName
-
-		miPayload := &db.MigrationInfoPayload{
-			VCSPushEvent: vcsPushEvent,
-		}
-		bytes, err := json.Marshal(miPayload)
-		if err != nil {
-			return nil, fmt.Errorf("failed to prepare for database migration, unable to marshal vcs push event payload, error: %w", err)
-		}
-		mi.Payload = string(bytes)
 	}
 
 	mi.Database = databaseName
@@ -122,15 +136,63 @@ func preMigration(ctx context.Context, server *Server, task *api.Task, migration
 	if mi.Type != db.Baseline && statement == "" {
 		return nil, fmt.Errorf("empty statement")
 	}
-	// We will force migration for baseline and migrate type of migrations.
+
+	// Generate the rollback statement for the purely additive schema migration if it's not provided.
+	if (mi.Type == db.Migrate || mi.Type == db.MigrateSDL) && rollbackStatement == "" {
+		downStatement, err := generateDownStatement(task.Instance.Engine, statement)
+		if err != nil {
+			log.Warn("Failed to generate the rollback statement of the schema migration", zap.Int("task_id", task.ID), zap.Error(err))
+		}
+		rollbackStatement = downStatement
+	}
+	if vcsPushEvent != nil || rollbackStatement != "" || desiredSchema != "" {
+		miPayload := &db.MigrationInfoPayload{
+			VCSPushEvent:      vcsPushEvent,
+			RollbackStatement: strings.TrimSpace(rollbackStatement),
+			DesiredSchema:     desiredSchema,
+		}
+		bytes, err := json.Marshal(miPayload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare for database migration, unable to marshal migration payload, error: %w", err)
+		}
+		mi.Payload = string(bytes)
+	}
+	// We will force migration for baseline, migrate and declarative migrate type of migrations.
 	// This usually happens when the previous attempt fails and the client retries the migration.
-	if mi.Type == db.Baseline || mi.Type == db.Migrate {
+	if mi.Type == db.Baseline || mi.Type == db.Migrate || mi.Type == db.MigrateSDL {
+		mi.Force = true
+	}
+	// The task scheduler only retries the data migration automatically if it runs in a single transaction,
+	// so the failed attempt has been rolled back.
+	if mi.Type == db.Data && getTaskRetryCount(task) > 0 {
 		mi.Force = true
 	}
 
 	return mi, nil
 }
 
+// generateDownStatement generates the rollback statement of the schema migration if it's purely additive, or returns "" otherwise.
+func generateDownStatement(engine db.Type, statement string) (string, error) {
+	switch engine {
+	case db.MySQL, db.TiDB:
+		return mysql.GenerateDownStatement(statement)
+	case db.Postgres:
+		return pg.GenerateDownStatement(statement)
+	}
+	return "", nil
+}
+
+// generateSchemaDiff generates the DDL statements migrating the current schema to the desired schema.
+func generateSchemaDiff(engine db.Type, currentSchema, desiredSchema string) (string, error) {
+	switch engine {
+	case db.MySQL, db.TiDB:
+		return mysql.GenerateSchemaDiff(currentSchema, desiredSchema)
+	case db.Postgres:
+		return pg.GenerateSchemaDiff(currentSchema, desiredSchema)
+	}
+	return "", fmt.Errorf("declarative schema migration is not supported for %s", engine)
+}
+
 func executeMigration(ctx context.Context, pgInstanceDir string, task *api.Task, statement string, mi *db.MigrationInfo) (migrationID int64, schema string, err error) {
 	statement = strings.TrimSpace(statement)
 	databaseName := task.Database.Name
@@ -178,7 +240,8 @@ func postMigration(ctx context.Context, server *Server, task *api.Task, vcsPushE
 		}
 	}
 	// If VCS based and schema path template is specified, then we will write back the latest schema file after migration.
-	writeBack := (vcsPushEvent != nil) && (repo.SchemaPathTemplate != "")
+	// The declarative migration is pushed from the schema file itself, which must not be overwritten with the dump.
+	writeBack := (vcsPushEvent != nil) && (repo.SchemaPathTemplate != "") && mi.Type != db.MigrateSDL
 	// For tenant mode project, we will only write back latest schema file on the last task.
 	project, err := server.store.GetProjectByID(ctx, task.Database.ProjectID)
 	if err != nil {
@@ -300,16 +363,56 @@ func postMigration(ctx context.Context, server *Server, task *api.Task, vcsPushE
 	}, nil
 }
 
-func runMigration(ctx context.Context, server *Server, task *api.Task, migrationType db.MigrationType, statement, schemaVersion string, vcsPushEvent *vcsPlugin.PushEvent) (terminated bool, result *api.TaskRunResultPayload, err error) {
-	mi, err := preMigration(ctx, server, task, migrationType, statement, schemaVersion, vcsPushEvent)
+func runMigration(ctx context.Context, server *Server, task *api.Task, migrationType db.MigrationType, statement, schemaVersion, rollbackStatement, desiredSchema string, vcsPushEvent *vcsPlugin.PushEvent) (terminated bool, result *api.TaskRunResultPayload, err error) {
+	mi, err := preMigration(ctx, server, task, migrationType, statement, schemaVersion, rollbackStatement, desiredSchema, vcsPushEvent)
 	if err != nil {
 		return true, nil, err
 	}
-	migrationID, schema, err := executeMigration(ctx, server.pgInstanceDir, task, statement, mi)
+	startedTime := time.Now()
+	// The driver records the execution detail of each statement with the recorder, and the start and end events of
+	// each statement are streamed as the driver executes it.
+	streamed := false
+	recorder := &db.StatementRecorder{
+		OnStart: func(statementResult *db.StatementResult) {
+			streamed = true
+			entry := getStatementStartLog(statementResult)
+			server.TaskRunLogManager.AppendLog(ctx, task, entry.logType, entry.payload)
+		},
+		OnEnd: func(statementResult *db.StatementResult) {
+			entry := getStatementEndLog(statementResult)
+			server.TaskRunLogManager.AppendLog(ctx, task, entry.logType, entry.payload)
+		},
+	}
+	migrationID, schema, err := executeMigration(db.WithStatementRecorder(ctx, recorder), server.pgInstanceDir, task, statement, mi)
+	statementResultList := recorder.ResultList()
+	// Fall back to logging the statements after the execution for the drivers not streaming the events.
+	if !streamed && (len(statementResultList) > 0 || err == nil) {
+		logResultList := statementResultList
+		if len(logResultList) == 0 {
+			// The driver doesn't record the execution detail of each statement, so we log the whole statement instead.
+			logResultList = []*db.StatementResult{
+				{
+					Statement:    statement,
+					DurationNs:   time.Since(startedTime).Nanoseconds(),
+					RowsAffected: -1,
+					Status:       db.StatementDone,
+				},
+			}
+		}
+		for _, entry := range getStatementLogList(logResultList) {
+			server.TaskRunLogManager.AppendLog(ctx, task, entry.logType, entry.payload)
+		}
+	}
 	if err != nil {
-		return true, nil, err
+		return true, &api.TaskRunResultPayload{
+			StatementList: statementResultList,
+		}, err
+	}
+	terminated, result, err = postMigration(ctx, server, task, vcsPushEvent, mi, migrationID, schema)
+	if result != nil {
+		result.StatementList = statementResultList
 	}
-	return postMigration(ctx, server, task, vcsPushEvent, mi, migrationID, schema)
+	return terminated, result, err
 }
 
 func findIssueByTask(ctx context.Context, server *Server, task *api.Task) (*api.Issue, error) {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync/atomic"
+	"time"
 
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/plugin/db"
@@ -18,16 +19,55 @@ func NewDataUpdateTaskExecutor() TaskExecutor {
 // DataUpdateTaskExecutor is the data update (DML) task executor.
 type DataUpdateTaskExecutor struct {
 	completed int32
+	createdTs int64
+	// batchController is set for the batched data update.
+	batchController atomic.Value // *db.BatchController
 }
 
 // RunOnce will run the data update (DML) task executor once.
-func (*DataUpdateTaskExecutor) RunOnce(ctx context.Context, server *Server, task *api.Task) (terminated bool, result *api.TaskRunResultPayload, err error) {
+func (exec *DataUpdateTaskExecutor) RunOnce(ctx context.Context, server *Server, task *api.Task) (terminated bool, result *api.TaskRunResultPayload, err error) {
+	defer atomic.StoreInt32(&exec.completed, 1)
 	payload := &api.TaskDatabaseDataUpdatePayload{}
 	if err := json.Unmarshal([]byte(task.Payload), payload); err != nil {
 		return true, nil, fmt.Errorf("invalid database data update payload: %w", err)
 	}
 
-	return runMigration(ctx, server, task, db.Data, payload.Statement, payload.SchemaVersion, payload.VCSPushEvent)
+	if payload.BatchConfig != nil {
+		controller := &db.BatchController{
+			BatchSize:     payload.BatchConfig.BatchSize,
+			SleepInterval: time.Duration(payload.BatchConfig.SleepMs) * time.Millisecond,
+		}
+		atomic.StoreInt64(&exec.createdTs, time.Now().Unix())
+		exec.batchController.Store(controller)
+		ctx = db.WithBatchController(ctx, controller)
+	}
+
+	var dataBackupRecorder *db.DataBackupRecorder
+	if payload.RollbackEnabled {
+		// The backup tables are named after the task run, so that each run has its own backup.
+		dataBackupRecorder = &db.DataBackupRecorder{
+			TablePrefix: fmt.Sprintf("_%d_%d_", time.Now().Unix(), task.ID),
+		}
+		ctx = db.WithDataBackupRecorder(ctx, dataBackupRecorder)
+	}
+	// The binlog range of the data update on MySQL is recorded to generate the reverse statements from the binlog.
+	var binlogRangeRecorder *db.BinlogRangeRecorder
+	if task.Instance.Engine == db.MySQL {
+		binlogRangeRecorder = &db.BinlogRangeRecorder{}
+		ctx = db.WithBinlogRangeRecorder(ctx, binlogRangeRecorder)
+	}
+
+	terminated, result, err = runMigration(ctx, server, task, db.Data, payload.Statement, payload.SchemaVersion, "" /* rollbackStatement */, "" /* desiredSchema */, payload.VCSPushEvent)
+	if result == nil {
+		result = &api.TaskRunResultPayload{}
+	}
+	if dataBackupRecorder != nil {
+		result.DataBackupList = dataBackupRecorder.TableList()
+	}
+	if binlogRangeRecorder != nil {
+		result.BinlogRange = binlogRangeRecorder.BinlogRange()
+	}
+	return terminated, result, err
 }
 
 // IsCompleted tells the scheduler if the task execution has completed.
@@ -36,6 +76,50 @@ func (exec *DataUpdateTaskExecutor) IsCompleted() bool {
 }
 
 // GetProgress returns the task progress.
-func (*DataUpdateTaskExecutor) GetProgress() api.Progress {
-	return api.Progress{}
+// For the batched data update, the unit is a batch, and the payload tells the rows affected so far and whether the task is paused.
+func (exec *DataUpdateTaskExecutor) GetProgress() api.Progress {
+	controller := exec.getBatchController()
+	if controller == nil {
+		return api.Progress{}
+	}
+	progress := controller.Progress()
+	payload, err := json.Marshal(api.DataUpdateBatchProgressPayload{
+		RowsAffected: progress.RowsAffected,
+		Paused:       progress.Paused,
+	})
+	if err != nil {
+		return api.Progress{}
+	}
+	return api.Progress{
+		TotalUnit:     progress.TotalBatches,
+		CompletedUnit: progress.CompletedBatches,
+		CreatedTs:     atomic.LoadInt64(&exec.createdTs),
+		UpdatedTs:     time.Now().Unix(),
+		Payload:       string(payload),
+	}
+}
+
+// Pause pauses the batched data update before the next batch.
+func (exec *DataUpdateTaskExecutor) Pause() error {
+	controller := exec.getBatchController()
+	if controller == nil {
+		return fmt.Errorf("only the batched data update can be paused")
+	}
+	controller.Pause()
+	return nil
+}
+
+// Resume resumes the paused batched data update.
+func (exec *DataUpdateTaskExecutor) Resume() error {
+	controller := exec.getBatchController()
+	if controller == nil {
+		return fmt.Errorf("only the batched data update can be resumed")
+	}
+	controller.Resume()
+	return nil
+}
+
+func (exec *DataUpdateTaskExecutor) getBatchController() *db.BatchController {
+	controller, _ := exec.batchController.Load().(*db.BatchController)
+	return controller
 }
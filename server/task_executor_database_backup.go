@@ -4,15 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync/atomic"
+	"time"
 
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common/log"
 	"go.uber.org/zap"
 )
 
+// backupProgressLogInterval is the interval between two backup progress logs.
+const backupProgressLogInterval = 10 * time.Second
+
 // NewDatabaseBackupTaskExecutor creates a new database backup task executor.
 func NewDatabaseBackupTaskExecutor() TaskExecutor {
 	return &DatabaseBackupTaskExecutor{}
@@ -54,7 +59,28 @@ func (exec *DatabaseBackupTaskExecutor) RunOnce(ctx context.Context, server *Ser
 		zap.String("backup", backup.Name),
 	)
 
-	backupPayload, backupErr := exec.backupDatabase(ctx, task.Instance, task.Database.Name, backup, server.profile.DataDir, server.pgInstanceDir)
+	var bytesWritten int64
+	backupDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(backupProgressLogInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				server.TaskRunLogManager.AppendLog(ctx, task, api.TaskRunLogBackupProgress, api.TaskRunLogPayload{
+					BytesWritten: atomic.LoadInt64(&bytesWritten),
+				})
+			case <-backupDone:
+				return
+			}
+		}
+	}()
+	backupPayload, backupErr := exec.backupDatabase(ctx, task.Instance, task.Database.Name, backup, server.profile.DataDir, server.pgInstanceDir, &bytesWritten)
+	close(backupDone)
+	server.TaskRunLogManager.AppendLog(ctx, task, api.TaskRunLogBackupProgress, api.TaskRunLogPayload{
+		BytesWritten: atomic.LoadInt64(&bytesWritten),
+		Message:      "Backup finished",
+	})
 	backupPatch := api.BackupPatch{
 		ID:        backup.ID,
 		Status:    string(api.BackupStatusDone),
@@ -80,7 +106,8 @@ func (exec *DatabaseBackupTaskExecutor) RunOnce(ctx context.Context, server *Ser
 }
 
 // backupDatabase will take a backup of a database.
-func (*DatabaseBackupTaskExecutor) backupDatabase(ctx context.Context, instance *api.Instance, databaseName string, backup *api.Backup, dataDir, pgInstanceDir string) (string, error) {
+// The number of bytes written to the backup file is atomically updated to bytesWritten as the backup proceeds.
+func (*DatabaseBackupTaskExecutor) backupDatabase(ctx context.Context, instance *api.Instance, databaseName string, backup *api.Backup, dataDir, pgInstanceDir string, bytesWritten *int64) (string, error) {
 	driver, err := getAdminDatabaseDriver(ctx, instance, databaseName, pgInstanceDir)
 	if err != nil {
 		return "", err
@@ -93,7 +120,7 @@ func (*DatabaseBackupTaskExecutor) backupDatabase(ctx context.Context, instance
 	}
 	defer f.Close()
 
-	payload, err := driver.Dump(ctx, databaseName, f, false /* schemaOnly */)
+	payload, err := driver.Dump(ctx, databaseName, &countingWriter{w: f, count: bytesWritten}, false /* schemaOnly */)
 	if err != nil {
 		return "", err
 	}
@@ -101,6 +128,18 @@ func (*DatabaseBackupTaskExecutor) backupDatabase(ctx context.Context, instance
 	return payload, nil
 }
 
+// countingWriter atomically counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w     io.Writer
+	count *int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	atomic.AddInt64(cw.count, int64(n))
+	return n, err
+}
+
 // Get backup dir relative to the data dir.
 func getBackupRelativeDir(databaseID int) string {
 	return filepath.Join("backup", "db", fmt.Sprintf("%d", databaseID))
@@ -16,6 +16,7 @@ import (
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
 	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/pg"
 	"github.com/bytebase/bytebase/plugin/db/util"
 	vcsPlugin "github.com/bytebase/bytebase/plugin/vcs"
 )
@@ -48,6 +49,17 @@ func (exec *SchemaUpdateGhostCutoverTaskExecutor) RunOnce(ctx context.Context, s
 		return true, nil, fmt.Errorf("invalid database schema update gh-ost sync payload: %w", err)
 	}
 
+	if task.Instance.Engine == db.Postgres {
+		terminated, result, err := cutover(ctx, server, task, payload.Statement, payload.SchemaVersion, payload.VCSPushEvent, switchPgOnlineSchemaChangeTable(task, payload.Statement))
+		if err == nil && result != nil {
+			// Keep the original table in case the change needs to be reverted.
+			if osc, oscErr := pg.NewOnlineSchemaChange(payload.Statement); oscErr == nil {
+				result.Detail = fmt.Sprintf("%s, the original table is kept as %q", result.Detail, osc.OldTable())
+			}
+		}
+		return terminated, result, err
+	}
+
 	tableName, err := getTableNameFromStatement(payload.Statement)
 	if err != nil {
 		return true, nil, fmt.Errorf("failed to parse table name from statement, error: %w", err)
@@ -56,13 +68,34 @@ func (exec *SchemaUpdateGhostCutoverTaskExecutor) RunOnce(ctx context.Context, s
 	socketFilename := getSocketFilename(syncTaskID, task.Database.ID, task.Database.Name, tableName)
 	postponeFilename := getPostponeFlagFilename(syncTaskID, task.Database.ID, task.Database.Name, tableName)
 
-	return cutover(ctx, server, task, payload.Statement, payload.SchemaVersion, payload.VCSPushEvent, socketFilename, postponeFilename)
+	return cutover(ctx, server, task, payload.Statement, payload.SchemaVersion, payload.VCSPushEvent, switchGhostTable(socketFilename, postponeFilename))
 }
 
-func cutover(ctx context.Context, server *Server, task *api.Task, statement, schemaVersion string, vcsPushEvent *vcsPlugin.PushEvent, socketFilename, postponeFilename string) (terminated bool, result *api.TaskRunResultPayload, err error) {
+// switchGhostTable returns the function letting gh-ost switch the original table and the ghost table by removing the postpone flag file.
+func switchGhostTable(socketFilename, postponeFilename string) func(ctx context.Context, driver db.Driver) error {
+	return func(_ context.Context, _ db.Driver) error {
+		if err := os.Remove(postponeFilename); err != nil {
+			return fmt.Errorf("failed to remove postpone flag file, error: %w", err)
+		}
+
+		ticker := time.NewTicker(time.Second * 1)
+		defer ticker.Stop()
+
+		// gh-ost removes the socket file on exit.
+		for range ticker.C {
+			if _, err := os.Stat(socketFilename); err != nil {
+				break
+			}
+		}
+		return nil
+	}
+}
+
+// cutover records the migration history around switchTable, which switches the original table and the synced table.
+func cutover(ctx context.Context, server *Server, task *api.Task, statement, schemaVersion string, vcsPushEvent *vcsPlugin.PushEvent, switchTable func(ctx context.Context, driver db.Driver) error) (terminated bool, result *api.TaskRunResultPayload, err error) {
 	statement = strings.TrimSpace(statement)
 
-	mi, err := preMigration(ctx, server, task, db.Migrate, statement, schemaVersion, vcsPushEvent)
+	mi, err := preMigration(ctx, server, task, db.Migrate, statement, schemaVersion, "" /* rollbackStatement */, "" /* desiredSchema */, vcsPushEvent)
 	if err != nil {
 		return true, nil, err
 	}
@@ -105,17 +138,8 @@ func cutover(ctx context.Context, server *Server, task *api.Task, statement, sch
 			}
 		}()
 
-		if err := os.Remove(postponeFilename); err != nil {
-			return -1, "", fmt.Errorf("failed to remove postpone flag file, error: %w", err)
-		}
-
-		ticker := time.NewTicker(time.Second * 1)
-		defer ticker.Stop()
-
-		for range ticker.C {
-			if _, err := os.Stat(socketFilename); err != nil {
-				break
-			}
+		if err := switchTable(ctx, driver); err != nil {
+			return -1, "", err
 		}
 
 		var afterSchemaBuf bytes.Buffer
@@ -17,6 +17,7 @@ import (
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
 )
 
 // NewSchemaUpdateGhostSyncTaskExecutor creates a schema update (gh-ost) sync task executor.
@@ -37,6 +38,9 @@ func (exec *SchemaUpdateGhostSyncTaskExecutor) RunOnce(ctx context.Context, serv
 	if err := json.Unmarshal([]byte(task.Payload), payload); err != nil {
 		return true, nil, fmt.Errorf("invalid database schema update gh-ost sync payload: %w", err)
 	}
+	if task.Instance.Engine == db.Postgres {
+		return exec.runPgOnlineSchemaChange(ctx, server, task, payload.Statement)
+	}
 	return exec.runGhostMigration(ctx, server, task, payload.Statement)
 }
 
@@ -54,6 +58,9 @@ func (exec *SchemaUpdateGhostSyncTaskExecutor) GetProgress() api.Progress {
 	return progress.(api.Progress)
 }
 
+// ghostProgressLogIntervalTicks is the number of progress ticks between two gh-ost progress logs.
+const ghostProgressLogIntervalTicks = 10
+
 func getSocketFilename(taskID int, databaseID int, databaseName string, tableName string) string {
 	return fmt.Sprintf("/tmp/gh-ost.%v.%v.%v.%v.sock", taskID, databaseID, databaseName, tableName)
 }
@@ -169,7 +176,7 @@ func newMigrationContext(config ghostConfig) (*base.MigrationContext, error) {
 	return migrationContext, nil
 }
 
-func (exec *SchemaUpdateGhostSyncTaskExecutor) runGhostMigration(_ context.Context, _ *Server, task *api.Task, statement string) (terminated bool, result *api.TaskRunResultPayload, err error) {
+func (exec *SchemaUpdateGhostSyncTaskExecutor) runGhostMigration(_ context.Context, server *Server, task *api.Task, statement string) (terminated bool, result *api.TaskRunResultPayload, err error) {
 	syncDone := make(chan struct{})
 	syncError := make(chan error)
 	instance := task.Instance
@@ -215,6 +222,7 @@ func (exec *SchemaUpdateGhostSyncTaskExecutor) runGhostMigration(_ context.Conte
 		ticker := time.NewTicker(1 * time.Second)
 		defer ticker.Stop()
 		createdTs := time.Now().Unix()
+		tickCount := 0
 		for {
 			select {
 			case <-ticker.C:
@@ -229,6 +237,12 @@ func (exec *SchemaUpdateGhostSyncTaskExecutor) runGhostMigration(_ context.Conte
 					CreatedTs:     createdTs,
 					UpdatedTs:     updatedTs,
 				})
+				tickCount++
+				if tickCount%ghostProgressLogIntervalTicks == 0 {
+					server.TaskRunLogManager.AppendLog(ctx, task, api.TaskRunLogGhostProgress, api.TaskRunLogPayload{
+						Message: fmt.Sprintf("Copy: %d/%d rows; Applied: %d events; Elapsed: %ds", completedUnit, totalUnit, atomic.LoadInt64(&migrationContext.TotalDMLEventsApplied), updatedTs-createdTs),
+					})
+				}
 				// Since we are using postpone flag file to postpone cutover, it's gh-ost mechanism to set migrationContext.IsPostponingCutOver to 1 after synced and before postpone flag file is removed. We utilize this mechanism here to check if synced.
 				if atomic.LoadInt64(&migrationContext.IsPostponingCutOver) > 0 {
 					close(syncDone)
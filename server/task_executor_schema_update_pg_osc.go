@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/pg"
+)
+
+const (
+	// pgOnlineSchemaChangeBatchSize is the number of rows copied to the shadow table in a batch.
+	pgOnlineSchemaChangeBatchSize = 1000
+	// pgOnlineSchemaChangeProgressLogIntervalBatches is the number of batches between two progress logs.
+	pgOnlineSchemaChangeProgressLogIntervalBatches = 100
+)
+
+// getPgDriver returns the Postgres driver for the database of the task, which the caller must close.
+func getPgDriver(ctx context.Context, task *api.Task) (*pg.Driver, error) {
+	driver, err := getAdminDatabaseDriver(ctx, task.Instance, task.Database.Name, "" /* pgInstanceDir */)
+	if err != nil {
+		return nil, err
+	}
+	pgDriver, ok := driver.(*pg.Driver)
+	if !ok {
+		driver.Close(ctx)
+		return nil, fmt.Errorf("[internal] cast driver to pg.Driver failed")
+	}
+	return pgDriver, nil
+}
+
+// runPgOnlineSchemaChange creates the shadow table and keeps it in sync with the original table until the cutover.
+func (exec *SchemaUpdateGhostSyncTaskExecutor) runPgOnlineSchemaChange(ctx context.Context, server *Server, task *api.Task, statement string) (terminated bool, result *api.TaskRunResultPayload, err error) {
+	osc, err := pg.NewOnlineSchemaChange(statement)
+	if err != nil {
+		return true, nil, err
+	}
+	driver, err := getPgDriver(ctx, task)
+	if err != nil {
+		return true, nil, err
+	}
+	defer driver.Close(ctx)
+
+	createdTs := time.Now().Unix()
+	batchCount := 0
+	progress := func(copied, total int64) {
+		updatedTs := time.Now().Unix()
+		exec.progress.Store(api.Progress{
+			TotalUnit:     total,
+			CompletedUnit: copied,
+			CreatedTs:     createdTs,
+			UpdatedTs:     updatedTs,
+		})
+		batchCount++
+		if batchCount%pgOnlineSchemaChangeProgressLogIntervalBatches == 0 {
+			server.TaskRunLogManager.AppendLog(ctx, task, api.TaskRunLogGhostProgress, api.TaskRunLogPayload{
+				Message: fmt.Sprintf("Copy: %d/%d rows; Elapsed: %ds", copied, total, updatedTs-createdTs),
+			})
+		}
+	}
+	if err := driver.SyncOnlineSchemaChange(ctx, task.Database.Name, osc, pgOnlineSchemaChangeBatchSize, progress); err != nil {
+		return true, nil, fmt.Errorf("failed to sync shadow table %q, error: %w", osc.ShadowTable(), err)
+	}
+	return true, &api.TaskRunResultPayload{Detail: fmt.Sprintf("sync done, the changes to %q are replayed to the shadow table %q until the cutover", osc.Table(), osc.ShadowTable())}, nil
+}
+
+// switchPgOnlineSchemaChangeTable returns the function switching the original table and the shadow table.
+func switchPgOnlineSchemaChangeTable(task *api.Task, statement string) func(ctx context.Context, driver db.Driver) error {
+	return func(ctx context.Context, driver db.Driver) error {
+		osc, err := pg.NewOnlineSchemaChange(statement)
+		if err != nil {
+			return err
+		}
+		pgDriver, ok := driver.(*pg.Driver)
+		if !ok {
+			return fmt.Errorf("[internal] cast driver to pg.Driver failed")
+		}
+		return pgDriver.CutoverOnlineSchemaChange(ctx, task.Database.Name, osc)
+	}
+}
+
+// checkPgOnlineSchemaChange checks whether the online schema change can run on the database of the task.
+func checkPgOnlineSchemaChange(ctx context.Context, task *api.Task, statement string) error {
+	osc, err := pg.NewOnlineSchemaChange(statement)
+	if err != nil {
+		return err
+	}
+	driver, err := getPgDriver(ctx, task)
+	if err != nil {
+		return err
+	}
+	defer driver.Close(ctx)
+	return driver.CheckOnlineSchemaChange(ctx, task.Database.Name, osc)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+// ghostCommandTimeout is the timeout of sending an interactive command to gh-ost and reading the response.
+const ghostCommandTimeout = 10 * time.Second
+
+// isGhostMigration returns true if the task is the gh-ost sync task, whose gh-ost keeps running until the cutover.
+func isGhostMigration(task *api.Task) bool {
+	return task.Type == api.TaskDatabaseSchemaUpdateGhostSync && task.Instance.Engine == db.MySQL
+}
+
+// getGhostTaskSocketFilename returns the socket file of the gh-ost started by the sync task.
+func getGhostTaskSocketFilename(task *api.Task) (string, error) {
+	payload := &api.TaskDatabaseSchemaUpdateGhostSyncPayload{}
+	if err := json.Unmarshal([]byte(task.Payload), payload); err != nil {
+		return "", fmt.Errorf("invalid database schema update gh-ost sync payload: %w", err)
+	}
+	tableName, err := getTableNameFromStatement(payload.Statement)
+	if err != nil {
+		return "", err
+	}
+	return getSocketFilename(task.ID, task.Database.ID, task.Database.Name, tableName), nil
+}
+
+// sendGhostCommand sends the interactive command to the gh-ost started by the sync task, returning the response.
+// See https://github.com/github/gh-ost/blob/master/doc/interactive-commands.md for the commands.
+func sendGhostCommand(ctx context.Context, task *api.Task, command string) (string, error) {
+	if !isGhostMigration(task) {
+		return "", common.Errorf(common.Invalid, "task %q isn't a gh-ost migration", task.Name)
+	}
+	socketFilename, err := getGhostTaskSocketFilename(task)
+	if err != nil {
+		return "", err
+	}
+	// gh-ost removes the socket file on exit.
+	if _, err := os.Stat(socketFilename); err != nil {
+		return "", common.Errorf(common.Invalid, "gh-ost migration of task %q isn't running", task.Name)
+	}
+
+	dialer := net.Dialer{Timeout: ghostCommandTimeout}
+	conn, err := dialer.DialContext(ctx, "unix", socketFilename)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to gh-ost socket %q, error: %w", socketFilename, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(ghostCommandTimeout)); err != nil {
+		return "", err
+	}
+	if _, err := fmt.Fprintf(conn, "%s\n", command); err != nil {
+		return "", fmt.Errorf("failed to send command %q to gh-ost, error: %w", command, err)
+	}
+	// gh-ost closes the connection after writing the response.
+	response, err := io.ReadAll(conn)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the response of command %q from gh-ost, error: %w", command, err)
+	}
+	return strings.TrimSpace(string(response)), nil
+}
+
+// controlGhostMigration sends the command changing the running gh-ost migration, and records the change as an activity.
+func (s *Server) controlGhostMigration(ctx context.Context, task *api.Task, updaterID int, command string) (string, error) {
+	response, err := sendGhostCommand(ctx, task, command)
+	if err != nil {
+		return "", err
+	}
+
+	issue, err := s.store.GetIssueByPipelineID(ctx, task.PipelineID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch issue with pipeline ID %v, error: %w", task.PipelineID, err)
+	}
+	if issue == nil {
+		return "", fmt.Errorf("issue not found with pipeline ID %v", task.PipelineID)
+	}
+	payload, err := json.Marshal(api.ActivityPipelineTaskGhostControlPayload{
+		TaskID:    task.ID,
+		Command:   command,
+		IssueName: issue.Name,
+		TaskName:  task.Name,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal gh-ost control activity payload, error: %w", err)
+	}
+	if _, err := s.ActivityManager.CreateActivity(ctx, &api.ActivityCreate{
+		CreatorID:   updaterID,
+		ContainerID: issue.ID,
+		Type:        api.ActivityPipelineTaskGhostControl,
+		Payload:     string(payload),
+		Level:       api.ActivityInfo,
+	}, &ActivityMeta{
+		issue: issue,
+	}); err != nil {
+		return "", fmt.Errorf("failed to create activity after sending command %q to gh-ost, error: %w", command, err)
+	}
+	return response, nil
+}
+
+// getGhostConfigCommandList returns the gh-ost interactive commands applying the config patch.
+func getGhostConfigCommandList(patch *api.TaskGhostConfigPatch) []string {
+	var commandList []string
+	if patch.ChunkSize != nil {
+		commandList = append(commandList, fmt.Sprintf("chunk-size=%d", *patch.ChunkSize))
+	}
+	if patch.MaxLoad != nil {
+		commandList = append(commandList, fmt.Sprintf("max-load=%s", *patch.MaxLoad))
+	}
+	if patch.CriticalLoad != nil {
+		commandList = append(commandList, fmt.Sprintf("critical-load=%s", *patch.CriticalLoad))
+	}
+	return commandList
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
+	"github.com/bytebase/bytebase/store"
+)
+
+const (
+	// taskRunLogStatementMaxLength is the max length of the statement recorded in the task run log.
+	taskRunLogStatementMaxLength = 1024
+	// taskRunLogSubscriberBufferSize is the buffer size of the channel of a task run log subscriber.
+	// A subscriber falling behind by more than the buffer size is dropped, and it's expected to reconnect and replay from the last received event.
+	taskRunLogSubscriberBufferSize = 64
+)
+
+// TaskRunLogManager persists the task run log events and fans them out to the clients streaming the log of running task runs.
+type TaskRunLogManager struct {
+	store *store.Store
+
+	mu sync.Mutex
+	// subscribers maps the task run ID to the channels of its subscribers.
+	subscribers map[int]map[chan *api.TaskRunLog]bool
+}
+
+// NewTaskRunLogManager creates a task run log manager.
+func NewTaskRunLogManager(store *store.Store) *TaskRunLogManager {
+	return &TaskRunLogManager{
+		store:       store,
+		subscribers: make(map[int]map[chan *api.TaskRunLog]bool),
+	}
+}
+
+// AppendLog persists the log event to the running task run of the task, and publishes it to the subscribers.
+// Failures are only logged since the log is not critical to the task execution.
+func (m *TaskRunLogManager) AppendLog(ctx context.Context, task *api.Task, logType api.TaskRunLogType, payload api.TaskRunLogPayload) {
+	taskRun := getRunningTaskRun(task)
+	if taskRun == nil {
+		return
+	}
+	payload.Statement = util.TruncateStatement(payload.Statement, taskRunLogStatementMaxLength)
+	taskRunLog, err := m.store.CreateTaskRunLog(ctx, &api.TaskRunLogCreate{
+		TaskRunID: taskRun.ID,
+		Type:      logType,
+		Payload:   payload,
+	})
+	if err != nil {
+		log.Error("Failed to create task run log",
+			zap.Int("task_id", task.ID),
+			zap.Int("task_run_id", taskRun.ID),
+			zap.String("type", string(logType)),
+			zap.Error(err),
+		)
+		return
+	}
+	m.publish(taskRunLog)
+}
+
+// EndLog publishes the end event to the subscribers of the task run and closes them.
+func (m *TaskRunLogManager) EndLog(taskRunID int, status api.TaskRunStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for ch := range m.subscribers[taskRunID] {
+		select {
+		case ch <- &api.TaskRunLog{
+			TaskRunID: taskRunID,
+			Type:      api.TaskRunLogEnd,
+			Payload:   api.TaskRunLogPayload{Status: status},
+		}:
+		default:
+		}
+		close(ch)
+	}
+	delete(m.subscribers, taskRunID)
+}
+
+// Subscribe subscribes to the log events of the task run.
+// The channel is closed after the end event, or if the subscriber falls behind.
+// The caller must call unsubscribe once it stops receiving.
+func (m *TaskRunLogManager) Subscribe(taskRunID int) (ch <-chan *api.TaskRunLog, unsubscribe func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := make(chan *api.TaskRunLog, taskRunLogSubscriberBufferSize)
+	if _, ok := m.subscribers[taskRunID]; !ok {
+		m.subscribers[taskRunID] = make(map[chan *api.TaskRunLog]bool)
+	}
+	m.subscribers[taskRunID][c] = true
+	return c, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if _, ok := m.subscribers[taskRunID][c]; ok {
+			delete(m.subscribers[taskRunID], c)
+			close(c)
+		}
+		if len(m.subscribers[taskRunID]) == 0 {
+			delete(m.subscribers, taskRunID)
+		}
+	}
+}
+
+func (m *TaskRunLogManager) publish(taskRunLog *api.TaskRunLog) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for ch := range m.subscribers[taskRunLog.TaskRunID] {
+		select {
+		case ch <- taskRunLog:
+		default:
+			// Drop the subscriber falling behind.
+			delete(m.subscribers[taskRunLog.TaskRunID], ch)
+			close(ch)
+		}
+	}
+}
+
+// getRunningTaskRun returns the running task run of the task, or nil if there is none.
+func getRunningTaskRun(task *api.Task) *api.TaskRun {
+	for i := len(task.TaskRunList) - 1; i >= 0; i-- {
+		if task.TaskRunList[i].Status == api.TaskRunRunning {
+			return task.TaskRunList[i]
+		}
+	}
+	return nil
+}
+
+type statementLog struct {
+	logType api.TaskRunLogType
+	payload api.TaskRunLogPayload
+}
+
+// getStatementLogList returns the start and end log events of each executed statement. The end event of the failed statement carries the error.
+// It's used after the execution for the statements whose events weren't streamed as the driver executed them.
+func getStatementLogList(resultList []*db.StatementResult) []statementLog {
+	var logList []statementLog
+	for _, result := range resultList {
+		if result.Status == db.StatementSkipped {
+			continue
+		}
+		logList = append(logList, getStatementStartLog(result), getStatementEndLog(result))
+	}
+	return logList
+}
+
+func getStatementStartLog(result *db.StatementResult) statementLog {
+	return statementLog{
+		logType: api.TaskRunLogStatementStart,
+		payload: api.TaskRunLogPayload{
+			Statement: result.Statement,
+		},
+	}
+}
+
+func getStatementEndLog(result *db.StatementResult) statementLog {
+	return statementLog{
+		logType: api.TaskRunLogStatementEnd,
+		payload: api.TaskRunLogPayload{
+			Statement:    result.Statement,
+			DurationNs:   result.DurationNs,
+			RowsAffected: result.RowsAffected,
+			Message:      result.Error,
+		},
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+func TestTaskRunLogManagerSubscribe(t *testing.T) {
+	m := NewTaskRunLogManager(nil)
+	ch, unsubscribe := m.Subscribe(101)
+	defer unsubscribe()
+	otherCh, otherUnsubscribe := m.Subscribe(102)
+	defer otherUnsubscribe()
+
+	m.publish(&api.TaskRunLog{ID: 1, TaskRunID: 101, Type: api.TaskRunLogStatementStart})
+	m.EndLog(101, api.TaskRunDone)
+
+	var got []*api.TaskRunLog
+	for taskRunLog := range ch {
+		got = append(got, taskRunLog)
+	}
+	require.Len(t, got, 2)
+	require.Equal(t, api.TaskRunLogStatementStart, got[0].Type)
+	require.Equal(t, api.TaskRunLogEnd, got[1].Type)
+	require.Equal(t, api.TaskRunDone, got[1].Payload.Status)
+	require.Len(t, otherCh, 0)
+}
+
+func TestTaskRunLogManagerDropSlowSubscriber(t *testing.T) {
+	m := NewTaskRunLogManager(nil)
+	ch, unsubscribe := m.Subscribe(101)
+	defer unsubscribe()
+
+	for i := 0; i <= taskRunLogSubscriberBufferSize; i++ {
+		m.publish(&api.TaskRunLog{ID: i + 1, TaskRunID: 101, Type: api.TaskRunLogGhostProgress})
+	}
+	count := 0
+	for range ch {
+		count++
+	}
+	require.Equal(t, taskRunLogSubscriberBufferSize, count)
+}
+
+func TestGetStatementLogList(t *testing.T) {
+	logList := getStatementLogList([]*db.StatementResult{
+		{Index: 0, Statement: "UPDATE t SET a = 1", DurationNs: 100, RowsAffected: 3, Status: db.StatementDone},
+		{Index: 1, Statement: "UPDATE t SET b = 1", DurationNs: 200, RowsAffected: -1, Status: db.StatementFailed, Error: "deadlock"},
+		{Index: 2, Statement: "UPDATE t SET c = 1", RowsAffected: -1, Status: db.StatementSkipped},
+	})
+	require.Equal(t, []statementLog{
+		{logType: api.TaskRunLogStatementStart, payload: api.TaskRunLogPayload{Statement: "UPDATE t SET a = 1"}},
+		{logType: api.TaskRunLogStatementEnd, payload: api.TaskRunLogPayload{Statement: "UPDATE t SET a = 1", DurationNs: 100, RowsAffected: 3}},
+		{logType: api.TaskRunLogStatementStart, payload: api.TaskRunLogPayload{Statement: "UPDATE t SET b = 1"}},
+		{logType: api.TaskRunLogStatementEnd, payload: api.TaskRunLogPayload{Statement: "UPDATE t SET b = 1", DurationNs: 200, RowsAffected: -1, Message: "deadlock"}},
+	}, logList)
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/common/log"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/plugin/db/util"
 
 	"go.uber.org/zap"
 )
@@ -33,7 +35,11 @@ type TaskScheduler struct {
 	executorGetters  map[api.TaskType]func() TaskExecutor
 	runningExecutors map[int]TaskExecutor
 	taskProgress     sync.Map
-	server           *Server
+	// executorCancels maps the ID of a running task to the context.CancelFunc of its executor.
+	executorCancels sync.Map
+	// pausableExecutors maps the ID of a running task to its executor if the executor is a PausableTaskExecutor.
+	pausableExecutors sync.Map
+	server            *Server
 }
 
 // Run will run the task scheduler.
@@ -81,6 +87,10 @@ func (s *TaskScheduler) Run(ctx context.Context, wg *sync.WaitGroup) {
 					log.Error("Failed to retrieve open pipelines", zap.Error(err))
 					return
 				}
+
+				// Retry the failed tasks whose backoff has elapsed
+				s.retryFailedTasks(ctx, pipelineList)
+
 				for _, pipeline := range pipelineList {
 					if pipeline.ID == api.OnboardingPipelineID {
 						continue
@@ -131,9 +141,24 @@ func (s *TaskScheduler) Run(ctx context.Context, wg *sync.WaitGroup) {
 						continue
 					}
 					s.runningExecutors[task.ID] = executorGetter()
+					executorCtx, cancel := context.WithCancel(ctx)
+					s.executorCancels.Store(task.ID, cancel)
+					if pausable, ok := s.runningExecutors[task.ID].(PausableTaskExecutor); ok {
+						s.pausableExecutors.Store(task.ID, pausable)
+					}
 
-					go func(task *api.Task, executor TaskExecutor) {
-						done, result, err := RunTaskExecutorOnce(ctx, executor, s.server, task)
+					go func(task *api.Task, executor TaskExecutor, executorCtx context.Context, cancel context.CancelFunc) {
+						defer func() {
+							cancel()
+							s.executorCancels.Delete(task.ID)
+							s.pausableExecutors.Delete(task.ID)
+						}()
+						done, result, err := RunTaskExecutorOnce(executorCtx, executor, s.server, task)
+						// The task has been moved to CANCELED, so we only record what the executor has done.
+						if executorCtx.Err() == context.Canceled {
+							s.recordCanceledTaskRun(ctx, task, done, result, err)
+							return
+						}
 						if !done && err != nil {
 							log.Debug("Encountered transient error running task, will retry",
 								zap.Int("id", task.ID),
@@ -150,9 +175,19 @@ func (s *TaskScheduler) Run(ctx context.Context, wg *sync.WaitGroup) {
 								zap.String("type", string(task.Type)),
 								zap.Error(err),
 							)
-							bytes, marshalErr := json.Marshal(api.TaskRunResultPayload{
-								Detail: err.Error(),
-							})
+							payload := api.TaskRunResultPayload{
+								Detail:     err.Error(),
+								RetryCount: getTaskRetryCount(task),
+							}
+							// Keep the execution detail of the statements, telling which statement failed and which have been committed,
+							// and the pre-change backup and the binlog range to roll back the committed ones.
+							if result != nil {
+								payload.StatementList = result.StatementList
+								payload.DataBackupList = result.DataBackupList
+								payload.BinlogRange = result.BinlogRange
+							}
+							payload.NextRetryTs = s.getNextRetryTs(ctx, task, payload.RetryCount, payload.StatementList, err)
+							bytes, marshalErr := json.Marshal(payload)
 							if marshalErr != nil {
 								log.Error("Failed to marshal task run result",
 									zap.Int("task_id", task.ID),
@@ -170,6 +205,9 @@ func (s *TaskScheduler) Run(ctx context.Context, wg *sync.WaitGroup) {
 								Code:      &code,
 								Result:    &result,
 							}
+							s.server.TaskRunLogManager.AppendLog(ctx, task, api.TaskRunLogError, api.TaskRunLogPayload{
+								Message: err.Error(),
+							})
 							_, err = s.server.changeTaskStatusWithPatch(ctx, task, taskStatusPatch)
 							if err != nil {
 								log.Error("Failed to mark task as FAILED",
@@ -181,6 +219,7 @@ func (s *TaskScheduler) Run(ctx context.Context, wg *sync.WaitGroup) {
 							return
 						}
 						if done && err == nil {
+							result.RetryCount = getTaskRetryCount(task)
 							bytes, err := json.Marshal(*result)
 							if err != nil {
 								log.Error("Failed to marshal task run result",
@@ -209,7 +248,7 @@ func (s *TaskScheduler) Run(ctx context.Context, wg *sync.WaitGroup) {
 							}
 							return
 						}
-					}(task, s.runningExecutors[task.ID])
+					}(task, s.runningExecutors[task.ID], executorCtx, cancel)
 				}
 			}()
 		case <-ctx.Done(): // if cancel() execute
@@ -229,6 +268,278 @@ func (s *TaskScheduler) Register(taskType api.TaskType, executorGetter func() Ta
 	s.executorGetters[taskType] = executorGetter
 }
 
+// CancelTask cancels the context of the running executor of the task, and the database drivers kill the query running on the database accordingly.
+// Returns false if the task has no running executor.
+func (s *TaskScheduler) CancelTask(taskID int) bool {
+	cancel, ok := s.executorCancels.Load(taskID)
+	if !ok {
+		return false
+	}
+	cancel.(context.CancelFunc)()
+	return true
+}
+
+// PauseTask pauses the running task.
+func (s *TaskScheduler) PauseTask(taskID int) error {
+	executor, err := s.getPausableExecutor(taskID)
+	if err != nil {
+		return err
+	}
+	if err := executor.Pause(); err != nil {
+		return common.Errorf(common.Invalid, "%v", err)
+	}
+	return nil
+}
+
+// ResumeTask resumes the paused task.
+func (s *TaskScheduler) ResumeTask(taskID int) error {
+	executor, err := s.getPausableExecutor(taskID)
+	if err != nil {
+		return err
+	}
+	if err := executor.Resume(); err != nil {
+		return common.Errorf(common.Invalid, "%v", err)
+	}
+	return nil
+}
+
+func (s *TaskScheduler) getPausableExecutor(taskID int) (PausableTaskExecutor, error) {
+	executor, ok := s.pausableExecutors.Load(taskID)
+	if !ok {
+		return nil, common.Errorf(common.Invalid, "task %d is not running or can't be paused", taskID)
+	}
+	return executor.(PausableTaskExecutor), nil
+}
+
+// recordCanceledTaskRun records the result of the canceled task run after its executor exits, telling what was and wasn't applied.
+func (s *TaskScheduler) recordCanceledTaskRun(ctx context.Context, task *api.Task, done bool, result *api.TaskRunResultPayload, runErr error) {
+	payload := api.TaskRunResultPayload{}
+	if result != nil {
+		payload = *result
+	}
+	code := common.Ok
+	switch {
+	case runErr != nil:
+		code = common.ErrorCode(runErr)
+		if task.Instance != nil && isTaskIdempotent(task.Type, task.Instance.Engine) && !isBatchedDataUpdate(task) && !hasAppliedStatement(task.Instance.Engine, payload.StatementList) {
+			payload.Detail = fmt.Sprintf("Task canceled, and the change has been rolled back since it runs in a single transaction. Error: %v", runErr)
+		} else {
+			payload.Detail = fmt.Sprintf("Task canceled, and the running statement has been killed. The statements executed before it may have been applied, please check the database before retrying. Error: %v", runErr)
+		}
+	case done:
+		payload.Detail = "Task canceled after the change had been applied."
+	default:
+		payload.Detail = "Task canceled before the change was applied."
+	}
+
+	canceledTask, err := s.server.store.GetTaskByID(ctx, task.ID)
+	if err != nil {
+		log.Error("Failed to fetch the canceled task",
+			zap.Int("task_id", task.ID),
+			zap.Error(err),
+		)
+		return
+	}
+	if canceledTask == nil || len(canceledTask.TaskRunList) == 0 {
+		return
+	}
+	// The task run may have been superseded if the task was restarted in the meantime.
+	taskRun := canceledTask.TaskRunList[len(canceledTask.TaskRunList)-1]
+	if taskRun.Status != api.TaskRunCanceled {
+		return
+	}
+
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		log.Error("Failed to marshal task run result",
+			zap.Int("task_id", task.ID),
+			zap.String("type", string(task.Type)),
+			zap.Error(err),
+		)
+		return
+	}
+	resultString := string(bytes)
+	if _, err := s.server.store.PatchTaskRunStatus(ctx, &api.TaskRunStatusPatch{
+		ID:        &taskRun.ID,
+		UpdaterID: api.SystemBotID,
+		Status:    api.TaskRunCanceled,
+		Code:      &code,
+		Result:    &resultString,
+	}); err != nil {
+		log.Error("Failed to record the result of the canceled task run",
+			zap.Int("task_id", task.ID),
+			zap.Int("task_run_id", taskRun.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// getNextRetryTs returns when the failed task is retried automatically, or 0 if it isn't retried. The task is retried if
+// 1. the task is idempotent, i.e. a failed run leaves no partial change behind, and no statement of the failed run has been applied.
+// 2. the error is known to roll back the failed statement, and its class is retryable for the engine according to the task
+// retry policy of the project or the environment.
+// 3. the task hasn't used up the max attempts.
+func (s *TaskScheduler) getNextRetryTs(ctx context.Context, task *api.Task, retryCount int, statementList []*db.StatementResult, runErr error) int64 {
+	if task.Instance == nil || !isTaskIdempotent(task.Type, task.Instance.Engine) || isBatchedDataUpdate(task) {
+		return 0
+	}
+	if hasAppliedStatement(task.Instance.Engine, statementList) {
+		return 0
+	}
+	errorClass := util.ClassifyError(task.Instance.Engine, runErr)
+	if !errorClass.IsRolledBack() {
+		return 0
+	}
+
+	policy, err := s.getTaskRetryPolicy(ctx, task)
+	if err != nil {
+		log.Error("Failed to get task retry policy",
+			zap.Int("task_id", task.ID),
+			zap.Int("environment_id", task.Instance.EnvironmentID),
+			zap.Error(err),
+		)
+		return 0
+	}
+	if !policy.IsRetryable(task.Instance.Engine, errorClass) {
+		return 0
+	}
+	// The failed run is attempt retryCount+1 since the first run isn't a retry.
+	attempts := retryCount + 1
+	if attempts >= policy.MaxAttempts {
+		return 0
+	}
+
+	retryTime := time.Now().Add(policy.GetBackoff(attempts))
+	log.Info("Scheduled automatic retry for failed task",
+		zap.Int("id", task.ID),
+		zap.String("name", task.Name),
+		zap.String("error_class", string(errorClass)),
+		zap.Int("attempt", attempts+1),
+		zap.Time("retry_time", retryTime),
+	)
+	return retryTime.Unix()
+}
+
+// getTaskRetryPolicy returns the task retry policy of the project the task's database belongs to if set, or the one of the environment otherwise.
+func (s *TaskScheduler) getTaskRetryPolicy(ctx context.Context, task *api.Task) (*api.TaskRetryPolicy, error) {
+	if task.Database != nil && task.Database.Project != nil && task.Database.Project.TaskRetryPolicy != "" {
+		return api.UnmarshalTaskRetryPolicy(task.Database.Project.TaskRetryPolicy)
+	}
+	return s.server.store.GetTaskRetryPolicy(ctx, task.Instance.EnvironmentID)
+}
+
+// retryFailedTasks moves the failed tasks of the open pipelines whose retry time has come back to RUNNING,
+// which creates a new task run for the attempt carrying the retry count.
+func (s *TaskScheduler) retryFailedTasks(ctx context.Context, pipelineList []*api.Pipeline) {
+	openPipelineSet := make(map[int]bool)
+	for _, pipeline := range pipelineList {
+		openPipelineSet[pipeline.ID] = true
+	}
+	taskStatusList := []api.TaskStatus{api.TaskFailed}
+	taskList, err := s.server.store.FindTask(ctx, &api.TaskFind{StatusList: &taskStatusList}, false)
+	if err != nil {
+		log.Error("Failed to retrieve failed tasks", zap.Error(err))
+		return
+	}
+
+	now := time.Now().Unix()
+	for _, task := range taskList {
+		if !openPipelineSet[task.PipelineID] || len(task.TaskRunList) == 0 {
+			continue
+		}
+		result := getTaskRunResult(task.TaskRunList[len(task.TaskRunList)-1])
+		if result.NextRetryTs == 0 || now < result.NextRetryTs {
+			continue
+		}
+
+		bytes, err := json.Marshal(api.TaskRunResultPayload{
+			RetryCount: result.RetryCount + 1,
+		})
+		if err != nil {
+			log.Error("Failed to marshal task run result",
+				zap.Int("task_id", task.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		comment := fmt.Sprintf("Automatic retry, attempt %d.", result.RetryCount+2)
+		retryResult := string(bytes)
+		if _, err := s.server.changeTaskStatusWithPatch(ctx, task, &api.TaskStatusPatch{
+			ID:        task.ID,
+			UpdaterID: api.SystemBotID,
+			Status:    api.TaskRunning,
+			Comment:   &comment,
+			Result:    &retryResult,
+		}); err != nil {
+			log.Error("Failed to retry task",
+				zap.Int("id", task.ID),
+				zap.String("name", task.Name),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// isTaskIdempotent returns whether a failed run of the task is rolled back as a whole, so that it's safe to retry automatically.
+// Data updates run in a single transaction, and so do schema updates on Postgres thanks to the transactional DDL. On MySQL,
+// the statements causing an implicit commit and the ones on non-transactional tables break it, see hasAppliedStatement.
+func isTaskIdempotent(taskType api.TaskType, engine db.Type) bool {
+	switch taskType {
+	case api.TaskDatabaseDataUpdate:
+		return true
+	case api.TaskDatabaseSchemaUpdate:
+		return engine == db.Postgres
+	}
+	return false
+}
+
+// hasAppliedStatement returns whether the failed run may have applied some of the statements, so that retrying it would
+// apply them twice. The committed statements, e.g. the ones committed implicitly by a MySQL DDL, have been applied. On MySQL,
+// the changes to the non-transactional tables, e.g. MyISAM, aren't rolled back either, so any statement executed counts.
+func hasAppliedStatement(engine db.Type, statementList []*db.StatementResult) bool {
+	for _, statement := range statementList {
+		if statement.Committed {
+			return true
+		}
+		if engine == db.MySQL && statement.Status == db.StatementDone {
+			return true
+		}
+	}
+	return false
+}
+
+// isBatchedDataUpdate returns whether the task is a batched data update, which commits each batch on its own and thus isn't idempotent.
+func isBatchedDataUpdate(task *api.Task) bool {
+	if task.Type != api.TaskDatabaseDataUpdate {
+		return false
+	}
+	payload := &api.TaskDatabaseDataUpdatePayload{}
+	if err := json.Unmarshal([]byte(task.Payload), payload); err != nil {
+		return false
+	}
+	return payload.BatchConfig != nil
+}
+
+// getTaskRetryCount returns the number of automatic retries of the task before its last run.
+// A run started by a user or by the pipeline scheduling carries no retry count, which starts counting afresh.
+func getTaskRetryCount(task *api.Task) int {
+	if len(task.TaskRunList) == 0 {
+		return 0
+	}
+	return getTaskRunResult(task.TaskRunList[len(task.TaskRunList)-1]).RetryCount
+}
+
+func getTaskRunResult(taskRun *api.TaskRun) *api.TaskRunResultPayload {
+	result := &api.TaskRunResultPayload{}
+	if err := json.Unmarshal([]byte(taskRun.Result), result); err != nil {
+		log.Warn("Failed to unmarshal task run result",
+			zap.Int("task_run_id", taskRun.ID),
+			zap.Error(err),
+		)
+	}
+	return result
+}
+
 // canScheduleTask checks if the task can be scheduled, i.e. change the task status from PENDING to RUNNING.
 func (s *TaskScheduler) canScheduleTask(ctx context.Context, task *api.Task) (bool, error) {
 	blocked, err := s.isTaskBlocked(ctx, task)
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+func TestIsTaskIdempotent(t *testing.T) {
+	require.True(t, isTaskIdempotent(api.TaskDatabaseDataUpdate, db.MySQL))
+	require.True(t, isTaskIdempotent(api.TaskDatabaseSchemaUpdate, db.Postgres))
+	require.False(t, isTaskIdempotent(api.TaskDatabaseSchemaUpdate, db.MySQL))
+	require.False(t, isTaskIdempotent(api.TaskDatabaseCreate, db.Postgres))
+}
+
+func TestHasAppliedStatement(t *testing.T) {
+	done := &db.StatementResult{Status: db.StatementDone}
+	failed := &db.StatementResult{Status: db.StatementFailed}
+	committed := &db.StatementResult{Status: db.StatementDone, Committed: true}
+
+	require.False(t, hasAppliedStatement(db.MySQL, nil))
+	require.False(t, hasAppliedStatement(db.MySQL, []*db.StatementResult{failed}))
+	// The changes to the non-transactional tables on MySQL aren't rolled back.
+	require.True(t, hasAppliedStatement(db.MySQL, []*db.StatementResult{done, failed}))
+	require.False(t, hasAppliedStatement(db.Postgres, []*db.StatementResult{done, failed}))
+	require.True(t, hasAppliedStatement(db.Postgres, []*db.StatementResult{committed, failed}))
+}
+
+func TestIsBatchedDataUpdate(t *testing.T) {
+	require.True(t, isBatchedDataUpdate(&api.Task{
+		Type:    api.TaskDatabaseDataUpdate,
+		Payload: `{"statement":"DELETE FROM t","batchConfig":{"batchSize":1000,"sleepMs":10}}`,
+	}))
+	require.False(t, isBatchedDataUpdate(&api.Task{
+		Type:    api.TaskDatabaseDataUpdate,
+		Payload: `{"statement":"DELETE FROM t"}`,
+	}))
+	require.False(t, isBatchedDataUpdate(&api.Task{
+		Type:    api.TaskDatabaseSchemaUpdate,
+		Payload: `{"statement":"ALTER TABLE t ADD COLUMN a INT"}`,
+	}))
+}
+
+func TestGetTaskRetryCount(t *testing.T) {
+	tests := []struct {
+		name        string
+		taskRunList []*api.TaskRun
+		want        int
+	}{
+		{
+			name: "noRun",
+			want: 0,
+		},
+		{
+			name: "automaticRetry",
+			taskRunList: []*api.TaskRun{
+				{Status: api.TaskRunFailed, Result: `{"detail":"deadlock","nextRetryTs":1659312000}`},
+				{Status: api.TaskRunFailed, Result: `{"detail":"deadlock","retryCount":1,"nextRetryTs":1659312010}`},
+				{Status: api.TaskRunRunning, Result: `{"retryCount":2}`},
+			},
+			want: 2,
+		},
+		{
+			// The run started by a user carries no retry count.
+			name: "manualRetry",
+			taskRunList: []*api.TaskRun{
+				{Status: api.TaskRunFailed, Result: `{"detail":"deadlock","retryCount":2}`},
+				{Status: api.TaskRunRunning, Result: `{}`},
+			},
+			want: 0,
+		},
+		{
+			name: "malformedResult",
+			taskRunList: []*api.TaskRun{
+				{Status: api.TaskRunRunning, Result: ``},
+			},
+			want: 0,
+		},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.want, getTaskRetryCount(&api.Task{TaskRunList: test.taskRunList}), test.name)
+	}
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/common/log"
+)
+
+// tenantDatabaseSchema is the schema fetched from a tenant database at the time of the check.
+type tenantDatabaseSchema struct {
+	database *api.Database
+	version  string
+	schema   string
+	// err is the reason why the schema cannot be fetched.
+	err error
+}
+
+// checkTenantSchemaConsistency compares the schemas of the tenant databases in the project, and upserts or archives the schema inconsistency anomalies accordingly.
+// Only the databases last synced as existing take part in the comparison. The schema compared is dumped from the database at the
+// time of the check, since the schema syncer only stores the table metadata instead of the schema dump.
+// The anomalies of databases excluded from the comparison, i.e. not found by the last sync or not following the database name template, are archived.
+// Databases whose schema cannot be fetched are reported as unchecked, and their anomalies are left untouched.
+func (s *Server) checkTenantSchemaConsistency(ctx context.Context, project *api.Project) (*api.TenantSchemaReport, error) {
+	if project.TenantMode != api.TenantModeTenant {
+		return nil, common.Errorf(common.Invalid, "project %q is not in tenant mode", project.Name)
+	}
+
+	dbList, err := s.store.FindDatabase(ctx, &api.DatabaseFind{
+		ProjectID: &project.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find databases in project %q, error: %w", project.Name, err)
+	}
+
+	// Only tenant databases sharing the same base database name are supposed to have the same schema.
+	baseNameToDatabaseList := make(map[string][]*api.Database)
+	var baseNameList []string
+	for _, database := range dbList {
+		if database.SyncStatus != api.OK {
+			s.upsertOrArchiveSchemaInconsistencyAnomaly(ctx, project, database, nil)
+			continue
+		}
+		baseDatabaseName, err := api.GetBaseDatabaseName(database.Name, project.DBNameTemplate, database.Labels)
+		if err != nil {
+			log.Debug("Skip checking the schema consistency of database not following the database name template",
+				zap.String("project", project.Name),
+				zap.String("database", database.Name),
+				zap.Error(err))
+			s.upsertOrArchiveSchemaInconsistencyAnomaly(ctx, project, database, nil)
+			continue
+		}
+		if _, ok := baseNameToDatabaseList[baseDatabaseName]; !ok {
+			baseNameList = append(baseNameList, baseDatabaseName)
+		}
+		baseNameToDatabaseList[baseDatabaseName] = append(baseNameToDatabaseList[baseDatabaseName], database)
+	}
+	sort.Strings(baseNameList)
+
+	report := &api.TenantSchemaReport{
+		ProjectID: project.ID,
+		GroupList: []*api.TenantSchemaGroup{},
+	}
+	for _, baseDatabaseName := range baseNameList {
+		var schemaList []*tenantDatabaseSchema
+		for _, database := range baseNameToDatabaseList[baseDatabaseName] {
+			schemaList = append(schemaList, s.getTenantDatabaseSchema(ctx, database))
+		}
+		group, payloadMap := groupTenantDatabaseSchema(baseDatabaseName, schemaList)
+		for _, schema := range schemaList {
+			if schema.err != nil {
+				continue
+			}
+			s.upsertOrArchiveSchemaInconsistencyAnomaly(ctx, project, schema.database, payloadMap[schema.database.ID])
+		}
+		report.GroupList = append(report.GroupList, group)
+	}
+	return report, nil
+}
+
+// getTenantDatabaseSchema fetches the latest migration version and the current schema dump of the database.
+func (s *Server) getTenantDatabaseSchema(ctx context.Context, database *api.Database) *tenantDatabaseSchema {
+	ret := &tenantDatabaseSchema{database: database}
+	driver, err := getAdminDatabaseDriver(ctx, database.Instance, database.Name, s.pgInstanceDir)
+	if err != nil {
+		ret.err = err
+		return ret
+	}
+	defer driver.Close(ctx)
+
+	version, err := getLatestSchemaVersion(ctx, driver, database.Name)
+	if err != nil {
+		ret.err = err
+		return ret
+	}
+	ret.version = version
+
+	var schemaBuf bytes.Buffer
+	if _, err := driver.Dump(ctx, database.Name, &schemaBuf, true /* schemaOnly */); err != nil {
+		ret.err = err
+		return ret
+	}
+	ret.schema = schemaBuf.String()
+	return ret
+}
+
+// groupTenantDatabaseSchema groups the tenant database schemas by the schema hash.
+// It returns the group and the anomaly payloads keyed by the IDs of the outlier databases.
+// The majority schema is the one shared by the most databases, ties are broken by the order of the first database in schemaList.
+func groupTenantDatabaseSchema(baseDatabaseName string, schemaList []*tenantDatabaseSchema) (*api.TenantSchemaGroup, map[int]*api.AnomalyDatabaseSchemaInconsistencyPayload) {
+	group := &api.TenantSchemaGroup{
+		BaseDatabaseName:      baseDatabaseName,
+		SchemaList:            []*api.TenantSchema{},
+		UncheckedDatabaseList: []*api.TenantSchemaDatabase{},
+	}
+
+	hashToSchema := make(map[string]*api.TenantSchema)
+	// hashToFirst records the first database schema of each hash, which is used for computing the diff.
+	hashToFirst := make(map[string]*tenantDatabaseSchema)
+	for _, schema := range schemaList {
+		tenantDatabase := &api.TenantSchemaDatabase{
+			ID:      schema.database.ID,
+			Name:    schema.database.Name,
+			Version: schema.version,
+		}
+		if schema.err != nil {
+			tenantDatabase.Error = schema.err.Error()
+			group.UncheckedDatabaseList = append(group.UncheckedDatabaseList, tenantDatabase)
+			continue
+		}
+
+		hash := getSchemaHash(schema.schema)
+		if _, ok := hashToSchema[hash]; !ok {
+			hashToSchema[hash] = &api.TenantSchema{Hash: hash}
+			hashToFirst[hash] = schema
+			group.SchemaList = append(group.SchemaList, hashToSchema[hash])
+		}
+		hashToSchema[hash].DatabaseList = append(hashToSchema[hash].DatabaseList, tenantDatabase)
+	}
+
+	payloadMap := make(map[int]*api.AnomalyDatabaseSchemaInconsistencyPayload)
+	if len(group.SchemaList) == 0 {
+		return group, payloadMap
+	}
+
+	sort.SliceStable(group.SchemaList, func(i, j int) bool {
+		return len(group.SchemaList[i].DatabaseList) > len(group.SchemaList[j].DatabaseList)
+	})
+	majority := hashToFirst[group.SchemaList[0].Hash]
+	for _, tenantSchema := range group.SchemaList[1:] {
+		outlier := hashToFirst[tenantSchema.Hash]
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(majority.schema),
+			B:        difflib.SplitLines(outlier.schema),
+			FromFile: majority.database.Name,
+			ToFile:   outlier.database.Name,
+			Context:  3,
+		})
+		if err != nil {
+			diff = fmt.Sprintf("failed to compute schema diff, error: %v", err)
+		}
+		tenantSchema.Diff = diff
+
+		for _, tenantDatabase := range tenantSchema.DatabaseList {
+			payloadMap[tenantDatabase.ID] = &api.AnomalyDatabaseSchemaInconsistencyPayload{
+				ProjectID:          majority.database.ProjectID,
+				Version:            tenantDatabase.Version,
+				ExpectDatabaseID:   majority.database.ID,
+				ExpectDatabaseName: majority.database.Name,
+				ExpectVersion:      majority.version,
+				Diff:               diff,
+			}
+		}
+	}
+	return group, payloadMap
+}
+
+// getSchemaHash returns the SHA-256 hex digest of the schema.
+func getSchemaHash(schema string) string {
+	h := sha256.Sum256([]byte(schema))
+	return hex.EncodeToString(h[:])
+}
+
+// upsertOrArchiveSchemaInconsistencyAnomaly upserts the schema inconsistency anomaly if payload is not nil, otherwise archives it.
+func (s *Server) upsertOrArchiveSchemaInconsistencyAnomaly(ctx context.Context, project *api.Project, database *api.Database, anomalyPayload *api.AnomalyDatabaseSchemaInconsistencyPayload) {
+	if anomalyPayload == nil {
+		err := s.store.ArchiveAnomaly(ctx, &api.AnomalyArchive{
+			DatabaseID: &database.ID,
+			Type:       api.AnomalyDatabaseSchemaInconsistency,
+		})
+		if err != nil && common.ErrorCode(err) != common.NotFound {
+			log.Error("Failed to close anomaly",
+				zap.String("project", project.Name),
+				zap.String("database", database.Name),
+				zap.String("type", string(api.AnomalyDatabaseSchemaInconsistency)),
+				zap.Error(err))
+		}
+		return
+	}
+
+	payload, err := json.Marshal(anomalyPayload)
+	if err != nil {
+		log.Error("Failed to marshal anomaly payload",
+			zap.String("project", project.Name),
+			zap.String("database", database.Name),
+			zap.String("type", string(api.AnomalyDatabaseSchemaInconsistency)),
+			zap.Error(err))
+		return
+	}
+	if _, err = s.store.UpsertActiveAnomaly(ctx, &api.AnomalyUpsert{
+		CreatorID:  api.SystemBotID,
+		InstanceID: database.InstanceID,
+		DatabaseID: &database.ID,
+		Type:       api.AnomalyDatabaseSchemaInconsistency,
+		Payload:    string(payload),
+	}); err != nil {
+		log.Error("Failed to create anomaly",
+			zap.String("project", project.Name),
+			zap.String("database", database.Name),
+			zap.String("type", string(api.AnomalyDatabaseSchemaInconsistency)),
+			zap.Error(err))
+	}
+}
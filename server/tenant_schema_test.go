@@ -0,0 +1,46 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bytebase/bytebase/api"
+)
+
+func TestGroupTenantDatabaseSchema(t *testing.T) {
+	schema := "CREATE TABLE t (id INT);\n"
+	outlierSchema := "CREATE TABLE t (id INT, name TEXT);\n"
+	schemaList := []*tenantDatabaseSchema{
+		{database: &api.Database{ID: 1, ProjectID: 101, Name: "db_us"}, version: "v2", schema: outlierSchema},
+		{database: &api.Database{ID: 2, ProjectID: 101, Name: "db_eu"}, version: "v1", schema: schema},
+		{database: &api.Database{ID: 3, ProjectID: 101, Name: "db_asia"}, err: fmt.Errorf("connection refused")},
+		{database: &api.Database{ID: 4, ProjectID: 101, Name: "db_au"}, version: "v1", schema: schema},
+	}
+
+	group, payloadMap := groupTenantDatabaseSchema("db", schemaList)
+
+	require.Equal(t, "db", group.BaseDatabaseName)
+	require.Len(t, group.SchemaList, 2)
+	require.Equal(t, getSchemaHash(schema), group.SchemaList[0].Hash)
+	require.Equal(t, []*api.TenantSchemaDatabase{
+		{ID: 2, Name: "db_eu", Version: "v1"},
+		{ID: 4, Name: "db_au", Version: "v1"},
+	}, group.SchemaList[0].DatabaseList)
+	require.Equal(t, "", group.SchemaList[0].Diff)
+	require.Equal(t, getSchemaHash(outlierSchema), group.SchemaList[1].Hash)
+	require.Contains(t, group.SchemaList[1].Diff, "+CREATE TABLE t (id INT, name TEXT);")
+	require.Equal(t, []*api.TenantSchemaDatabase{
+		{ID: 3, Name: "db_asia", Error: "connection refused"},
+	}, group.UncheckedDatabaseList)
+
+	require.Len(t, payloadMap, 1)
+	payload := payloadMap[1]
+	require.Equal(t, 101, payload.ProjectID)
+	require.Equal(t, "v2", payload.Version)
+	require.Equal(t, 2, payload.ExpectDatabaseID)
+	require.Equal(t, "db_eu", payload.ExpectDatabaseName)
+	require.Equal(t, "v1", payload.ExpectVersion)
+	require.Equal(t, group.SchemaList[1].Diff, payload.Diff)
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -24,15 +25,22 @@ import (
 	"github.com/bytebase/bytebase/common/log"
 	"github.com/bytebase/bytebase/plugin/db"
 	"github.com/bytebase/bytebase/plugin/vcs"
+	"github.com/bytebase/bytebase/plugin/vcs/bitbucket"
+	"github.com/bytebase/bytebase/plugin/vcs/gitea"
 	"github.com/bytebase/bytebase/plugin/vcs/github"
 	"github.com/bytebase/bytebase/plugin/vcs/gitlab"
 )
 
 var (
-	gitlabWebhookPath = "hook/gitlab"
-	githubWebhookPath = "hook/github"
+	gitlabWebhookPath    = "hook/gitlab"
+	githubWebhookPath    = "hook/github"
+	giteaWebhookPath     = "hook/gitea"
+	bitbucketWebhookPath = "hook/bitbucket"
 )
 
+// downMigrationFileSuffix is the suffix of the down migration file name, which is committed alongside the migration file.
+const downMigrationFileSuffix = "_down"
+
 func (s *Server) registerWebhookRoutes(g *echo.Group) {
 	g.POST("/gitlab/:id", func(c echo.Context) error {
 		ctx := c.Request().Context()
@@ -46,9 +54,9 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 			return echo.NewHTTPError(http.StatusBadRequest, "Malformed push event").SetInternal(err)
 		}
 
-		// This shouldn't happen as we only setup webhook to receive push event, just in case.
-		if pushEvent.ObjectKind != gitlab.WebhookPush {
-			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid webhook event type, got %s, want push", pushEvent.ObjectKind))
+		// This shouldn't happen as we only setup webhook to receive push and merge request events, just in case.
+		if pushEvent.ObjectKind != gitlab.WebhookPush && pushEvent.ObjectKind != gitlab.WebhookMergeRequest {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid webhook event type, got %s, want push or merge_request", pushEvent.ObjectKind))
 		}
 
 		webhookEndpointID := c.Param("id")
@@ -73,17 +81,42 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Project mismatch, got %d, want %s", pushEvent.Project.ID, repo.ExternalID))
 		}
 
+		if pushEvent.ObjectKind == gitlab.WebhookMergeRequest {
+			mergeRequestEvent := &gitlab.WebhookMergeRequestEvent{}
+			if err := json.Unmarshal(body, mergeRequestEvent); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Malformed merge request event").SetInternal(err)
+			}
+			mergeRequest := mergeRequestEvent.ObjectAttributes
+			// Only review when the merge request is opened or its source branch gets new commits.
+			if mergeRequest.Action != gitlab.WebhookMergeRequestOpen && mergeRequest.Action != gitlab.WebhookMergeRequestReopen &&
+				!(mergeRequest.Action == gitlab.WebhookMergeRequestUpdate && mergeRequest.OldRev != "") {
+				return c.String(http.StatusOK, fmt.Sprintf("Ignored merge request action %q", mergeRequest.Action))
+			}
+
+			log.Debug("Processing GitLab webhook merge request event...",
+				zap.String("project", repo.Project.Name),
+				zap.Int("merge_request", mergeRequest.IID),
+			)
+			message, err := s.reviewPullRequest(ctx, repo, pullRequestEvent{
+				ID:           strconv.Itoa(mergeRequest.IID),
+				HeadCommitID: mergeRequest.LastCommit.ID,
+				TargetBranch: mergeRequest.TargetBranch,
+			})
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to review merge request %d", mergeRequest.IID)).SetInternal(err)
+			}
+			return c.String(http.StatusOK, message)
+		}
+
 		log.Debug("Processing GitLab webhook push event...",
 			zap.String("project", repo.Project.Name),
 		)
 
 		distinctFileList := dedupMigrationFilesFromCommitList(pushEvent.CommitList)
-		var createdMessageList []string
+		var fileList []*api.PushEventFile
 		for _, item := range distinctFileList {
-			createdMessage, created, httpErr := s.createIssueFromPushEvent(
-				ctx,
-				repo,
-				vcs.PushEvent{
+			fileList = append(fileList, &api.PushEventFile{
+				PushEvent: vcs.PushEvent{
 					VCSType:            repo.VCS.Type,
 					BaseDirectory:      repo.BaseDirectory,
 					Ref:                pushEvent.Ref,
@@ -102,33 +135,61 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 						Added:       common.EscapeForLogging(item.fileName),
 					},
 				},
-				item.fileName,
-				webhookEndpointID,
-			)
-			if httpErr != nil {
-				return httpErr
+				File: item.fileName,
+			})
+		}
+		var modifiedFileList []*api.PushEventFile
+		for _, commit := range pushEvent.CommitList {
+			createdTime, err := time.Parse(time.RFC3339, commit.Timestamp)
+			if err != nil {
+				log.Warn("Failed to parse commit timestamp.", zap.String("commit", common.EscapeForLogging(commit.ID)), zap.String("timestamp", common.EscapeForLogging(commit.Timestamp)), zap.Error(err))
 			}
-
-			if created {
-				createdMessageList = append(createdMessageList, createdMessage)
+			for _, modified := range commit.ModifiedList {
+				modifiedFileList = append(modifiedFileList, &api.PushEventFile{
+					PushEvent: vcs.PushEvent{
+						VCSType:            repo.VCS.Type,
+						BaseDirectory:      repo.BaseDirectory,
+						Ref:                pushEvent.Ref,
+						RepositoryID:       strconv.Itoa(pushEvent.Project.ID),
+						RepositoryURL:      pushEvent.Project.WebURL,
+						RepositoryFullPath: pushEvent.Project.FullPath,
+						AuthorName:         pushEvent.AuthorName,
+						FileCommit: vcs.FileCommit{
+							ID:          commit.ID,
+							Title:       commit.Title,
+							Message:     commit.Message,
+							CreatedTs:   createdTime.Unix(),
+							URL:         commit.URL,
+							AuthorName:  commit.Author.Name,
+							AuthorEmail: commit.Author.Email,
+						},
+					},
+					File: modified,
+				})
 			}
 		}
 
+		modifiedMessageList := s.checkModifiedMigrationFileList(ctx, repo, modifiedFileList)
+		createdMessageList, err := s.processPushEvent(ctx, repo, body, fileList)
+		if err != nil {
+			return err
+		}
+
 		if len(createdMessageList) == 0 {
 			msg := "Ignored push event. No applicable file found in the commit list."
 			log.Warn(msg,
 				zap.String("project", repo.Project.Name),
 			)
 		}
-		return c.String(http.StatusOK, strings.Join(createdMessageList, "\n"))
+		return c.String(http.StatusOK, strings.Join(append(createdMessageList, modifiedMessageList...), "\n"))
 	})
 	g.POST("/github/:id", func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		// This shouldn't happen as we only setup webhook to receive push event, just in case.
+		// This shouldn't happen as we only setup webhook to receive push and pull request events, just in case.
 		eventType := github.WebhookType(c.Request().Header.Get("X-GitHub-Event"))
-		if eventType != github.WebhookPush {
-			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid webhook event type, got %s, want %s", eventType, github.WebhookPush))
+		if eventType != github.WebhookPush && eventType != github.WebhookPullRequest {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid webhook event type, got %s, want %s or %s", eventType, github.WebhookPush, github.WebhookPullRequest))
 		}
 
 		webhookEndpointID := c.Param("id")
@@ -156,6 +217,36 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 			return echo.NewHTTPError(http.StatusBadRequest, "Mismatched payload signature")
 		}
 
+		if eventType == github.WebhookPullRequest {
+			var webhookPullRequestEvent github.WebhookPullRequestEvent
+			if err := json.Unmarshal(body, &webhookPullRequestEvent); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Malformed pull request event").SetInternal(err)
+			}
+			if webhookPullRequestEvent.Repository.FullName != repo.ExternalID {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Project mismatch, got %s, want %s", webhookPullRequestEvent.Repository.FullName, repo.ExternalID))
+			}
+			// Only review when the pull request is opened or its head branch gets new commits.
+			action := webhookPullRequestEvent.Action
+			if action != github.WebhookPullRequestOpened && action != github.WebhookPullRequestReopened && action != github.WebhookPullRequestSynchronize {
+				return c.String(http.StatusOK, fmt.Sprintf("Ignored pull request action %q", action))
+			}
+
+			pullRequest := webhookPullRequestEvent.PullRequest
+			log.Debug("Processing GitHub webhook pull request event...",
+				zap.String("project", repo.Project.Name),
+				zap.Int("pull_request", pullRequest.Number),
+			)
+			message, err := s.reviewPullRequest(ctx, repo, pullRequestEvent{
+				ID:           strconv.Itoa(pullRequest.Number),
+				HeadCommitID: pullRequest.Head.SHA,
+				TargetBranch: pullRequest.Base.Ref,
+			})
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to review pull request %d", pullRequest.Number)).SetInternal(err)
+			}
+			return c.String(http.StatusOK, message)
+		}
+
 		var pushEvent github.WebhookPushEvent
 		if err := json.Unmarshal(body, &pushEvent); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "Malformed push event").SetInternal(err)
@@ -169,59 +260,301 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 			zap.String("project", repo.Project.Name),
 		)
 
-		var createdMessageList []string
+		// If the repository maps the branches to the environments, the migration files are promoted by merging the
+		// branch, whose commits have been pushed to the previous branch before thus are not distinct.
+		includeNonDistinct := hasBranchEnvironment(repo)
+		var fileList, modifiedFileList []*api.PushEventFile
 		for _, commit := range pushEvent.Commits {
 			// The Distinct is false if the commit is superseded by a later commit.
-			if !commit.Distinct {
+			if !commit.Distinct && !includeNonDistinct {
 				continue
 			}
 
+			// Per Git convention, the message title and body are separated by two new line characters.
+			messages := strings.SplitN(commit.Message, "\n\n", 2)
+			messageTitle := messages[0]
+			commitPushEvent := vcs.PushEvent{
+				VCSType:            repo.VCS.Type,
+				BaseDirectory:      repo.BaseDirectory,
+				Ref:                pushEvent.Ref,
+				RepositoryID:       strconv.Itoa(pushEvent.Repository.ID),
+				RepositoryURL:      pushEvent.Repository.HTMLURL,
+				RepositoryFullPath: pushEvent.Repository.FullName,
+				AuthorName:         pushEvent.Sender.Login,
+				FileCommit: vcs.FileCommit{
+					ID:          commit.ID,
+					Title:       messageTitle,
+					Message:     commit.Message,
+					CreatedTs:   commit.Timestamp.Unix(),
+					URL:         commit.URL,
+					AuthorName:  commit.Author.Name,
+					AuthorEmail: commit.Author.Email,
+				},
+			}
+
+			for _, added := range commit.Added {
+				addedPushEvent := commitPushEvent
+				addedPushEvent.FileCommit.Added = common.EscapeForLogging(added)
+				fileList = append(fileList, &api.PushEventFile{
+					PushEvent: addedPushEvent,
+					File:      added,
+				})
+			}
+			for _, modified := range commit.Modified {
+				modifiedFileList = append(modifiedFileList, &api.PushEventFile{
+					PushEvent: commitPushEvent,
+					File:      modified,
+				})
+			}
+		}
+		if includeNonDistinct {
+			fileList = dedupPushEventFileList(fileList)
+		}
+
+		modifiedMessageList := s.checkModifiedMigrationFileList(ctx, repo, modifiedFileList)
+		createdMessageList, err := s.processPushEvent(ctx, repo, body, fileList)
+		if err != nil {
+			return err
+		}
+
+		if len(createdMessageList) == 0 {
+			log.Warn("Ignored push event. No applicable file found in the commit list.",
+				zap.String("project", repo.Project.Name),
+			)
+		}
+		return c.String(http.StatusOK, strings.Join(append(createdMessageList, modifiedMessageList...), "\n"))
+	})
+	g.POST("/gitea/:id", func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		// This shouldn't happen as we only setup webhook to receive push event, just in case.
+		// Forgejo sends the same event with both its own and the Gitea headers.
+		eventType := gitea.WebhookType(getGiteaWebhookHeader(c.Request().Header, "Event"))
+		if eventType != gitea.WebhookPush {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid webhook event type, got %s, want %s", eventType, gitea.WebhookPush))
+		}
+
+		webhookEndpointID := c.Param("id")
+		repo, err := s.store.GetRepository(ctx, &api.RepositoryFind{WebhookEndpointID: &webhookEndpointID})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to respond webhook event for endpoint: %v", webhookEndpointID)).SetInternal(err)
+		}
+		if repo == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Webhook endpoint not found: %v", webhookEndpointID))
+		}
+
+		if repo.VCS == nil {
+			err := fmt.Errorf("VCS not found for ID: %v", repo.VCSID)
+			return echo.NewHTTPError(http.StatusInternalServerError, err).SetInternal(err)
+		}
+
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Failed to read webhook request").SetInternal(err)
+		}
+
+		// Validate the request body first because there is no point in unmarshalling
+		// the request body if the signature doesn't match.
+		if !validateGiteaWebhookSignature256(getGiteaWebhookHeader(c.Request().Header, "Signature"), repo.WebhookSecretToken, body) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Mismatched payload signature")
+		}
+
+		var pushEvent gitea.WebhookPushEvent
+		if err := json.Unmarshal(body, &pushEvent); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Malformed push event").SetInternal(err)
+		}
+
+		if pushEvent.Repository.FullName != repo.ExternalID {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Project mismatch, got %s, want %s", pushEvent.Repository.FullName, repo.ExternalID))
+		}
+
+		log.Debug("Processing Gitea webhook push event...",
+			zap.String("project", repo.Project.Name),
+		)
+
+		var fileList, modifiedFileList []*api.PushEventFile
+		for _, commit := range pushEvent.Commits {
+			// Per Git convention, the message title and body are separated by two new line characters.
+			messages := strings.SplitN(commit.Message, "\n\n", 2)
+			messageTitle := messages[0]
+			commitPushEvent := vcs.PushEvent{
+				VCSType:            repo.VCS.Type,
+				BaseDirectory:      repo.BaseDirectory,
+				Ref:                pushEvent.Ref,
+				RepositoryID:       pushEvent.Repository.FullName,
+				RepositoryURL:      pushEvent.Repository.HTMLURL,
+				RepositoryFullPath: pushEvent.Repository.FullName,
+				AuthorName:         pushEvent.Sender.Login,
+				FileCommit: vcs.FileCommit{
+					ID:          commit.ID,
+					Title:       messageTitle,
+					Message:     commit.Message,
+					CreatedTs:   commit.Timestamp.Unix(),
+					URL:         commit.URL,
+					AuthorName:  commit.Author.Name,
+					AuthorEmail: commit.Author.Email,
+				},
+			}
+
 			for _, added := range commit.Added {
+				addedPushEvent := commitPushEvent
+				addedPushEvent.FileCommit.Added = common.EscapeForLogging(added)
+				fileList = append(fileList, &api.PushEventFile{
+					PushEvent: addedPushEvent,
+					File:      added,
+				})
+			}
+			for _, modified := range commit.Modified {
+				modifiedFileList = append(modifiedFileList, &api.PushEventFile{
+					PushEvent: commitPushEvent,
+					File:      modified,
+				})
+			}
+		}
+
+		modifiedMessageList := s.checkModifiedMigrationFileList(ctx, repo, modifiedFileList)
+		createdMessageList, err := s.processPushEvent(ctx, repo, body, fileList)
+		if err != nil {
+			return err
+		}
+
+		if len(createdMessageList) == 0 {
+			log.Warn("Ignored push event. No applicable file found in the commit list.",
+				zap.String("project", repo.Project.Name),
+			)
+		}
+		return c.String(http.StatusOK, strings.Join(append(createdMessageList, modifiedMessageList...), "\n"))
+	})
+	g.POST("/bitbucket/:id", func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		// This shouldn't happen as we only setup webhook to receive push event, just in case.
+		eventKey := bitbucket.WebhookEventKey(c.Request().Header.Get("X-Event-Key"))
+		if eventKey != bitbucket.WebhookRepoPush && eventKey != bitbucket.WebhookRepoRefsChanged {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid webhook event type, got %s, want %s or %s", eventKey, bitbucket.WebhookRepoPush, bitbucket.WebhookRepoRefsChanged))
+		}
+
+		webhookEndpointID := c.Param("id")
+		repo, err := s.store.GetRepository(ctx, &api.RepositoryFind{WebhookEndpointID: &webhookEndpointID})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to respond webhook event for endpoint: %v", webhookEndpointID)).SetInternal(err)
+		}
+		if repo == nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Webhook endpoint not found: %v", webhookEndpointID))
+		}
+
+		if repo.VCS == nil {
+			err := fmt.Errorf("VCS not found for ID: %v", repo.VCSID)
+			return echo.NewHTTPError(http.StatusInternalServerError, err).SetInternal(err)
+		}
+
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Failed to read webhook request").SetInternal(err)
+		}
+
+		// Validate the request body first because there is no point in unmarshalling
+		// the request body if the signature doesn't match. Both Bitbucket editions sign
+		// the payload in the same way as GitHub.
+		if !validateGitHubWebhookSignature256(c.Request().Header.Get("X-Hub-Signature"), repo.WebhookSecretToken, body) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Mismatched payload signature")
+		}
+
+		pushEvent, err := bitbucket.ParseWebhookPushEvent(eventKey, body)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Malformed push event").SetInternal(err)
+		}
+
+		if pushEvent.Repository.FullName != repo.ExternalID {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Project mismatch, got %s, want %s", pushEvent.Repository.FullName, repo.ExternalID))
+		}
+
+		log.Debug("Processing Bitbucket webhook push event...",
+			zap.String("project", repo.Project.Name),
+		)
+
+		provider, ok := vcs.Get(repo.VCS.Type, vcs.ProviderConfig{}).(*bitbucket.Provider)
+		if !ok {
+			err := fmt.Errorf("unexpected VCS type %s for the Bitbucket webhook", repo.VCS.Type)
+			return echo.NewHTTPError(http.StatusInternalServerError, err).SetInternal(err)
+		}
+		oauthContext := common.OauthContext{
+			ClientID:     repo.VCS.ApplicationID,
+			ClientSecret: repo.VCS.Secret,
+			AccessToken:  repo.AccessToken,
+			RefreshToken: repo.RefreshToken,
+			Refresher:    s.refreshToken(ctx, repo.ID),
+		}
+
+		var fileList, modifiedFileList []*api.PushEventFile
+		for _, change := range pushEvent.Changes {
+			// Bitbucket webhooks cannot filter the push event by branch, thus we do it here. The branch environment
+			// list supersedes the branch filter, which is matched when creating the issue from each file.
+			if !hasBranchEnvironment(repo) && !matchBranchFilter(repo.BranchFilter, change.Branch) {
+				log.Debug("Ignored ref change, branch does not match the branch filter.",
+					zap.String("branch", common.EscapeForLogging(change.Branch)),
+					zap.String("branch_filter", repo.BranchFilter),
+				)
+				continue
+			}
+
+			commitList, err := provider.FetchPushEventCommitList(ctx, oauthContext, repo.VCS.InstanceURL, repo.ExternalID, change)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch commits of the push event").SetInternal(err)
+			}
+
+			for _, commit := range commitList {
 				// Per Git convention, the message title and body are separated by two new line characters.
 				messages := strings.SplitN(commit.Message, "\n\n", 2)
 				messageTitle := messages[0]
-
-				createdMessage, created, httpErr := s.createIssueFromPushEvent(
-					ctx,
-					repo,
-					vcs.PushEvent{
-						VCSType:            repo.VCS.Type,
-						BaseDirectory:      repo.BaseDirectory,
-						Ref:                pushEvent.Ref,
-						RepositoryID:       strconv.Itoa(pushEvent.Repository.ID),
-						RepositoryURL:      pushEvent.Repository.HTMLURL,
-						RepositoryFullPath: pushEvent.Repository.FullName,
-						AuthorName:         pushEvent.Sender.Login,
-						FileCommit: vcs.FileCommit{
-							ID:          commit.ID,
-							Title:       messageTitle,
-							Message:     commit.Message,
-							CreatedTs:   commit.Timestamp.Unix(),
-							URL:         commit.URL,
-							AuthorName:  commit.Author.Name,
-							AuthorEmail: commit.Author.Email,
-							Added:       common.EscapeForLogging(added),
-						},
+				commitPushEvent := vcs.PushEvent{
+					VCSType:            repo.VCS.Type,
+					BaseDirectory:      repo.BaseDirectory,
+					Ref:                change.Ref,
+					RepositoryID:       pushEvent.Repository.FullName,
+					RepositoryURL:      pushEvent.Repository.HTMLURL,
+					RepositoryFullPath: pushEvent.Repository.FullName,
+					AuthorName:         pushEvent.ActorName,
+					FileCommit: vcs.FileCommit{
+						ID:          commit.ID,
+						Title:       messageTitle,
+						Message:     commit.Message,
+						CreatedTs:   commit.Timestamp.Unix(),
+						URL:         commit.URL,
+						AuthorName:  commit.AuthorName,
+						AuthorEmail: commit.AuthorEmail,
 					},
-					added,
-					webhookEndpointID,
-				)
-				if httpErr != nil {
-					return httpErr
 				}
 
-				if created {
-					createdMessageList = append(createdMessageList, createdMessage)
+				for _, added := range commit.Added {
+					addedPushEvent := commitPushEvent
+					addedPushEvent.FileCommit.Added = common.EscapeForLogging(added)
+					fileList = append(fileList, &api.PushEventFile{
+						PushEvent: addedPushEvent,
+						File:      added,
+					})
+				}
+				for _, modified := range commit.Modified {
+					modifiedFileList = append(modifiedFileList, &api.PushEventFile{
+						PushEvent: commitPushEvent,
+						File:      modified,
+					})
 				}
 			}
 		}
 
+		modifiedMessageList := s.checkModifiedMigrationFileList(ctx, repo, modifiedFileList)
+		createdMessageList, err := s.processPushEvent(ctx, repo, body, fileList)
+		if err != nil {
+			return err
+		}
+
 		if len(createdMessageList) == 0 {
 			log.Warn("Ignored push event. No applicable file found in the commit list.",
 				zap.String("project", repo.Project.Name),
 			)
 		}
-		return c.String(http.StatusOK, strings.Join(createdMessageList, "\n"))
+		return c.String(http.StatusOK, strings.Join(append(createdMessageList, modifiedMessageList...), "\n"))
 	})
 }
 
@@ -239,6 +572,38 @@ func validateGitHubWebhookSignature256(signature, key string, body []byte) bool
 	return subtle.ConstantTimeCompare([]byte(signature), []byte(got)) == 1
 }
 
+// matchBranchFilter returns true if the branch matches the branch filter, which
+// is either the branch name or a glob pattern such as "release/*". An empty
+// branch filter matches all branches.
+func matchBranchFilter(branchFilter, branch string) bool {
+	if branchFilter == "" {
+		return true
+	}
+	matched, err := path.Match(branchFilter, branch)
+	return err == nil && matched
+}
+
+// getGiteaWebhookHeader returns the value of the Gitea webhook header with the
+// given name, e.g. "Event" for "X-Gitea-Event". Forgejo sends its own
+// "X-Forgejo-*" headers along with the Gitea ones, which are used as the
+// fallback.
+func getGiteaWebhookHeader(header http.Header, name string) string {
+	if v := header.Get("X-Gitea-" + name); v != "" {
+		return v
+	}
+	return header.Get("X-Forgejo-" + name)
+}
+
+// validateGiteaWebhookSignature256 returns true if the signature matches the
+// HMAC hex digested SHA256 hash of the body using the given key. Unlike GitHub,
+// Gitea sends the hex digest without the "sha256=" prefix.
+func validateGiteaWebhookSignature256(signature, key string, body []byte) bool {
+	m := hmac.New(sha256.New, []byte(key))
+	m.Write(body)
+	got := hex.EncodeToString(m.Sum(nil))
+	return subtle.ConstantTimeCompare([]byte(signature), []byte(got)) == 1
+}
+
 // We are observing the push webhook event so that we will receive the event either when:
 // 1. A commit is directly pushed to a branch.
 // 2. One or more commits are merged to a branch.
@@ -305,19 +670,30 @@ func dedupMigrationFilesFromCommitList(commitList []gitlab.WebhookCommit) []dist
 	return distinctFileList
 }
 
-func (s *Server) createSchemaUpdateIssue(ctx context.Context, repository *api.Repository, mi *db.MigrationInfo, vcsPushEvent vcs.PushEvent, added string, statement string) (string, error) {
-	// Find matching database list
-	databaseFind := &api.DatabaseFind{
-		ProjectID: &repository.ProjectID,
-		Name:      &mi.Database,
-	}
-	databaseList, err := s.store.FindDatabase(ctx, databaseFind)
-	if err != nil {
-		return "", fmt.Errorf("failed to find database matching database %q referenced by the committed file", mi.Database)
-	} else if len(databaseList) == 0 {
-		return "", fmt.Errorf("project with ID %d does not own database %q referenced by the committed file", repository.ProjectID, mi.Database)
+// dedupPushEventFileList dedups the files of the push event by the file path, keeping the one from the latest commit
+// in the position of its first occurrence. See distinctFileItem for the rationale.
+func dedupPushEventFileList(fileList []*api.PushEventFile) []*api.PushEventFile {
+	var distinctFileList []*api.PushEventFile
+	indexByFile := make(map[string]int)
+	for _, file := range fileList {
+		i, ok := indexByFile[file.File]
+		if !ok {
+			indexByFile[file.File] = len(distinctFileList)
+			distinctFileList = append(distinctFileList, file)
+			continue
+		}
+		if distinctFileList[i].PushEvent.FileCommit.CreatedTs < file.PushEvent.FileCommit.CreatedTs {
+			distinctFileList[i] = file
+		}
 	}
+	return distinctFileList
+}
 
+// createSchemaUpdateIssue returns the issue create context for the databases referenced by the committed file. If
+// skipAppliedVersion is true, the databases having applied or being applying the migration version are excluded, and
+// an empty create context is returned if no database is left. The error is common.Invalid if the committed file doesn't
+// reference the databases properly.
+func (s *Server) createSchemaUpdateIssue(ctx context.Context, repository *api.Repository, mi *db.MigrationInfo, vcsPushEvent vcs.PushEvent, added string, statement, rollbackStatement, desiredSchema string, skipAppliedVersion bool) (string, error) {
 	// We support 3 patterns on how to organize the schema files.
 	// Pattern 1: 	The database name is the same across all environments. Each environment will have its own directory, so the
 	//              schema file looks like "dev/v1__db1", "staging/v1__db1".
@@ -329,20 +705,16 @@ func (s *Server) createSchemaUpdateIssue(ctx context.Context, repository *api.Re
 	// Pattern 3:  	The database name is different among different environments. In such case, the database name alone is enough
 	//             	to identify ambiguity.
 
-	// Further filter by environment name if applicable.
-	filteredDatabaseList := []*api.Database{}
-	if mi.Environment != "" {
-		for _, database := range databaseList {
-			// Environment name comparison is case insensitive
-			if strings.EqualFold(database.Instance.Environment.Name, mi.Environment) {
-				filteredDatabaseList = append(filteredDatabaseList, database)
-			}
-		}
-		if len(filteredDatabaseList) == 0 {
-			return "", fmt.Errorf("project does not contain committed file database %q for environment %q", mi.Database, mi.Environment)
+	// Find matching database list, further filtered by environment name if applicable.
+	filteredDatabaseList, err := s.findMigrationDatabaseList(ctx, repository.ProjectID, mi)
+	if err != nil {
+		return "", err
+	}
+	if len(filteredDatabaseList) == 0 {
+		if mi.Environment != "" {
+			return "", common.Errorf(common.Invalid, "project does not contain committed file database %q for environment %q", mi.Database, mi.Environment)
 		}
-	} else {
-		filteredDatabaseList = databaseList
+		return "", common.Errorf(common.Invalid, "project with ID %d does not own database %q referenced by the committed file", repository.ProjectID, mi.Database)
 	}
 
 	// It could happen that for a particular environment a project contain 2 database with the same name.
@@ -358,7 +730,17 @@ func (s *Server) createSchemaUpdateIssue(ctx context.Context, repository *api.Re
 		}
 	}
 	if len(multipleDatabaseForSameEnv) > 0 {
-		return "", fmt.Errorf("ignored committed files with multiple ambiguous databases %s", strings.Join(multipleDatabaseForSameEnv, ", "))
+		return "", common.Errorf(common.Invalid, "ignored committed files with multiple ambiguous databases %s", strings.Join(multipleDatabaseForSameEnv, ", "))
+	}
+
+	if skipAppliedVersion {
+		filteredDatabaseList, err = s.filterAppliedMigrationDatabaseList(ctx, repository, filteredDatabaseList, mi.Version)
+		if err != nil {
+			return "", err
+		}
+		if len(filteredDatabaseList) == 0 {
+			return "", nil
+		}
 	}
 
 	// Compose the new issue
@@ -369,8 +751,10 @@ func (s *Server) createSchemaUpdateIssue(ctx context.Context, repository *api.Re
 	for _, database := range filteredDatabaseList {
 		m.DetailList = append(m.DetailList,
 			&api.UpdateSchemaDetail{
-				DatabaseID: database.ID,
-				Statement:  statement,
+				DatabaseID:        database.ID,
+				Statement:         statement,
+				RollbackStatement: rollbackStatement,
+				DesiredSchema:     desiredSchema,
 			})
 	}
 	createContext, err := json.Marshal(m)
@@ -380,18 +764,20 @@ func (s *Server) createSchemaUpdateIssue(ctx context.Context, repository *api.Re
 	return string(createContext), nil
 }
 
-func createTenantSchemaUpdateIssue(mi *db.MigrationInfo, vcsPushEvent vcs.PushEvent, statement string) (string, error) {
+func createTenantSchemaUpdateIssue(mi *db.MigrationInfo, vcsPushEvent vcs.PushEvent, statement, rollbackStatement, desiredSchema string) (string, error) {
 	// We don't take environment for tenant mode project because the databases needing schema update are determined by database name and deployment configuration.
 	if mi.Environment != "" {
-		return "", fmt.Errorf("environment isn't accepted in schema update for tenant mode project")
+		return "", common.Errorf(common.Invalid, "environment isn't accepted in schema update for tenant mode project")
 	}
 	m := &api.UpdateSchemaContext{
 		MigrationType: mi.Type,
 		VCSPushEvent:  &vcsPushEvent,
 		DetailList: []*api.UpdateSchemaDetail{
 			{
-				DatabaseName: mi.Database,
-				Statement:    statement,
+				DatabaseName:      mi.Database,
+				Statement:         statement,
+				RollbackStatement: rollbackStatement,
+				DesiredSchema:     desiredSchema,
 			},
 		},
 	}
@@ -403,10 +789,14 @@ func createTenantSchemaUpdateIssue(mi *db.MigrationInfo, vcsPushEvent vcs.PushEv
 }
 
 // createIssueFromPushEvent attempts to create a new issue for the given file of
-// the push event. It returns "created=true" when a new issue has been created,
-// along with the creation message to be presented in the UI. An *echo.HTTPError
-// is returned in case of the error during the process.
-func (s *Server) createIssueFromPushEvent(ctx context.Context, repo *api.Repository, pushEvent vcs.PushEvent, file, webhookEndpointID string) (message string, created bool, _ error) {
+// the push event. It returns the outcome of the file, which is either ignored with
+// the reason or has an issue created along with the creation message to be presented
+// in the UI. An *echo.HTTPError is returned in case of the error during the process.
+func (s *Server) createIssueFromPushEvent(ctx context.Context, repo *api.Repository, pushEvent vcs.PushEvent, file string) (*api.PushEventFile, error) {
+	result := &api.PushEventFile{
+		PushEvent: pushEvent,
+		File:      file,
+	}
 	fileEscaped := common.EscapeForLogging(file)
 	log.Debug("Processing added file...",
 		zap.String("file", fileEscaped),
@@ -418,23 +808,37 @@ func (s *Server) createIssueFromPushEvent(ctx context.Context, repo *api.Reposit
 			zap.String("file", fileEscaped),
 			zap.String("base_directory", repo.BaseDirectory),
 		)
-		return "", false, nil
+		result.Status, result.Message = api.PushEventFileIgnored, fmt.Sprintf("not under the base directory %q", repo.BaseDirectory)
+		return result, nil
+	}
+
+	// The project with declarative schema migration only takes the schema files, which hold the desired schema.
+	isSDL := repo.Project.SchemaChangeType == api.ProjectSchemaChangeTypeSDL
+	if isSDL && !isSkipGeneratedSchemaFile(repo, fileEscaped) {
+		log.Debug("Ignored committed file, not a schema file for the declarative schema migration.",
+			zap.String("file", fileEscaped),
+			zap.String("schema_path_template", repo.SchemaPathTemplate),
+		)
+		result.Status, result.Message = api.PushEventFileIgnored, "not a schema file for the declarative schema migration"
+		return result, nil
 	}
 
 	// Ignore the schema file we auto generated to the repository.
-	if isSkipGeneratedSchemaFile(repo, fileEscaped) {
+	if !isSDL && isSkipGeneratedSchemaFile(repo, fileEscaped) {
 		log.Debug("Ignored generated latest schema file.",
 			zap.String("file", fileEscaped),
 		)
-		return "", false, nil
+		result.Status, result.Message = api.PushEventFileIgnored, "the latest schema file generated by Bytebase"
+		return result, nil
 	}
 
-	// Create a WARNING project activity if committed file is ignored
+	// Create a WARNING project activity if committed file is ignored, and record the reason in the result.
 	var createIgnoredFileActivity = func(err error) {
 		log.Warn("Ignored committed file",
 			zap.String("file", fileEscaped),
 			zap.Error(err),
 		)
+		result.Status, result.Message = api.PushEventFileIgnored, err.Error()
 		bytes, marshalErr := json.Marshal(
 			api.ActivityProjectRepositoryPushPayload{
 				VCSPushEvent: pushEvent,
@@ -463,42 +867,138 @@ func (s *Server) createIssueFromPushEvent(ctx context.Context, repo *api.Reposit
 		}
 	}
 
-	mi, err := db.ParseMigrationInfo(fileEscaped, filepath.Join(repo.BaseDirectory, repo.FilePathTemplate))
+	// Create the same WARNING project activity if the committed file fails to be processed, e.g. reading the file from
+	// the VCS fails, and record it as an error in the result, so that it's not mistaken for a file not applicable.
+	var createErroredFileActivity = func(err error) {
+		createIgnoredFileActivity(err)
+		result.Status = api.PushEventFileError
+	}
+
+	// The down migration file is read along with its up migration file.
+	if !isSDL {
+		isDown, err := s.isDownMigrationFile(ctx, repo, fileEscaped, pushEvent.FileCommit.ID)
+		if err != nil {
+			createErroredFileActivity(err)
+			return result, nil
+		}
+		if isDown {
+			createIgnoredFileActivity(fmt.Errorf("it's a down migration file, which is read along with its migration file"))
+			return result, nil
+		}
+	}
+
+	var mi *db.MigrationInfo
+	var err error
+	if isSDL {
+		mi, err = db.ParseSchemaFileInfo(fileEscaped, filepath.Join(repo.BaseDirectory, repo.SchemaPathTemplate))
+	} else {
+		mi, err = db.ParseMigrationInfo(fileEscaped, filepath.Join(repo.BaseDirectory, repo.FilePathTemplate))
+	}
 	if err != nil {
 		createIgnoredFileActivity(err)
-		return "", false, nil
+		return result, nil
+	}
+
+	// If the repository maps the branches to the environments, the file only applies to the databases in the environment
+	// mapped by the pushed branch. Since the same file is pushed again when being promoted to the next environment by
+	// merging the branch, the databases having applied the migration version, or having an open issue to apply it, are skipped.
+	skipAppliedVersion := false
+	if repo.Project.TenantMode != api.TenantModeTenant {
+		branch := getBranchFromRef(pushEvent.Ref)
+		environment, err := s.getBranchEnvironment(ctx, repo, branch)
+		if err != nil {
+			if common.ErrorCode(err) == common.NotFound {
+				log.Debug("Ignored committed file, branch isn't mapped to any environment.",
+					zap.String("file", fileEscaped),
+					zap.String("branch", common.EscapeForLogging(branch)),
+				)
+				result.Status, result.Message = api.PushEventFileIgnored, err.Error()
+				return result, nil
+			}
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to find the environment mapped by branch %q", branch)).SetInternal(err)
+		}
+		if environment != nil {
+			// Environment name comparison is case insensitive
+			if mi.Environment != "" && !strings.EqualFold(mi.Environment, environment.Name) {
+				createIgnoredFileActivity(fmt.Errorf("environment %q of the committed file doesn't match environment %q mapped by branch %q", mi.Environment, environment.Name, branch))
+				return result, nil
+			}
+			mi.Environment = environment.Name
+			skipAppliedVersion = mi.Version != ""
+		}
+	}
+
+	// Compare the version of the migration file with the versions applied to the databases, the baseline establishes
+	// a new version instead. The file is rejected if the project requires the versions in order.
+	outOfOrderMessage := ""
+	if repo.Project.MigrationOrder != api.MigrationOrderIgnore && (mi.Type == db.Migrate || mi.Type == db.Data) {
+		message, err := s.checkMigrationVersionOrder(ctx, repo, mi)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to check the order of migration version %s", mi.Version)).SetInternal(err)
+		}
+		if message != "" && repo.Project.MigrationOrder == api.MigrationOrderStrict {
+			createIgnoredFileActivity(fmt.Errorf("%s, the project only accepts the migration files in order", message))
+			return result, nil
+		}
+		outOfOrderMessage = message
 	}
 
 	// Retrieve the latest AccessToken and RefreshToken as the previous
 	// ReadFileContent call may have updated the stored token pair. ReadFileContent
 	// will fetch and store the new token pair if the existing token pair has
 	// expired.
-	repo2, err := s.store.GetRepository(ctx, &api.RepositoryFind{WebhookEndpointID: &webhookEndpointID})
+	repo2, err := s.store.GetRepository(ctx, &api.RepositoryFind{WebhookEndpointID: &repo.WebhookEndpointID})
 	if err != nil {
-		return "", false, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to respond webhook event for endpoint: %v", webhookEndpointID)).SetInternal(err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to respond webhook event for endpoint: %v", repo.WebhookEndpointID)).SetInternal(err)
 	}
 	if repo2 == nil {
-		return "", false, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Webhook endpoint not found: %v", webhookEndpointID))
+		return nil, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Webhook endpoint not found: %v", repo.WebhookEndpointID))
 	}
 
 	// Retrieve migration SQL script by reading the file content
+	oauthContext := common.OauthContext{
+		ClientID:     repo2.VCS.ApplicationID,
+		ClientSecret: repo2.VCS.Secret,
+		AccessToken:  repo2.AccessToken,
+		RefreshToken: repo2.RefreshToken,
+		Refresher:    s.refreshToken(ctx, repo2.ID),
+	}
 	content, err := vcs.Get(repo2.VCS.Type, vcs.ProviderConfig{}).ReadFileContent(
 		ctx,
-		common.OauthContext{
-			ClientID:     repo2.VCS.ApplicationID,
-			ClientSecret: repo2.VCS.Secret,
-			AccessToken:  repo2.AccessToken,
-			RefreshToken: repo2.RefreshToken,
-			Refresher:    s.refreshToken(ctx, repo2.ID),
-		},
+		oauthContext,
 		repo2.VCS.InstanceURL,
 		repo2.ExternalID,
 		fileEscaped,
 		pushEvent.FileCommit.ID,
 	)
 	if err != nil {
-		createIgnoredFileActivity(err)
-		return "", false, nil
+		createErroredFileActivity(err)
+		return result, nil
+	}
+
+	// Retrieve the rollback statement from the down migration file alongside, which is optional.
+	var rollbackStatement string
+	if mi.Type == db.Migrate {
+		downFile := getDownMigrationFile(fileEscaped)
+		downContent, err := vcs.Get(repo2.VCS.Type, vcs.ProviderConfig{}).ReadFileContent(
+			ctx,
+			oauthContext,
+			repo2.VCS.InstanceURL,
+			repo2.ExternalID,
+			downFile,
+			pushEvent.FileCommit.ID,
+		)
+		if err != nil {
+			if common.ErrorCode(err) != common.NotFound {
+				createErroredFileActivity(fmt.Errorf("failed to read the down migration file %q, error: %w", downFile, err))
+				return result, nil
+			}
+			log.Debug("No down migration file found, the rollback statement will be generated if the migration is purely additive.",
+				zap.String("file", downFile),
+			)
+		} else {
+			rollbackStatement = downContent
+		}
 	}
 
 	// Create schema update issue.
@@ -520,18 +1020,35 @@ func (s *Server) createIssueFromPushEvent(ctx context.Context, repo *api.Reposit
 		}
 	}
 
+	// The statement of the declarative schema migration is generated from the desired schema when creating the issue.
+	statement, desiredSchema := content, ""
+	if isSDL {
+		statement, desiredSchema = "", content
+	}
 	var createContext string
 	if repo.Project.TenantMode == api.TenantModeTenant {
 		if !s.feature(api.FeatureMultiTenancy) {
-			return "", false, echo.NewHTTPError(http.StatusForbidden, api.FeatureMultiTenancy.AccessErrorMessage())
+			return nil, echo.NewHTTPError(http.StatusForbidden, api.FeatureMultiTenancy.AccessErrorMessage())
 		}
-		createContext, err = createTenantSchemaUpdateIssue(mi, pushEvent, content)
+		createContext, err = createTenantSchemaUpdateIssue(mi, pushEvent, statement, rollbackStatement, desiredSchema)
 	} else {
-		createContext, err = s.createSchemaUpdateIssue(ctx, repo, mi, pushEvent, fileEscaped, content)
+		createContext, err = s.createSchemaUpdateIssue(ctx, repo, mi, pushEvent, fileEscaped, statement, rollbackStatement, desiredSchema, skipAppliedVersion)
 	}
 	if err != nil {
-		createIgnoredFileActivity(err)
-		return "", false, nil
+		if common.ErrorCode(err) == common.Invalid {
+			createIgnoredFileActivity(err)
+		} else {
+			createErroredFileActivity(err)
+		}
+		return result, nil
+	}
+	if createContext == "" {
+		log.Debug("Ignored committed file, the migration version has been applied or is being applied to all databases.",
+			zap.String("file", fileEscaped),
+			zap.String("version", mi.Version),
+		)
+		result.Status, result.Message = api.PushEventFileIgnored, fmt.Sprintf("version %s has been applied or is being applied to all databases in environment %q", mi.Version, mi.Environment)
+		return result, nil
 	}
 
 	issueType := api.IssueDatabaseSchemaUpdate
@@ -548,11 +1065,16 @@ func (s *Server) createIssueFromPushEvent(ctx context.Context, repo *api.Reposit
 	}
 	issue, err := s.createIssue(ctx, issueCreate, creatorID)
 	if err != nil {
+		// The desired schema may have no difference from the live schema, or fail to be diffed.
+		if httpErr, ok := err.(*echo.HTTPError); ok && isSDL && httpErr.Code == http.StatusBadRequest {
+			createIgnoredFileActivity(fmt.Errorf("%v", httpErr.Message))
+			return result, nil
+		}
 		errMsg := "Failed to create schema update issue"
 		if issueType == api.IssueDatabaseDataUpdate {
 			errMsg = "Failed to create data update issue"
 		}
-		return "", false, echo.NewHTTPError(http.StatusInternalServerError, errMsg).SetInternal(err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, errMsg).SetInternal(err)
 	}
 
 	// Create a project activity after successfully creating the issue as the result of the push event
@@ -564,7 +1086,7 @@ func (s *Server) createIssueFromPushEvent(ctx context.Context, repo *api.Reposit
 		},
 	)
 	if err != nil {
-		return "", false, echo.NewHTTPError(http.StatusInternalServerError, "Failed to construct activity payload").SetInternal(err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to construct activity payload").SetInternal(err)
 	}
 
 	activityCreate := &api.ActivityCreate{
@@ -575,11 +1097,19 @@ func (s *Server) createIssueFromPushEvent(ctx context.Context, repo *api.Reposit
 		Comment:     fmt.Sprintf("Created issue %q.", issue.Name),
 		Payload:     string(bytes),
 	}
+	if outOfOrderMessage != "" {
+		activityCreate.Level = api.ActivityWarn
+		activityCreate.Comment = fmt.Sprintf("Created issue %q, %s.", issue.Name, outOfOrderMessage)
+	}
 	if _, err = s.ActivityManager.CreateActivity(ctx, activityCreate, &ActivityMeta{}); err != nil {
-		return "", false, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to create project activity after creating issue from repository push event: %d", issue.ID)).SetInternal(err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to create project activity after creating issue from repository push event: %d", issue.ID)).SetInternal(err)
 	}
 
-	return fmt.Sprintf("Created issue %q on adding %s", issue.Name, fileEscaped), true, nil
+	result.Status, result.Message, result.IssueID = api.PushEventFileIssueCreated, fmt.Sprintf("Created issue %q on adding %s", issue.Name, fileEscaped), issue.ID
+	if outOfOrderMessage != "" {
+		result.Message = fmt.Sprintf("%s, %s", result.Message, outOfOrderMessage)
+	}
+	return result, nil
 }
 
 // We may write back the latest schema file to the repository after migration and we need to ignore
@@ -607,3 +1137,51 @@ func isSkipGeneratedSchemaFile(repository *api.Repository, added string) bool {
 	}
 	return false
 }
+
+// isDownMigrationFile returns true if the file is the down migration file of an up migration file existing at the ref,
+// e.g. "v1__db1__migrate__add_column_down.sql" alongside "v1__db1__migrate__add_column.sql". A file whose name merely ends
+// with the suffix, e.g. "v1__db1__migrate__scale_down.sql" without "v1__db1__migrate__scale.sql", is an up migration file.
+func (s *Server) isDownMigrationFile(ctx context.Context, repo *api.Repository, file, ref string) (bool, error) {
+	upFile, ok := getUpMigrationFile(file)
+	if !ok {
+		return false, nil
+	}
+	if _, err := vcs.Get(repo.VCS.Type, vcs.ProviderConfig{}).ReadFileContent(
+		ctx,
+		common.OauthContext{
+			ClientID:     repo.VCS.ApplicationID,
+			ClientSecret: repo.VCS.Secret,
+			AccessToken:  repo.AccessToken,
+			RefreshToken: repo.RefreshToken,
+			Refresher:    s.refreshToken(ctx, repo.ID),
+		},
+		repo.VCS.InstanceURL,
+		repo.ExternalID,
+		upFile,
+		ref,
+	); err != nil {
+		if common.ErrorCode(err) == common.NotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read file %q to tell whether %q is its down migration file, error: %w", upFile, file, err)
+	}
+	return true, nil
+}
+
+// getUpMigrationFile returns the up migration file of the file if the file is named as a down migration file,
+// e.g. "v1__db1__migrate__add_column.sql" for "v1__db1__migrate__add_column_down.sql".
+func getUpMigrationFile(file string) (string, bool) {
+	ext := filepath.Ext(file)
+	name := strings.TrimSuffix(file, ext)
+	if !strings.HasSuffix(name, downMigrationFileSuffix) {
+		return "", false
+	}
+	return strings.TrimSuffix(name, downMigrationFileSuffix) + ext, true
+}
+
+// getDownMigrationFile returns the down migration file alongside the migration file,
+// e.g. "v1__db1__migrate__add_column_down.sql" for "v1__db1__migrate__add_column.sql".
+func getDownMigrationFile(file string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + downMigrationFileSuffix + ext
+}
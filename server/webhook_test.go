@@ -317,3 +317,64 @@ func TestValidateGitHubWebhookSignature256(t *testing.T) {
 		assert.True(t, got)
 	})
 }
+
+func TestValidateGiteaWebhookSignature256(t *testing.T) {
+	const payload = `{"ref":"refs/heads/main","repository":{"id":1,"full_name":"alice/shop"},"commits":[{"id":"5a96148a","added":["bytebase/db1__202101131000__migrate__add_column.sql"]}]}`
+
+	t.Run("wrong key", func(t *testing.T) {
+		got := validateGiteaWebhookSignature256(
+			"eba75f58ce5ce5d58aa5007da0450174d126dca6dcad3a26ed85a9b7f2f845d3",
+			"abadkey",
+			[]byte(payload),
+		)
+		assert.False(t, got)
+	})
+
+	t.Run("wrong signature", func(t *testing.T) {
+		got := validateGiteaWebhookSignature256(
+			"07b98af7351bbe2c300d6204da187404005718bf973aeab8130c3fa1b2098dee",
+			"bZovosSKsJ8QKCG9",
+			[]byte(payload),
+		)
+		assert.False(t, got)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		got := validateGiteaWebhookSignature256(
+			"eba75f58ce5ce5d58aa5007da0450174d126dca6dcad3a26ed85a9b7f2f845d3",
+			"bZovosSKsJ8QKCG9",
+			[]byte(payload),
+		)
+		assert.True(t, got)
+	})
+}
+
+func TestDownMigrationFile(t *testing.T) {
+	file := "bytebase/db1__202101131000__migrate__add_column.sql"
+	downFile := getDownMigrationFile(file)
+	assert.Equal(t, "bytebase/db1__202101131000__migrate__add_column_down.sql", downFile)
+	upFile, ok := getUpMigrationFile(downFile)
+	assert.True(t, ok)
+	assert.Equal(t, file, upFile)
+	_, ok = getUpMigrationFile(file)
+	assert.False(t, ok)
+}
+
+func TestMatchBranchFilter(t *testing.T) {
+	tests := []struct {
+		branchFilter string
+		branch       string
+		want         bool
+	}{
+		{"", "main", true},
+		{"main", "main", true},
+		{"main", "dev", false},
+		{"release/*", "release/1.0", true},
+		{"release/*", "release/1.0/hotfix", false},
+		{"[", "main", false},
+	}
+	for _, test := range tests {
+		got := matchBranchFilter(test.branchFilter, test.branch)
+		assert.Equal(t, test.want, got, "branch filter %q, branch %q", test.branchFilter, test.branch)
+	}
+}
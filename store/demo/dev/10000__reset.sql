@@ -12,6 +12,9 @@ UPDATE setting SET creator_id = 1, updater_id = 1 WHERE name = 'bb.enterprise.li
 DELETE FROM
     anomaly;
 
+DELETE FROM
+    push_event_delivery;
+
 DELETE FROM
     repository;
 
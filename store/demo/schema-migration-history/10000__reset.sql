@@ -5,6 +5,9 @@ DELETE FROM
 DELETE FROM
     anomaly;
 
+DELETE FROM
+    push_event_delivery;
+
 DELETE FROM
     repository;
 
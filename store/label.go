@@ -174,6 +174,19 @@ func (s *Store) FindLabelKey(ctx context.Context, find *api.LabelKeyFind) ([]*ap
 	return labelKeyList, nil
 }
 
+// CreateLabelKey creates an instance of LabelKey.
+func (s *Store) CreateLabelKey(ctx context.Context, create *api.LabelKeyCreate) (*api.LabelKey, error) {
+	labelKeyRaw, err := s.createLabelKeyRaw(ctx, create)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LabelKey with LabelKeyCreate[%+v], error: %w", create, err)
+	}
+	labelKey, err := s.composeLabelKey(ctx, labelKeyRaw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compose LabelKey with labelKeyRaw[%+v], error: %w", labelKeyRaw, err)
+	}
+	return labelKey, nil
+}
+
 // PatchLabelKey patches an instance of LabelKey.
 func (s *Store) PatchLabelKey(ctx context.Context, patch *api.LabelKeyPatch) (*api.LabelKey, error) {
 	labelKeyRaw, err := s.patchLabelKeyRaw(ctx, patch)
@@ -346,6 +359,57 @@ func (*Store) findLabelKeyImpl(ctx context.Context, tx *sql.Tx, find *api.LabelK
 	return labelKeyRawList, nil
 }
 
+// createLabelKeyRaw creates a label key with its values.
+func (s *Store) createLabelKeyRaw(ctx context.Context, create *api.LabelKeyCreate) (*labelKeyRaw, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.PTx.Rollback()
+
+	var labelKeyRaw labelKeyRaw
+	if err := tx.PTx.QueryRowContext(ctx, `
+		INSERT INTO label_key (
+			creator_id,
+			updater_id,
+			key
+		)
+		VALUES ($1, $2, $3)
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, key
+	`,
+		create.CreatorID,
+		create.CreatorID,
+		create.Key,
+	).Scan(
+		&labelKeyRaw.ID,
+		&labelKeyRaw.CreatorID,
+		&labelKeyRaw.CreatedTs,
+		&labelKeyRaw.UpdaterID,
+		&labelKeyRaw.UpdatedTs,
+		&labelKeyRaw.Key,
+	); err != nil {
+		return nil, FormatError(err)
+	}
+
+	for _, v := range create.ValueList {
+		if err := s.upsertLabelValueImpl(ctx, tx.PTx, labelValueUpsert{
+			rowStatus: api.Normal,
+			updaterID: create.CreatorID,
+			key:       create.Key,
+			value:     v,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.PTx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	labelKeyRaw.ValueList = create.ValueList
+	return &labelKeyRaw, nil
+}
+
 type labelValueUpsert struct {
 	rowStatus api.RowStatus
 	updaterID int
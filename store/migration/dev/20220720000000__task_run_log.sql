@@ -0,0 +1,12 @@
+-- task run log table stores the structured log events of a task run, which are streamed to the client while running and replayed afterwards.
+CREATE TABLE task_run_log (
+    id SERIAL PRIMARY KEY,
+    created_ts BIGINT NOT NULL DEFAULT extract(epoch from now()),
+    task_run_id INTEGER NOT NULL REFERENCES task_run (id),
+    type TEXT NOT NULL CHECK (type LIKE 'bb.task-run-log.%'),
+    payload JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_task_run_log_task_run_id ON task_run_log(task_run_id);
+
+ALTER SEQUENCE task_run_log_id_seq RESTART WITH 101;
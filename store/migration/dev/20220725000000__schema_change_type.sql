@@ -0,0 +1,2 @@
+-- schema_change_type is either DDL (imperative migration statements) or SDL (declarative desired schema).
+ALTER TABLE project ADD schema_change_type TEXT NOT NULL CHECK (schema_change_type IN ('DDL', 'SDL')) DEFAULT 'DDL';
@@ -0,0 +1,24 @@
+-- push_event_delivery table stores the VCS push events received by the project along with the outcome of each added file, so that a failed delivery can be replayed.
+CREATE TABLE push_event_delivery (
+    id SERIAL PRIMARY KEY,
+    creator_id INTEGER NOT NULL REFERENCES principal (id),
+    created_ts BIGINT NOT NULL DEFAULT extract(epoch from now()),
+    updater_id INTEGER NOT NULL REFERENCES principal (id),
+    updated_ts BIGINT NOT NULL DEFAULT extract(epoch from now()),
+    project_id INTEGER NOT NULL REFERENCES project (id),
+    -- Not a foreign key as the repository record is deleted when the project is unlinked from the VCS repository.
+    repository_id INTEGER NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('DONE', 'FAILED')),
+    raw_payload TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_push_event_delivery_project_id ON push_event_delivery(project_id);
+
+ALTER SEQUENCE push_event_delivery_id_seq RESTART WITH 101;
+
+CREATE TRIGGER update_push_event_delivery_updated_ts
+BEFORE
+UPDATE
+    ON push_event_delivery FOR EACH ROW
+EXECUTE FUNCTION trigger_update_updated_ts();
@@ -0,0 +1,2 @@
+-- branch_environment_list maps the branches to the environments, so that a push to a branch only creates issues for the databases in the environment.
+ALTER TABLE repository ADD branch_environment_list JSONB NOT NULL DEFAULT '[]';
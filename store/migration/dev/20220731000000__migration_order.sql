@@ -0,0 +1,2 @@
+-- migration_order is how the versions of the migration files pushed to the VCS are ordered against the versions applied to the databases.
+ALTER TABLE project ADD migration_order TEXT NOT NULL CHECK (migration_order IN ('STRICT', 'ALLOW_OUT_OF_ORDER', 'IGNORE')) DEFAULT 'IGNORE';
@@ -0,0 +1,2 @@
+-- task_retry_policy is the task retry policy in json format overriding the one of the environments, empty means the policy of the environment applies.
+ALTER TABLE project ADD task_retry_policy TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE push_event_delivery DROP CONSTRAINT push_event_delivery_status_check;
+ALTER TABLE push_event_delivery ADD CONSTRAINT push_event_delivery_status_check CHECK (status IN ('RUNNING', 'DONE', 'FAILED'));
@@ -168,8 +168,14 @@ CREATE TABLE project (
     -- db_name_template is only used when a project is in tenant mode.
     -- Empty value means {{DB_NAME}}.
     db_name_template TEXT NOT NULL,
-    role_provider TEXT NOT NULL CHECK (role_provider IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM')) DEFAULT 'BYTEBASE',
-    schema_version_type TEXT NOT NULL CHECK (schema_version_type IN ('TIMESTAMP', 'SEMANTIC')) DEFAULT 'TIMESTAMP'
+    role_provider TEXT NOT NULL CHECK (role_provider IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM', 'GITHUB_ENTERPRISE', 'GITEA', 'BITBUCKET')) DEFAULT 'BYTEBASE',
+    schema_version_type TEXT NOT NULL CHECK (schema_version_type IN ('TIMESTAMP', 'SEMANTIC')) DEFAULT 'TIMESTAMP',
+    -- schema_change_type is either DDL (imperative migration statements) or SDL (declarative desired schema).
+    schema_change_type TEXT NOT NULL CHECK (schema_change_type IN ('DDL', 'SDL')) DEFAULT 'DDL',
+    -- migration_order is how the versions of the migration files pushed to the VCS are ordered against the versions applied to the databases.
+    migration_order TEXT NOT NULL CHECK (migration_order IN ('STRICT', 'ALLOW_OUT_OF_ORDER', 'IGNORE')) DEFAULT 'IGNORE',
+    -- task_retry_policy is the task retry policy in json format overriding the one of the environments, empty means the policy of the environment applies.
+    task_retry_policy TEXT NOT NULL DEFAULT ''
 );
 
 CREATE UNIQUE INDEX idx_project_unique_key ON project(key);
@@ -218,7 +224,7 @@ CREATE TABLE project_member (
     project_id INTEGER NOT NULL REFERENCES project (id),
     role TEXT NOT NULL CHECK (role IN ('OWNER', 'DEVELOPER')),
     principal_id INTEGER NOT NULL REFERENCES principal (id),
-    role_provider TEXT NOT NULL CHECK (role_provider IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM')) DEFAULT 'BYTEBASE',
+    role_provider TEXT NOT NULL CHECK (role_provider IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM', 'GITHUB_ENTERPRISE', 'GITEA', 'BITBUCKET')) DEFAULT 'BYTEBASE',
     -- payload is determined by the type of role_provider
     payload JSONB NOT NULL DEFAULT '{}'
 );
@@ -722,6 +728,19 @@ UPDATE
     ON task_run FOR EACH ROW
 EXECUTE FUNCTION trigger_update_updated_ts();
 
+-- task run log table stores the structured log events of a task run, which are streamed to the client while running and replayed afterwards.
+CREATE TABLE task_run_log (
+    id SERIAL PRIMARY KEY,
+    created_ts BIGINT NOT NULL DEFAULT extract(epoch from now()),
+    task_run_id INTEGER NOT NULL REFERENCES task_run (id),
+    type TEXT NOT NULL CHECK (type LIKE 'bb.task-run-log.%'),
+    payload JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_task_run_log_task_run_id ON task_run_log(task_run_id);
+
+ALTER SEQUENCE task_run_log_id_seq RESTART WITH 101;
+
 -- task check run table stores the task check run
 CREATE TABLE task_check_run (
     id SERIAL PRIMARY KEY,
@@ -876,7 +895,7 @@ CREATE TABLE vcs (
     updater_id INTEGER NOT NULL REFERENCES principal (id),
     updated_ts BIGINT NOT NULL DEFAULT extract(epoch from now()),
     name TEXT NOT NULL,
-    type TEXT NOT NULL CHECK (type IN ('GITLAB_SELF_HOST', 'GITHUB_COM')),
+    type TEXT NOT NULL CHECK (type IN ('GITLAB_SELF_HOST', 'GITHUB_COM', 'GITHUB_ENTERPRISE', 'GITEA', 'BITBUCKET')),
     instance_url TEXT NOT NULL CHECK ((instance_url LIKE 'http://%' OR instance_url LIKE 'https://%') AND instance_url = rtrim(instance_url, '/')),
     api_url TEXT NOT NULL CHECK ((api_url LIKE 'http://%' OR api_url LIKE 'https://%') AND api_url = rtrim(api_url, '/')),
     application_id TEXT NOT NULL,
@@ -915,6 +934,8 @@ CREATE TABLE repository (
     -- Branch we are interested.
     -- For GitLab, this corresponds to webhook's push_events_branch_filter. Wildcard is supported
     branch_filter TEXT NOT NULL DEFAULT '',
+    -- The JSON encoded list of the branch filter to environment mapping. If not empty, a push to a branch only creates issues for the databases in the mapped environment.
+    branch_environment_list JSONB NOT NULL DEFAULT '[]',
     -- Base working directory we are interested.
     base_directory TEXT NOT NULL DEFAULT '',
     -- The file path template for matching the commited migration script.
@@ -954,6 +975,31 @@ UPDATE
     ON repository FOR EACH ROW
 EXECUTE FUNCTION trigger_update_updated_ts();
 
+-- push_event_delivery table stores the VCS push events received by the project along with the outcome of each added file, so that a failed delivery can be replayed.
+CREATE TABLE push_event_delivery (
+    id SERIAL PRIMARY KEY,
+    creator_id INTEGER NOT NULL REFERENCES principal (id),
+    created_ts BIGINT NOT NULL DEFAULT extract(epoch from now()),
+    updater_id INTEGER NOT NULL REFERENCES principal (id),
+    updated_ts BIGINT NOT NULL DEFAULT extract(epoch from now()),
+    project_id INTEGER NOT NULL REFERENCES project (id),
+    -- Not a foreign key as the repository record is deleted when the project is unlinked from the VCS repository.
+    repository_id INTEGER NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('RUNNING', 'DONE', 'FAILED')),
+    raw_payload TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_push_event_delivery_project_id ON push_event_delivery(project_id);
+
+ALTER SEQUENCE push_event_delivery_id_seq RESTART WITH 101;
+
+CREATE TRIGGER update_push_event_delivery_updated_ts
+BEFORE
+UPDATE
+    ON push_event_delivery FOR EACH ROW
+EXECUTE FUNCTION trigger_update_updated_ts();
+
 -- Anomaly
 -- anomaly stores various anomalies found by the scanner.
 -- For now, anomaly can be associated with a particular instance or database.
@@ -1090,7 +1136,7 @@ CREATE TABLE sheet (
     name TEXT NOT NULL,
     statement TEXT NOT NULL,
     visibility TEXT NOT NULL CHECK (visibility IN ('PRIVATE', 'PROJECT', 'PUBLIC')) DEFAULT 'PRIVATE',
-    source TEXT NOT NULL CHECK (source IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM')) DEFAULT 'BYTEBASE',
+    source TEXT NOT NULL CHECK (source IN ('BYTEBASE', 'GITLAB_SELF_HOST', 'GITHUB_COM', 'GITHUB_ENTERPRISE', 'GITEA', 'BITBUCKET')) DEFAULT 'BYTEBASE',
     type TEXT NOT NULL CHECK (type IN ('SQL')) DEFAULT 'SQL',
     payload JSONB NOT NULL DEFAULT '{}'
 );
@@ -177,6 +177,19 @@ func (s *Store) GetPipelineApprovalPolicy(ctx context.Context, environmentID int
 	return api.UnmarshalPipelineApprovalPolicy(policy.Payload)
 }
 
+// GetTaskRetryPolicy will get the task retry policy for an environment.
+func (s *Store) GetTaskRetryPolicy(ctx context.Context, environmentID int) (*api.TaskRetryPolicy, error) {
+	pType := api.PolicyTypeTaskRetry
+	policy, err := s.getPolicyRaw(ctx, &api.PolicyFind{
+		EnvironmentID: &environmentID,
+		Type:          &pType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return api.UnmarshalTaskRetryPolicy(policy.Payload)
+}
+
 // GetNormalSchemaReviewPolicy will get the normal schema review policy for an environment.
 func (s *Store) GetNormalSchemaReviewPolicy(ctx context.Context, find *api.PolicyFind) (*advisor.SQLReviewPolicy, error) {
 	if find.ID != nil && *find.ID == api.DefaultPolicyID {
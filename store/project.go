@@ -24,13 +24,16 @@ type projectRaw struct {
 	UpdatedTs int64
 
 	// Domain specific fields
-	Name           string
-	Key            string
-	WorkflowType   api.ProjectWorkflowType
-	Visibility     api.ProjectVisibility
-	TenantMode     api.ProjectTenantMode
-	DBNameTemplate string
-	RoleProvider   api.ProjectRoleProvider
+	Name             string
+	Key              string
+	WorkflowType     api.ProjectWorkflowType
+	Visibility       api.ProjectVisibility
+	TenantMode       api.ProjectTenantMode
+	DBNameTemplate   string
+	RoleProvider     api.ProjectRoleProvider
+	SchemaChangeType api.ProjectSchemaChangeType
+	MigrationOrder   api.ProjectMigrationOrder
+	TaskRetryPolicy  string
 }
 
 // toProject creates an instance of Project based on the projectRaw.
@@ -45,13 +48,16 @@ func (raw *projectRaw) toProject() *api.Project {
 		UpdaterID: raw.UpdaterID,
 		UpdatedTs: raw.UpdatedTs,
 
-		Name:           raw.Name,
-		Key:            raw.Key,
-		WorkflowType:   raw.WorkflowType,
-		Visibility:     raw.Visibility,
-		TenantMode:     raw.TenantMode,
-		DBNameTemplate: raw.DBNameTemplate,
-		RoleProvider:   raw.RoleProvider,
+		Name:             raw.Name,
+		Key:              raw.Key,
+		WorkflowType:     raw.WorkflowType,
+		Visibility:       raw.Visibility,
+		TenantMode:       raw.TenantMode,
+		DBNameTemplate:   raw.DBNameTemplate,
+		RoleProvider:     raw.RoleProvider,
+		SchemaChangeType: raw.SchemaChangeType,
+		MigrationOrder:   raw.MigrationOrder,
+		TaskRetryPolicy:  raw.TaskRetryPolicy,
 	}
 }
 
@@ -308,6 +314,12 @@ func createProjectImpl(ctx context.Context, tx *sql.Tx, create *api.ProjectCreat
 	if create.RoleProvider == "" {
 		create.RoleProvider = api.ProjectRoleProviderBytebase
 	}
+	if create.SchemaChangeType == "" {
+		create.SchemaChangeType = api.ProjectSchemaChangeTypeDDL
+	}
+	if create.MigrationOrder == "" {
+		create.MigrationOrder = api.MigrationOrderIgnore
+	}
 	query := `
 		INSERT INTO project (
 			creator_id,
@@ -318,10 +330,12 @@ func createProjectImpl(ctx context.Context, tx *sql.Tx, create *api.ProjectCreat
 			visibility,
 			tenant_mode,
 			db_name_template,
-			role_provider
+			role_provider,
+			schema_change_type,
+			migration_order
 		)
-		VALUES ($1, $2, $3, $4, 'UI', 'PUBLIC', $5, $6, $7)
-		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, name, key, workflow_type, visibility, tenant_mode, db_name_template, role_provider
+		VALUES ($1, $2, $3, $4, 'UI', 'PUBLIC', $5, $6, $7, $8, $9)
+		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, name, key, workflow_type, visibility, tenant_mode, db_name_template, role_provider, schema_change_type, migration_order, task_retry_policy
 	`
 	var project projectRaw
 	if err := tx.QueryRowContext(ctx, query,
@@ -332,6 +346,8 @@ func createProjectImpl(ctx context.Context, tx *sql.Tx, create *api.ProjectCreat
 		create.TenantMode,
 		create.DBNameTemplate,
 		create.RoleProvider,
+		create.SchemaChangeType,
+		create.MigrationOrder,
 	).Scan(
 		&project.ID,
 		&project.RowStatus,
@@ -346,6 +362,9 @@ func createProjectImpl(ctx context.Context, tx *sql.Tx, create *api.ProjectCreat
 		&project.TenantMode,
 		&project.DBNameTemplate,
 		&project.RoleProvider,
+		&project.SchemaChangeType,
+		&project.MigrationOrder,
+		&project.TaskRetryPolicy,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, common.FormatDBErrorEmptyRowWithQuery(query)
@@ -382,7 +401,10 @@ func findProjectImpl(ctx context.Context, tx *sql.Tx, find *api.ProjectFind) ([]
 			visibility,
 			tenant_mode,
 			db_name_template,
-			role_provider
+			role_provider,
+			schema_change_type,
+			migration_order,
+			task_retry_policy
 		FROM project
 		WHERE `+strings.Join(where, " AND "),
 		args...,
@@ -410,6 +432,9 @@ func findProjectImpl(ctx context.Context, tx *sql.Tx, find *api.ProjectFind) ([]
 			&project.TenantMode,
 			&project.DBNameTemplate,
 			&project.RoleProvider,
+			&project.SchemaChangeType,
+			&project.MigrationOrder,
+			&project.TaskRetryPolicy,
 		); err != nil {
 			return nil, FormatError(err)
 		}
@@ -442,6 +467,15 @@ func patchProjectImpl(ctx context.Context, tx *sql.Tx, patch *api.ProjectPatch)
 	if v := patch.RoleProvider; v != nil {
 		set, args = append(set, fmt.Sprintf("role_provider = $%d", len(args)+1)), append(args, *v)
 	}
+	if v := patch.SchemaChangeType; v != nil {
+		set, args = append(set, fmt.Sprintf("schema_change_type = $%d", len(args)+1)), append(args, *v)
+	}
+	if v := patch.MigrationOrder; v != nil {
+		set, args = append(set, fmt.Sprintf("migration_order = $%d", len(args)+1)), append(args, *v)
+	}
+	if v := patch.TaskRetryPolicy; v != nil {
+		set, args = append(set, fmt.Sprintf("task_retry_policy = $%d", len(args)+1)), append(args, *v)
+	}
 
 	args = append(args, patch.ID)
 
@@ -451,7 +485,7 @@ func patchProjectImpl(ctx context.Context, tx *sql.Tx, patch *api.ProjectPatch)
 		UPDATE project
 		SET `+strings.Join(set, ", ")+`
 		WHERE id = $%d
-		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, name, key, workflow_type, visibility, tenant_mode, db_name_template, role_provider
+		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, name, key, workflow_type, visibility, tenant_mode, db_name_template, role_provider, schema_change_type, migration_order, task_retry_policy
 	`, len(args)),
 		args...,
 	).Scan(
@@ -468,6 +502,9 @@ func patchProjectImpl(ctx context.Context, tx *sql.Tx, patch *api.ProjectPatch)
 		&project.TenantMode,
 		&project.DBNameTemplate,
 		&project.RoleProvider,
+		&project.SchemaChangeType,
+		&project.MigrationOrder,
+		&project.TaskRetryPolicy,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, &common.Error{Code: common.NotFound, Err: fmt.Errorf("project ID not found: %d", patch.ID)}
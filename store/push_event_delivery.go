@@ -0,0 +1,346 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+)
+
+// pushEventDeliveryRaw is the store model for a PushEventDelivery.
+// Fields have exactly the same meanings as PushEventDelivery.
+type pushEventDeliveryRaw struct {
+	ID int
+
+	// Standard fields
+	CreatorID int
+	CreatedTs int64
+	UpdaterID int
+	UpdatedTs int64
+
+	// Related fields
+	ProjectID    int
+	RepositoryID int
+
+	// Domain specific fields
+	Status     api.PushEventDeliveryStatus
+	RawPayload string
+	Payload    string
+}
+
+// toPushEventDelivery creates an instance of PushEventDelivery based on the pushEventDeliveryRaw.
+// This is intended to be called when we need to compose a PushEventDelivery relationship.
+func (raw *pushEventDeliveryRaw) toPushEventDelivery() *api.PushEventDelivery {
+	return &api.PushEventDelivery{
+		ID: raw.ID,
+
+		// Standard fields
+		CreatorID: raw.CreatorID,
+		CreatedTs: raw.CreatedTs,
+		UpdaterID: raw.UpdaterID,
+		UpdatedTs: raw.UpdatedTs,
+
+		// Related fields
+		ProjectID:    raw.ProjectID,
+		RepositoryID: raw.RepositoryID,
+
+		// Domain specific fields
+		Status:     raw.Status,
+		RawPayload: raw.RawPayload,
+		Payload:    raw.Payload,
+	}
+}
+
+// CreatePushEventDelivery creates an instance of PushEventDelivery.
+func (s *Store) CreatePushEventDelivery(ctx context.Context, create *api.PushEventDeliveryCreate) (*api.PushEventDelivery, error) {
+	pushEventDeliveryRaw, err := s.createPushEventDeliveryRaw(ctx, create)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PushEventDelivery with PushEventDeliveryCreate[%+v], error: %w", create, err)
+	}
+	pushEventDelivery, err := s.composePushEventDelivery(ctx, pushEventDeliveryRaw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compose PushEventDelivery with pushEventDeliveryRaw[%+v], error: %w", pushEventDeliveryRaw, err)
+	}
+	return pushEventDelivery, nil
+}
+
+// GetPushEventDeliveryByID gets an instance of PushEventDelivery.
+func (s *Store) GetPushEventDeliveryByID(ctx context.Context, id int) (*api.PushEventDelivery, error) {
+	find := &api.PushEventDeliveryFind{ID: &id}
+	pushEventDeliveryRawList, err := s.findPushEventDeliveryRaw(ctx, find)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PushEventDelivery with ID %d, error: %w", id, err)
+	}
+	if len(pushEventDeliveryRawList) == 0 {
+		return nil, nil
+	} else if len(pushEventDeliveryRawList) > 1 {
+		return nil, &common.Error{Code: common.Conflict, Err: fmt.Errorf("found %d push event deliveries with filter %+v, expect 1", len(pushEventDeliveryRawList), find)}
+	}
+	pushEventDelivery, err := s.composePushEventDelivery(ctx, pushEventDeliveryRawList[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to compose PushEventDelivery with pushEventDeliveryRaw[%+v], error: %w", pushEventDeliveryRawList[0], err)
+	}
+	return pushEventDelivery, nil
+}
+
+// FindPushEventDelivery finds a list of PushEventDelivery instances, the latest first.
+func (s *Store) FindPushEventDelivery(ctx context.Context, find *api.PushEventDeliveryFind) ([]*api.PushEventDelivery, error) {
+	pushEventDeliveryRawList, err := s.findPushEventDeliveryRaw(ctx, find)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find PushEventDelivery list with PushEventDeliveryFind[%+v], error: %w", find, err)
+	}
+	var pushEventDeliveryList []*api.PushEventDelivery
+	for _, raw := range pushEventDeliveryRawList {
+		pushEventDelivery, err := s.composePushEventDelivery(ctx, raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compose PushEventDelivery with pushEventDeliveryRaw[%+v], error: %w", raw, err)
+		}
+		pushEventDeliveryList = append(pushEventDeliveryList, pushEventDelivery)
+	}
+	return pushEventDeliveryList, nil
+}
+
+// PatchPushEventDelivery patches an instance of PushEventDelivery.
+func (s *Store) PatchPushEventDelivery(ctx context.Context, patch *api.PushEventDeliveryPatch) (*api.PushEventDelivery, error) {
+	pushEventDeliveryRaw, err := s.patchPushEventDeliveryRaw(ctx, patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch PushEventDelivery with PushEventDeliveryPatch[%+v], error: %w", patch, err)
+	}
+	pushEventDelivery, err := s.composePushEventDelivery(ctx, pushEventDeliveryRaw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compose PushEventDelivery with pushEventDeliveryRaw[%+v], error: %w", pushEventDeliveryRaw, err)
+	}
+	return pushEventDelivery, nil
+}
+
+//
+// private functions
+//
+
+func (s *Store) composePushEventDelivery(ctx context.Context, raw *pushEventDeliveryRaw) (*api.PushEventDelivery, error) {
+	pushEventDelivery := raw.toPushEventDelivery()
+
+	creator, err := s.GetPrincipalByID(ctx, pushEventDelivery.CreatorID)
+	if err != nil {
+		return nil, err
+	}
+	pushEventDelivery.Creator = creator
+
+	updater, err := s.GetPrincipalByID(ctx, pushEventDelivery.UpdaterID)
+	if err != nil {
+		return nil, err
+	}
+	pushEventDelivery.Updater = updater
+
+	return pushEventDelivery, nil
+}
+
+func (s *Store) createPushEventDeliveryRaw(ctx context.Context, create *api.PushEventDeliveryCreate) (*pushEventDeliveryRaw, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.PTx.Rollback()
+
+	pushEventDelivery, err := createPushEventDeliveryImpl(ctx, tx.PTx, create)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.PTx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return pushEventDelivery, nil
+}
+
+func (s *Store) findPushEventDeliveryRaw(ctx context.Context, find *api.PushEventDeliveryFind) ([]*pushEventDeliveryRaw, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.PTx.Rollback()
+
+	list, err := findPushEventDeliveryImpl(ctx, tx.PTx, find)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (s *Store) patchPushEventDeliveryRaw(ctx context.Context, patch *api.PushEventDeliveryPatch) (*pushEventDeliveryRaw, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.PTx.Rollback()
+
+	pushEventDelivery, err := patchPushEventDeliveryImpl(ctx, tx.PTx, patch)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+
+	if err := tx.PTx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return pushEventDelivery, nil
+}
+
+func createPushEventDeliveryImpl(ctx context.Context, tx *sql.Tx, create *api.PushEventDeliveryCreate) (*pushEventDeliveryRaw, error) {
+	query := `
+		INSERT INTO push_event_delivery (
+			creator_id,
+			updater_id,
+			project_id,
+			repository_id,
+			status,
+			raw_payload,
+			payload
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, project_id, repository_id, status, raw_payload, payload
+	`
+	var pushEventDeliveryRaw pushEventDeliveryRaw
+	if err := tx.QueryRowContext(ctx, query,
+		create.CreatorID,
+		create.CreatorID,
+		create.ProjectID,
+		create.RepositoryID,
+		create.Status,
+		create.RawPayload,
+		create.Payload,
+	).Scan(
+		&pushEventDeliveryRaw.ID,
+		&pushEventDeliveryRaw.CreatorID,
+		&pushEventDeliveryRaw.CreatedTs,
+		&pushEventDeliveryRaw.UpdaterID,
+		&pushEventDeliveryRaw.UpdatedTs,
+		&pushEventDeliveryRaw.ProjectID,
+		&pushEventDeliveryRaw.RepositoryID,
+		&pushEventDeliveryRaw.Status,
+		&pushEventDeliveryRaw.RawPayload,
+		&pushEventDeliveryRaw.Payload,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, common.FormatDBErrorEmptyRowWithQuery(query)
+		}
+		return nil, FormatError(err)
+	}
+	return &pushEventDeliveryRaw, nil
+}
+
+func findPushEventDeliveryImpl(ctx context.Context, tx *sql.Tx, find *api.PushEventDeliveryFind) ([]*pushEventDeliveryRaw, error) {
+	// Build WHERE clause.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := find.ID; v != nil {
+		where, args = append(where, fmt.Sprintf("id = $%d", len(args)+1)), append(args, *v)
+	}
+	if v := find.ProjectID; v != nil {
+		where, args = append(where, fmt.Sprintf("project_id = $%d", len(args)+1)), append(args, *v)
+	}
+
+	query := `
+		SELECT
+			id,
+			creator_id,
+			created_ts,
+			updater_id,
+			updated_ts,
+			project_id,
+			repository_id,
+			status,
+			raw_payload,
+			payload
+		FROM push_event_delivery
+		WHERE ` + strings.Join(where, " AND ") + ` ORDER BY id DESC`
+	if v := find.Limit; v != nil {
+		query += fmt.Sprintf(" LIMIT %d", *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over result set and deserialize rows into pushEventDeliveryRawList.
+	var pushEventDeliveryRawList []*pushEventDeliveryRaw
+	for rows.Next() {
+		var pushEventDelivery pushEventDeliveryRaw
+		if err := rows.Scan(
+			&pushEventDelivery.ID,
+			&pushEventDelivery.CreatorID,
+			&pushEventDelivery.CreatedTs,
+			&pushEventDelivery.UpdaterID,
+			&pushEventDelivery.UpdatedTs,
+			&pushEventDelivery.ProjectID,
+			&pushEventDelivery.RepositoryID,
+			&pushEventDelivery.Status,
+			&pushEventDelivery.RawPayload,
+			&pushEventDelivery.Payload,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+
+		pushEventDeliveryRawList = append(pushEventDeliveryRawList, &pushEventDelivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return pushEventDeliveryRawList, nil
+}
+
+func patchPushEventDeliveryImpl(ctx context.Context, tx *sql.Tx, patch *api.PushEventDeliveryPatch) (*pushEventDeliveryRaw, error) {
+	// Build UPDATE clause.
+	set, args := []string{"updater_id = $1"}, []interface{}{patch.UpdaterID}
+	if v := patch.Status; v != nil {
+		set, args = append(set, fmt.Sprintf("status = $%d", len(args)+1)), append(args, *v)
+	}
+	if v := patch.Payload; v != nil {
+		set, args = append(set, fmt.Sprintf("payload = $%d", len(args)+1)), append(args, *v)
+	}
+
+	// Build WHERE clause.
+	where := []string{fmt.Sprintf("id = $%d", len(args)+1)}
+	args = append(args, patch.ID)
+	if v := patch.ExpectedStatus; v != nil {
+		where, args = append(where, fmt.Sprintf("status = $%d", len(args)+1)), append(args, *v)
+	}
+
+	var pushEventDeliveryRaw pushEventDeliveryRaw
+	// Execute update query with RETURNING.
+	if err := tx.QueryRowContext(ctx, `
+		UPDATE push_event_delivery
+		SET `+strings.Join(set, ", ")+`
+		WHERE `+strings.Join(where, " AND ")+`
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, project_id, repository_id, status, raw_payload, payload
+	`,
+		args...,
+	).Scan(
+		&pushEventDeliveryRaw.ID,
+		&pushEventDeliveryRaw.CreatorID,
+		&pushEventDeliveryRaw.CreatedTs,
+		&pushEventDeliveryRaw.UpdaterID,
+		&pushEventDeliveryRaw.UpdatedTs,
+		&pushEventDeliveryRaw.ProjectID,
+		&pushEventDeliveryRaw.RepositoryID,
+		&pushEventDeliveryRaw.Status,
+		&pushEventDeliveryRaw.RawPayload,
+		&pushEventDeliveryRaw.Payload,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			if v := patch.ExpectedStatus; v != nil {
+				return nil, &common.Error{Code: common.Conflict, Err: fmt.Errorf("push event delivery ID %d not found or not in status %s", patch.ID, *v)}
+			}
+			return nil, &common.Error{Code: common.NotFound, Err: fmt.Errorf("push event delivery ID not found: %d", patch.ID)}
+		}
+		return nil, FormatError(err)
+	}
+	return &pushEventDeliveryRaw, nil
+}
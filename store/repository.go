@@ -26,22 +26,23 @@ type repositoryRaw struct {
 	ProjectID int
 
 	// Domain specific fields
-	Name               string
-	FullPath           string
-	WebURL             string
-	BranchFilter       string
-	BaseDirectory      string
-	FilePathTemplate   string
-	SchemaPathTemplate string
-	SheetPathTemplate  string
-	ExternalID         string
-	ExternalWebhookID  string
-	WebhookURLHost     string
-	WebhookEndpointID  string
-	WebhookSecretToken string
-	AccessToken        string
-	ExpiresTs          int64
-	RefreshToken       string
+	Name                  string
+	FullPath              string
+	WebURL                string
+	BranchFilter          string
+	BranchEnvironmentList string
+	BaseDirectory         string
+	FilePathTemplate      string
+	SchemaPathTemplate    string
+	SheetPathTemplate     string
+	ExternalID            string
+	ExternalWebhookID     string
+	WebhookURLHost        string
+	WebhookEndpointID     string
+	WebhookSecretToken    string
+	AccessToken           string
+	ExpiresTs             int64
+	RefreshToken          string
 }
 
 // toRepository creates an instance of Repository based on the repositoryRaw.
@@ -58,22 +59,23 @@ func (raw *repositoryRaw) toRepository() *api.Repository {
 		VCSID:     raw.VCSID,
 		ProjectID: raw.ProjectID,
 
-		Name:               raw.Name,
-		FullPath:           raw.FullPath,
-		WebURL:             raw.WebURL,
-		BranchFilter:       raw.BranchFilter,
-		BaseDirectory:      raw.BaseDirectory,
-		FilePathTemplate:   raw.FilePathTemplate,
-		SchemaPathTemplate: raw.SchemaPathTemplate,
-		SheetPathTemplate:  raw.SheetPathTemplate,
-		ExternalID:         raw.ExternalID,
-		ExternalWebhookID:  raw.ExternalWebhookID,
-		WebhookURLHost:     raw.WebhookURLHost,
-		WebhookEndpointID:  raw.WebhookEndpointID,
-		WebhookSecretToken: raw.WebhookSecretToken,
-		AccessToken:        raw.AccessToken,
-		ExpiresTs:          raw.ExpiresTs,
-		RefreshToken:       raw.RefreshToken,
+		Name:                  raw.Name,
+		FullPath:              raw.FullPath,
+		WebURL:                raw.WebURL,
+		BranchFilter:          raw.BranchFilter,
+		BranchEnvironmentList: raw.BranchEnvironmentList,
+		BaseDirectory:         raw.BaseDirectory,
+		FilePathTemplate:      raw.FilePathTemplate,
+		SchemaPathTemplate:    raw.SchemaPathTemplate,
+		SheetPathTemplate:     raw.SheetPathTemplate,
+		ExternalID:            raw.ExternalID,
+		ExternalWebhookID:     raw.ExternalWebhookID,
+		WebhookURLHost:        raw.WebhookURLHost,
+		WebhookEndpointID:     raw.WebhookEndpointID,
+		WebhookSecretToken:    raw.WebhookSecretToken,
+		AccessToken:           raw.AccessToken,
+		ExpiresTs:             raw.ExpiresTs,
+		RefreshToken:          raw.RefreshToken,
 	}
 }
 
@@ -298,6 +300,7 @@ func (s *Store) createRepositoryImpl(ctx context.Context, tx *sql.Tx, create *ap
 				full_path,
 				web_url,
 				branch_filter,
+				branch_environment_list,
 				base_directory,
 				file_path_template,
 				schema_path_template,
@@ -311,8 +314,8 @@ func (s *Store) createRepositoryImpl(ctx context.Context, tx *sql.Tx, create *ap
 				expires_ts,
 				refresh_token
 			)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
-			RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, branch_filter, base_directory, file_path_template, schema_path_template, sheet_path_template, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+			RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, branch_filter, branch_environment_list, base_directory, file_path_template, schema_path_template, sheet_path_template, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
 		`
 		if err := tx.QueryRowContext(ctx, query,
 			create.CreatorID,
@@ -323,6 +326,7 @@ func (s *Store) createRepositoryImpl(ctx context.Context, tx *sql.Tx, create *ap
 			create.FullPath,
 			create.WebURL,
 			create.BranchFilter,
+			create.BranchEnvironmentList,
 			create.BaseDirectory,
 			create.FilePathTemplate,
 			create.SchemaPathTemplate,
@@ -347,6 +351,7 @@ func (s *Store) createRepositoryImpl(ctx context.Context, tx *sql.Tx, create *ap
 			&repository.FullPath,
 			&repository.WebURL,
 			&repository.BranchFilter,
+			&repository.BranchEnvironmentList,
 			&repository.BaseDirectory,
 			&repository.FilePathTemplate,
 			&repository.SchemaPathTemplate,
@@ -377,6 +382,7 @@ func (s *Store) createRepositoryImpl(ctx context.Context, tx *sql.Tx, create *ap
 			full_path,
 			web_url,
 			branch_filter,
+			branch_environment_list,
 			base_directory,
 			file_path_template,
 			schema_path_template,
@@ -389,8 +395,8 @@ func (s *Store) createRepositoryImpl(ctx context.Context, tx *sql.Tx, create *ap
 			expires_ts,
 			refresh_token
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, branch_filter, base_directory, file_path_template, schema_path_template, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, branch_filter, branch_environment_list, base_directory, file_path_template, schema_path_template, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
 	`
 	if err := tx.QueryRowContext(ctx, query,
 		create.CreatorID,
@@ -401,6 +407,7 @@ func (s *Store) createRepositoryImpl(ctx context.Context, tx *sql.Tx, create *ap
 		create.FullPath,
 		create.WebURL,
 		create.BranchFilter,
+		create.BranchEnvironmentList,
 		create.BaseDirectory,
 		create.FilePathTemplate,
 		create.SchemaPathTemplate,
@@ -424,6 +431,7 @@ func (s *Store) createRepositoryImpl(ctx context.Context, tx *sql.Tx, create *ap
 		&repository.FullPath,
 		&repository.WebURL,
 		&repository.BranchFilter,
+		&repository.BranchEnvironmentList,
 		&repository.BaseDirectory,
 		&repository.FilePathTemplate,
 		&repository.SchemaPathTemplate,
@@ -473,6 +481,7 @@ func findRepositoryImpl(ctx context.Context, tx *sql.Tx, find *api.RepositoryFin
 			full_path,
 			web_url,
 			branch_filter,
+			branch_environment_list,
 			base_directory,
 			file_path_template,
 			schema_path_template,
@@ -510,6 +519,7 @@ func findRepositoryImpl(ctx context.Context, tx *sql.Tx, find *api.RepositoryFin
 			&repository.FullPath,
 			&repository.WebURL,
 			&repository.BranchFilter,
+			&repository.BranchEnvironmentList,
 			&repository.BaseDirectory,
 			&repository.FilePathTemplate,
 			&repository.SchemaPathTemplate,
@@ -542,6 +552,9 @@ func patchRepositoryImpl(ctx context.Context, tx *sql.Tx, patch *api.RepositoryP
 	if v := patch.BranchFilter; v != nil {
 		set, args = append(set, fmt.Sprintf("branch_filter = $%d", len(args)+1)), append(args, *v)
 	}
+	if v := patch.BranchEnvironmentList; v != nil {
+		set, args = append(set, fmt.Sprintf("branch_environment_list = $%d", len(args)+1)), append(args, *v)
+	}
 	if v := patch.BaseDirectory; v != nil {
 		set, args = append(set, fmt.Sprintf("base_directory = $%d", len(args)+1)), append(args, *v)
 	}
@@ -572,7 +585,7 @@ func patchRepositoryImpl(ctx context.Context, tx *sql.Tx, patch *api.RepositoryP
 		UPDATE repository
 		SET `+strings.Join(set, ", ")+`
 		WHERE id = $%d
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, branch_filter, base_directory, file_path_template, schema_path_template, sheet_path_template, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, branch_filter, branch_environment_list, base_directory, file_path_template, schema_path_template, sheet_path_template, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
 		`, len(args)),
 		args...,
 	).Scan(
@@ -587,6 +600,7 @@ func patchRepositoryImpl(ctx context.Context, tx *sql.Tx, patch *api.RepositoryP
 		&repository.FullPath,
 		&repository.WebURL,
 		&repository.BranchFilter,
+		&repository.BranchEnvironmentList,
 		&repository.BaseDirectory,
 		&repository.FilePathTemplate,
 		&repository.SchemaPathTemplate,
@@ -488,6 +488,9 @@ func (s *Store) findTaskImpl(ctx context.Context, tx *sql.Tx, find *api.TaskFind
 	if v := find.StageID; v != nil {
 		where, args = append(where, fmt.Sprintf("stage_id = $%d", len(args)+1)), append(args, *v)
 	}
+	if v := find.DatabaseID; v != nil {
+		where, args = append(where, fmt.Sprintf("database_id = $%d", len(args)+1)), append(args, *v)
+	}
 	if v := find.StatusList; v != nil {
 		list := []string{}
 		for _, status := range *v {
@@ -659,6 +662,9 @@ func (s *Store) patchTaskStatusImpl(ctx context.Context, tx *sql.Tx, patch *api.
 				Type:      taskRawObj.Type,
 				Payload:   taskRawObj.Payload,
 			}
+			if patch.Result != nil {
+				taskRunCreate.Result = *patch.Result
+			}
 			// insert a running taskRun
 			if _, err := s.createTaskRunImpl(ctx, tx, taskRunCreate); err != nil {
 				return nil, err
@@ -60,11 +60,34 @@ func (raw *taskRunRaw) toTaskRun() *api.TaskRun {
 	}
 }
 
+// PatchTaskRunStatus patches an instance of TaskRun.
+// It's used to update the result of a task run which has already ended, e.g. a canceled task run whose executor exits afterwards.
+func (s *Store) PatchTaskRunStatus(ctx context.Context, patch *api.TaskRunStatusPatch) (*api.TaskRun, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.PTx.Rollback()
+
+	taskRunRaw, err := s.patchTaskRunStatusImpl(ctx, tx.PTx, patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch TaskRun with TaskRunStatusPatch[%+v], error: %w", patch, err)
+	}
+
+	if err := tx.PTx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+	return taskRunRaw.toTaskRun(), nil
+}
+
 // createTaskRunImpl creates a new taskRun.
 func (*Store) createTaskRunImpl(ctx context.Context, tx *sql.Tx, create *api.TaskRunCreate) (*taskRunRaw, error) {
 	if create.Payload == "" {
 		create.Payload = "{}"
 	}
+	if create.Result == "" {
+		create.Result = "{}"
+	}
 	query := `
 		INSERT INTO task_run (
 			creator_id,
@@ -73,9 +96,10 @@ func (*Store) createTaskRunImpl(ctx context.Context, tx *sql.Tx, create *api.Tas
 			name,
 			status,
 			type,
+			result,
 			payload
 		)
-		VALUES ($1, $2, $3, $4, 'RUNNING', $5, $6)
+		VALUES ($1, $2, $3, $4, 'RUNNING', $5, $6, $7)
 		RETURNING id, creator_id, created_ts, updater_id, updated_ts, task_id, name, status, type, code, comment, result, payload
 	`
 	var taskRunRaw taskRunRaw
@@ -85,6 +109,7 @@ func (*Store) createTaskRunImpl(ctx context.Context, tx *sql.Tx, create *api.Tas
 		create.TaskID,
 		create.Name,
 		create.Type,
+		create.Result,
 		create.Payload,
 	).Scan(
 		&taskRunRaw.ID,
@@ -218,7 +243,7 @@ func (*Store) findTaskRunImpl(ctx context.Context, tx *sql.Tx, find *api.TaskRun
 			result,
 			payload
 		FROM task_run
-		WHERE `+strings.Join(where, " AND "),
+		WHERE `+strings.Join(where, " AND ")+` ORDER BY id ASC`,
 		args...,
 	)
 	if err != nil {
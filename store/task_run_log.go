@@ -0,0 +1,185 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+)
+
+// taskRunLogRaw is the store model for a TaskRunLog.
+// Fields have exactly the same meanings as TaskRunLog.
+type taskRunLogRaw struct {
+	ID int
+
+	// Standard fields
+	CreatedTs int64
+
+	// Related fields
+	TaskRunID int
+
+	// Domain specific fields
+	Type    api.TaskRunLogType
+	Payload string
+}
+
+// toTaskRunLog creates an instance of TaskRunLog based on the taskRunLogRaw.
+func (raw *taskRunLogRaw) toTaskRunLog() (*api.TaskRunLog, error) {
+	taskRunLog := &api.TaskRunLog{
+		ID: raw.ID,
+
+		// Standard fields
+		CreatedTs: raw.CreatedTs,
+
+		// Related fields
+		TaskRunID: raw.TaskRunID,
+
+		// Domain specific fields
+		Type: raw.Type,
+	}
+	if err := json.Unmarshal([]byte(raw.Payload), &taskRunLog.Payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task run log payload %q, error: %w", raw.Payload, err)
+	}
+	return taskRunLog, nil
+}
+
+// CreateTaskRunLog creates an instance of TaskRunLog.
+func (s *Store) CreateTaskRunLog(ctx context.Context, create *api.TaskRunLogCreate) (*api.TaskRunLog, error) {
+	taskRunLogRaw, err := s.createTaskRunLogRaw(ctx, create)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TaskRunLog with TaskRunLogCreate[%+v], error: %w", create, err)
+	}
+	return taskRunLogRaw.toTaskRunLog()
+}
+
+// FindTaskRunLog finds a list of TaskRunLog instances ordered by ID.
+func (s *Store) FindTaskRunLog(ctx context.Context, find *api.TaskRunLogFind) ([]*api.TaskRunLog, error) {
+	taskRunLogRawList, err := s.findTaskRunLogRaw(ctx, find)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find TaskRunLog list with TaskRunLogFind[%+v], error: %w", find, err)
+	}
+	var taskRunLogList []*api.TaskRunLog
+	for _, raw := range taskRunLogRawList {
+		taskRunLog, err := raw.toTaskRunLog()
+		if err != nil {
+			return nil, err
+		}
+		taskRunLogList = append(taskRunLogList, taskRunLog)
+	}
+	return taskRunLogList, nil
+}
+
+//
+// private functions
+//
+
+func (s *Store) createTaskRunLogRaw(ctx context.Context, create *api.TaskRunLogCreate) (*taskRunLogRaw, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.PTx.Rollback()
+
+	taskRunLog, err := createTaskRunLogImpl(ctx, tx.PTx, create)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.PTx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+	return taskRunLog, nil
+}
+
+func (s *Store) findTaskRunLogRaw(ctx context.Context, find *api.TaskRunLogFind) ([]*taskRunLogRaw, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.PTx.Rollback()
+
+	list, err := findTaskRunLogImpl(ctx, tx.PTx, find)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func createTaskRunLogImpl(ctx context.Context, tx *sql.Tx, create *api.TaskRunLogCreate) (*taskRunLogRaw, error) {
+	payload, err := json.Marshal(create.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task run log payload, error: %w", err)
+	}
+	query := `
+		INSERT INTO task_run_log (
+			task_run_id,
+			type,
+			payload
+		)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_ts, task_run_id, type, payload
+	`
+	var taskRunLogRaw taskRunLogRaw
+	if err := tx.QueryRowContext(ctx, query,
+		create.TaskRunID,
+		create.Type,
+		string(payload),
+	).Scan(
+		&taskRunLogRaw.ID,
+		&taskRunLogRaw.CreatedTs,
+		&taskRunLogRaw.TaskRunID,
+		&taskRunLogRaw.Type,
+		&taskRunLogRaw.Payload,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, common.FormatDBErrorEmptyRowWithQuery(query)
+		}
+		return nil, FormatError(err)
+	}
+	return &taskRunLogRaw, nil
+}
+
+func findTaskRunLogImpl(ctx context.Context, tx *sql.Tx, find *api.TaskRunLogFind) ([]*taskRunLogRaw, error) {
+	where, args := []string{"task_run_id = $1"}, []interface{}{find.TaskRunID}
+	if v := find.AfterID; v != nil {
+		where, args = append(where, fmt.Sprintf("id > $%d", len(args)+1)), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			created_ts,
+			task_run_id,
+			type,
+			payload
+		FROM task_run_log
+		WHERE `+strings.Join(where, " AND ")+` ORDER BY id ASC`,
+		args...,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	var taskRunLogRawList []*taskRunLogRaw
+	for rows.Next() {
+		var taskRunLogRaw taskRunLogRaw
+		if err := rows.Scan(
+			&taskRunLogRaw.ID,
+			&taskRunLogRaw.CreatedTs,
+			&taskRunLogRaw.TaskRunID,
+			&taskRunLogRaw.Type,
+			&taskRunLogRaw.Payload,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+		taskRunLogRawList = append(taskRunLogRawList, &taskRunLogRaw)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, FormatError(err)
+	}
+	return taskRunLogRawList, nil
+}